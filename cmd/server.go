@@ -19,12 +19,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/moby/moby/pkg/fileutils"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/spf13/cobra"
@@ -47,6 +49,7 @@ const (
 	AtlantisURLFlag            = "atlantis-url"
 	AutomergeFlag              = "automerge"
 	AutoplanFileListFlag       = "autoplan-file-list"
+	AutoplanModulesFlag        = "autoplan-modules"
 	BitbucketBaseURLFlag       = "bitbucket-base-url"
 	BitbucketTokenFlag         = "bitbucket-token"
 	BitbucketUserFlag          = "bitbucket-user"
@@ -54,15 +57,25 @@ const (
 	ConfigFlag                 = "config"
 	CheckoutStrategyFlag       = "checkout-strategy"
 	DataDirFlag                = "data-dir"
+	DefaultTFDistributionFlag  = "default-tf-distribution"
 	DefaultTFVersionFlag       = "default-tf-version"
 	DisableApplyAllFlag        = "disable-apply-all"
 	DisableApplyFlag           = "disable-apply"
 	DisableAutoplanFlag        = "disable-autoplan"
 	DisableMarkdownFoldingFlag = "disable-markdown-folding"
 	DisableRepoLockingFlag     = "disable-repo-locking"
+	DriftDetectionIntervalFlag = "drift-detection-interval"
+	EnableGitMirrorsFlag       = "enable-git-mirrors"
+	EnableGithubChecksFlag     = "enable-github-checks"
+	EnableLockQueueFlag        = "enable-lock-queue"
 	EnablePolicyChecksFlag     = "enable-policy-checks"
 	EnableRegExpCmdFlag        = "enable-regexp-cmd"
+	EnableSparseCheckoutFlag   = "enable-sparse-checkout"
 	EnableDiffMarkdownFormat   = "enable-diff-markdown-format"
+	GiteaBaseURLFlag           = "gitea-base-url"
+	GiteaTokenFlag             = "gitea-token" // nolint: gosec
+	GiteaUserFlag              = "gitea-user"
+	GiteaWebhookSecretFlag     = "gitea-webhook-secret" // nolint: gosec
 	GHHostnameFlag             = "gh-hostname"
 	GHTeamAllowlistFlag        = "gh-team-allowlist"
 	GHTokenFlag                = "gh-token"
@@ -74,16 +87,28 @@ const (
 	GHOrganizationFlag         = "gh-org"
 	GHWebhookSecretFlag        = "gh-webhook-secret" // nolint: gosec
 	GitlabHostnameFlag         = "gitlab-hostname"
+	GitlabPlanDiscussionsFlag  = "gitlab-plan-discussions"
+	GitlabRequireResolvedFlag  = "gitlab-require-resolved-plan-discussions"
 	GitlabTokenFlag            = "gitlab-token"
 	GitlabUserFlag             = "gitlab-user"
 	GitlabWebhookSecretFlag    = "gitlab-webhook-secret" // nolint: gosec
 	APISecretFlag              = "api-secret"
 	HidePrevPlanComments       = "hide-prev-plan-comments"
+	LockingDBType              = "locking-db-type"
+	LockMaxAgeFlag             = "lock-max-age"
 	LogLevelFlag               = "log-level"
 	ParallelPoolSize           = "parallel-pool-size"
 	StatsNamespace             = "stats-namespace"
 	AllowDraftPRs              = "allow-draft-prs"
+	PolicyEngineFlag           = "policy-engine"
 	PortFlag                   = "port"
+	RedisHost                  = "redis-host"
+	RedisPassword              = "redis-password"
+	RedisPort                  = "redis-port"
+	RedisDB                    = "redis-db"
+	RedisTLSEnabled            = "redis-tls-enabled"
+	RedisInsecureSkipVerify    = "redis-insecure-skip-verify"
+	RedisLockTTL               = "redis-lock-ttl"
 	RepoConfigFlag             = "repo-config"
 	RepoConfigJSONFlag         = "repo-config-json"
 	// RepoWhitelistFlag is deprecated for RepoAllowlistFlag.
@@ -102,6 +127,7 @@ const (
 	SSLCertFileFlag            = "ssl-cert-file"
 	SSLKeyFileFlag             = "ssl-key-file"
 	TFDownloadURLFlag          = "tf-download-url"
+	TofuDownloadURLFlag        = "tofu-download-url"
 	VarFileAllowlistFlag       = "var-file-allowlist"
 	VCSStatusName              = "vcs-status-name"
 	TFEHostnameFlag            = "tfe-hostname"
@@ -120,14 +146,21 @@ const (
 	DefaultCheckoutStrategy = "branch"
 	DefaultBitbucketBaseURL = bitbucketcloud.BaseURL
 	DefaultDataDir          = "~/.atlantis"
+	DefaultDriftInterval    = "24h"
 	DefaultGHHostname       = "github.com"
 	DefaultGitlabHostname   = "gitlab.com"
+	DefaultLockingDBType    = "boltdb"
 	DefaultLogLevel         = "info"
 	DefaultParallelPoolSize = 15
+	DefaultPolicyEngine     = "conftest"
 	DefaultStatsNamespace   = "atlantis"
 	DefaultPort             = 4141
+	DefaultRedisDB          = 0
+	DefaultRedisPort        = 6379
+	DefaultTFDistribution   = "terraform"
 	DefaultTFDownloadURL    = "https://releases.hashicorp.com"
 	DefaultTFEHostname      = "app.terraform.io"
+	DefaultTofuDownloadURL  = "https://github.com/opentofu/opentofu/releases/download"
 	DefaultVCSStatusName    = "atlantis"
 	DefaultWebBasicAuth     = false
 	DefaultWebUsername      = "atlantis"
@@ -201,6 +234,27 @@ var stringFlags = map[string]stringFlag{
 		description:  "Path to directory to store Atlantis data.",
 		defaultValue: DefaultDataDir,
 	},
+	DriftDetectionIntervalFlag: {
+		description: "How often to check the repos that have 'drift_detection' configured in the server-side repo config for drift, ex. '24h'." +
+			" Their projects are planned on the default branch and the ones with changes are reported as drifted.",
+		defaultValue: DefaultDriftInterval,
+	},
+	GiteaBaseURLFlag: {
+		description: "Base URL of your Gitea or Forgejo installation, ex. 'https://gitea.example.com'." +
+			" Must include 'http://' or 'https://'. Required if --" + GiteaUserFlag + " is set.",
+	},
+	GiteaUserFlag: {
+		description: "Gitea username of API user.",
+	},
+	GiteaTokenFlag: {
+		description: "Gitea access token of API user. Can also be specified via the ATLANTIS_GITEA_TOKEN environment variable.",
+	},
+	GiteaWebhookSecretFlag: {
+		description: "Optional secret used to validate Gitea webhooks." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Gitea. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
+			"Should be specified via the ATLANTIS_GITEA_WEBHOOK_SECRET environment variable.",
+	},
 	GHHostnameFlag: {
 		description:  "Hostname of your Github Enterprise installation. If using github.com, no need to set.",
 		defaultValue: DefaultGHHostname,
@@ -263,14 +317,42 @@ var stringFlags = map[string]stringFlag{
 	APISecretFlag: {
 		description: "Secret to validate requests made to the API",
 	},
+	LockingDBType: {
+		description: "The locking database type to use for storing plan and apply locks." +
+			" Either 'boltdb' (default), which stores them in a file in --" + DataDirFlag + "," +
+			" or 'redis', which stores them in a Redis-compatible server shared by multiple Atlantis instances.",
+		defaultValue: DefaultLockingDBType,
+	},
+	LockMaxAgeFlag: {
+		description: "Maximum time a project lock can be held, ex. '168h'. Expired locks are released automatically," +
+			" their plans are deleted and the pull request is commented on." +
+			" Can be overridden per repo with 'lock_max_age' in the server-side repo config. If not set, locks never expire.",
+	},
 	LogLevelFlag: {
 		description:  "Log level. Either debug, info, warn, or error.",
 		defaultValue: DefaultLogLevel,
 	},
+	PolicyEngineFlag: {
+		description: "Engine that evaluates policy checks." +
+			" Either 'conftest' (default), which downloads and runs the conftest binary," +
+			" or 'opa', which evaluates the policies in-process without downloading anything.",
+		defaultValue: DefaultPolicyEngine,
+	},
 	StatsNamespace: {
 		description:  "Namespace for aggregating stats.",
 		defaultValue: DefaultStatsNamespace,
 	},
+	RedisHost: {
+		description: "The Redis hostname for the locking database. Required if --" + LockingDBType + " is 'redis'.",
+	},
+	RedisLockTTL: {
+		description: "Time after which a project lock in Redis expires unless its pull request runs another command on the project, ex. '720h'." +
+			" Releases the locks of replicas that never unlock them. If not set, locks don't expire.",
+	},
+	RedisPassword: {
+		description: "The Redis password for the locking database." +
+			" Should be specified via the ATLANTIS_REDIS_PASSWORD environment variable.",
+	},
 	RepoConfigFlag: {
 		description: "Path to a repo config file, used to customize how Atlantis runs on each repo. See runatlantis.io/docs for more details.",
 	},
@@ -300,6 +382,10 @@ var stringFlags = map[string]stringFlag{
 		description:  "Base URL to download Terraform versions from.",
 		defaultValue: DefaultTFDownloadURL,
 	},
+	TofuDownloadURLFlag: {
+		description:  "Base URL to download OpenTofu versions from.",
+		defaultValue: DefaultTofuDownloadURL,
+	},
 	TFEHostnameFlag: {
 		description:  "Hostname of your Terraform Enterprise installation. If using Terraform Cloud no need to set.",
 		defaultValue: DefaultTFEHostname,
@@ -309,6 +395,11 @@ var stringFlags = map[string]stringFlag{
 			" Only set if using TFC/E as a remote backend." +
 			" Should be specified via the ATLANTIS_TFE_TOKEN environment variable for security.",
 	},
+	DefaultTFDistributionFlag: {
+		description: "Distribution of Terraform to default to. Accepts either 'terraform' (default) or 'opentofu'." +
+			" Projects can override it with the distribution key of the repo config.",
+		defaultValue: DefaultTFDistribution,
+	},
 	DefaultTFVersionFlag: {
 		description: "Terraform version to default to (ex. v0.12.0). Will download if not yet on disk." +
 			" If not set, Atlantis uses the terraform binary in its PATH.",
@@ -355,6 +446,10 @@ var boolFlags = map[string]boolFlag{
 		description:  "Disable all \"atlantis apply\" command regardless of which flags are passed with it.",
 		defaultValue: false,
 	},
+	AutoplanModulesFlag: {
+		description:  "Also plan the projects that use a modified local module, directly or through other modules. Modules are found by parsing the module blocks of the projects.",
+		defaultValue: false,
+	},
 	DisableAutoplanFlag: {
 		description:  "Disable atlantis auto planning feature",
 		defaultValue: false,
@@ -362,6 +457,18 @@ var boolFlags = map[string]boolFlag{
 	DisableRepoLockingFlag: {
 		description: "Disable atlantis locking repos",
 	},
+	EnableGithubChecksFlag: {
+		description:  "Create GitHub check runs instead of commit statuses for repos on the GitHub host. Project check runs show the output of plans and applies and have buttons to apply and unlock. Requires a GitHub App (--" + GHAppIDFlag + ").",
+		defaultValue: false,
+	},
+	EnableGitMirrorsFlag: {
+		description:  "Keep a bare mirror of each repo in the data dir that is fetched incrementally and referenced by the clones of pull requests so that they don't download and store the whole repo. Mirrors of repos that are no longer seen are deleted.",
+		defaultValue: false,
+	},
+	EnableLockQueueFlag: {
+		description:  "Queue pull requests that fail to lock a project because it is locked by another pull request. Once the lock is released, it's given to the first pull request in the queue, which is then re-planned automatically.",
+		defaultValue: false,
+	},
 	EnablePolicyChecksFlag: {
 		description:  "Enable atlantis to run user defined policy checks.  This is explicitly disabled for TFE/TFC backends since plan files are inaccessible.",
 		defaultValue: false,
@@ -370,6 +477,18 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enable Atlantis to use regular expressions on plan/apply commands when \"-p\" flag is passed with it.",
 		defaultValue: false,
 	},
+	EnableSparseCheckoutFlag: {
+		description:  "Only check out the projects modified by a pull request, the files in the root of the repo and the dirs the projects' when_modified patterns point to. The whole repo is checked out for commands on dirs that aren't projects in atlantis.yaml.",
+		defaultValue: false,
+	},
+	RedisTLSEnabled: {
+		description:  "Enable TLS on the connection to the Redis server.",
+		defaultValue: false,
+	},
+	RedisInsecureSkipVerify: {
+		description:  "Skip verification of the Redis server's TLS certificate. Only applies if --" + RedisTLSEnabled + " is set.",
+		defaultValue: false,
+	},
 	EnableDiffMarkdownFormat: {
 		description:  "Enable Atlantis to format Terraform plan output into a markdown-diff friendly format for color-coding purposes.",
 		defaultValue: false,
@@ -378,6 +497,14 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enable autoplan for Github Draft Pull Requests",
 		defaultValue: false,
 	},
+	GitlabPlanDiscussionsFlag: {
+		description:  "Post the plan of each project on GitLab merge requests as a resolvable discussion that is updated on re-plan and resolved once the project is applied, instead of as new comments. Unresolved plan discussions don't count when checking if the merge request is mergeable unless --" + GitlabRequireResolvedFlag + " is set.",
+		defaultValue: false,
+	},
+	GitlabRequireResolvedFlag: {
+		description:  "Require the plan discussions of GitLab merge requests to be resolved for them to be mergeable. Requires --" + GitlabPlanDiscussionsFlag + ".",
+		defaultValue: false,
+	},
 	HidePrevPlanComments: {
 		description: "Hide previous plan comments to reduce clutter in the PR. " +
 			"VCS support is limited to: GitHub.",
@@ -445,6 +572,14 @@ var intFlags = map[string]intFlag{
 		description:  "Port to bind to.",
 		defaultValue: DefaultPort,
 	},
+	RedisDB: {
+		description:  "The Redis database number for the locking database.",
+		defaultValue: DefaultRedisDB,
+	},
+	RedisPort: {
+		description:  "The Redis port for the locking database.",
+		defaultValue: DefaultRedisPort,
+	},
 }
 
 var int64Flags = map[string]int64Flag{
@@ -658,6 +793,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig) {
 	if c.DataDir == "" {
 		c.DataDir = DefaultDataDir
 	}
+	if c.DriftDetectionInterval == "" {
+		c.DriftDetectionInterval = DefaultDriftInterval
+	}
 	if c.GithubHostname == "" {
 		c.GithubHostname = DefaultGHHostname
 	}
@@ -667,21 +805,36 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig) {
 	if c.BitbucketBaseURL == "" {
 		c.BitbucketBaseURL = DefaultBitbucketBaseURL
 	}
+	if c.LockingDBType == "" {
+		c.LockingDBType = DefaultLockingDBType
+	}
 	if c.LogLevel == "" {
 		c.LogLevel = DefaultLogLevel
 	}
 	if c.ParallelPoolSize == 0 {
 		c.ParallelPoolSize = DefaultParallelPoolSize
 	}
+	if c.PolicyEngine == "" {
+		c.PolicyEngine = DefaultPolicyEngine
+	}
 	if c.StatsNamespace == "" {
 		c.StatsNamespace = DefaultStatsNamespace
 	}
 	if c.Port == 0 {
 		c.Port = DefaultPort
 	}
+	if c.RedisPort == 0 {
+		c.RedisPort = DefaultRedisPort
+	}
 	if c.TFDownloadURL == "" {
 		c.TFDownloadURL = DefaultTFDownloadURL
 	}
+	if c.TofuDownloadURL == "" {
+		c.TofuDownloadURL = DefaultTofuDownloadURL
+	}
+	if c.DefaultTFDistribution == "" {
+		c.DefaultTFDistribution = DefaultTFDistribution
+	}
 	if c.VCSStatusName == "" {
 		c.VCSStatusName = DefaultVCSStatusName
 	}
@@ -707,6 +860,52 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return errors.New("invalid checkout strategy: not one of branch or merge")
 	}
 
+	distribution := userConfig.DefaultTFDistribution
+	if distribution != "terraform" && distribution != "opentofu" {
+		return fmt.Errorf("invalid --%s: not one of terraform or opentofu", DefaultTFDistributionFlag)
+	}
+
+	lockingDBType := userConfig.LockingDBType
+	if lockingDBType != "boltdb" && lockingDBType != "redis" {
+		return fmt.Errorf("invalid --%s: not one of boltdb or redis", LockingDBType)
+	}
+	if lockingDBType == "redis" && userConfig.RedisHost == "" {
+		return fmt.Errorf("--%s must be set when --%s is redis", RedisHost, LockingDBType)
+	}
+
+	policyEngine := userConfig.PolicyEngine
+	if policyEngine != "conftest" && policyEngine != "opa" {
+		return fmt.Errorf("invalid --%s: not one of conftest or opa", PolicyEngineFlag)
+	}
+
+	if userConfig.RedisLockTTL != "" {
+		redisLockTTL, err := time.ParseDuration(userConfig.RedisLockTTL)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --%s", RedisLockTTL)
+		}
+		if redisLockTTL < 0 {
+			return fmt.Errorf("--%s must not be negative", RedisLockTTL)
+		}
+	}
+
+	if userConfig.LockMaxAge != "" {
+		lockMaxAge, err := time.ParseDuration(userConfig.LockMaxAge)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --%s", LockMaxAgeFlag)
+		}
+		if lockMaxAge < 0 {
+			return fmt.Errorf("--%s must not be negative", LockMaxAgeFlag)
+		}
+	}
+
+	driftInterval, err := time.ParseDuration(userConfig.DriftDetectionInterval)
+	if err != nil {
+		return errors.Wrapf(err, "invalid --%s", DriftDetectionIntervalFlag)
+	}
+	if driftInterval <= 0 {
+		return fmt.Errorf("--%s must be positive", DriftDetectionIntervalFlag)
+	}
+
 	if (userConfig.SSLKeyFile == "") != (userConfig.SSLCertFile == "") {
 		return fmt.Errorf("--%s and --%s are both required for ssl", SSLKeyFileFlag, SSLCertFileFlag)
 	}
@@ -717,9 +916,11 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	// 3. gitlab user and token set
 	// 4. bitbucket user and token set
 	// 5. azuredevops user and token set
-	// 6. any combination of the above
-	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GHAppIDFlag, GHAppKeyFileFlag, GHAppIDFlag, GHAppKeyFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag)
-	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "")) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) || ((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) {
+	// 6. gitea user and token set
+	// 7. hosts in the vcs-hosts config
+	// 8. any combination of the above
+	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GHAppIDFlag, GHAppKeyFileFlag, GHAppIDFlag, GHAppKeyFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag, GiteaUserFlag, GiteaTokenFlag)
+	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "")) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) || ((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) || ((userConfig.GiteaUser == "") != (userConfig.GiteaToken == "")) {
 		return vcsErr
 	}
 	if (userConfig.GithubAppID != 0) && ((userConfig.GithubAppKey == "") && (userConfig.GithubAppKeyFile == "")) {
@@ -730,10 +931,18 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	}
 	// At this point, we know that there can't be a single user/token without
 	// its partner, but we haven't checked if any user/token is set at all.
-	if userConfig.GithubAppID == 0 && userConfig.GithubUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" {
+	if userConfig.GithubAppID == 0 && userConfig.GithubUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" && userConfig.GiteaUser == "" && len(userConfig.VCSHosts) == 0 {
 		return vcsErr
 	}
 
+	if userConfig.EnableGithubChecks && userConfig.GithubAppID == 0 {
+		return fmt.Errorf("--%s requires --%s to be set because only GitHub Apps can create check runs", EnableGithubChecksFlag, GHAppIDFlag)
+	}
+
+	if userConfig.GitlabRequireResolved && !userConfig.GitlabPlanDiscussions {
+		return fmt.Errorf("--%s requires --%s to be set", GitlabRequireResolvedFlag, GitlabPlanDiscussionsFlag)
+	}
+
 	// Handle deprecation of repo whitelist.
 	if userConfig.RepoWhitelist == "" && userConfig.RepoAllowlist == "" {
 		return fmt.Errorf("--%s must be set for security purposes", RepoAllowlistFlag)
@@ -763,6 +972,23 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("--%s must have http:// or https://, got %q", BitbucketBaseURLFlag, userConfig.BitbucketBaseURL)
 	}
 
+	if userConfig.GiteaUser != "" {
+		if userConfig.GiteaBaseURL == "" {
+			return fmt.Errorf("--%s must be set if --%s is set", GiteaBaseURLFlag, GiteaUserFlag)
+		}
+		parsed, err := url.Parse(userConfig.GiteaBaseURL)
+		if err != nil {
+			return fmt.Errorf("error parsing --%s flag value %q: %s", GiteaBaseURLFlag, userConfig.GiteaBaseURL, err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("--%s must have http:// or https://, got %q", GiteaBaseURLFlag, userConfig.GiteaBaseURL)
+		}
+	}
+
+	if err := s.validateVCSHosts(userConfig); err != nil {
+		return err
+	}
+
 	if userConfig.RepoConfig != "" && userConfig.RepoConfigJSON != "" {
 		return fmt.Errorf("cannot use --%s and --%s at the same time", RepoConfigFlag, RepoConfigJSONFlag)
 	}
@@ -775,11 +1001,18 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		GitlabWebhookSecretFlag:    userConfig.GitlabWebhookSecret,
 		BitbucketTokenFlag:         userConfig.BitbucketToken,
 		BitbucketWebhookSecretFlag: userConfig.BitbucketWebhookSecret,
+		GiteaTokenFlag:             userConfig.GiteaToken,
+		GiteaWebhookSecretFlag:     userConfig.GiteaWebhookSecret,
 	} {
 		if strings.Contains(token, "\n") {
 			s.Logger.Warn("--%s contains a newline which is usually unintentional", name)
 		}
 	}
+	for i, c := range userConfig.VCSHosts {
+		if strings.Contains(c.Token, "\n") || strings.Contains(c.WebhookSecret, "\n") {
+			s.Logger.Warn("vcs-hosts[%d] token or webhook-secret contains a newline which is usually unintentional", i)
+		}
+	}
 
 	if userConfig.TFEHostname != DefaultTFEHostname && userConfig.TFEToken == "" {
 		return fmt.Errorf("if setting --%s, must set --%s", TFEHostnameFlag, TFETokenFlag)
@@ -793,6 +1026,42 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	return nil
 }
 
+// validateVCSHosts validates the hosts in the vcs-hosts config. Each host can
+// only be configured once, either in the config or with flags.
+func (s *ServerCmd) validateVCSHosts(userConfig server.UserConfig) error {
+	var flagHostCfgs []server.VCSHostConfig
+	if userConfig.GithubUser != "" || userConfig.GithubAppID != 0 {
+		flagHostCfgs = append(flagHostCfgs, server.VCSHostConfig{Type: "github", Hostname: userConfig.GithubHostname})
+	}
+	if userConfig.GitlabUser != "" {
+		flagHostCfgs = append(flagHostCfgs, server.VCSHostConfig{Type: "gitlab", Hostname: userConfig.GitlabHostname})
+	}
+	if userConfig.GiteaUser != "" {
+		flagHostCfgs = append(flagHostCfgs, server.VCSHostConfig{Type: "gitea", BaseURL: userConfig.GiteaBaseURL})
+	}
+	configured := make(map[models.VCSHost]string)
+	for _, c := range flagHostCfgs {
+		if host, err := c.VCSHost(); err == nil {
+			configured[host] = "with flags"
+		}
+	}
+
+	for i, c := range userConfig.VCSHosts {
+		host, err := c.VCSHost()
+		if err != nil {
+			return fmt.Errorf("vcs-hosts[%d]: %s", i, err)
+		}
+		if c.User == "" || c.Token == "" {
+			return fmt.Errorf("vcs-hosts[%d]: user and token must be set", i)
+		}
+		if where, ok := configured[host]; ok {
+			return fmt.Errorf("vcs-hosts[%d]: %s host %q is already configured %s", i, c.Type, host.Hostname, where)
+		}
+		configured[host] = fmt.Sprintf("in vcs-hosts[%d]", i)
+	}
+	return nil
+}
+
 // setAtlantisURL sets the externally accessible URL for atlantis.
 func (s *ServerCmd) setAtlantisURL(userConfig *server.UserConfig) error {
 	if userConfig.AtlantisURL == "" {
@@ -843,6 +1112,10 @@ func (s *ServerCmd) trimAtSymbolFromUsers(userConfig *server.UserConfig) {
 	userConfig.GitlabUser = strings.TrimPrefix(userConfig.GitlabUser, "@")
 	userConfig.BitbucketUser = strings.TrimPrefix(userConfig.BitbucketUser, "@")
 	userConfig.AzureDevopsUser = strings.TrimPrefix(userConfig.AzureDevopsUser, "@")
+	userConfig.GiteaUser = strings.TrimPrefix(userConfig.GiteaUser, "@")
+	for i := range userConfig.VCSHosts {
+		userConfig.VCSHosts[i].User = strings.TrimPrefix(userConfig.VCSHosts[i].User, "@")
+	}
 }
 
 func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
@@ -858,6 +1131,14 @@ func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
 	if userConfig.BitbucketUser != "" && userConfig.BitbucketBaseURL == DefaultBitbucketBaseURL && !s.SilenceOutput {
 		s.Logger.Warn("Bitbucket Cloud does not support webhook secrets. This could allow attackers to spoof requests from Bitbucket. Ensure you are allowing only Bitbucket IPs")
 	}
+	if userConfig.GiteaUser != "" && userConfig.GiteaWebhookSecret == "" && !s.SilenceOutput {
+		s.Logger.Warn("no Gitea webhook secret set. This could allow attackers to spoof requests from Gitea")
+	}
+	for _, c := range userConfig.VCSHosts {
+		if host, err := c.VCSHost(); err == nil && c.WebhookSecret == "" && !s.SilenceOutput {
+			s.Logger.Warn("no webhook secret set for %s host %q in vcs-hosts. This could allow attackers to spoof requests from it", c.Type, host.Hostname)
+		}
+	}
 	if userConfig.AzureDevopsWebhookUser != "" && userConfig.AzureDevopsWebhookPassword == "" && !s.SilenceOutput {
 		s.Logger.Warn("no Azure DevOps webhook user and password set. This could allow attackers to spoof requests from Azure DevOps.")
 	}
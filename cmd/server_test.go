@@ -60,17 +60,24 @@ var testFlags = map[string]interface{}{
 	AllowRepoConfigFlag:        true,
 	AutomergeFlag:              true,
 	AutoplanFileListFlag:       "**/*.tf,**/*.yml",
+	AutoplanModulesFlag:        true,
 	BitbucketBaseURLFlag:       "https://bitbucket-base-url.com",
 	BitbucketTokenFlag:         "bitbucket-token",
 	BitbucketUserFlag:          "bitbucket-user",
 	BitbucketWebhookSecretFlag: "bitbucket-secret",
 	CheckoutStrategyFlag:       "merge",
 	DataDirFlag:                "/path",
+	DefaultTFDistributionFlag:  "opentofu",
 	DefaultTFVersionFlag:       "v0.11.0",
 	DisableApplyAllFlag:        true,
 	DisableApplyFlag:           true,
 	DisableMarkdownFoldingFlag: true,
+	DriftDetectionIntervalFlag: "12h",
 	DisableRepoLockingFlag:     true,
+	GiteaBaseURLFlag:           "https://gitea.example.com",
+	GiteaTokenFlag:             "gitea-token",
+	GiteaUserFlag:              "gitea-user",
+	GiteaWebhookSecretFlag:     "gitea-secret",
 	GHHostnameFlag:             "ghhostname",
 	GHTokenFlag:                "token",
 	GHUserFlag:                 "user",
@@ -82,13 +89,25 @@ var testFlags = map[string]interface{}{
 	GHWebhookSecretFlag:        "secret",
 	GitlabHostnameFlag:         "gitlab-hostname",
 	GitlabTokenFlag:            "gitlab-token",
+	GitlabPlanDiscussionsFlag:  true,
+	GitlabRequireResolvedFlag:  true,
 	GitlabUserFlag:             "gitlab-user",
 	GitlabWebhookSecretFlag:    "gitlab-secret",
+	LockingDBType:              "boltdb",
+	LockMaxAgeFlag:             "168h",
 	LogLevelFlag:               "debug",
 	StatsNamespace:             "atlantis",
 	AllowDraftPRs:              true,
+	PolicyEngineFlag:           "opa",
 	PortFlag:                   8181,
 	ParallelPoolSize:           100,
+	RedisDB:                    0,
+	RedisHost:                  "redis.localhost",
+	RedisInsecureSkipVerify:    false,
+	RedisLockTTL:               "720h",
+	RedisPassword:              "redis-pass",
+	RedisPort:                  6380,
+	RedisTLSEnabled:            false,
 	RepoAllowlistFlag:          "github.com/runatlantis/atlantis",
 	RequireApprovalFlag:        true,
 	RequireMergeableFlag:       true,
@@ -101,14 +120,19 @@ var testFlags = map[string]interface{}{
 	SSLCertFileFlag:            "cert-file",
 	SSLKeyFileFlag:             "key-file",
 	TFDownloadURLFlag:          "https://my-hostname.com",
+	TofuDownloadURLFlag:        "https://my-tofu-hostname.com",
 	TFEHostnameFlag:            "my-hostname",
 	TFELocalExecutionModeFlag:  true,
 	TFETokenFlag:               "my-token",
 	VCSStatusName:              "my-status",
 	WriteGitCredsFlag:          true,
 	DisableAutoplanFlag:        true,
+	EnableGitMirrorsFlag:       true,
+	EnableGithubChecksFlag:     false,
+	EnableLockQueueFlag:        true,
 	EnablePolicyChecksFlag:     false,
 	EnableRegExpCmdFlag:        false,
+	EnableSparseCheckoutFlag:   true,
 	EnableDiffMarkdownFormat:   false,
 }
 
@@ -306,6 +330,78 @@ func TestExecute_ValidateCheckoutStrategy(t *testing.T) {
 	ErrEquals(t, "invalid checkout strategy: not one of branch or merge", err)
 }
 
+func TestExecute_ValidateLockingDBType(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		LockingDBType: "invalid",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --locking-db-type: not one of boltdb or redis", err)
+}
+
+func TestExecute_ValidatePolicyEngine(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		PolicyEngineFlag: "invalid",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --policy-engine: not one of conftest or opa", err)
+}
+
+func TestExecute_ValidateRedisHost(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		LockingDBType: "redis",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--redis-host must be set when --locking-db-type is redis", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		LockingDBType: "redis",
+		RedisHost:     "redis.localhost",
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_ValidateRedisLockTTL(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		RedisLockTTL: "30 days",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --redis-lock-ttl: time: unknown unit \" days\" in duration \"30 days\"", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		RedisLockTTL: "-1h",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--redis-lock-ttl must not be negative", err)
+}
+
+func TestExecute_ValidateLockMaxAge(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		LockMaxAgeFlag: "2 days",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --lock-max-age: time: unknown unit \" days\" in duration \"2 days\"", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		LockMaxAgeFlag: "-1h",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--lock-max-age must not be negative", err)
+}
+
+func TestExecute_ValidateDriftDetectionInterval(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		DriftDetectionIntervalFlag: "1 day",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --drift-detection-interval: time: unknown unit \" day\" in duration \"1 day\"", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		DriftDetectionIntervalFlag: "0s",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--drift-detection-interval must be positive", err)
+}
+
 func TestExecute_ValidateSSLConfig(t *testing.T) {
 	expErr := "--ssl-key-file and --ssl-cert-file are both required for ssl"
 	cases := []struct {
@@ -355,7 +451,7 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 }
 
 func TestExecute_ValidateVCSConfig(t *testing.T) {
-	expErr := "--gh-user/--gh-token or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token must be set"
+	expErr := "--gh-user/--gh-token or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token or --gitea-user/--gitea-token must be set"
 	cases := []struct {
 		description string
 		flags       map[string]interface{}
@@ -394,6 +490,13 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea token set",
+			map[string]interface{}{
+				GiteaTokenFlag: "token",
+			},
+			true,
+		},
 		{
 			"just github user set",
 			map[string]interface{}{
@@ -443,6 +546,14 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea user set",
+			map[string]interface{}{
+				GiteaUserFlag:    "user",
+				GiteaBaseURLFlag: "https://gitea.example.com",
+			},
+			true,
+		},
 		{
 			"github user and gitlab token set",
 			map[string]interface{}{
@@ -515,6 +626,15 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"gitea user and gitea token set and should be successful",
+			map[string]interface{}{
+				GiteaUserFlag:    "user",
+				GiteaTokenFlag:   "token",
+				GiteaBaseURLFlag: "https://gitea.example.com",
+			},
+			false,
+		},
 		{
 			"all set should be successful",
 			map[string]interface{}{
@@ -526,6 +646,9 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 				BitbucketTokenFlag: "token",
 				ADUserFlag:         "user",
 				ADTokenFlag:        "token",
+				GiteaUserFlag:      "user",
+				GiteaTokenFlag:     "token",
+				GiteaBaseURLFlag:   "https://gitea.example.com",
 			},
 			false,
 		},
@@ -602,6 +725,27 @@ func TestExecute_GithubApp(t *testing.T) {
 	Equals(t, int64(1), passedConfig.GithubAppID)
 }
 
+func TestExecute_EnableGithubChecks(t *testing.T) {
+	t.Log("Should require a GitHub App for check runs.")
+	c := setup(map[string]interface{}{
+		GHUserFlag:             "user",
+		GHTokenFlag:            "token",
+		EnableGithubChecksFlag: true,
+		RepoAllowlistFlag:      "*",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--enable-github-checks requires --gh-app-id to be set because only GitHub Apps can create check runs", err)
+
+	c = setup(map[string]interface{}{
+		GHAppKeyFlag:           fixtures.GithubPrivateKey,
+		GHAppIDFlag:            "1",
+		EnableGithubChecksFlag: true,
+		RepoAllowlistFlag:      "*",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, true, passedConfig.EnableGithubChecks)
+}
+
 func TestExecute_GitlabUser(t *testing.T) {
 	t.Log("Should remove the @ from the gitlab username if it's passed.")
 	c := setup(map[string]interface{}{
@@ -615,6 +759,18 @@ func TestExecute_GitlabUser(t *testing.T) {
 	Equals(t, "user", passedConfig.GitlabUser)
 }
 
+func TestExecute_GitlabRequireResolved(t *testing.T) {
+	t.Log("Should require plan discussions for resolved plan discussions to be required.")
+	c := setup(map[string]interface{}{
+		GitlabUserFlag:            "user",
+		GitlabTokenFlag:           "token",
+		GitlabRequireResolvedFlag: true,
+		RepoAllowlistFlag:         "*",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--gitlab-require-resolved-plan-discussions requires --gitlab-plan-discussions to be set", err)
+}
+
 func TestExecute_BitbucketUser(t *testing.T) {
 	t.Log("Should remove the @ from the bitbucket username if it's passed.")
 	c := setup(map[string]interface{}{
@@ -641,6 +797,107 @@ func TestExecute_ADUser(t *testing.T) {
 	Equals(t, "user", passedConfig.AzureDevopsUser)
 }
 
+func TestExecute_GiteaUser(t *testing.T) {
+	t.Log("Should remove the @ from the gitea username if it's passed.")
+	c := setup(map[string]interface{}{
+		GiteaUserFlag:     "@user",
+		GiteaTokenFlag:    "token",
+		GiteaBaseURLFlag:  "https://gitea.example.com",
+		RepoAllowlistFlag: "*",
+	}, t)
+	err := c.Execute()
+	Ok(t, err)
+
+	Equals(t, "user", passedConfig.GiteaUser)
+}
+
+func TestExecute_GiteaBaseURL(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GiteaUserFlag:     "user",
+		GiteaTokenFlag:    "token",
+		RepoAllowlistFlag: "*",
+	}, t)
+	ErrEquals(t, "--gitea-base-url must be set if --gitea-user is set", c.Execute())
+
+	c = setup(map[string]interface{}{
+		GiteaUserFlag:     "user",
+		GiteaTokenFlag:    "token",
+		RepoAllowlistFlag: "*",
+		GiteaBaseURLFlag:  "gitea.example.com",
+	}, t)
+	ErrEquals(t, "--gitea-base-url must have http:// or https://, got \"gitea.example.com\"", c.Execute())
+}
+
+func TestExecute_VCSHosts(t *testing.T) {
+	tmpFile := tempFile(t, `
+vcs-hosts:
+- type: github
+  hostname: github.example.com
+  user: "@ghe-user"
+  token: ghe-token
+  webhook-secret: ghe-secret
+- type: gitea
+  base-url: https://gitea.example.com
+  user: gitea-user
+  token: gitea-token
+`)
+	defer os.Remove(tmpFile) // nolint: errcheck
+	c := setup(map[string]interface{}{
+		ConfigFlag:        tmpFile,
+		RepoAllowlistFlag: "*",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, []server.VCSHostConfig{
+		{
+			Type:          "github",
+			Hostname:      "github.example.com",
+			User:          "ghe-user",
+			Token:         "ghe-token",
+			WebhookSecret: "ghe-secret",
+		},
+		{
+			Type:    "gitea",
+			BaseURL: "https://gitea.example.com",
+			User:    "gitea-user",
+			Token:   "gitea-token",
+		},
+	}, passedConfig.VCSHosts)
+}
+
+func TestExecute_VCSHostsErrs(t *testing.T) {
+	cases := map[string]struct {
+		config string
+		expErr string
+	}{
+		"unsupported type": {
+			"- {type: bitbucket, hostname: bitbucket.org, user: user, token: token}",
+			"vcs-hosts[0]: type \"bitbucket\" is not supported, must be one of github, gitlab or gitea",
+		},
+		"missing token": {
+			"- {type: gitlab, hostname: gitlab.example.com, user: user}",
+			"vcs-hosts[0]: user and token must be set",
+		},
+		"configured with flags": {
+			"- {type: github, hostname: github.com, user: user, token: token}",
+			"vcs-hosts[0]: github host \"github.com\" is already configured with flags",
+		},
+		"configured twice": {
+			"- {type: gitlab, hostname: gitlab.example.com, user: user, token: token}\n- {type: gitlab, hostname: https://gitlab.example.com, user: user, token: token}",
+			"vcs-hosts[1]: gitlab host \"gitlab.example.com\" is already configured in vcs-hosts[0]",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpFile := tempFile(t, "vcs-hosts:\n"+c.config+"\n")
+			defer os.Remove(tmpFile) // nolint: errcheck
+			cmd := setupWithDefaults(map[string]interface{}{
+				ConfigFlag: tmpFile,
+			}, t)
+			ErrEquals(t, c.expErr, cmd.Execute())
+		})
+	}
+}
+
 // If using bitbucket cloud, webhook secrets are not supported.
 func TestExecute_BitbucketCloudWithWebhookSecret(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -64,9 +64,11 @@ module.exports = {
                         ['configuring-atlantis', 'Overview'],
                         'server-configuration',
                         'server-side-repo-config',
+                        'multiple-vcs-hosts',
                         'pre-workflow-hooks',
                         'post-workflow-hooks',
                         'policy-checking',
+                        'drift-detection',
                         'custom-workflows',
                         'repo-level-atlantis-yaml',
                         'upgrading-atlantis-yaml',
@@ -74,14 +76,17 @@ module.exports = {
                         'checkout-strategy',
                         'terraform-versions',
                         'terraform-cloud',
-                        'using-slack-hooks'
+                        'using-slack-hooks',
+                        'using-http-hooks',
+                        'using-teams-and-mattermost-hooks'
                     ]
                 },
                 {
                     title: 'Using Atlantis',
                     collapsable: true,
                     children: [
-                        ['using-atlantis', 'Overview']
+                        ['using-atlantis', 'Overview'],
+                        'api-endpoints'
                     ]
                 },
                 {
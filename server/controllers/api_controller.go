@@ -1,27 +1,55 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/scheduled"
 	"github.com/uber-go/tally"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 const atlantisTokenHeader = "X-Atlantis-Token"
 
+// APIUser is the user that commands run through the API are run as when the
+// request is authenticated with the API secret instead of an API token.
+const APIUser = "atlantis-api"
+
+// apiWorkingDirLockPath is the path that API requests lock their repo's
+// working dir under, see apiStart. Commands lock the dirs of their projects
+// themselves, which are relative to the repo, so it can't be one of them.
+const apiWorkingDirLockPath = "/"
+
 type APIController struct {
 	APISecret                 []byte
+	APITokens                 []valid.APIToken
 	Locker                    locking.Locker
+	LockQueue                 locking.LockQueue
+	ApplyLocker               locking.ApplyLocker
+	CommandRunner             events.APICommandRunner
+	DeleteLockCommand         events.DeleteLockCommand
+	DB                        locking.Backend
+	DriftStore                scheduled.DriftStore
+	Drainer                   *events.Drainer
+	Jobs                      *APIJobs
+	JobURLGenerator           jobs.ProjectJobURLGenerator
 	Logger                    logging.SimpleLogging
 	Parser                    events.EventParsing
 	ProjectCommandBuilder     events.ProjectCommandBuilder
@@ -30,17 +58,29 @@ type APIController struct {
 	RepoAllowlistChecker      *events.RepoAllowlistChecker
 	Scope                     tally.Scope
 	VCSClient                 vcs.Client
+	WorkingDirLocker          events.WorkingDirLocker
 }
 
 type APIRequest struct {
 	Repository string `validate:"required"`
-	Ref        string `validate:"required"`
+	Ref        string `validate:"required_without=PullNum"`
 	Type       string `validate:"required"`
-	Projects   []string
-	Paths      []struct {
+	// PullNum, if set, runs the commands against that pull request the same
+	// way as if they had been commented on it.
+	PullNum  int
+	Projects []string
+	Paths    []struct {
 		Directory string
 		Workspace string
 	}
+	// Async makes the request return immediately instead of waiting for the
+	// commands to finish. Without PullNum, it returns a job for each project.
+	Async bool
+}
+
+// APIJobsResponse is the response to an asynchronous plan or apply request.
+type APIJobsResponse struct {
+	Jobs []command.APIJob
 }
 
 func (a *APIRequest) getCommands(ctx *command.Context, cmdBuilder func(*command.Context, *events.CommentCommand) ([]command.ProjectContext, error)) ([]command.ProjectContext, error) {
@@ -70,6 +110,23 @@ func (a *APIRequest) getCommands(ctx *command.Context, cmdBuilder func(*command.
 	return cmds, nil
 }
 
+// getCommentCommands returns the comment commands to run against a pull
+// request. If no projects or paths were requested, it returns a single
+// command for all the projects, like commenting without any flags.
+func (a *APIRequest) getCommentCommands(name command.Name) []*events.CommentCommand {
+	var cc []*events.CommentCommand
+	for _, project := range a.Projects {
+		cc = append(cc, events.NewCommentCommand("", nil, name, false, false, "", project, ""))
+	}
+	for _, path := range a.Paths {
+		cc = append(cc, events.NewCommentCommand(strings.TrimRight(path.Directory, "/"), nil, name, false, false, path.Workspace, "", ""))
+	}
+	if len(cc) == 0 {
+		cc = append(cc, events.NewCommentCommand("", nil, name, false, false, "", "", ""))
+	}
+	return cc
+}
+
 func (a *APIController) apiReportError(w http.ResponseWriter, code int, err error) {
 	response, _ := json.Marshal(map[string]string{
 		"error": err.Error(),
@@ -80,18 +137,31 @@ func (a *APIController) apiReportError(w http.ResponseWriter, code int, err erro
 func (a *APIController) Plan(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	request, ctx, code, err := a.apiParseAndValidate(r)
+	request, ctx, code, err := a.apiParseAndValidate(r, command.Plan)
 	if err != nil {
 		a.apiReportError(w, code, err)
 		return
 	}
+	if request.PullNum != 0 {
+		a.apiRunPullCommand(w, request, ctx, command.Plan)
+		return
+	}
+	done, ok := a.apiStart(w, ctx)
+	if !ok {
+		return
+	}
+	if request.Async {
+		a.apiPlanAsync(w, request, ctx, done)
+		return
+	}
+	defer done()
 
-	result, err := a.apiPlan(request, ctx)
+	result, cmds, err := a.apiPlan(request, ctx)
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, 0) // nolint: errcheck
+	defer a.apiUnlock(ctx, cmds)
 	if result.HasErrors() {
 		code = http.StatusInternalServerError
 	}
@@ -108,19 +178,32 @@ func (a *APIController) Plan(w http.ResponseWriter, r *http.Request) {
 func (a *APIController) Apply(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	request, ctx, code, err := a.apiParseAndValidate(r)
+	request, ctx, code, err := a.apiParseAndValidate(r, command.Apply)
 	if err != nil {
 		a.apiReportError(w, code, err)
 		return
 	}
+	if request.PullNum != 0 {
+		a.apiRunPullCommand(w, request, ctx, command.Apply)
+		return
+	}
+	done, ok := a.apiStart(w, ctx)
+	if !ok {
+		return
+	}
+	if request.Async {
+		a.apiApplyAsync(w, request, ctx, done)
+		return
+	}
+	defer done()
 
 	// We must first make the plan for all projects
-	_, err = a.apiPlan(request, ctx)
+	_, cmds, err := a.apiPlan(request, ctx)
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, 0) // nolint: errcheck
+	defer a.apiUnlock(ctx, cmds)
 
 	// We can now prepare and run the apply step
 	result, err := a.apiApply(request, ctx)
@@ -140,10 +223,406 @@ func (a *APIController) Apply(w http.ResponseWriter, r *http.Request) {
 	a.respond(w, logging.Debug, code, string(response))
 }
 
-func (a *APIController) apiPlan(request *APIRequest, ctx *command.Context) (*command.Result, error) {
+// GetJob is the GET /api/jobs/{id} route. It returns the status of an
+// asynchronous plan or apply job.
+func (a *APIController) GetJob(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("no job id in request"))
+		return
+	}
+	var job *command.APIJob
+	if a.Jobs != nil {
+		var err error
+		if job, err = a.Jobs.Get(id); err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if job == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no job found with id %q", id))
+		return
+	}
+	if err := caller.authorizeRepo(job.RepoFullName, job.VCSHost); err != nil {
+		a.apiReportError(w, http.StatusForbidden, err)
+		return
+	}
+	a.apiRespond(w, http.StatusOK, job)
+}
+
+// APILock is a project lock as returned by the API.
+type APILock struct {
+	// ID is the lock's id. It's used to get or delete the lock.
+	ID           string
+	RepoFullName string
+	Path         string
+	Workspace    string
+	PullNum      int
+	PullURL      string
+	// User is the user that created the lock.
+	User string
+	Time time.Time
+}
+
+// APIProjectStatus is the status of a project in a pull request as returned
+// by the API.
+type APIProjectStatus struct {
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	Status      string
+	// PolicySets are the results of the project's last policy check, if the
+	// policy engine reports them.
+	PolicySets []APIPolicySetStatus
+}
+
+// APIPolicySetStatus is the result of a policy set in a project's last policy
+// check as returned by the API.
+type APIPolicySetStatus struct {
+	Name     string
+	Passed   bool
+	Passes   int
+	Warnings int
+	Failures int
+}
+
+// APIPull is a pull request tracked by Atlantis as returned by the API.
+type APIPull struct {
+	VCSHost      string
+	RepoFullName string
+	Num          int
+	URL          string
+	Author       string
+	HeadBranch   string
+	BaseBranch   string
+	HeadCommit   string
+	Projects     []APIProjectStatus
+}
+
+// APIProjectDrift is the result of the last drift detection run for a
+// project as returned by the API.
+type APIProjectDrift struct {
+	RepoID       string
+	RepoFullName string
+	ProjectName  string
+	RepoRelDir   string
+	Workspace    string
+	Branch       string
+	Commit       string
+	// Status is one of "no_drift", "drifted" or "errored".
+	Status      string
+	PlanSummary string
+	Error       string
+	CheckedAt   time.Time
+	IssueURL    string
+}
+
+// ListDriftResponse is the response to a ListDrift request.
+type ListDriftResponse struct {
+	Projects []APIProjectDrift
+}
+
+// ListLocksResponse is the response to a ListLocks request.
+type ListLocksResponse struct {
+	Locks []APILock
+}
+
+// ListPullsResponse is the response to a ListPulls request.
+type ListPullsResponse struct {
+	Pulls []APIPull
+}
+
+// LockQueueResponse is the response to a GetLockQueue request.
+type LockQueueResponse struct {
+	Lock APILock
+	// Queue holds the pull requests waiting for the lock, in order.
+	Queue []APILock
+}
+
+// ListLocks is the GET /api/locks route. It returns all the project locks of
+// the repos that the caller can access.
+func (a *APIController) ListLocks(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	locks, err := a.Locker.List()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Sort the locks so the response is stable.
+	var ids []string
+	for id, lock := range locks {
+		if caller.authorizeLock(lock) != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	response := ListLocksResponse{Locks: []APILock{}}
+	for _, id := range ids {
+		response.Locks = append(response.Locks, newAPILock(id, locks[id]))
+	}
+	a.apiRespond(w, http.StatusOK, response)
+}
+
+// GetLock is the GET /api/locks?id={id} route. It returns the lock with that
+// id.
+func (a *APIController) GetLock(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	id, lock, ok := a.apiGetLock(w, r)
+	if !ok {
+		return
+	}
+	if err := caller.authorizeLock(*lock); err != nil {
+		a.apiReportError(w, http.StatusForbidden, err)
+		return
+	}
+	a.apiRespond(w, http.StatusOK, newAPILock(id, *lock))
+}
+
+// DeleteLock is the DELETE /api/locks?id={id} route. It deletes the lock with
+// that id, discards its plan and comments on its pull request.
+func (a *APIController) DeleteLock(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	id, existing, ok := a.apiGetLock(w, r)
+	if !ok {
+		return
+	}
+	repo := models.Repo{FullName: existing.Project.RepoFullName, VCSHost: existing.Pull.BaseRepo.VCSHost}
+	if err := caller.authorize(valid.UnlockAPICommand, repo); err != nil {
+		a.apiReportError(w, http.StatusForbidden, err)
+		return
+	}
+	a.Logger.Info("deleting lock %q for %s", id, caller.user().Username)
+	lock, err := a.DeleteLockCommand.DeleteLock(id)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, fmt.Errorf("deleting lock failed with: %s", err))
+		return
+	}
+	if lock == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no lock found at id %q", id))
+		return
+	}
+
+	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
+	// installations of Atlantis will have locks in their DB that do not have
+	// this field on PullRequest. We skip commenting in this case.
+	if lock.Pull.BaseRepo != (models.Repo{}) {
+		comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via the Atlantis API.\n\n"+
+			"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace)
+		if err = a.VCSClient.CreateComment(lock.Pull.BaseRepo, lock.Pull.Num, comment, ""); err != nil {
+			a.Logger.Warn("failed commenting on pull request: %s", err)
+		}
+	}
+	a.apiRespond(w, http.StatusOK, newAPILock(id, *lock))
+}
+
+// GetLockQueue is the GET /api/locks/queue?id={id} route. It returns the lock
+// with that id and the pull requests queued for it.
+func (a *APIController) GetLockQueue(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	if a.LockQueue == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("lock queueing is disabled"))
+		return
+	}
+	id, lock, ok := a.apiGetLock(w, r)
+	if !ok {
+		return
+	}
+	if err := caller.authorizeLock(*lock); err != nil {
+		a.apiReportError(w, http.StatusForbidden, err)
+		return
+	}
+	queue, err := a.LockQueue.GetQueue(lock.Project, lock.Workspace)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := LockQueueResponse{
+		Lock:  newAPILock(id, *lock),
+		Queue: []APILock{},
+	}
+	for _, queued := range queue {
+		response.Queue = append(response.Queue, newAPILock(queuedLockID(id, queued), queued))
+	}
+	a.apiRespond(w, http.StatusOK, response)
+}
+
+// ListPulls is the GET /api/pulls route. It returns the pull requests that
+// Atlantis is tracking along with the status of their projects. The optional
+// repo query parameter filters the pull requests by repo full name. Only the
+// pull requests of the repos that the caller can access are returned.
+func (a *APIController) ListPulls(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	statuses, err := a.DB.ListPullStatuses()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repo := r.URL.Query().Get("repo")
+	response := ListPullsResponse{Pulls: []APIPull{}}
+	for _, status := range statuses {
+		if repo != "" && status.Pull.BaseRepo.FullName != repo {
+			continue
+		}
+		if caller.authorizeRepo(status.Pull.BaseRepo.FullName, status.Pull.BaseRepo.VCSHost.Hostname) != nil {
+			continue
+		}
+		response.Pulls = append(response.Pulls, newAPIPull(status))
+	}
+	sort.Slice(response.Pulls, func(i, j int) bool {
+		if response.Pulls[i].RepoFullName != response.Pulls[j].RepoFullName {
+			return response.Pulls[i].RepoFullName < response.Pulls[j].RepoFullName
+		}
+		return response.Pulls[i].Num < response.Pulls[j].Num
+	})
+	a.apiRespond(w, http.StatusOK, response)
+}
+
+// ListDrift is the GET /api/drift route. It returns the result of the last
+// drift detection run for each project. The optional repo query parameter
+// filters the projects by repo full name. Only the projects of the repos that
+// the caller can access are returned.
+func (a *APIController) ListDrift(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	drifts, err := a.DriftStore.ListProjectDrifts()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repo := r.URL.Query().Get("repo")
+	response := ListDriftResponse{Projects: []APIProjectDrift{}}
+	for _, drift := range drifts {
+		if repo != "" && drift.RepoFullName != repo {
+			continue
+		}
+		// The repo ID is the repo's hostname followed by its full name.
+		hostname := strings.TrimSuffix(drift.RepoID, "/"+drift.RepoFullName)
+		if caller.authorizeRepo(drift.RepoFullName, hostname) != nil {
+			continue
+		}
+		response.Projects = append(response.Projects, APIProjectDrift{
+			RepoID:       drift.RepoID,
+			RepoFullName: drift.RepoFullName,
+			ProjectName:  drift.ProjectName,
+			RepoRelDir:   drift.RepoRelDir,
+			Workspace:    drift.Workspace,
+			Branch:       drift.Branch,
+			Commit:       drift.Commit,
+			Status:       drift.Status.String(),
+			PlanSummary:  drift.PlanSummary,
+			Error:        drift.Error,
+			CheckedAt:    drift.CheckedAt,
+			IssueURL:     drift.IssueURL,
+		})
+	}
+	sort.SliceStable(response.Projects, func(i, j int) bool {
+		pi, pj := response.Projects[i], response.Projects[j]
+		if pi.RepoFullName != pj.RepoFullName {
+			return pi.RepoFullName < pj.RepoFullName
+		}
+		return pi.RepoRelDir < pj.RepoRelDir
+	})
+	a.apiRespond(w, http.StatusOK, response)
+}
+
+// GetApplyLock is the GET /api/apply/lock route. It returns the status of the
+// global apply lock.
+func (a *APIController) GetApplyLock(w http.ResponseWriter, r *http.Request) {
+	if _, ok := a.apiAuthenticated(w, r); !ok {
+		return
+	}
+	lock, err := a.ApplyLocker.CheckApplyLock()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.apiRespond(w, http.StatusOK, lock)
+}
+
+// LockApply is the POST /api/apply/lock route. It creates the global apply
+// lock. If the lock already exists it's a no-op.
+func (a *APIController) LockApply(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	// The global apply lock affects every repo so scoped tokens can't change it.
+	if caller.token != nil {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token %q is not allowed to change the apply lock, only the API secret is", caller.token.Name))
+		return
+	}
+	lock, err := a.ApplyLocker.LockApply()
+	if err != nil {
+		// Locking fails if the lock already exists, which isn't an error
+		// here.
+		existing, checkErr := a.ApplyLocker.CheckApplyLock()
+		if checkErr != nil || !existing.Locked {
+			a.apiReportError(w, http.StatusInternalServerError, fmt.Errorf("creating apply lock failed with: %s", err))
+			return
+		}
+		lock = existing
+	}
+	a.apiRespond(w, http.StatusOK, lock)
+}
+
+// UnlockApply is the DELETE /api/apply/lock route. It deletes the global apply
+// lock. If the lock doesn't exist it's a no-op.
+func (a *APIController) UnlockApply(w http.ResponseWriter, r *http.Request) {
+	caller, ok := a.apiAuthenticated(w, r)
+	if !ok {
+		return
+	}
+	// The global apply lock affects every repo so scoped tokens can't change it.
+	if caller.token != nil {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token %q is not allowed to change the apply lock, only the API secret is", caller.token.Name))
+		return
+	}
+	unlockErr := a.ApplyLocker.UnlockApply()
+	lock, err := a.ApplyLocker.CheckApplyLock()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// Unlocking fails if there is no lock, which isn't an error here.
+	if unlockErr != nil && lock.Locked {
+		a.apiReportError(w, http.StatusInternalServerError, fmt.Errorf("deleting apply lock failed with: %s", unlockErr))
+		return
+	}
+	a.apiRespond(w, http.StatusOK, lock)
+}
+
+// apiPlan plans the requested projects. It also returns the plan commands so
+// that the locks they create can be deleted.
+func (a *APIController) apiPlan(request *APIRequest, ctx *command.Context) (*command.Result, []command.ProjectContext, error) {
 	cmds, err := request.getCommands(ctx, a.ProjectCommandBuilder.BuildPlanCommands)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var projectResults []command.ProjectResult
@@ -151,7 +630,262 @@ func (a *APIController) apiPlan(request *APIRequest, ctx *command.Context) (*com
 		res := a.ProjectPlanCommandRunner.Plan(cmd)
 		projectResults = append(projectResults, res)
 	}
-	return &command.Result{ProjectResults: projectResults}, nil
+	return &command.Result{ProjectResults: projectResults}, cmds, nil
+}
+
+// apiStart is called before running the commands of a request that isn't for
+// a pull request. All such requests for a repo share its working dir for pull
+// number 0 so only one of them can run at a time, otherwise they'd re-clone it
+// at another ref while the other's commands run. The commands also count as
+// in-progress operations so that shutdown waits for them. It returns the
+// function to call once the commands are done. If they can't run, it
+// responds with an error and returns false.
+func (a *APIController) apiStart(w http.ResponseWriter, ctx *command.Context) (func(), bool) {
+	if !a.Drainer.StartOp() {
+		a.apiReportError(w, http.StatusServiceUnavailable, fmt.Errorf("atlantis is shutting down, try again later"))
+		return nil, false
+	}
+	unlockFn, err := a.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, events.DefaultWorkspace, apiWorkingDirLockPath)
+	if err != nil {
+		a.Drainer.OpDone()
+		a.apiReportError(w, http.StatusConflict, fmt.Errorf("another API request is running commands for repo %s, try again once it's done", ctx.Pull.BaseRepo.FullName))
+		return nil, false
+	}
+	return func() {
+		unlockFn()
+		a.Drainer.OpDone()
+	}, true
+}
+
+// apiUnlock deletes the locks of the projects of cmds. Requests that aren't
+// for a pull request lock their projects with pull number 0 only for as long
+// as their commands run. Drift detection also locks with pull number 0 so
+// only the locks of the request's user are deleted.
+func (a *APIController) apiUnlock(ctx *command.Context, cmds []command.ProjectContext) {
+	locks, err := a.Locker.List()
+	if err != nil {
+		ctx.Log.Warn("listing locks to delete: %s", err)
+		return
+	}
+	for key, lock := range locks {
+		if lock.Pull.Num != 0 || lock.User.Username != ctx.User.Username || lock.Pull.BaseRepo.VCSHost.Hostname != ctx.Pull.BaseRepo.VCSHost.Hostname {
+			continue
+		}
+		for _, cmd := range cmds {
+			if lock.Project == models.NewRepoProject(cmd.BaseRepo, cmd.RepoRelDir) && lock.Workspace == cmd.Workspace {
+				if _, err := a.Locker.Unlock(key); err != nil {
+					ctx.Log.Warn("deleting lock %q: %s", key, err)
+				}
+				break
+			}
+		}
+	}
+}
+
+// apiRunPullCommand runs the requested commands against the pull request
+// through the CommandRunner so that they lock, check apply requirements,
+// comment and update commit statuses just like commands commented on the pull
+// request. It responds with the status of the pull request's projects, with
+// an error code if a command failed.
+func (a *APIController) apiRunPullCommand(w http.ResponseWriter, request *APIRequest, ctx *command.Context, name command.Name) {
+	if a.CommandRunner == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("running commands against pull requests is not supported"))
+		return
+	}
+
+	baseRepo := ctx.Pull.BaseRepo
+	pull, headRepo, err := a.CommandRunner.GetPullRequest(baseRepo, request.PullNum)
+	if err != nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("getting pull request %d of %s: %s", request.PullNum, baseRepo.FullName, err))
+		return
+	}
+	if pull.State != models.OpenPullState {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("pull request %d of %s is closed", request.PullNum, baseRepo.FullName))
+		return
+	}
+
+	// run returns the code to respond with, and an error if a command
+	// couldn't be run at all.
+	run := func() (int, error) {
+		code := http.StatusOK
+		for _, cmd := range request.getCommentCommands(name) {
+			results, err := a.CommandRunner.RunAPICommand(headRepo, pull, ctx.User, cmd)
+			if errors.Is(err, events.ErrShuttingDown) {
+				return http.StatusServiceUnavailable, err
+			}
+			if err != nil {
+				return http.StatusBadRequest, err
+			}
+			for _, result := range results {
+				if result.HasErrors() {
+					code = http.StatusInternalServerError
+				}
+			}
+		}
+		return code, nil
+	}
+	if request.Async {
+		go func() {
+			if _, err := run(); err != nil {
+				ctx.Log.Warn("running API request against pull request %d: %s", request.PullNum, err)
+			}
+		}()
+		a.apiRespondPull(w, http.StatusAccepted, pull)
+		return
+	}
+	code, err := run()
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	a.apiRespondPull(w, code, pull)
+}
+
+// apiRespondPull responds with code and the status of pull's projects.
+func (a *APIController) apiRespondPull(w http.ResponseWriter, code int, pull models.PullRequest) {
+	apiPull := APIPull{
+		VCSHost:      pull.BaseRepo.VCSHost.Hostname,
+		RepoFullName: pull.BaseRepo.FullName,
+		Num:          pull.Num,
+		Projects:     []APIProjectStatus{},
+	}
+	if a.DB != nil {
+		status, err := a.DB.GetPullStatus(pull)
+		if err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if status != nil {
+			apiPull = newAPIPull(*status)
+		}
+	}
+	a.apiRespond(w, code, apiPull)
+}
+
+// apiPlanAsync builds the plan commands, responds with a job for each of them
+// and then runs the plans in the background. It calls done once the plans
+// have finished.
+func (a *APIController) apiPlanAsync(w http.ResponseWriter, request *APIRequest, ctx *command.Context, done func()) {
+	if a.Jobs == nil {
+		done()
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("asynchronous requests are not supported"))
+		return
+	}
+	cmds, err := request.getCommands(ctx, a.ProjectCommandBuilder.BuildPlanCommands)
+	if err != nil {
+		done()
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	added, err := a.Jobs.Add(command.Plan, cmds, a.apiJobURL)
+	if err != nil {
+		done()
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	go func() {
+		defer done()
+		defer a.apiUnlock(ctx, cmds)
+		for _, cmd := range cmds {
+			a.apiJobUpdated(ctx, a.Jobs.Start(cmd.JobID, cmd, a.apiJobURL(cmd)))
+			a.apiJobUpdated(ctx, a.Jobs.Finish(cmd.JobID, a.ProjectPlanCommandRunner.Plan(cmd), true))
+		}
+	}()
+	a.apiRespond(w, http.StatusAccepted, APIJobsResponse{Jobs: added})
+}
+
+// apiApplyAsync builds the plan commands, responds with a job for each of them
+// and then plans and applies the projects in the background. Each job keeps
+// the id of its project's plan while it's applied. It calls done once the
+// applies have finished.
+func (a *APIController) apiApplyAsync(w http.ResponseWriter, request *APIRequest, ctx *command.Context, done func()) {
+	if a.Jobs == nil {
+		done()
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("asynchronous requests are not supported"))
+		return
+	}
+	planCmds, err := request.getCommands(ctx, a.ProjectCommandBuilder.BuildPlanCommands)
+	if err != nil {
+		done()
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	added, err := a.Jobs.Add(command.Apply, planCmds, a.apiJobURL)
+	if err != nil {
+		done()
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	go func() {
+		defer done()
+		defer a.apiUnlock(ctx, planCmds)
+
+		// We must first make the plan for all projects.
+		jobIDs := make(map[string]string)
+		for _, cmd := range planCmds {
+			a.apiJobUpdated(ctx, a.Jobs.Start(cmd.JobID, cmd, a.apiJobURL(cmd)))
+			a.apiJobUpdated(ctx, a.Jobs.Finish(cmd.JobID, a.ProjectPlanCommandRunner.Plan(cmd), false))
+			jobIDs[apiJobKey(cmd)] = cmd.JobID
+		}
+
+		applyCmds, err := request.getCommands(ctx, a.ProjectCommandBuilder.BuildApplyCommands)
+		if err != nil {
+			for _, cmd := range planCmds {
+				if finished, _ := a.Jobs.IsFinished(cmd.JobID); !finished {
+					a.apiJobUpdated(ctx, a.Jobs.Fail(cmd.JobID, err))
+				}
+			}
+			return
+		}
+		for _, cmd := range applyCmds {
+			id, ok := jobIDs[apiJobKey(cmd)]
+			if !ok {
+				continue
+			}
+			if finished, _ := a.Jobs.IsFinished(id); finished {
+				continue
+			}
+			a.apiJobUpdated(ctx, a.Jobs.Start(id, cmd, a.apiJobURL(cmd)))
+			a.apiJobUpdated(ctx, a.Jobs.Finish(id, a.ProjectApplyCommandRunner.Apply(cmd), true))
+		}
+
+		// Any job that's still running didn't have a plan to apply.
+		for _, cmd := range planCmds {
+			if finished, _ := a.Jobs.IsFinished(cmd.JobID); !finished {
+				a.apiJobUpdated(ctx, a.Jobs.Fail(cmd.JobID, fmt.Errorf("no plan found to apply")))
+			}
+		}
+	}()
+	a.apiRespond(w, http.StatusAccepted, APIJobsResponse{Jobs: added})
+}
+
+// apiJobUpdated logs err if updating a job failed.
+func (a *APIController) apiJobUpdated(ctx *command.Context, err error) {
+	if err != nil {
+		ctx.Log.Warn("updating API job: %s", err)
+	}
+}
+
+// apiJobKey identifies the project of cmd so that its apply can be matched to
+// its plan.
+func apiJobKey(cmd command.ProjectContext) string {
+	return fmt.Sprintf("%s/%s/%s", cmd.ProjectName, cmd.RepoRelDir, cmd.Workspace)
+}
+
+// apiJobURL returns the URL of the page that streams the output of cmd or an
+// empty string if it can't be generated.
+func (a *APIController) apiJobURL(cmd command.ProjectContext) string {
+	if a.JobURLGenerator == nil {
+		return ""
+	}
+	jobURL, err := a.JobURLGenerator.GenerateProjectJobURL(cmd)
+	if err != nil {
+		a.Logger.Warn("generating job url: %s", err)
+		return ""
+	}
+	return jobURL
 }
 
 func (a *APIController) apiApply(request *APIRequest, ctx *command.Context) (*command.Result, error) {
@@ -168,15 +902,86 @@ func (a *APIController) apiApply(request *APIRequest, ctx *command.Context) (*co
 	return &command.Result{ProjectResults: projectResults}, nil
 }
 
-func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *command.Context, int, error) {
-	if len(a.APISecret) == 0 {
-		return nil, nil, http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
+// apiCaller is who made an API request.
+type apiCaller struct {
+	// token is the scoped token that the request was authenticated with. It's
+	// nil if the request was authenticated with the API secret, which is
+	// allowed to do everything.
+	token *valid.APIToken
+}
+
+// user returns the user that the caller's commands run as.
+func (c apiCaller) user() models.User {
+	if c.token == nil {
+		return models.User{Username: APIUser}
+	}
+	return models.User{Username: c.token.Name}
+}
+
+// authorize returns an error if the caller isn't allowed to run cmd on repo.
+func (c apiCaller) authorize(cmd string, repo models.Repo) error {
+	if c.token == nil {
+		return nil
+	}
+	if !c.token.AllowsCommand(cmd) {
+		return fmt.Errorf("token %q is not allowed to run %s", c.token.Name, cmd)
+	}
+	return c.authorizeRepo(repo.FullName, repo.VCSHost.Hostname)
+}
+
+// authorizeRepo returns an error if the caller isn't allowed to access the
+// repo with fullName on the VCS host with hostname.
+func (c apiCaller) authorizeRepo(fullName string, hostname string) error {
+	if c.token == nil {
+		return nil
+	}
+	// Tokens' repos use the same syntax as --repo-allowlist.
+	checker, err := events.NewRepoAllowlistChecker(strings.Join(c.token.Repos, ","))
+	if err != nil {
+		return err
+	}
+	if !checker.IsAllowlisted(fullName, hostname) {
+		return fmt.Errorf("token %q is not allowed to access repo %s", c.token.Name, fullName)
+	}
+	return nil
+}
+
+// authorizeLock returns an error if the caller isn't allowed to access the
+// repo of lock.
+func (c apiCaller) authorizeLock(lock models.ProjectLock) error {
+	return c.authorizeRepo(lock.Project.RepoFullName, lock.Pull.BaseRepo.VCSHost.Hostname)
+}
+
+// apiAuthenticate checks that the API is enabled and that the request has the
+// API secret or one of the API tokens. If not, it returns the HTTP code to
+// respond with and an error.
+func (a *APIController) apiAuthenticate(r *http.Request) (apiCaller, int, error) {
+	if len(a.APISecret) == 0 && len(a.APITokens) == 0 {
+		return apiCaller{}, http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
 	}
 
 	// Validate the secret token
 	secret := r.Header.Get(atlantisTokenHeader)
-	if secret != string(a.APISecret) {
-		return nil, nil, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+	if len(a.APISecret) != 0 && subtle.ConstantTimeCompare([]byte(secret), a.APISecret) == 1 {
+		return apiCaller{}, http.StatusOK, nil
+	}
+	for i := range a.APITokens {
+		token := &a.APITokens[i]
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(token.Token)) != 1 {
+			continue
+		}
+		if token.IsExpired(time.Now()) {
+			return apiCaller{}, http.StatusUnauthorized, fmt.Errorf("token %q has expired", token.Name)
+		}
+		return apiCaller{token: token}, http.StatusOK, nil
+	}
+	return apiCaller{}, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+}
+
+func (a *APIController) apiParseAndValidate(r *http.Request, name command.Name) (*APIRequest, *command.Context, int, error) {
+	caller, code, err := a.apiAuthenticate(r)
+	if err != nil {
+		return nil, nil, code, err
 	}
 
 	// Parse the JSON payload
@@ -210,8 +1015,13 @@ func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *comm
 	if !a.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		return nil, nil, http.StatusForbidden, fmt.Errorf("repo not allowlisted")
 	}
+	if err := caller.authorize(name.String(), baseRepo); err != nil {
+		return nil, nil, http.StatusForbidden, err
+	}
 
+	user := caller.user()
 	return &request, &command.Context{
+		User:     user,
 		HeadRepo: baseRepo,
 		Pull: models.PullRequest{
 			Num:        0,
@@ -221,10 +1031,126 @@ func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *comm
 			BaseRepo:   baseRepo,
 		},
 		Scope: a.Scope,
-		Log:   a.Logger,
+		Log:   a.Logger.WithHistory("user", user.Username),
 	}, http.StatusOK, nil
 }
 
+// apiAuthenticated sets the JSON content type and checks that the request is
+// authenticated. If it isn't, it responds with an error and returns false.
+// Otherwise it returns who made the request.
+func (a *APIController) apiAuthenticated(w http.ResponseWriter, r *http.Request) (apiCaller, bool) {
+	w.Header().Set("Content-Type", "application/json")
+	caller, code, err := a.apiAuthenticate(r)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return apiCaller{}, false
+	}
+	return caller, true
+}
+
+// apiLockID returns the unescaped lock id from the request. If there isn't a
+// valid id, it responds with an error and returns false.
+func (a *APIController) apiLockID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("no lock id in request"))
+		return "", false
+	}
+	idUnencoded, err := url.QueryUnescape(id)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid lock id: %s", err))
+		return "", false
+	}
+	return idUnencoded, true
+}
+
+// apiGetLock returns the lock whose id is in the request. If there is no such
+// lock, it responds with an error and returns false.
+func (a *APIController) apiGetLock(w http.ResponseWriter, r *http.Request) (string, *models.ProjectLock, bool) {
+	id, ok := a.apiLockID(w, r)
+	if !ok {
+		return "", nil, false
+	}
+	lock, err := a.Locker.GetLock(id)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return "", nil, false
+	}
+	if lock == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no lock found at id %q", id))
+		return "", nil, false
+	}
+	return id, lock, true
+}
+
+// apiRespond responds with code and v serialized to JSON.
+func (a *APIController) apiRespond(w http.ResponseWriter, code int, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, code, "%s", string(response))
+}
+
+// newAPILock converts lock to its API representation. We don't return
+// models.ProjectLock directly because its repo's clone URL contains
+// credentials.
+func newAPILock(id string, lock models.ProjectLock) APILock {
+	return APILock{
+		ID:           id,
+		RepoFullName: lock.Project.RepoFullName,
+		Path:         lock.Project.Path,
+		Workspace:    lock.Workspace,
+		PullNum:      lock.Pull.Num,
+		PullURL:      lock.Pull.URL,
+		User:         lock.User.Username,
+		Time:         lock.Time,
+	}
+}
+
+// queuedLockID returns the id of the entry for queued in the queue for the
+// lock with lockID. Queued pull requests don't hold the lock so their id is
+// the lock's id followed by their pull request number.
+func queuedLockID(lockID string, queued models.ProjectLock) string {
+	return fmt.Sprintf("%s#%d", lockID, queued.Pull.Num)
+}
+
+// newAPIPull converts status to its API representation.
+func newAPIPull(status models.PullStatus) APIPull {
+	pull := APIPull{
+		VCSHost:      status.Pull.BaseRepo.VCSHost.Hostname,
+		RepoFullName: status.Pull.BaseRepo.FullName,
+		Num:          status.Pull.Num,
+		URL:          status.Pull.URL,
+		Author:       status.Pull.Author,
+		HeadBranch:   status.Pull.HeadBranch,
+		BaseBranch:   status.Pull.BaseBranch,
+		HeadCommit:   status.Pull.HeadCommit,
+		Projects:     []APIProjectStatus{},
+	}
+	for _, p := range status.Projects {
+		project := APIProjectStatus{
+			ProjectName: p.ProjectName,
+			RepoRelDir:  p.RepoRelDir,
+			Workspace:   p.Workspace,
+			Status:      p.Status.String(),
+			PolicySets:  []APIPolicySetStatus{},
+		}
+		for _, ps := range p.PolicyStatus {
+			project.PolicySets = append(project.PolicySets, APIPolicySetStatus{
+				Name:     ps.PolicySetName,
+				Passed:   ps.Passed(),
+				Passes:   ps.Passes,
+				Warnings: ps.Warnings,
+				Failures: ps.Failures,
+			})
+		}
+		pull.Projects = append(pull.Projects, project)
+	}
+	return pull
+}
+
 func (a *APIController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
 	response := fmt.Sprintf(format, args...)
 	a.Logger.Log(lvl, response)
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// apiJobRetention is how long finished API jobs are kept before they're
+// removed.
+const apiJobRetention = 24 * time.Hour
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_api_job_store.go APIJobStore
+
+// APIJobStore stores the asynchronous API jobs.
+type APIJobStore interface {
+	// UpdateAPIJob creates or replaces the job with the ID of job.
+	UpdateAPIJob(job command.APIJob) error
+	// GetAPIJob returns the job with id or nil if there is no such job.
+	GetAPIJob(id string) (*command.APIJob, error)
+	// DeleteAPIJobsFinishedBefore deletes the jobs that finished before t.
+	DeleteAPIJobsFinishedBefore(t time.Time) error
+}
+
+// APIJobs stores the asynchronous API jobs in the DB so that every replica
+// sharing the DB can return them.
+type APIJobs struct {
+	// mutex serializes the updates of jobs made by this replica. A job is
+	// only updated by the replica that runs it.
+	mutex sync.Mutex
+	store APIJobStore
+	// now returns the current time. It's overridden in tests.
+	now func() time.Time
+}
+
+// NewAPIJobs returns an APIJobs that stores its jobs in store.
+func NewAPIJobs(store APIJobStore) *APIJobs {
+	return &APIJobs{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Add creates a pending job for each of cmds and returns them. Finished jobs
+// older than apiJobRetention are removed.
+func (j *APIJobs) Add(name command.Name, cmds []command.ProjectContext, logURL func(command.ProjectContext) string) ([]command.APIJob, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	now := j.now()
+	if err := j.store.DeleteAPIJobsFinishedBefore(now.Add(-apiJobRetention)); err != nil {
+		return nil, err
+	}
+
+	var added []command.APIJob
+	for _, cmd := range cmds {
+		job := command.APIJob{
+			ID:           cmd.JobID,
+			Status:       command.PendingAPIJobStatus,
+			Command:      name.String(),
+			VCSHost:      cmd.BaseRepo.VCSHost.Hostname,
+			RepoFullName: cmd.BaseRepo.FullName,
+			ProjectName:  cmd.ProjectName,
+			RepoRelDir:   cmd.RepoRelDir,
+			Workspace:    cmd.Workspace,
+			LogURL:       logURL(cmd),
+			CreatedAt:    now,
+		}
+		if err := j.store.UpdateAPIJob(job); err != nil {
+			return nil, err
+		}
+		added = append(added, job)
+	}
+	return added, nil
+}
+
+// Get returns the job with id or nil if there is no such job.
+func (j *APIJobs) Get(id string) (*command.APIJob, error) {
+	return j.store.GetAPIJob(id)
+}
+
+// Start marks the job with id as running the command in cmd.
+func (j *APIJobs) Start(id string, cmd command.ProjectContext, logURL string) error {
+	return j.update(id, func(job *command.APIJob) {
+		job.Status = command.RunningAPIJobStatus
+		job.Command = cmd.CommandName.String()
+		job.LogURL = logURL
+	})
+}
+
+// Finish marks the job with id as finished with result. If final is false,
+// the job keeps running because another command follows, unless result
+// failed.
+func (j *APIJobs) Finish(id string, result command.ProjectResult, final bool) error {
+	return j.update(id, func(job *command.APIJob) {
+		failed := result.Error != nil || result.Failure != ""
+		if result.Error != nil {
+			job.Error = result.Error.Error()
+		}
+		result.Error = nil
+		job.Result = &result
+		if !final && !failed {
+			return
+		}
+		now := j.now()
+		job.FinishedAt = &now
+		job.Status = command.SucceededAPIJobStatus
+		if failed {
+			job.Status = command.FailedAPIJobStatus
+		}
+	})
+}
+
+// Fail marks the job with id as failed with err.
+func (j *APIJobs) Fail(id string, err error) error {
+	return j.update(id, func(job *command.APIJob) {
+		now := j.now()
+		job.Status = command.FailedAPIJobStatus
+		job.Error = err.Error()
+		job.FinishedAt = &now
+	})
+}
+
+// IsFinished returns true if the job with id has finished.
+func (j *APIJobs) IsFinished(id string) (bool, error) {
+	job, err := j.store.GetAPIJob(id)
+	if err != nil {
+		return false, err
+	}
+	return job != nil && job.FinishedAt != nil, nil
+}
+
+// update applies fn to the job with id and stores it. It's a no-op if there
+// is no such job.
+func (j *APIJobs) update(id string, fn func(job *command.APIJob)) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	job, err := j.store.GetAPIJob(id)
+	if err != nil || job == nil {
+		return err
+	}
+	fn(job)
+	return j.store.UpdateAPIJob(*job)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/command"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func noLogURL(command.ProjectContext) string { return "" }
+
+func newTestAPIJobs(t *testing.T) *APIJobs {
+	backend, err := db.New(t.TempDir())
+	Ok(t, err)
+	return NewAPIJobs(backend)
+}
+
+func getAPIJob(t *testing.T, j *APIJobs, id string) *command.APIJob {
+	t.Helper()
+	job, err := j.Get(id)
+	Ok(t, err)
+	return job
+}
+
+func TestAPIJobs_FinishNotFinal(t *testing.T) {
+	j := newTestAPIJobs(t)
+	_, err := j.Add(command.Apply, []command.ProjectContext{{JobID: "id"}}, noLogURL)
+	Ok(t, err)
+
+	Ok(t, j.Start("id", command.ProjectContext{CommandName: command.Plan}, "plan-url"))
+	job := getAPIJob(t, j, "id")
+	Equals(t, command.RunningAPIJobStatus, job.Status)
+	Equals(t, "plan", job.Command)
+	Equals(t, "plan-url", job.LogURL)
+
+	// A successful plan that will be followed by an apply keeps running.
+	Ok(t, j.Finish("id", command.ProjectResult{}, false))
+	Equals(t, command.RunningAPIJobStatus, getAPIJob(t, j, "id").Status)
+	finished, err := j.IsFinished("id")
+	Ok(t, err)
+	Assert(t, !finished, "exp job to still be running")
+
+	Ok(t, j.Start("id", command.ProjectContext{CommandName: command.Apply}, "apply-url"))
+	Ok(t, j.Finish("id", command.ProjectResult{ApplySuccess: "success"}, true))
+	job = getAPIJob(t, j, "id")
+	Equals(t, command.SucceededAPIJobStatus, job.Status)
+	Equals(t, "apply", job.Command)
+	Equals(t, "apply-url", job.LogURL)
+	Equals(t, "success", job.Result.ApplySuccess)
+	Assert(t, job.FinishedAt != nil, "exp finished at to be set")
+}
+
+func TestAPIJobs_FinishWithError(t *testing.T) {
+	j := newTestAPIJobs(t)
+	_, err := j.Add(command.Apply, []command.ProjectContext{{JobID: "id"}}, noLogURL)
+	Ok(t, err)
+
+	// A failed plan finishes the job even if an apply would follow.
+	Ok(t, j.Finish("id", command.ProjectResult{Error: errors.New("err")}, false))
+	job := getAPIJob(t, j, "id")
+	Equals(t, command.FailedAPIJobStatus, job.Status)
+	Equals(t, "err", job.Error)
+}
+
+// Test that jobs are shared by APIJobs using the same backend, like replicas
+// sharing a Redis backend.
+func TestAPIJobs_SharedBackend(t *testing.T) {
+	backend, err := db.New(t.TempDir())
+	Ok(t, err)
+	j := NewAPIJobs(backend)
+	other := NewAPIJobs(backend)
+	_, err = j.Add(command.Plan, []command.ProjectContext{{JobID: "id"}}, noLogURL)
+	Ok(t, err)
+	Ok(t, j.Finish("id", command.ProjectResult{Failure: "failure"}, true))
+
+	job := getAPIJob(t, other, "id")
+	Equals(t, command.FailedAPIJobStatus, job.Status)
+	Equals(t, "failure", job.Result.Failure)
+}
+
+func TestAPIJobs_RemovesOldJobs(t *testing.T) {
+	j := newTestAPIJobs(t)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+	_, err := j.Add(command.Plan, []command.ProjectContext{{JobID: "finished"}, {JobID: "running"}}, noLogURL)
+	Ok(t, err)
+	Ok(t, j.Finish("finished", command.ProjectResult{}, true))
+
+	now = now.Add(apiJobRetention + time.Minute)
+	_, err = j.Add(command.Plan, []command.ProjectContext{{JobID: "new"}}, noLogURL)
+	Ok(t, err)
+
+	Assert(t, getAPIJob(t, j, "finished") == nil, "exp finished job to be removed")
+	Assert(t, getAPIJob(t, j, "running") != nil, "exp running job to be kept")
+	Assert(t, getAPIJob(t, j, "new") != nil, "exp new job to be added")
+}
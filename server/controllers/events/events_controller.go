@@ -14,9 +14,11 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/google/go-github/v31/github"
@@ -28,6 +30,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/uber-go/tally"
 	gitlab "github.com/xanzy/go-gitlab"
@@ -35,6 +38,11 @@ import (
 
 const githubHeader = "X-Github-Event"
 const gitlabHeader = "X-Gitlab-Event"
+
+// githubEnterpriseHostHeader and gitlabInstanceHeader identify the host that
+// sent the webhook so its secret can be used to validate it.
+const githubEnterpriseHostHeader = "X-Github-Enterprise-Host"
+const gitlabInstanceHeader = "X-Gitlab-Instance"
 const azuredevopsHeader = "Request-Id"
 
 // bitbucketEventTypeHeader is the same in both cloud and server.
@@ -43,6 +51,13 @@ const bitbucketCloudRequestIDHeader = "X-Request-UUID"
 const bitbucketServerRequestIDHeader = "X-Request-ID"
 const bitbucketServerSignatureHeader = "X-Hub-Signature"
 
+// Gitea also sends GitHub's headers, so giteaHeader must be checked before
+// githubHeader. Forgejo sends the same headers as Gitea.
+const giteaHeader = "X-Gitea-Event"
+const giteaEventTypeHeader = "X-Gitea-Event-Type"
+const giteaRequestIDHeader = "X-Gitea-Delivery"
+const giteaSignatureHeader = "X-Gitea-Signature"
+
 // VCSEventsController handles all webhook requests which signify 'events' in the
 // VCS host, ex. GitHub.
 type VCSEventsController struct {
@@ -86,11 +101,28 @@ type VCSEventsController struct {
 	// Azure DevOps Team Project. If empty, no request validation is done.
 	AzureDevopsWebhookBasicPassword []byte
 	AzureDevopsRequestValidator     AzureDevopsRequestValidator
+	// GiteaWebhookSecret is the secret added to this webhook via the Gitea
+	// UI that identifies this call as coming from Gitea. If empty, no
+	// request validation is done.
+	GiteaWebhookSecret []byte
+	// VCSHostWebhookSecrets are the webhook secrets of VCS hosts other than
+	// the default host of their type, ex. a GitHub Enterprise host alongside
+	// github.com. Webhooks for repos on these hosts must be validated with
+	// their host's secret instead of the secret of their type above.
+	VCSHostWebhookSecrets map[models.VCSHost][]byte
 }
 
 // Post handles POST webhook requests.
 func (e *VCSEventsController) Post(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get(githubHeader) != "" {
+	if r.Header.Get(giteaHeader) != "" {
+		if !e.supportsHost(models.Gitea) {
+			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+			return
+		}
+		e.Logger.Debug("handling Gitea post")
+		e.handleGiteaPost(w, r)
+		return
+	} else if r.Header.Get(githubHeader) != "" {
 		if !e.supportsHost(models.Github) {
 			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
 			return
@@ -150,7 +182,8 @@ type HTTPResponse struct {
 
 func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional webhook secret.
-	payload, err := e.GithubRequestValidator.Validate(r, e.GithubWebhookSecret)
+	secret, signedHost := e.webhookSecret(models.Github, r.Header.Get(githubEnterpriseHostHeader), e.GithubWebhookSecret)
+	payload, err := e.GithubRequestValidator.Validate(r, secret)
 	if err != nil {
 		e.respond(w, logging.Warn, http.StatusBadRequest, err.Error())
 		return
@@ -163,6 +196,12 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 	logger.Debug("request valid")
 
 	event, _ := github.ParseWebHook(github.WebHookType(r), payload)
+	if repoEvent, ok := event.(interface{ GetRepo() *github.Repository }); ok {
+		if err := e.checkWebhookHost(signedHost, models.Github, repoEvent.GetRepo().GetCloneURL()); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, "%s %s", err, githubReqID)
+			return
+		}
+	}
 
 	var resp HTTPResponse
 
@@ -173,6 +212,9 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 	case *github.PullRequestEvent:
 		resp = e.HandleGithubPullRequestEvent(logger, event, githubReqID)
 		scope = scope.SubScope(fmt.Sprintf("pr.%s", *event.Action))
+	case *github.CheckRunEvent:
+		resp = e.HandleGithubCheckRunEvent(event, githubReqID, logger)
+		scope = scope.SubScope(fmt.Sprintf("check_run.%s", event.GetAction()))
 	default:
 		resp = HTTPResponse{
 			body: fmt.Sprintf("Ignoring unsupported event %s", githubReqID),
@@ -278,6 +320,96 @@ func (e *VCSEventsController) handleAzureDevopsPost(w http.ResponseWriter, r *ht
 	}
 }
 
+func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Request) {
+	eventType := r.Header.Get(giteaEventTypeHeader)
+	reqID := r.Header.Get(giteaRequestIDHeader)
+	sig := r.Header.Get(giteaSignatureHeader)
+	defer r.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	// Gitea doesn't send its hostname in a header so the secret is chosen by
+	// the host of the repo in the payload, which the signature covers.
+	secret, _ := e.webhookSecret(models.Gitea, giteaWebhookHostname(body), e.GiteaWebhookSecret)
+	if len(secret) > 0 {
+		if err := gitea.ValidateSignature(body, sig, secret); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, errors.Wrap(err, "request did not pass validation").Error())
+			return
+		}
+	}
+	e.Logger.Debug("request valid")
+
+	event, err := gitea.ParseWebhook(eventType, body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	switch event := event.(type) {
+	case *gitea.IssueCommentEvent:
+		e.Logger.Debug("handling as comment event")
+		e.HandleGiteaCommentEvent(w, event, reqID)
+	case *gitea.PullRequestEvent:
+		e.Logger.Debug("handling as pull request event")
+		e.HandleGiteaPullRequestEvent(w, event, reqID)
+	default:
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, giteaRequestIDHeader, reqID)
+	}
+}
+
+// HandleGiteaCommentEvent handles comment events from Gitea where Atlantis
+// commands can come from. It's exported to make testing easier.
+func (e *VCSEventsController) HandleGiteaCommentEvent(w http.ResponseWriter, event *gitea.IssueCommentEvent, reqID string) {
+	if *event.Action != "created" {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since action was not created %s=%s", giteaRequestIDHeader, reqID)
+		return
+	}
+	baseRepo, user, pullNum, err := e.Parser.ParseGiteaIssueCommentEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	// We pass in nil for maybeHeadRepo because the head repo data isn't
+	// available in the comment event.
+	resp := e.handleCommentEvent(e.Logger, baseRepo, nil, nil, user, pullNum, *event.Comment.Body, models.Gitea)
+
+	//TODO: move this to the outer most function similar to github
+	lvl := logging.Debug
+	code := http.StatusOK
+	msg := resp.body
+	if resp.err.code != 0 {
+		lvl = logging.Error
+		code = resp.err.code
+		msg = resp.err.err.Error()
+	}
+	e.respond(w, lvl, code, msg)
+}
+
+// HandleGiteaPullRequestEvent will delete any locks associated with the pull
+// request if the event is a pull request closed event. It's exported to make
+// testing easier.
+func (e *VCSEventsController) HandleGiteaPullRequestEvent(w http.ResponseWriter, event *gitea.PullRequestEvent, reqID string) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGiteaPullEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	e.Logger.Info("identified event as type %q", pullEventType.String())
+	resp := e.handlePullRequestEvent(e.Logger, baseRepo, headRepo, pull, user, pullEventType)
+
+	//TODO: move this to the outer most function similar to github
+	lvl := logging.Debug
+	code := http.StatusOK
+	msg := resp.body
+	if resp.err.code != 0 {
+		lvl = logging.Error
+		code = resp.err.code
+		msg = resp.err.err.Error()
+	}
+	e.respond(w, lvl, code, msg)
+}
+
 // HandleGithubCommentEvent handles comment events from GitHub where Atlantis
 // commands can come from. It's exported to make testing easier.
 func (e *VCSEventsController) HandleGithubCommentEvent(event *github.IssueCommentEvent, githubReqID string, logger logging.SimpleLogging) HTTPResponse {
@@ -305,6 +437,48 @@ func (e *VCSEventsController) HandleGithubCommentEvent(event *github.IssueCommen
 	return e.handleCommentEvent(logger, baseRepo, nil, nil, user, pullNum, event.Comment.GetBody(), models.Github)
 }
 
+// HandleGithubCheckRunEvent runs the actions that users request on the
+// project check runs created by Atlantis, ex. apply, as comment commands so
+// they're validated like comments. It's exported to make testing easier.
+func (e *VCSEventsController) HandleGithubCheckRunEvent(event *github.CheckRunEvent, githubReqID string, logger logging.SimpleLogging) HTTPResponse {
+	if event.GetAction() != "requested_action" {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring check run event since action was not requested_action %s", githubReqID),
+		}
+	}
+
+	var externalID events.CheckRunExternalID
+	if err := json.Unmarshal([]byte(event.GetCheckRun().GetExternalID()), &externalID); err != nil || externalID.PullNum == 0 {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring check run event for a check run not created by Atlantis %s", githubReqID),
+		}
+	}
+	var identifier string
+	if event.GetRequestedAction() != nil {
+		identifier = event.GetRequestedAction().Identifier
+	}
+	comment := externalID.Comment(identifier)
+	if comment == "" {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring check run event for unknown action %q %s", identifier, githubReqID),
+		}
+	}
+
+	baseRepo, err := e.Parser.ParseGithubRepo(event.GetRepo())
+	if err != nil {
+		wrapped := errors.Wrapf(err, "Failed parsing event: %s", githubReqID)
+		return HTTPResponse{
+			body: wrapped.Error(),
+			err: HTTPError{
+				code: http.StatusBadRequest,
+				err:  wrapped,
+			},
+		}
+	}
+	user := models.User{Username: event.GetSender().GetLogin()}
+	return e.handleCommentEvent(logger, baseRepo, nil, nil, user, externalID.PullNum, comment, models.Github)
+}
+
 // HandleBitbucketCloudCommentEvent handles comment events from Bitbucket.
 func (e *VCSEventsController) HandleBitbucketCloudCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, comment, err := e.Parser.ParseBitbucketCloudPullCommentEvent(body)
@@ -411,6 +585,15 @@ func (e *VCSEventsController) HandleGithubPullRequestEvent(logger logging.Simple
 }
 
 func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType) HTTPResponse {
+	if err := checkSameHost(baseRepo, &headRepo); err != nil {
+		return HTTPResponse{
+			body: err.Error(),
+			err: HTTPError{
+				code: http.StatusBadRequest,
+				err:  err,
+			},
+		}
+	}
 	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		// If the repo isn't allowlisted and we receive an opened pull request
 		// event we comment back on the pull request that the repo isn't
@@ -472,7 +655,12 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 }
 
 func (e *VCSEventsController) handleGitlabPost(w http.ResponseWriter, r *http.Request) {
-	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, e.GitlabWebhookSecret)
+	var instanceHostname string
+	if instanceURL, err := url.Parse(r.Header.Get(gitlabInstanceHeader)); err == nil {
+		instanceHostname = instanceURL.Hostname()
+	}
+	secret, signedHost := e.webhookSecret(models.Gitlab, instanceHostname, e.GitlabWebhookSecret)
+	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, secret)
 	if err != nil {
 		e.respond(w, logging.Warn, http.StatusBadRequest, err.Error())
 		return
@@ -481,9 +669,17 @@ func (e *VCSEventsController) handleGitlabPost(w http.ResponseWriter, r *http.Re
 
 	switch event := event.(type) {
 	case gitlab.MergeCommentEvent:
+		if err := e.checkWebhookHost(signedHost, models.Gitlab, event.Project.GitHTTPURL); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, err.Error())
+			return
+		}
 		e.Logger.Debug("handling as comment event")
 		e.HandleGitlabCommentEvent(w, event)
 	case gitlab.MergeEvent:
+		if err := e.checkWebhookHost(signedHost, models.Gitlab, event.Project.GitHTTPURL); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, err.Error())
+			return
+		}
 		e.Logger.Debug("handling as pull request event")
 		e.HandleGitlabMergeRequestEvent(w, event)
 	case gitlab.CommitCommentEvent:
@@ -519,6 +715,15 @@ func (e *VCSEventsController) HandleGitlabCommentEvent(w http.ResponseWriter, ev
 }
 
 func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, comment string, vcsHost models.VCSHostType) HTTPResponse {
+	if err := checkSameHost(baseRepo, maybeHeadRepo); err != nil {
+		return HTTPResponse{
+			body: err.Error(),
+			err: HTTPError{
+				code: http.StatusBadRequest,
+				err:  err,
+			},
+		}
+	}
 	parseResult := e.CommentParser.Parse(comment, vcsHost)
 	if parseResult.Ignore {
 		truncated := comment
@@ -684,6 +889,60 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseW
 	e.respond(w, lvl, code, msg)
 }
 
+// webhookSecret returns the secret to validate a webhook from hostname, a
+// host of type hostType, and the host it belongs to. If hostname isn't in
+// e.VCSHostWebhookSecrets it returns defaultSecret and an empty host.
+func (e *VCSEventsController) webhookSecret(hostType models.VCSHostType, hostname string, defaultSecret []byte) ([]byte, models.VCSHost) {
+	host := models.VCSHost{Type: hostType, Hostname: hostname}
+	if secret, ok := e.VCSHostWebhookSecrets[host]; ok {
+		return secret, host
+	}
+	return defaultSecret, models.VCSHost{}
+}
+
+// checkWebhookHost returns an error if the repo with cloneURL isn't on
+// signedHost, the host whose secret validated the webhook, as returned by
+// webhookSecret. Otherwise a webhook signed with the secret of one host could
+// make Atlantis use the credentials of another.
+func (e *VCSEventsController) checkWebhookHost(signedHost models.VCSHost, hostType models.VCSHostType, cloneURL string) error {
+	parsed, err := url.Parse(cloneURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing repo clone url")
+	}
+	if _, repoHost := e.webhookSecret(hostType, parsed.Hostname(), nil); repoHost != signedHost {
+		return fmt.Errorf("webhook for repo on %q was not validated with the webhook secret of that host", parsed.Hostname())
+	}
+	return nil
+}
+
+// checkSameHost returns an error if maybeHeadRepo isn't on the same VCS host
+// as baseRepo. Forks are always on the same host and repos on other hosts
+// would be cloned with that host's credentials.
+func checkSameHost(baseRepo models.Repo, maybeHeadRepo *models.Repo) error {
+	if maybeHeadRepo != nil && maybeHeadRepo.VCSHost != baseRepo.VCSHost {
+		return fmt.Errorf("head repo on %q is not on the same host as base repo on %q", maybeHeadRepo.VCSHost.Hostname, baseRepo.VCSHost.Hostname)
+	}
+	return nil
+}
+
+// giteaWebhookHostname returns the hostname of the repo in a Gitea webhook
+// payload or "" if it can't be parsed.
+func giteaWebhookHostname(payload []byte) string {
+	var event struct {
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return ""
+	}
+	cloneURL, err := url.Parse(event.Repository.CloneURL)
+	if err != nil {
+		return ""
+	}
+	return cloneURL.Hostname()
+}
+
 // supportsHost returns true if h is in e.SupportedVCSHosts and false otherwise.
 func (e *VCSEventsController) supportsHost(h models.VCSHostType) bool {
 	for _, supported := range e.SupportedVCSHosts {
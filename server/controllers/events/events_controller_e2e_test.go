@@ -853,7 +853,7 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 		GithubUser: "github-user",
 		GitlabUser: "gitlab-user",
 	}
-	terraformClient, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", "default-tf-version", "https://releases.hashicorp.com", &NoopTFDownloader{}, false, projectCmdOutputHandler)
+	terraformClient, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", "default-tf-version", "terraform", "https://releases.hashicorp.com", "https://github.com/opentofu/opentofu/releases/download", &NoopTFDownloader{}, false, projectCmdOutputHandler)
 	Ok(t, err)
 	boltdb, err := db.New(dataDir)
 	Ok(t, err)
@@ -943,7 +943,7 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 
 	conftestVersion, _ := version.NewVersion(ConftestVersion)
 
-	conftextExec := policy.NewConfTestExecutorWorkflow(logger, binDir, &NoopTFDownloader{})
+	conftextExec := policy.NewConfTestExecutorWorkflow(logger, binDir, &NoopTFDownloader{}, policy.NewGitSourceResolver(filepath.Join(dataDir, "policies"), "github.com", nil, logger))
 
 	// swapping out version cache to something that always returns local contest
 	// binary
@@ -1025,6 +1025,7 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 		parallelPoolSize,
 		silenceNoProjects,
 		boltdb,
+		nil,
 	)
 
 	e2ePullReqStatusFetcher := vcs.NewPullReqStatusFetcher(e2eVCSClient)
@@ -1133,7 +1134,7 @@ func (m *mockLockURLGenerator) GenerateLockURL(lockID string) string {
 
 type mockWebhookSender struct{}
 
-func (w *mockWebhookSender) Send(log logging.SimpleLogging, result webhooks.ApplyResult) error {
+func (w *mockWebhookSender) Send(log logging.SimpleLogging, event webhooks.Event) error {
 	return nil
 }
 
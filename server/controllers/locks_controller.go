@@ -6,7 +6,6 @@ import (
 	"net/url"
 
 	"github.com/runatlantis/atlantis/server/controllers/templates"
-	"github.com/runatlantis/atlantis/server/core/db"
 
 	"github.com/gorilla/mux"
 	"github.com/runatlantis/atlantis/server/core/locking"
@@ -27,8 +26,10 @@ type LocksController struct {
 	LockDetailTemplate templates.TemplateWriter
 	WorkingDir         events.WorkingDir
 	WorkingDirLocker   events.WorkingDirLocker
-	DB                 *db.BoltDB
+	DB                 locking.Backend
 	DeleteLockCommand  events.DeleteLockCommand
+	// LockQueue is nil if lock queueing is disabled.
+	LockQueue locking.LockQueue
 }
 
 // LockApply handles creating a global apply lock.
@@ -78,6 +79,30 @@ func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var queue []templates.LockQueueData
+	if l.LockQueue != nil {
+		queued, err := l.LockQueue.GetQueue(lock.Project, lock.Workspace)
+		if err != nil {
+			l.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting lock queue: %s", err)
+			return
+		}
+		for i, q := range queued {
+			queue = append(queue, templates.LockQueueData{
+				Position:        i + 1,
+				PullNum:         q.Pull.Num,
+				PullRequestLink: q.Pull.URL,
+				QueuedBy:        q.User.Username,
+				TimeFormatted:   q.Time.Format("02-01-2006 15:04:05"),
+			})
+		}
+	}
+
+	policySets, err := l.lockPolicySets(*lock)
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting pull status: %s", err)
+		return
+	}
+
 	owner, repo := models.SplitRepoFullName(lock.Project.RepoFullName)
 	viewData := templates.LockDetailData{
 		LockKeyEncoded:  id,
@@ -85,6 +110,8 @@ func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 		PullRequestLink: lock.Pull.URL,
 		LockedBy:        lock.Pull.Author,
 		Workspace:       lock.Workspace,
+		Queue:           queue,
+		PolicySets:      policySets,
 		AtlantisVersion: l.AtlantisVersion,
 		CleanedBasePath: l.AtlantisURL.Path,
 		RepoOwner:       owner,
@@ -97,6 +124,35 @@ func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// lockPolicySets returns the results of the last policy check of the project
+// that's locked by lock.
+func (l *LocksController) lockPolicySets(lock models.ProjectLock) ([]templates.LockPolicySetData, error) {
+	if l.DB == nil {
+		return nil, nil
+	}
+	status, err := l.DB.GetPullStatus(lock.Pull)
+	if err != nil || status == nil {
+		return nil, err
+	}
+	var policySets []templates.LockPolicySetData
+	for _, p := range status.Projects {
+		if p.RepoRelDir != lock.Project.Path || p.Workspace != lock.Workspace {
+			continue
+		}
+		for _, ps := range p.PolicyStatus {
+			policySets = append(policySets, templates.LockPolicySetData{
+				Name:     ps.PolicySetName,
+				Passed:   ps.Passed(),
+				WarnOnly: ps.WarnOnly,
+				Passes:   ps.Passes,
+				Warnings: ps.Warnings,
+				Failures: ps.Failures,
+			})
+		}
+	}
+	return policySets, nil
+}
+
 // DeleteLock handles deleting the lock at id and commenting back on the
 // pull request that the lock has been deleted.
 func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
@@ -127,7 +183,7 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 	// installations of Atlantis will have locks in their DB that do not have
 	// this field on PullRequest. We skip commenting in this case.
 	if lock.Pull.BaseRepo != (models.Repo{}) {
-		unlock, err := l.WorkingDirLocker.TryLock(lock.Pull.BaseRepo.FullName, lock.Pull.Num, lock.Workspace, lock.Project.Path)
+		unlock, err := l.WorkingDirLocker.TryLock(lock.Pull.BaseRepo.ID(), lock.Pull.Num, lock.Workspace, lock.Project.Path)
 		if err != nil {
 			l.Logger.Err("unable to obtain working dir lock when trying to delete old plans: %s", err)
 		} else {
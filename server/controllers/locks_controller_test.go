@@ -205,6 +205,139 @@ func TestGetLock_Success(t *testing.T) {
 	ResponseContains(t, w, http.StatusOK, "")
 }
 
+func TestGetLock_SuccessWithQueue(t *testing.T) {
+	t.Log("Should render the pull requests queued for the lock")
+	RegisterMockTestingT(t)
+	l := mocks.NewMockLocker()
+	lock := &models.ProjectLock{
+		Project:   models.Project{RepoFullName: "owner/repo", Path: "path"},
+		Pull:      models.PullRequest{URL: "url", Author: "lkysow"},
+		Workspace: "workspace",
+	}
+	When(l.GetLock("id")).ThenReturn(lock, nil)
+	queueTime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	q := mocks.NewMockLockQueue()
+	When(q.GetQueue(lock.Project, lock.Workspace)).ThenReturn([]models.ProjectLock{
+		{
+			Pull: models.PullRequest{Num: 2, URL: "url2"},
+			User: models.User{Username: "queued-user"},
+			Time: queueTime,
+		},
+	}, nil)
+	tmpl := tMocks.NewMockTemplateWriter()
+	atlantisURL, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	lc := controllers.LocksController{
+		Logger:             logging.NewNoopLogger(t),
+		Locker:             l,
+		LockQueue:          q,
+		LockDetailTemplate: tmpl,
+		AtlantisVersion:    "1300135",
+		AtlantisURL:        atlantisURL,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	lc.GetLock(w, req)
+	tmpl.VerifyWasCalledOnce().Execute(w, templates.LockDetailData{
+		LockKeyEncoded:  "id",
+		LockKey:         "id",
+		RepoOwner:       "owner",
+		RepoName:        "repo",
+		PullRequestLink: "url",
+		LockedBy:        "lkysow",
+		Workspace:       "workspace",
+		Queue: []templates.LockQueueData{
+			{
+				Position:        1,
+				PullNum:         2,
+				PullRequestLink: "url2",
+				QueuedBy:        "queued-user",
+				TimeFormatted:   "02-01-2022 03:04:05",
+			},
+		},
+		AtlantisVersion: "1300135",
+		CleanedBasePath: "/basepath",
+	})
+	ResponseContains(t, w, http.StatusOK, "")
+}
+
+func TestGetLock_SuccessWithPolicySets(t *testing.T) {
+	t.Log("Should render the results of the last policy check of the locked project")
+	RegisterMockTestingT(t)
+	l := mocks.NewMockLocker()
+	pull := models.PullRequest{
+		Num:      1,
+		URL:      "url",
+		Author:   "lkysow",
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+	}
+	lock := &models.ProjectLock{
+		Project:   models.Project{RepoFullName: "owner/repo", Path: "path"},
+		Pull:      pull,
+		Workspace: "workspace",
+	}
+	When(l.GetLock("id")).ThenReturn(lock, nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	policySetResults := []models.PolicySetResult{
+		{
+			PolicySetName: "policies",
+			Rules: []models.PolicyRuleResult{
+				{Namespace: "main", Name: "deny", Messages: []string{"no"}},
+				{Namespace: "main", Name: "warn"},
+			},
+		},
+	}
+	_, err = db.UpdatePullWithResults(pull, []command.ProjectResult{
+		{
+			Command:          command.PolicyCheck,
+			RepoRelDir:       "path",
+			Workspace:        "workspace",
+			Error:            errors.New("1 policy check failures"),
+			PolicySetResults: policySetResults,
+		},
+		{
+			Command:          command.PolicyCheck,
+			RepoRelDir:       "other",
+			Workspace:        "workspace",
+			PolicySetResults: policySetResults,
+		},
+	})
+	Ok(t, err)
+	tmpl := tMocks.NewMockTemplateWriter()
+	atlantisURL, err := url.Parse("https://example.com")
+	Ok(t, err)
+	lc := controllers.LocksController{
+		Logger:             logging.NewNoopLogger(t),
+		Locker:             l,
+		LockDetailTemplate: tmpl,
+		AtlantisVersion:    "1300135",
+		AtlantisURL:        atlantisURL,
+		DB:                 db,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	lc.GetLock(w, req)
+	tmpl.VerifyWasCalledOnce().Execute(w, templates.LockDetailData{
+		LockKeyEncoded:  "id",
+		LockKey:         "id",
+		RepoOwner:       "owner",
+		RepoName:        "repo",
+		PullRequestLink: "url",
+		LockedBy:        "lkysow",
+		Workspace:       "workspace",
+		PolicySets: []templates.LockPolicySetData{
+			{Name: "policies", Passed: false, Passes: 1, Warnings: 0, Failures: 1},
+		},
+		AtlantisVersion: "1300135",
+	})
+	ResponseContains(t, w, http.StatusOK, "")
+}
+
 func TestDeleteLock_NoLockID(t *testing.T) {
 	t.Log("If there is no lock ID in the request then we should get a 400")
 	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
@@ -123,6 +123,9 @@ var IndexTemplate = template.Must(template.New("index.html.tmpl").Parse(`
     <p class="placeholder">No locks found.</p>
     {{ end }}
   </section>
+  <section>
+    <a href="{{ .CleanedBasePath }}/drift">Drift detection</a>
+  </section>
   <div id="applyLockMessageModal" class="modal">
     <!-- Modal content -->
     <div class="modal-content">
@@ -243,6 +246,11 @@ type LockDetailData struct {
 	LockedBy        string
 	Workspace       string
 	Time            time.Time
+	// Queue holds the pull requests waiting for this lock, in order.
+	Queue []LockQueueData
+	// PolicySets are the results of the last policy check of the locked
+	// project, if the policy engine reports them.
+	PolicySets      []LockPolicySetData
 	AtlantisVersion string
 	// CleanedBasePath is the path Atlantis is accessible at externally. If
 	// not using a path-based proxy, this will be an empty string. Never ends
@@ -250,6 +258,27 @@ type LockDetailData struct {
 	CleanedBasePath string
 }
 
+// LockQueueData holds the fields needed to display a pull request that is
+// queued for a lock.
+type LockQueueData struct {
+	Position        int
+	PullNum         int
+	PullRequestLink string
+	QueuedBy        string
+	TimeFormatted   string
+}
+
+// LockPolicySetData holds the fields needed to display the result of a policy
+// set in the last policy check of a locked project.
+type LockPolicySetData struct {
+	Name     string
+	Passed   bool
+	WarnOnly bool
+	Passes   int
+	Warnings int
+	Failures int
+}
+
 var LockTemplate = template.Must(template.New("lock.html.tmpl").Parse(`
 <!DOCTYPE html>
 <html lang="en">
@@ -281,6 +310,27 @@ var LockTemplate = template.Must(template.New("lock.html.tmpl").Parse(`
         <h6><code>Pull Request Link</code>: <a href="{{.PullRequestLink}}" target="_blank"><strong>{{.PullRequestLink}}</strong></a></h6>
         <h6><code>Locked By</code>: <strong>{{.LockedBy}}</strong></h6>
         <h6><code>Workspace</code>: <strong>{{.Workspace}}</strong></h6>
+        {{ if .Queue }}
+        <h6><code>Queue</code>:</h6>
+        <ol>
+          {{ range .Queue }}
+          <li><a href="{{.PullRequestLink}}" target="_blank"><strong>#{{.PullNum}}</strong></a> queued by <strong>{{.QueuedBy}}</strong> at {{.TimeFormatted}}</li>
+          {{ end }}
+        </ol>
+        {{ end }}
+        {{ if .PolicySets }}
+        <h6><code>Policy Checks</code>:</h6>
+        <table>
+          <thead>
+            <tr><th>Policy Set</th><th>Status</th><th>Passed</th><th>Warnings</th><th>Failures</th></tr>
+          </thead>
+          <tbody>
+            {{ range .PolicySets }}
+            <tr><td>{{.Name}}</td><td><strong>{{ if .Passed }}Passed{{ else if .WarnOnly }}Warning{{ else }}Failed{{ end }}</strong></td><td>{{.Passes}}</td><td>{{.Warnings}}</td><td>{{.Failures}}</td></tr>
+            {{ end }}
+          </tbody>
+        </table>
+        {{ end }}
         <br>
       </div>
       <div class="four columns">
@@ -359,6 +409,73 @@ type ProjectJobData struct {
 	CleanedBasePath string
 }
 
+// DriftProjectData holds the fields to display a project in the drift view.
+type DriftProjectData struct {
+	RepoFullName       string
+	ProjectName        string
+	RepoRelDir         string
+	Workspace          string
+	Branch             string
+	Commit             string
+	Status             string
+	PlanSummary        string
+	Error              string
+	IssueURL           string
+	CheckedAtFormatted string
+}
+
+// DriftData holds the data for rendering the drift page.
+type DriftData struct {
+	Projects        []DriftProjectData
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var DriftTemplate = template.Must(template.New("drift.html.tmpl").Parse(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>atlantis</title>
+  <meta name="description" content="">
+  <meta name="author" content="">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/normalize.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/skeleton.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/custom.css">
+  <link rel="icon" type="image/png" href="{{ .CleanedBasePath }}/static/images/atlantis-icon.png">
+</head>
+<body>
+<div class="container">
+  <section class="header">
+    <a title="atlantis" href="{{ .CleanedBasePath }}/"><img class="hero" src="{{ .CleanedBasePath }}/static/images/atlantis-icon_512.png"/></a>
+    <p class="title-heading">atlantis</p>
+  </section>
+  <section>
+    <p class="title-heading small"><strong>Drift</strong></p>
+    {{ if .Projects }}
+    {{ range .Projects }}
+      <div class="twelve columns button content lock-row">
+      <div class="list-title">{{.RepoFullName}} {{ if .ProjectName }}<span class="heading-font-size">{{.ProjectName}}</span> {{ end }}<code>{{.RepoRelDir}}</code> <code>{{.Workspace}}</code> <span class="heading-font-size">{{.Branch}}@{{.Commit}}</span></div>
+      <div class="list-status"><code{{ if .Error }} title="{{.Error}}"{{ end }}>{{.Status}}</code>{{ if .PlanSummary }} {{.PlanSummary}}{{ end }}{{ if .IssueURL }} <a href="{{.IssueURL}}">Issue</a>{{ end }}</div>
+      <div class="list-timestamp"><span class="heading-font-size">{{.CheckedAtFormatted}}</span></div>
+      </div>
+    {{ end }}
+    {{ else }}
+    <p class="placeholder">No projects have been checked for drift.</p>
+    {{ end }}
+  </section>
+</div>
+<footer>
+v{{ .AtlantisVersion }}
+</footer>
+</body>
+</html>
+`))
+
 var ProjectJobsTemplate = template.Must(template.New("blank.html.tmpl").Parse(`
 <!DOCTYPE html>
 <html lang="en">
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config"
@@ -1143,6 +1144,8 @@ func TestParseGlobalCfg(t *testing.T) {
 	}
 	postWorkflowHooks := []*valid.WorkflowHook{postWorkflowHook}
 
+	apiTokenExpiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	customWorkflow1 := valid.Workflow{
 		Name: "custom1",
 		Plan: valid.Stage{
@@ -1237,6 +1240,114 @@ func TestParseGlobalCfg(t *testing.T) {
   apply_requirements: [invalid]`,
 			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.).).",
 		},
+		"invalid lock_max_age": {
+			input: `repos:
+- id: /.*/
+  lock_max_age: 2 days`,
+			expErr: "repos: (0: (lock_max_age: parsing: 2 days: time: unknown unit \" days\" in duration \"2 days\".).).",
+		},
+		"negative lock_max_age": {
+			input: `repos:
+- id: /.*/
+  lock_max_age: -1h`,
+			expErr: "repos: (0: (lock_max_age: \"-1h\" must not be negative.).).",
+		},
+		"lock_max_age": {
+			input: `repos:
+- id: github.com/owner/repo
+  lock_max_age: 168h`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:         "github.com/owner/repo",
+						LockMaxAge: Duration(168 * time.Hour),
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+			},
+		},
+		"drift_detection": {
+			input: `repos:
+- id: github.com/owner/repo
+  drift_detection:
+    projects: [prod]
+    create_issue: true`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID: "github.com/owner/repo",
+						DriftDetection: &valid.DriftDetection{
+							Enabled:     true,
+							Projects:    []string{"prod"},
+							CreateIssue: true,
+						},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+			},
+		},
+		"drift_detection with regex id": {
+			input: `repos:
+- id: /.*/
+  drift_detection:
+    enabled: true`,
+			expErr: "repos: (0: (drift_detection: only supported for repos with an exact id.).).",
+		},
+		"api_tokens": {
+			input: `api_tokens:
+- name: deploy-bot
+  token: secret
+  repos: [github.com/owner/*]
+  commands: [plan, apply]
+  expires: 2030-01-01T00:00:00Z`,
+			exp: valid.GlobalCfg{
+				Repos:     defaultCfg.Repos,
+				Workflows: defaultCfg.Workflows,
+				APITokens: []valid.APIToken{
+					{
+						Name:     "deploy-bot",
+						Token:    "secret",
+						Repos:    []string{"github.com/owner/*"},
+						Commands: []string{"plan", "apply"},
+						Expires:  &apiTokenExpiry,
+					},
+				},
+			},
+		},
+		"invalid api_tokens command": {
+			input: `api_tokens:
+- name: deploy-bot
+  token: secret
+  repos: ["*"]
+  commands: [destroy]`,
+			expErr: "api_tokens: (0: (commands: \"destroy\" is not a valid command, only \"plan\", \"apply\" and \"unlock\" are supported.).).",
+		},
+		"duplicate api_tokens": {
+			input: `api_tokens:
+- name: deploy-bot
+  token: secret
+  repos: ["*"]
+  commands: [plan]
+- name: deploy-bot
+  token: other
+  repos: ["*"]
+  commands: [plan]`,
+			expErr: "api token name \"deploy-bot\" is used more than once",
+		},
+		"api_tokens with the same token": {
+			input: `api_tokens:
+- name: deploy-bot
+  token: secret
+  repos: ["*"]
+  commands: [plan]
+- name: other-bot
+  token: secret
+  repos: ["github.com/owner/*"]
+  commands: [plan]`,
+			expErr: "api token \"other-bot\" has the same token as another api token",
+		},
 		"no workflows key": {
 			input: `repos: []`,
 			exp:   defaultCfg,
@@ -1374,9 +1485,11 @@ policies:
 					Version: conftestVersion,
 					PolicySets: []valid.PolicySet{
 						{
-							Name:   "good-policy",
-							Path:   "rel/path/to/policy",
-							Source: valid.LocalPolicySet,
+							Name:         "good-policy",
+							Path:         "rel/path/to/policy",
+							Source:       valid.LocalPolicySet,
+							ApproveCount: 1,
+							Severity:     valid.EnforcePolicySetSeverity,
 						},
 					},
 				},
@@ -1671,9 +1784,11 @@ func TestParserValidator_ParseGlobalCfgJSON(t *testing.T) {
 					Version: conftestVersion,
 					PolicySets: []valid.PolicySet{
 						{
-							Name:   "good-policy",
-							Path:   "rel/path/to/policy",
-							Source: valid.LocalPolicySet,
+							Name:         "good-policy",
+							Path:         "rel/path/to/policy",
+							Source:       valid.LocalPolicySet,
+							ApproveCount: 1,
+							Severity:     valid.EnforcePolicySetSeverity,
 						},
 					},
 				},
@@ -1785,3 +1900,7 @@ func String(v string) *string { return &v }
 // Bool is a helper routine that allocates a new bool value
 // to store v and returns a pointer to it.
 func Bool(v bool) *bool { return &v }
+
+// Duration is a helper routine that allocates a new time.Duration value
+// to store v and returns a pointer to it.
+func Duration(v time.Duration) *time.Duration { return &v }
@@ -0,0 +1,75 @@
+package raw
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// APIToken is the raw schema for a scoped API token in the server-side repo
+// config.
+type APIToken struct {
+	Name     string   `yaml:"name" json:"name"`
+	Token    string   `yaml:"token" json:"token"`
+	Repos    []string `yaml:"repos" json:"repos"`
+	Commands []string `yaml:"commands" json:"commands"`
+	Expires  string   `yaml:"expires,omitempty" json:"expires,omitempty"`
+}
+
+func (t APIToken) Validate() error {
+	reposValid := func(value interface{}) error {
+		for _, repo := range value.([]string) {
+			// Repos use the same syntax as --repo-allowlist.
+			if strings.Contains(repo, "://") {
+				return fmt.Errorf("%q must not contain ://", repo)
+			}
+		}
+		return nil
+	}
+
+	commandsValid := func(value interface{}) error {
+		for _, c := range value.([]string) {
+			if c != valid.PlanAPICommand && c != valid.ApplyAPICommand && c != valid.UnlockAPICommand {
+				return fmt.Errorf("%q is not a valid command, only %q, %q and %q are supported", c, valid.PlanAPICommand, valid.ApplyAPICommand, valid.UnlockAPICommand)
+			}
+		}
+		return nil
+	}
+
+	expiresValid := func(value interface{}) error {
+		expires := value.(string)
+		if expires == "" {
+			return nil
+		}
+		_, err := time.Parse(time.RFC3339, expires)
+		return errors.Wrapf(err, "parsing: %s", expires)
+	}
+
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.Name, validation.Required),
+		validation.Field(&t.Token, validation.Required),
+		validation.Field(&t.Repos, validation.Required, validation.By(reposValid)),
+		validation.Field(&t.Commands, validation.Required, validation.By(commandsValid)),
+		validation.Field(&t.Expires, validation.By(expiresValid)),
+	)
+}
+
+func (t APIToken) ToValid() valid.APIToken {
+	var expires *time.Time
+	if t.Expires != "" {
+		// Safe to ignore the error because we test it in Validate().
+		e, _ := time.Parse(time.RFC3339, t.Expires)
+		expires = &e
+	}
+	return valid.APIToken{
+		Name:     t.Name,
+		Token:    t.Token,
+		Repos:    t.Repos,
+		Commands: t.Commands,
+		Expires:  expires,
+	}
+}
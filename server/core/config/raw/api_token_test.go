@@ -0,0 +1,135 @@
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIToken_Validate_Success(t *testing.T) {
+	cases := []struct {
+		description string
+		subject     raw.APIToken
+	}{
+		{
+			description: "without expiry",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Repos:    []string{"github.com/owner/*"},
+				Commands: []string{"plan", "apply", "unlock"},
+			},
+		},
+		{
+			description: "with expiry",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Repos:    []string{"*"},
+				Commands: []string{"plan"},
+				Expires:  "2030-01-01T00:00:00Z",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.NoError(t, c.subject.Validate())
+		})
+	}
+}
+
+func TestAPIToken_Validate_Error(t *testing.T) {
+	cases := []struct {
+		description string
+		subject     raw.APIToken
+		expErr      string
+	}{
+		{
+			description: "missing name",
+			subject: raw.APIToken{
+				Token:    "secret",
+				Repos:    []string{"*"},
+				Commands: []string{"plan"},
+			},
+			expErr: "name: cannot be blank.",
+		},
+		{
+			description: "missing token",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Repos:    []string{"*"},
+				Commands: []string{"plan"},
+			},
+			expErr: "token: cannot be blank.",
+		},
+		{
+			description: "missing repos",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Commands: []string{"plan"},
+			},
+			expErr: "repos: cannot be blank.",
+		},
+		{
+			description: "repo with scheme",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Repos:    []string{"https://github.com/owner/repo"},
+				Commands: []string{"plan"},
+			},
+			expErr: "repos: \"https://github.com/owner/repo\" must not contain ://.",
+		},
+		{
+			description: "invalid command",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Repos:    []string{"*"},
+				Commands: []string{"destroy"},
+			},
+			expErr: "commands: \"destroy\" is not a valid command, only \"plan\", \"apply\" and \"unlock\" are supported.",
+		},
+		{
+			description: "invalid expiry",
+			subject: raw.APIToken{
+				Name:     "deploy-bot",
+				Token:    "secret",
+				Repos:    []string{"*"},
+				Commands: []string{"plan"},
+				Expires:  "tomorrow",
+			},
+			expErr: "expires: parsing: tomorrow: parsing time \"tomorrow\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"tomorrow\" as \"2006\".",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.EqualError(t, c.subject.Validate(), c.expErr)
+		})
+	}
+}
+
+func TestAPIToken_ToValid(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, valid.APIToken{
+		Name:     "deploy-bot",
+		Token:    "secret",
+		Repos:    []string{"*"},
+		Commands: []string{"plan"},
+		Expires:  &expires,
+	}, raw.APIToken{
+		Name:     "deploy-bot",
+		Token:    "secret",
+		Repos:    []string{"*"},
+		Commands: []string{"plan"},
+		Expires:  "2030-01-01T00:00:00Z",
+	}.ToValid())
+
+	assert.Nil(t, raw.APIToken{Name: "deploy-bot"}.ToValid().Expires)
+}
@@ -0,0 +1,47 @@
+package raw
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// DefaultAutodiscoverEnabled is the default setting for autodiscover.
+const DefaultAutodiscoverEnabled = false
+
+type Autodiscover struct {
+	Enabled     *bool    `yaml:"enabled,omitempty"`
+	IgnorePaths []string `yaml:"ignore_paths,omitempty"`
+}
+
+func (a Autodiscover) Validate() error {
+	validPatterns := func(value interface{}) error {
+		for _, pattern := range value.([]string) {
+			if strings.Contains(pattern, "..") {
+				return fmt.Errorf("%q cannot contain '..'", pattern)
+			}
+			if _, err := filepath.Match(strings.TrimPrefix(pattern, "!"), ""); err != nil {
+				return errors.Wrapf(err, "%q is not a valid pattern", pattern)
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.IgnorePaths, validation.By(validPatterns)),
+	)
+}
+
+func (a Autodiscover) ToValid() valid.Autodiscover {
+	v := valid.Autodiscover{
+		Enabled:     DefaultAutodiscoverEnabled,
+		IgnorePaths: a.IgnorePaths,
+	}
+	if a.Enabled != nil {
+		v.Enabled = *a.Enabled
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAutodiscover_UnmarshalYAML(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.Autodiscover
+	}{
+		{
+			description: "omit unset fields",
+			input:       "",
+			exp:         raw.Autodiscover{},
+		},
+		{
+			description: "all fields set",
+			input: `
+enabled: true
+ignore_paths: ["modules", "test/*"]
+`,
+			exp: raw.Autodiscover{
+				Enabled:     Bool(true),
+				IgnorePaths: []string{"modules", "test/*"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var a raw.Autodiscover
+			err := yaml.UnmarshalStrict([]byte(c.input), &a)
+			Ok(t, err)
+			Equals(t, c.exp, a)
+		})
+	}
+}
+
+func TestAutodiscover_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Autodiscover
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.Autodiscover{},
+		},
+		{
+			description: "valid patterns",
+			input: raw.Autodiscover{
+				Enabled:     Bool(true),
+				IgnorePaths: []string{"modules/**", "!modules/keep", "test/*"},
+			},
+		},
+		{
+			description: "dot dot",
+			input: raw.Autodiscover{
+				IgnorePaths: []string{"../other"},
+			},
+			expErr: "IgnorePaths: \"../other\" cannot contain '..'.",
+		},
+		{
+			description: "bad pattern",
+			input: raw.Autodiscover{
+				IgnorePaths: []string{"modules/["},
+			},
+			expErr: "IgnorePaths: \"modules/[\" is not a valid pattern: syntax error in pattern.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+				return
+			}
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+func TestAutodiscover_ToValid(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Autodiscover
+		exp         valid.Autodiscover
+	}{
+		{
+			description: "nothing set",
+			input:       raw.Autodiscover{},
+			exp:         valid.Autodiscover{Enabled: false},
+		},
+		{
+			description: "all fields set",
+			input: raw.Autodiscover{
+				Enabled:     Bool(true),
+				IgnorePaths: []string{"modules"},
+			},
+			exp: valid.Autodiscover{
+				Enabled:     true,
+				IgnorePaths: []string{"modules"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, c.input.ToValid())
+		})
+	}
+}
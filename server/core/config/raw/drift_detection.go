@@ -0,0 +1,27 @@
+package raw
+
+import (
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// DriftDetection is the raw schema for the drift detection of a repo in the
+// server-side repo config.
+type DriftDetection struct {
+	Enabled     *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Projects    []string `yaml:"projects,omitempty" json:"projects,omitempty"`
+	CreateIssue bool     `yaml:"create_issue,omitempty" json:"create_issue,omitempty"`
+}
+
+func (d DriftDetection) ToValid() *valid.DriftDetection {
+	// Drift detection is enabled by setting drift_detection unless it's
+	// explicitly disabled.
+	enabled := true
+	if d.Enabled != nil {
+		enabled = *d.Enabled
+	}
+	return &valid.DriftDetection{
+		Enabled:     enabled,
+		Projects:    d.Projects,
+		CreateIssue: d.CreateIssue,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/pkg/errors"
@@ -16,20 +17,23 @@ type GlobalCfg struct {
 	Workflows  map[string]Workflow `yaml:"workflows" json:"workflows"`
 	PolicySets PolicySets          `yaml:"policies" json:"policies"`
 	Metrics    Metrics             `yaml:"metrics" json:"metrics"`
+	APITokens  []APIToken          `yaml:"api_tokens" json:"api_tokens"`
 }
 
 // Repo is the raw schema for repos in the server-side repo config.
 type Repo struct {
-	ID                        string         `yaml:"id" json:"id"`
-	Branch                    string         `yaml:"branch" json:"branch"`
-	ApplyRequirements         []string       `yaml:"apply_requirements" json:"apply_requirements"`
-	PreWorkflowHooks          []WorkflowHook `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
-	Workflow                  *string        `yaml:"workflow,omitempty" json:"workflow,omitempty"`
-	PostWorkflowHooks         []WorkflowHook `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
-	AllowedWorkflows          []string       `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
-	AllowedOverrides          []string       `yaml:"allowed_overrides" json:"allowed_overrides"`
-	AllowCustomWorkflows      *bool          `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
-	DeleteSourceBranchOnMerge *bool          `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
+	ID                        string          `yaml:"id" json:"id"`
+	Branch                    string          `yaml:"branch" json:"branch"`
+	ApplyRequirements         []string        `yaml:"apply_requirements" json:"apply_requirements"`
+	PreWorkflowHooks          []WorkflowHook  `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
+	Workflow                  *string         `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+	PostWorkflowHooks         []WorkflowHook  `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
+	AllowedWorkflows          []string        `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
+	AllowedOverrides          []string        `yaml:"allowed_overrides" json:"allowed_overrides"`
+	AllowCustomWorkflows      *bool           `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
+	DeleteSourceBranchOnMerge *bool           `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
+	LockMaxAge                *string         `yaml:"lock_max_age,omitempty" json:"lock_max_age,omitempty"`
+	DriftDetection            *DriftDetection `yaml:"drift_detection,omitempty" json:"drift_detection,omitempty"`
 }
 
 func (g GlobalCfg) Validate() error {
@@ -37,11 +41,26 @@ func (g GlobalCfg) Validate() error {
 		validation.Field(&g.Repos),
 		validation.Field(&g.Workflows),
 		validation.Field(&g.Metrics),
+		validation.Field(&g.APITokens),
 	)
 	if err != nil {
 		return err
 	}
 
+	// Check that API tokens can be told apart.
+	tokenNames := make(map[string]bool)
+	tokens := make(map[string]bool)
+	for _, t := range g.APITokens {
+		if tokenNames[t.Name] {
+			return fmt.Errorf("api token name %q is used more than once", t.Name)
+		}
+		if tokens[t.Token] {
+			return fmt.Errorf("api token %q has the same token as another api token", t.Name)
+		}
+		tokenNames[t.Name] = true
+		tokens[t.Token] = true
+	}
+
 	// Check that all workflows referenced by repos are actually defined.
 	for _, repo := range g.Repos {
 		if repo.Workflow == nil {
@@ -128,11 +147,17 @@ func (g GlobalCfg) ToValid(defaultCfg valid.GlobalCfg) valid.GlobalCfg {
 	}
 	repos = append(defaultCfg.Repos, repos...)
 
+	var apiTokens []valid.APIToken
+	for _, t := range g.APITokens {
+		apiTokens = append(apiTokens, t.ToValid())
+	}
+
 	return valid.GlobalCfg{
 		Repos:      repos,
 		Workflows:  workflows,
 		PolicySets: g.PolicySets.ToValid(),
 		Metrics:    g.Metrics.ToValid(),
+		APITokens:  apiTokens,
 	}
 }
 
@@ -187,6 +212,29 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	lockMaxAgeValid := func(value interface{}) error {
+		maxAge := value.(*string)
+		if maxAge == nil {
+			return nil
+		}
+		d, err := time.ParseDuration(*maxAge)
+		if err != nil {
+			return errors.Wrapf(err, "parsing: %s", *maxAge)
+		}
+		if d < 0 {
+			return fmt.Errorf("%q must not be negative", *maxAge)
+		}
+		return nil
+	}
+
+	driftDetectionValid := func(value interface{}) error {
+		// We can only clone repos that we know the exact id of.
+		if value.(*DriftDetection) != nil && r.HasRegexID() {
+			return errors.New("only supported for repos with an exact id")
+		}
+		return nil
+	}
+
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.ID, validation.Required, validation.By(idValid)),
 		validation.Field(&r.Branch, validation.By(branchValid)),
@@ -194,6 +242,8 @@ func (r Repo) Validate() error {
 		validation.Field(&r.ApplyRequirements, validation.By(validApplyReq)),
 		validation.Field(&r.Workflow, validation.By(workflowExists)),
 		validation.Field(&r.DeleteSourceBranchOnMerge, validation.By(deleteSourceBranchOnMergeValid)),
+		validation.Field(&r.LockMaxAge, validation.By(lockMaxAgeValid)),
+		validation.Field(&r.DriftDetection, validation.By(driftDetectionValid)),
 	)
 }
 
@@ -252,6 +302,18 @@ OUTER:
 		mergedApplyReqs = append(mergedApplyReqs, globalReq)
 	}
 
+	var lockMaxAge *time.Duration
+	if r.LockMaxAge != nil {
+		// Safe to ignore the error because we test it in Validate().
+		d, _ := time.ParseDuration(*r.LockMaxAge)
+		lockMaxAge = &d
+	}
+
+	var driftDetection *valid.DriftDetection
+	if r.DriftDetection != nil {
+		driftDetection = r.DriftDetection.ToValid()
+	}
+
 	return valid.Repo{
 		ID:                        id,
 		IDRegex:                   idRegex,
@@ -264,5 +326,7 @@ OUTER:
 		AllowedOverrides:          r.AllowedOverrides,
 		AllowCustomWorkflows:      r.AllowCustomWorkflows,
 		DeleteSourceBranchOnMerge: r.DeleteSourceBranchOnMerge,
+		LockMaxAge:                lockMaxAge,
+		DriftDetection:            driftDetection,
 	}
 }
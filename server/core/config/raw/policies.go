@@ -1,11 +1,18 @@
 package raw
 
 import (
+	"errors"
+	"fmt"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 )
 
+// DefaultPolicySetApproveCount is the number of approvals a failing policy set
+// needs if approve_count isn't set.
+const DefaultPolicySetApproveCount = 1
+
 // PolicySets is the raw schema for repo-level atlantis.yaml config.
 type PolicySets struct {
 	Version    *string      `yaml:"conftest_version,omitempty" json:"conftest_version,omitempty"`
@@ -40,6 +47,7 @@ func (p PolicySets) ToValid() valid.PolicySets {
 
 type PolicyOwners struct {
 	Users []string `yaml:"users,omitempty" json:"users,omitempty"`
+	Teams []string `yaml:"teams,omitempty" json:"teams,omitempty"`
 }
 
 func (o PolicyOwners) ToValid() valid.PolicyOwners {
@@ -48,6 +56,9 @@ func (o PolicyOwners) ToValid() valid.PolicyOwners {
 	if len(o.Users) > 0 {
 		policyOwners.Users = o.Users
 	}
+	if len(o.Teams) > 0 {
+		policyOwners.Teams = o.Teams
+	}
 	return policyOwners
 }
 
@@ -55,15 +66,39 @@ type PolicySet struct {
 	Path   string       `yaml:"path" json:"path"`
 	Source string       `yaml:"source" json:"source"`
 	Name   string       `yaml:"name" json:"name"`
+	Repo   string       `yaml:"repo,omitempty" json:"repo,omitempty"`
+	Ref    string       `yaml:"ref,omitempty" json:"ref,omitempty"`
 	Owners PolicyOwners `yaml:"owners,omitempty" json:"owners,omitempty"`
+	// ApproveCount is the number of approvals required for the policy set to
+	// pass if it fails. Defaults to 1.
+	ApproveCount *int `yaml:"approve_count,omitempty" json:"approve_count,omitempty"`
+	// Severity is whether the failures of the policy set fail the policy check
+	// (enforce) or are only reported (warn). Defaults to enforce.
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"`
 }
 
 func (p PolicySet) Validate() error {
+	isRemote := p.Source == valid.GitPolicySet || p.Source == valid.GithubPolicySet
+	requiredIfRemote := func(value interface{}) error {
+		if isRemote && value.(string) == "" {
+			return fmt.Errorf("is required for %q policy sets", p.Source)
+		}
+		return nil
+	}
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.Name, validation.Required.Error("is required")),
 		validation.Field(&p.Owners),
 		validation.Field(&p.Path, validation.Required.Error("is required")),
-		validation.Field(&p.Source, validation.In(valid.LocalPolicySet, valid.GithubPolicySet).Error("only 'local' and 'github' source types are supported")),
+		validation.Field(&p.Source, validation.In(valid.LocalPolicySet, valid.GithubPolicySet, valid.GitPolicySet).Error("only 'local', 'github' and 'git' source types are supported")),
+		validation.Field(&p.Repo, validation.By(requiredIfRemote)),
+		validation.Field(&p.Ref, validation.By(requiredIfRemote)),
+		validation.Field(&p.Severity, validation.In(valid.EnforcePolicySetSeverity, valid.WarnPolicySetSeverity).Error("only 'enforce' and 'warn' severities are supported")),
+		validation.Field(&p.ApproveCount, validation.By(func(value interface{}) error {
+			if count := value.(*int); count != nil && *count < 1 {
+				return errors.New("must be at least 1")
+			}
+			return nil
+		})),
 	)
 }
 
@@ -73,7 +108,17 @@ func (p PolicySet) ToValid() valid.PolicySet {
 	policySet.Name = p.Name
 	policySet.Path = p.Path
 	policySet.Source = p.Source
+	policySet.Repo = p.Repo
+	policySet.Ref = p.Ref
 	policySet.Owners = p.Owners.ToValid()
+	policySet.ApproveCount = DefaultPolicySetApproveCount
+	if p.ApproveCount != nil {
+		policySet.ApproveCount = *p.ApproveCount
+	}
+	policySet.Severity = valid.EnforcePolicySetSeverity
+	if p.Severity != "" {
+		policySet.Severity = p.Severity
+	}
 
 	return policySet
 }
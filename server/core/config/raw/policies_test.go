@@ -37,6 +37,65 @@ policy_sets:
 				},
 			},
 		},
+		{
+			description: "git policy set",
+			input: `
+policy_sets:
+- name: policy-name
+  source: git
+  repo: https://github.com/runatlantis/policies.git
+  ref: v1.0.0
+  path: policies
+`,
+			exp: raw.PolicySets{
+				PolicySets: []raw.PolicySet{
+					{
+						Name:   "policy-name",
+						Source: valid.GitPolicySet,
+						Repo:   "https://github.com/runatlantis/policies.git",
+						Ref:    "v1.0.0",
+						Path:   "policies",
+					},
+				},
+			},
+		},
+		{
+			description: "owner teams and approve count",
+			input: `
+owners:
+  teams:
+  - policy-admins
+policy_sets:
+- name: policy-name
+  source: local
+  path: policies
+  approve_count: 2
+  severity: warn
+  owners:
+    users:
+    - john-doe
+    teams:
+    - security
+`,
+			exp: raw.PolicySets{
+				Owners: raw.PolicyOwners{
+					Teams: []string{"policy-admins"},
+				},
+				PolicySets: []raw.PolicySet{
+					{
+						Name:         "policy-name",
+						Source:       valid.LocalPolicySet,
+						Path:         "policies",
+						ApproveCount: Int(2),
+						Severity:     valid.WarnPolicySetSeverity,
+						Owners: raw.PolicyOwners{
+							Users: []string{"john-doe"},
+							Teams: []string{"security"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -88,6 +147,15 @@ func TestPolicySets_Validate(t *testing.T) {
 						},
 						Path:   "rel/path/to/source",
 						Source: valid.GithubPolicySet,
+						Repo:   "runatlantis/policies",
+						Ref:    "v1.0.0",
+					},
+					{
+						Name:   "policy-name-3",
+						Path:   ".",
+						Source: valid.GitPolicySet,
+						Repo:   "https://gitlab.com/runatlantis/policies.git",
+						Ref:    "main",
 					},
 				},
 			},
@@ -121,7 +189,48 @@ func TestPolicySets_Validate(t *testing.T) {
 					},
 				},
 			},
-			expErr: "policy_sets: (0: (source: only 'local' and 'github' source types are supported.).).",
+			expErr: "policy_sets: (0: (source: only 'local', 'github' and 'git' source types are supported.).).",
+		},
+		{
+			description: "git source without repo and ref",
+			input: raw.PolicySets{
+				PolicySets: []raw.PolicySet{
+					{
+						Name:   "good-policy",
+						Source: valid.GitPolicySet,
+						Path:   "rel/path/to/source",
+					},
+				},
+			},
+			expErr: "policy_sets: (0: (ref: is required for \"git\" policy sets; repo: is required for \"git\" policy sets.).).",
+		},
+		{
+			description: "approve count less than 1",
+			input: raw.PolicySets{
+				PolicySets: []raw.PolicySet{
+					{
+						Name:         "good-policy",
+						Source:       valid.LocalPolicySet,
+						Path:         "rel/path/to/source",
+						ApproveCount: Int(0),
+					},
+				},
+			},
+			expErr: "policy_sets: (0: (approve_count: must be at least 1.).).",
+		},
+		{
+			description: "invalid severity",
+			input: raw.PolicySets{
+				PolicySets: []raw.PolicySet{
+					{
+						Name:     "good-policy",
+						Source:   valid.LocalPolicySet,
+						Path:     "rel/path/to/source",
+						Severity: "error",
+					},
+				},
+			},
+			expErr: "policy_sets: (0: (severity: only 'enforce' and 'warn' severities are supported.).).",
 		},
 		{
 			description: "empty string version",
@@ -209,8 +318,47 @@ func TestPolicySets_ToValid(t *testing.T) {
 								"jane-doe",
 							},
 						},
-						Path:   "rel/path/to/source",
-						Source: "local",
+						Path:         "rel/path/to/source",
+						Source:       "local",
+						ApproveCount: 1,
+						Severity:     valid.EnforcePolicySetSeverity,
+					},
+				},
+			},
+		},
+		{
+			description: "owner teams and approve count",
+			input: raw.PolicySets{
+				Owners: raw.PolicyOwners{
+					Teams: []string{"policy-admins"},
+				},
+				PolicySets: []raw.PolicySet{
+					{
+						Name:         "good-policy",
+						Path:         "rel/path/to/source",
+						Source:       valid.LocalPolicySet,
+						ApproveCount: Int(2),
+						Severity:     valid.WarnPolicySetSeverity,
+						Owners: raw.PolicyOwners{
+							Teams: []string{"security"},
+						},
+					},
+				},
+			},
+			exp: valid.PolicySets{
+				Owners: valid.PolicyOwners{
+					Teams: []string{"policy-admins"},
+				},
+				PolicySets: []valid.PolicySet{
+					{
+						Name:         "good-policy",
+						Path:         "rel/path/to/source",
+						Source:       "local",
+						ApproveCount: 2,
+						Severity:     valid.WarnPolicySetSeverity,
+						Owners: valid.PolicyOwners{
+							Teams: []string{"security"},
+						},
 					},
 				},
 			},
@@ -244,8 +392,10 @@ func TestPolicySets_ToValid(t *testing.T) {
 								"jane-doe",
 							},
 						},
-						Path:   "rel/path/to/source",
-						Source: "local",
+						Path:         "rel/path/to/source",
+						Source:       "local",
+						ApproveCount: 1,
+						Severity:     valid.EnforcePolicySetSeverity,
 					},
 				},
 			},
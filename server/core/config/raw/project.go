@@ -25,6 +25,7 @@ type Project struct {
 	Workspace                 *string   `yaml:"workspace,omitempty"`
 	Workflow                  *string   `yaml:"workflow,omitempty"`
 	TerraformVersion          *string   `yaml:"terraform_version,omitempty"`
+	Distribution              *string   `yaml:"distribution,omitempty"`
 	Autoplan                  *Autoplan `yaml:"autoplan,omitempty"`
 	ApplyRequirements         []string  `yaml:"apply_requirements,omitempty"`
 	DeleteSourceBranchOnMerge *bool     `yaml:"delete_source_branch_on_merge,omitempty"`
@@ -56,6 +57,7 @@ func (p Project) Validate() error {
 		validation.Field(&p.Dir, validation.Required, validation.By(hasDotDot)),
 		validation.Field(&p.ApplyRequirements, validation.By(validApplyReq)),
 		validation.Field(&p.TerraformVersion, validation.By(VersionValidator)),
+		validation.Field(&p.Distribution, validation.In(valid.TerraformDistribution, valid.OpenTofuDistribution)),
 		validation.Field(&p.Name, validation.By(validName)),
 	)
 }
@@ -78,6 +80,7 @@ func (p Project) ToValid() valid.Project {
 	if p.TerraformVersion != nil {
 		v.TerraformVersion, _ = version.NewVersion(*p.TerraformVersion)
 	}
+	v.Distribution = p.Distribution
 	if p.Autoplan == nil {
 		v.Autoplan = DefaultAutoPlan()
 	} else {
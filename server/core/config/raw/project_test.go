@@ -185,6 +185,22 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: "",
 		},
+		{
+			description: "opentofu distribution",
+			input: raw.Project{
+				Dir:          String("."),
+				Distribution: String("opentofu"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unknown distribution",
+			input: raw.Project{
+				Dir:          String("."),
+				Distribution: String("terragrunt"),
+			},
+			expErr: "distribution: must be a valid value.",
+		},
 		{
 			description: "empty string for project name",
 			input: raw.Project{
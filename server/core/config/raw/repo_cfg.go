@@ -33,6 +33,7 @@ type RepoCfg struct {
 	ParallelPlan              *bool               `yaml:"parallel_plan,omitempty"`
 	DeleteSourceBranchOnMerge *bool               `yaml:"delete_source_branch_on_merge,omitempty"`
 	AllowedRegexpPrefixes     []string            `yaml:"allowed_regexp_prefixes,omitempty"`
+	Autodiscover              *Autodiscover       `yaml:"autodiscover,omitempty"`
 }
 
 func (r RepoCfg) Validate() error {
@@ -50,6 +51,7 @@ func (r RepoCfg) Validate() error {
 		validation.Field(&r.Version, validation.By(equals2)),
 		validation.Field(&r.Projects),
 		validation.Field(&r.Workflows),
+		validation.Field(&r.Autodiscover),
 	)
 }
 
@@ -79,6 +81,11 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		parallelPlan = *r.ParallelPlan
 	}
 
+	var autodiscover valid.Autodiscover
+	if r.Autodiscover != nil {
+		autodiscover = r.Autodiscover.ToValid()
+	}
+
 	return valid.RepoCfg{
 		Version:                   *r.Version,
 		Projects:                  validProjects,
@@ -89,5 +96,6 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		ParallelPolicyCheck:       parallelPlan,
 		DeleteSourceBranchOnMerge: r.DeleteSourceBranchOnMerge,
 		AllowedRegexpPrefixes:     r.AllowedRegexpPrefixes,
+		Autodiscover:              autodiscover,
 	}
 }
@@ -0,0 +1,41 @@
+package valid
+
+import "time"
+
+// Commands that an APIToken can be allowed to run.
+const (
+	PlanAPICommand   = "plan"
+	ApplyAPICommand  = "apply"
+	UnlockAPICommand = "unlock"
+)
+
+// APIToken is a token that authenticates requests to the API. Unlike the API
+// secret, it's only allowed to run some commands on some repos.
+type APIToken struct {
+	// Name identifies the token. It's used as the user for the locks and
+	// commands the token creates.
+	Name  string
+	Token string
+	// Repos are the repos the token can access, using the same syntax as
+	// --repo-allowlist.
+	Repos    []string
+	Commands []string
+	// Expires is when the token stops being accepted. If nil, the token never
+	// expires.
+	Expires *time.Time
+}
+
+// AllowsCommand returns true if the token is allowed to run cmd.
+func (t APIToken) AllowsCommand(cmd string) bool {
+	for _, c := range t.Commands {
+		if c == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the token has expired at now.
+func (t APIToken) IsExpired(now time.Time) bool {
+	return t.Expires != nil && !now.Before(*t.Expires)
+}
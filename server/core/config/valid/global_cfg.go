@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -22,6 +23,7 @@ const AllowedOverridesKey = "allowed_overrides"
 const AllowCustomWorkflowsKey = "allow_custom_workflows"
 const DefaultWorkflowName = "default"
 const DeleteSourceBranchOnMergeKey = "delete_source_branch_on_merge"
+const LockMaxAgeKey = "lock_max_age"
 
 // NonOverrideableApplyReqs will get applied across all "repos" in the server side config.
 // If repo config is allowed overrides, they can override this.
@@ -36,6 +38,7 @@ type GlobalCfg struct {
 	Workflows  map[string]Workflow
 	PolicySets PolicySets
 	Metrics    Metrics
+	APITokens  []APIToken
 }
 
 type Metrics struct {
@@ -69,6 +72,22 @@ type Repo struct {
 	AllowedOverrides          []string
 	AllowCustomWorkflows      *bool
 	DeleteSourceBranchOnMerge *bool
+	// LockMaxAge is how long a project lock can be held before it is
+	// released automatically. Zero means locks never expire.
+	LockMaxAge *time.Duration
+	// DriftDetection is how the repo is checked for drift. It's nil if it
+	// isn't configured.
+	DriftDetection *DriftDetection
+}
+
+// DriftDetection is how a repo is checked for drift.
+type DriftDetection struct {
+	Enabled bool
+	// Projects are the names or dirs of the projects to check. If empty, all
+	// projects are checked.
+	Projects []string
+	// CreateIssue is true if an issue should be opened when a project drifts.
+	CreateIssue bool
 }
 
 type MergedProjectCfg struct {
@@ -81,6 +100,7 @@ type MergedProjectCfg struct {
 	AutoplanEnabled           bool
 	AutoMergeDisabled         bool
 	TerraformVersion          *version.Version
+	TerraformDistribution     string
 	RepoCfgVersion            int
 	PolicySets                PolicySets
 	DeleteSourceBranchOnMerge bool
@@ -161,6 +181,9 @@ type GlobalCfgArgs struct {
 	PolicyCheckEnabled bool
 	PreWorkflowHooks   []*WorkflowHook
 	PostWorkflowHooks  []*WorkflowHook
+	// LockMaxAge is the default maximum lock age for all repos. Zero means
+	// locks never expire.
+	LockMaxAge time.Duration
 }
 
 func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
@@ -196,6 +219,11 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 		allowCustomWorkflows = true
 	}
 
+	var lockMaxAge *time.Duration
+	if args.LockMaxAge != 0 {
+		lockMaxAge = &args.LockMaxAge
+	}
+
 	return GlobalCfg{
 		Repos: []Repo{
 			{
@@ -209,6 +237,7 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 				AllowedOverrides:          allowedOverrides,
 				AllowCustomWorkflows:      &allowCustomWorkflows,
 				DeleteSourceBranchOnMerge: &deleteSourceBranchOnMerge,
+				LockMaxAge:                lockMaxAge,
 			},
 		},
 		Workflows: map[string]Workflow{
@@ -298,6 +327,11 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 	log.Debug("final settings: %s: [%s], %s: %s",
 		ApplyRequirementsKey, strings.Join(applyReqs, ","), WorkflowKey, workflow.Name)
 
+	var distribution string
+	if proj.Distribution != nil {
+		distribution = *proj.Distribution
+	}
+
 	return MergedProjectCfg{
 		ApplyRequirements:         applyReqs,
 		Workflow:                  workflow,
@@ -306,6 +340,7 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		Name:                      proj.GetName(),
 		AutoplanEnabled:           proj.Autoplan.Enabled,
 		TerraformVersion:          proj.TerraformVersion,
+		TerraformDistribution:     distribution,
 		RepoCfgVersion:            rCfg.Version,
 		PolicySets:                g.PolicySets,
 		DeleteSourceBranchOnMerge: deleteSourceBranchOnMerge,
@@ -490,6 +525,43 @@ func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (app
 	return
 }
 
+// LockMaxAge returns how long a project lock in the repo with id repoID can be
+// held before it is considered stale. Zero means locks never expire.
+// If multiple repos match, the last one that sets lock_max_age wins.
+func (g GlobalCfg) LockMaxAge(repoID string) time.Duration {
+	var maxAge time.Duration
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.LockMaxAge != nil {
+			maxAge = *repo.LockMaxAge
+		}
+	}
+	return maxAge
+}
+
+// DriftDetectionRepos returns the repos that are checked for drift. If a
+// repo sets drift_detection more than once, the last one wins.
+func (g GlobalCfg) DriftDetectionRepos() []Repo {
+	var ids []string
+	repos := make(map[string]Repo)
+	for _, repo := range g.Repos {
+		if repo.ID == "" || repo.DriftDetection == nil {
+			continue
+		}
+		if _, ok := repos[repo.ID]; !ok {
+			ids = append(ids, repo.ID)
+		}
+		repos[repo.ID] = repo
+	}
+
+	var enabled []Repo
+	for _, id := range ids {
+		if repos[id].DriftDetection.Enabled {
+			enabled = append(enabled, repos[id])
+		}
+	}
+	return enabled
+}
+
 // MatchingRepo returns an instance of Repo which matches a given repoID.
 // If multiple repos match, return the last one for consistency with getMatchingCfg.
 func (g GlobalCfg) MatchingRepo(repoID string) *Repo {
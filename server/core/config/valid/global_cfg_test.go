@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/mohae/deepcopy"
@@ -600,9 +601,11 @@ policies:
 					Version: nil,
 					PolicySets: []valid.PolicySet{
 						{
-							Name:   "good-policy",
-							Path:   "rel/path/to/source",
-							Source: "local",
+							Name:         "good-policy",
+							Path:         "rel/path/to/source",
+							Source:       "local",
+							ApproveCount: 1,
+							Severity:     valid.EnforcePolicySetSeverity,
 						},
 					},
 				},
@@ -641,9 +644,11 @@ policies:
 					Version: version,
 					PolicySets: []valid.PolicySet{
 						{
-							Name:   "good-policy",
-							Path:   "rel/path/to/source",
-							Source: "local",
+							Name:         "good-policy",
+							Path:         "rel/path/to/source",
+							Source:       "local",
+							ApproveCount: 1,
+							Severity:     valid.EnforcePolicySetSeverity,
 						},
 					},
 				},
@@ -992,3 +997,53 @@ func String(v string) *string { return &v }
 // Bool is a helper routine that allocates a new bool value
 // to store v and returns a pointer to it.
 func Bool(v bool) *bool { return &v }
+
+func TestGlobalCfg_LockMaxAge(t *testing.T) {
+	week := 168 * time.Hour
+	day := 24 * time.Hour
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+		LockMaxAge: week,
+	})
+	gCfg.Repos = append(gCfg.Repos,
+		valid.Repo{
+			IDRegex: regexp.MustCompile("^github.com/owner/.*$"),
+		},
+		valid.Repo{
+			ID:         "github.com/owner/short",
+			LockMaxAge: &day,
+		},
+	)
+
+	Equals(t, week, gCfg.LockMaxAge("github.com/other/repo"))
+	Equals(t, week, gCfg.LockMaxAge("github.com/owner/repo"))
+	Equals(t, day, gCfg.LockMaxAge("github.com/owner/short"))
+	Equals(t, time.Duration(0), valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}).LockMaxAge("github.com/owner/repo"))
+}
+
+func TestGlobalCfg_DriftDetectionRepos(t *testing.T) {
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	gCfg.Repos = append(gCfg.Repos,
+		valid.Repo{
+			ID:             "github.com/owner/enabled",
+			DriftDetection: &valid.DriftDetection{Enabled: true},
+		},
+		valid.Repo{
+			ID:             "github.com/owner/disabled",
+			DriftDetection: &valid.DriftDetection{Enabled: true},
+		},
+		valid.Repo{
+			ID: "github.com/owner/unset",
+		},
+		valid.Repo{
+			ID:             "github.com/owner/disabled",
+			DriftDetection: &valid.DriftDetection{Enabled: false},
+		},
+		valid.Repo{
+			ID: "github.com/owner/enabled",
+		},
+	)
+
+	repos := gCfg.DriftDetectionRepos()
+	Equals(t, 1, len(repos))
+	Equals(t, "github.com/owner/enabled", repos[0].ID)
+}
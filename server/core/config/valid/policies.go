@@ -9,6 +9,14 @@ import (
 const (
 	LocalPolicySet  string = "local"
 	GithubPolicySet string = "github"
+	GitPolicySet    string = "git"
+
+	// EnforcePolicySetSeverity policy sets fail the policy check and block
+	// apply when they fail.
+	EnforcePolicySetSeverity string = "enforce"
+	// WarnPolicySetSeverity policy sets only report their failures, so new
+	// policies can be rolled out gradually.
+	WarnPolicySetSeverity string = "warn"
 )
 
 // PolicySets defines version of policy checker binary(conftest) and a list of
@@ -22,25 +30,80 @@ type PolicySets struct {
 
 type PolicyOwners struct {
 	Users []string
+	// Teams are the names of the VCS teams or groups whose members are owners.
+	Teams []string
 }
 
 type PolicySet struct {
 	Source string
-	Path   string
-	Name   string
+	// Path is the path to the policies. For policy sets stored in git repos,
+	// it's relative to the root of the repo.
+	Path string
+	Name string
+	// Repo is the repo the policies are stored in. It's the clone URL for
+	// GitPolicySet and owner/repo for GithubPolicySet.
+	Repo string
+	// Ref is the branch, tag or commit of Repo to use.
+	Ref    string
 	Owners PolicyOwners
+	// ApproveCount is the number of approvals from its owners that a failing
+	// policy set needs to pass.
+	ApproveCount int
+	// Severity is EnforcePolicySetSeverity or WarnPolicySetSeverity.
+	Severity string
 }
 
 func (p *PolicySets) HasPolicies() bool {
 	return len(p.PolicySets) > 0
 }
 
-func (p *PolicySets) IsOwner(username string) bool {
-	for _, uname := range p.Owners.Users {
+// HasTeamOwners returns true if any of the owners are teams, in which case the
+// teams of the user have to be looked up to check if they're an owner.
+func (p *PolicySets) HasTeamOwners() bool {
+	if len(p.Owners.Teams) > 0 {
+		return true
+	}
+	for _, policySet := range p.PolicySets {
+		if len(policySet.Owners.Teams) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwner returns true if username, or one of userTeams, is an owner of all
+// the policy sets.
+func (p *PolicySets) IsOwner(username string, userTeams []string) bool {
+	return p.Owners.IsOwner(username, userTeams)
+}
+
+// IsWarnOnly returns true if the failures of the policy set don't fail the
+// policy check.
+func (p *PolicySet) IsWarnOnly() bool {
+	return p.Severity == WarnPolicySetSeverity
+}
+
+// IsOwner returns true if username, or one of userTeams, is an owner of the
+// policy set.
+func (p *PolicySet) IsOwner(username string, userTeams []string) bool {
+	return p.Owners.IsOwner(username, userTeams)
+}
+
+// IsOwner returns true if username is one of the users, or one of userTeams is
+// one of the teams.
+func (o PolicyOwners) IsOwner(username string, userTeams []string) bool {
+	for _, uname := range o.Users {
 		if strings.EqualFold(uname, username) {
 			return true
 		}
 	}
+	for _, team := range o.Teams {
+		for _, userTeam := range userTeams {
+			if strings.EqualFold(team, userTeam) {
+				return true
+			}
+		}
+	}
 
 	return false
 }
@@ -24,6 +24,9 @@ type RepoCfg struct {
 	ParallelPolicyCheck       bool
 	DeleteSourceBranchOnMerge *bool
 	AllowedRegexpPrefixes     []string
+	// Autodiscover configures whether the Terraform root modules of the repo
+	// are planned as projects without being listed in Projects.
+	Autodiscover Autodiscover
 }
 
 func (r RepoCfg) FindProjectsByDirWorkspace(repoRelDir string, workspace string) []Project {
@@ -123,6 +126,7 @@ type Project struct {
 	Name                      *string
 	WorkflowName              *string
 	TerraformVersion          *version.Version
+	Distribution              *string
 	Autoplan                  Autoplan
 	ApplyRequirements         []string
 	DeleteSourceBranchOnMerge *bool
@@ -138,6 +142,14 @@ func (p Project) GetName() string {
 	return ""
 }
 
+// Autodiscover is the config for discovering projects.
+type Autodiscover struct {
+	Enabled bool
+	// IgnorePaths are patterns of dirs, relative to the repo root, that are
+	// never discovered as projects.
+	IgnorePaths []string
+}
+
 type Autoplan struct {
 	WhenModified []string
 	Enabled      bool
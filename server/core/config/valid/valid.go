@@ -3,3 +3,10 @@
 package valid
 
 const DefaultAutoPlanEnabled = true
+
+const (
+	// TerraformDistribution runs projects with HashiCorp's terraform binary.
+	TerraformDistribution = "terraform"
+	// OpenTofuDistribution runs projects with OpenTofu's tofu binary.
+	OpenTofuDistribution = "opentofu"
+)
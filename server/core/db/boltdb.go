@@ -22,12 +22,20 @@ type BoltDB struct {
 	locksBucketName       []byte
 	pullsBucketName       []byte
 	globalLocksBucketName []byte
+	lockQueuesBucketName  []byte
+	driftBucketName       []byte
+	driftClaimsBucketName []byte
+	apiJobsBucketName     []byte
 }
 
 const (
 	locksBucketName       = "runLocks"
 	pullsBucketName       = "pulls"
 	globalLocksBucketName = "globalLocks"
+	lockQueuesBucketName  = "lockQueues"
+	driftBucketName       = "drift"
+	driftClaimsBucketName = "driftClaims"
+	apiJobsBucketName     = "apiJobs"
 	pullKeySeparator      = "::"
 )
 
@@ -56,18 +64,113 @@ func New(dataDir string) (*BoltDB, error) {
 		if _, err = tx.CreateBucketIfNotExists([]byte(globalLocksBucketName)); err != nil {
 			return errors.Wrapf(err, "creating bucket %q", globalLocksBucketName)
 		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(lockQueuesBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", lockQueuesBucketName)
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "starting BoltDB")
 	}
 	// todo: close BoltDB when server is sigtermed
-	return &BoltDB{
+	b := &BoltDB{
 		db:                    db,
 		locksBucketName:       []byte(locksBucketName),
 		pullsBucketName:       []byte(pullsBucketName),
 		globalLocksBucketName: []byte(globalLocksBucketName),
-	}, nil
+		lockQueuesBucketName:  []byte(lockQueuesBucketName),
+		driftBucketName:       []byte(driftBucketName),
+		driftClaimsBucketName: []byte(driftClaimsBucketName),
+		apiJobsBucketName:     []byte(apiJobsBucketName),
+	}
+	if err = b.migrateLockKeys(); err != nil {
+		return nil, errors.Wrap(err, "starting BoltDB")
+	}
+	return b, nil
+}
+
+// lockKeyMove is a value that must be moved from key from to key to.
+type lockKeyMove struct {
+	from  []byte
+	to    []byte
+	value []byte
+}
+
+// migrateLockKeys moves the locks and lock queues that were stored before
+// their keys included the VCS hostname to their current keys.
+func (b *BoltDB) migrateLockKeys() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		var lockMoves []lockKeyMove
+		err := tx.Bucket(b.locksBucketName).ForEach(func(k, v []byte) error {
+			var lock models.ProjectLock
+			if err := json.Unmarshal(v, &lock); err != nil {
+				return errors.Wrapf(err, "deserializing lock at key %q", string(k))
+			}
+			lock.Project = migrateLockProject(lock)
+			return b.appendLockKeyMove(&lockMoves, k, b.lockKey(lock.Project, lock.Workspace), lock)
+		})
+		if err != nil {
+			return err
+		}
+		if err = moveLockKeys(tx.Bucket(b.locksBucketName), lockMoves); err != nil {
+			return err
+		}
+
+		var queueMoves []lockKeyMove
+		err = tx.Bucket(b.lockQueuesBucketName).ForEach(func(k, v []byte) error {
+			var queue []models.ProjectLock
+			if err := json.Unmarshal(v, &queue); err != nil {
+				return errors.Wrapf(err, "deserializing lock queue at %q", k)
+			}
+			if len(queue) == 0 {
+				return nil
+			}
+			for i := range queue {
+				queue[i].Project = migrateLockProject(queue[i])
+			}
+			return b.appendLockKeyMove(&queueMoves, k, b.lockKey(queue[0].Project, queue[0].Workspace), queue)
+		})
+		if err != nil {
+			return err
+		}
+		return moveLockKeys(tx.Bucket(b.lockQueuesBucketName), queueMoves)
+	})
+}
+
+// appendLockKeyMove appends the move of value from key from to key to to
+// moves if the keys differ.
+func (b *BoltDB) appendLockKeyMove(moves *[]lockKeyMove, from []byte, to string, value interface{}) error {
+	if string(from) == to {
+		return nil
+	}
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	*moves = append(*moves, lockKeyMove{from: append([]byte(nil), from...), to: []byte(to), value: serialized})
+	return nil
+}
+
+func moveLockKeys(bucket *bolt.Bucket, moves []lockKeyMove) error {
+	for _, m := range moves {
+		if err := bucket.Delete(m.from); err != nil {
+			return err
+		}
+		if err := bucket.Put(m.to, m.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLockProject returns the project of lock with the VCS hostname of
+// its pull request's repo if the lock was stored without one.
+func migrateLockProject(lock models.ProjectLock) models.Project {
+	p := lock.Project
+	if p.VCSHostname == "" {
+		p.VCSHostname = lock.Pull.BaseRepo.VCSHost.Hostname
+	}
+	return p
 }
 
 // NewWithDB is used for testing.
@@ -77,6 +180,10 @@ func NewWithDB(db *bolt.DB, bucket string, globalBucket string) (*BoltDB, error)
 		locksBucketName:       []byte(bucket),
 		pullsBucketName:       []byte(pullsBucketName),
 		globalLocksBucketName: []byte(globalBucket),
+		lockQueuesBucketName:  []byte(lockQueuesBucketName),
+		driftBucketName:       []byte(driftBucketName),
+		driftClaimsBucketName: []byte(driftClaimsBucketName),
+		apiJobsBucketName:     []byte(apiJobsBucketName),
 	}, nil
 }
 
@@ -253,18 +360,19 @@ func (b *BoltDB) CheckCommandLock(cmdName command.Name) (*command.Lock, error) {
 }
 
 // UnlockByPull deletes all locks associated with that pull request and returns them.
-func (b *BoltDB) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+func (b *BoltDB) UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error) {
 	var locks []models.ProjectLock
 	err := b.db.View(func(tx *bolt.Tx) error {
 		c := tx.Bucket(b.locksBucketName).Cursor()
 
-		// we can use the repoFullName as a prefix search since that's the first part of the key
-		for k, v := c.Seek([]byte(repoFullName)); k != nil && bytes.HasPrefix(k, []byte(repoFullName)); k, v = c.Next() {
+		// we can use the repoID as a prefix search since that's the first part of the key
+		prefix := []byte(repoID + "/")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
 			var lock models.ProjectLock
 			if err := json.Unmarshal(v, &lock); err != nil {
 				return errors.Wrapf(err, "deserializing lock at key %q", string(k))
 			}
-			if lock.Pull.Num == pullNum {
+			if lock.Project.RepoID() == repoID && lock.Pull.Num == pullNum {
 				locks = append(locks, lock)
 			}
 		}
@@ -311,6 +419,249 @@ func (b *BoltDB) GetLock(p models.Project, workspace string) (*models.ProjectLoc
 	return &lock, nil
 }
 
+// EnqueueLock adds lock to the end of the queue of pull requests waiting for
+// its project and workspace and returns its 1-based position in the queue.
+// If lock's pull request is already queued, its position is returned and the
+// queue is left unchanged.
+func (b *BoltDB) EnqueueLock(lock models.ProjectLock) (int, error) {
+	key := []byte(b.lockKey(lock.Project, lock.Workspace))
+	var position int
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.lockQueuesBucketName)
+		if err != nil {
+			return errors.Wrapf(err, "creating bucket %q", b.lockQueuesBucketName)
+		}
+		queue, err := b.getLockQueueFromBucket(bucket, key)
+		if err != nil {
+			return err
+		}
+		for i, queued := range queue {
+			if queued.Pull.Num == lock.Pull.Num {
+				position = i + 1
+				return nil
+			}
+		}
+		queue = append(queue, lock)
+		position = len(queue)
+		return b.writeLockQueueToBucket(bucket, key, queue)
+	})
+	return position, errors.Wrap(err, "DB transaction failed")
+}
+
+// GetLockQueue returns the pull requests waiting for the lock on project and
+// workspace, in the order they were queued.
+func (b *BoltDB) GetLockQueue(p models.Project, workspace string) ([]models.ProjectLock, error) {
+	key := []byte(b.lockKey(p, workspace))
+	var queue []models.ProjectLock
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.lockQueuesBucketName)
+		if bucket == nil {
+			return nil
+		}
+		var txErr error
+		queue, txErr = b.getLockQueueFromBucket(bucket, key)
+		return txErr
+	})
+	return queue, errors.Wrap(err, "DB transaction failed")
+}
+
+// DequeueLock removes pullNum from the queue for project and workspace.
+func (b *BoltDB) DequeueLock(p models.Project, workspace string, pullNum int) error {
+	key := []byte(b.lockKey(p, workspace))
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.lockQueuesBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return b.dequeueFromBucket(bucket, key, pullNum)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// DequeueLocksByPull removes the pull request from every lock queue of the
+// repo it is waiting in.
+func (b *BoltDB) DequeueLocksByPull(repoID string, pullNum int) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.lockQueuesBucketName)
+		if bucket == nil {
+			return nil
+		}
+
+		// We can use the repoID as a prefix search since that's the first
+		// part of the key. We collect the keys first because the bucket can't
+		// be modified while iterating over it.
+		prefix := []byte(repoID + "/")
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := b.dequeueFromBucket(bucket, k, pullNum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// UpdateRepoDrift replaces the drift detection results of the repo with id
+// repoID with drifts.
+func (b *BoltDB) UpdateRepoDrift(repoID string, drifts []models.ProjectDrift) error {
+	serialized, err := json.Marshal(drifts)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.driftBucketName)
+		if err != nil {
+			return errors.Wrapf(err, "creating bucket %q", b.driftBucketName)
+		}
+		return bucket.Put([]byte(repoID), serialized)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// GetRepoDrift returns the drift detection results of the repo with id
+// repoID.
+func (b *BoltDB) GetRepoDrift(repoID string) ([]models.ProjectDrift, error) {
+	var drifts []models.ProjectDrift
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.driftBucketName)
+		if bucket == nil {
+			return nil
+		}
+		serialized := bucket.Get([]byte(repoID))
+		if serialized == nil {
+			return nil
+		}
+		if err := json.Unmarshal(serialized, &drifts); err != nil {
+			return errors.Wrapf(err, "deserializing drift at %q with contents %q", repoID, serialized)
+		}
+		return nil
+	})
+	return drifts, errors.Wrap(err, "DB transaction failed")
+}
+
+// ListProjectDrifts returns the drift detection results of all repos.
+func (b *BoltDB) ListProjectDrifts() ([]models.ProjectDrift, error) {
+	var drifts []models.ProjectDrift
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.driftBucketName)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var repoDrifts []models.ProjectDrift
+			if err := json.Unmarshal(v, &repoDrifts); err != nil {
+				return errors.Wrapf(err, "deserializing drift at %q with contents %q", k, v)
+			}
+			drifts = append(drifts, repoDrifts...)
+			return nil
+		})
+	})
+	return drifts, errors.Wrap(err, "DB transaction failed")
+}
+
+// ClaimDriftDetection claims the drift detection of the repo with id repoID
+// until until. It returns false if it's already claimed at now.
+func (b *BoltDB) ClaimDriftDetection(repoID string, now time.Time, until time.Time) (bool, error) {
+	claimed := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.driftClaimsBucketName)
+		if err != nil {
+			return errors.Wrapf(err, "creating bucket %q", b.driftClaimsBucketName)
+		}
+		if serialized := bucket.Get([]byte(repoID)); serialized != nil {
+			var claimedUntil time.Time
+			if err := claimedUntil.UnmarshalText(serialized); err != nil {
+				return errors.Wrapf(err, "deserializing drift claim at %q with contents %q", repoID, serialized)
+			}
+			if now.Before(claimedUntil) {
+				return nil
+			}
+		}
+		serialized, err := until.MarshalText()
+		if err != nil {
+			return errors.Wrap(err, "serializing")
+		}
+		claimed = true
+		return bucket.Put([]byte(repoID), serialized)
+	})
+	return claimed, errors.Wrap(err, "DB transaction failed")
+}
+
+// UpdateAPIJob creates or replaces the API job with job's id.
+func (b *BoltDB) UpdateAPIJob(job command.APIJob) error {
+	serialized, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.apiJobsBucketName)
+		if err != nil {
+			return errors.Wrapf(err, "creating bucket %q", b.apiJobsBucketName)
+		}
+		return bucket.Put([]byte(job.ID), serialized)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// GetAPIJob returns the API job with id or nil if there is no such job.
+func (b *BoltDB) GetAPIJob(id string) (*command.APIJob, error) {
+	var job *command.APIJob
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.apiJobsBucketName)
+		if bucket == nil {
+			return nil
+		}
+		serialized := bucket.Get([]byte(id))
+		if serialized == nil {
+			return nil
+		}
+		job = &command.APIJob{}
+		if err := json.Unmarshal(serialized, job); err != nil {
+			return errors.Wrapf(err, "deserializing API job at %q with contents %q", id, serialized)
+		}
+		return nil
+	})
+	return job, errors.Wrap(err, "DB transaction failed")
+}
+
+// DeleteAPIJobsFinishedBefore deletes the API jobs that finished before t.
+func (b *BoltDB) DeleteAPIJobsFinishedBefore(t time.Time) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.apiJobsBucketName)
+		if bucket == nil {
+			return nil
+		}
+		// Collect the keys first since the bucket can't be modified while
+		// iterating over it.
+		var keys [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var job command.APIJob
+			if err := json.Unmarshal(v, &job); err != nil {
+				return errors.Wrapf(err, "deserializing API job at %q with contents %q", k, v)
+			}
+			if job.FinishedAt != nil && job.FinishedAt.Before(t) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
 // UpdatePullWithResults updates pull's status with the latest project results.
 // It returns the new PullStatus object.
 func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []command.ProjectResult) (models.PullStatus, error) {
@@ -357,6 +708,20 @@ func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []com
 						res.ProjectName == proj.ProjectName {
 
 						proj.Status = res.PlanStatus()
+						if res.PlanSuccess != nil {
+							proj.PlanSummary = res.PlanSuccess.Summary()
+						}
+						// A new plan invalidates the results of the last
+						// policy check.
+						if res.Command == command.Plan {
+							proj.PolicyStatus = nil
+							proj.PolicyApprovals = nil
+						} else if len(res.PolicySetResults) > 0 {
+							proj.PolicyStatus = res.PolicyStatus()
+						}
+						if res.PolicyApprovals != nil {
+							proj.PolicyApprovals = res.PolicyApprovals
+						}
 						updatedExisting = true
 						break
 					}
@@ -393,6 +758,23 @@ func (b *BoltDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, err
 	return s, errors.Wrap(err, "DB transaction failed")
 }
 
+// ListPullStatuses returns the statuses of all the pull requests in the DB.
+func (b *BoltDB) ListPullStatuses() ([]models.PullStatus, error) {
+	var statuses []models.PullStatus
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.pullsBucketName)
+		return bucket.ForEach(func(k, v []byte) error {
+			var p models.PullStatus
+			if err := json.Unmarshal(v, &p); err != nil {
+				return errors.Wrapf(err, "deserializing pull at %q with contents %q", k, v)
+			}
+			statuses = append(statuses, p)
+			return nil
+		})
+	})
+	return statuses, errors.Wrap(err, "DB transaction failed")
+}
+
 // DeletePullStatus deletes the status for pull.
 func (b *BoltDB) DeletePullStatus(pull models.PullRequest) error {
 	key, err := b.pullKey(pull)
@@ -457,7 +839,48 @@ func (b *BoltDB) commandLockKey(cmdName command.Name) string {
 }
 
 func (b *BoltDB) lockKey(p models.Project, workspace string) string {
-	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
+	return fmt.Sprintf("%s/%s/%s", p.RepoID(), p.Path, workspace)
+}
+
+func (b *BoltDB) dequeueFromBucket(bucket *bolt.Bucket, key []byte, pullNum int) error {
+	queue, err := b.getLockQueueFromBucket(bucket, key)
+	if err != nil {
+		return err
+	}
+	var newQueue []models.ProjectLock
+	for _, queued := range queue {
+		if queued.Pull.Num != pullNum {
+			newQueue = append(newQueue, queued)
+		}
+	}
+	if len(newQueue) == len(queue) {
+		return nil
+	}
+	if len(newQueue) == 0 {
+		return bucket.Delete(key)
+	}
+	return b.writeLockQueueToBucket(bucket, key, newQueue)
+}
+
+func (b *BoltDB) getLockQueueFromBucket(bucket *bolt.Bucket, key []byte) ([]models.ProjectLock, error) {
+	serialized := bucket.Get(key)
+	if serialized == nil {
+		return nil, nil
+	}
+
+	var queue []models.ProjectLock
+	if err := json.Unmarshal(serialized, &queue); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock queue at %q", key)
+	}
+	return queue, nil
+}
+
+func (b *BoltDB) writeLockQueueToBucket(bucket *bolt.Bucket, key []byte, queue []models.ProjectLock) error {
+	serialized, err := json.Marshal(queue)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	return bucket.Put(key, serialized)
 }
 
 func (b *BoltDB) getPullFromBucket(bucket *bolt.Bucket, key []byte) (*models.PullStatus, error) {
@@ -482,10 +905,17 @@ func (b *BoltDB) writePullToBucket(bucket *bolt.Bucket, key []byte, pull models.
 }
 
 func (b *BoltDB) projectResultToProject(p command.ProjectResult) models.ProjectStatus {
+	var planSummary string
+	if p.PlanSuccess != nil {
+		planSummary = p.PlanSuccess.Summary()
+	}
 	return models.ProjectStatus{
-		Workspace:   p.Workspace,
-		RepoRelDir:  p.RepoRelDir,
-		ProjectName: p.ProjectName,
-		Status:      p.PlanStatus(),
+		Workspace:       p.Workspace,
+		RepoRelDir:      p.RepoRelDir,
+		ProjectName:     p.ProjectName,
+		Status:          p.PlanStatus(),
+		PlanSummary:     planSummary,
+		PolicyStatus:    p.PolicyStatus(),
+		PolicyApprovals: p.PolicyApprovals,
 	}
 }
@@ -14,7 +14,10 @@
 package db_test
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -29,7 +32,7 @@ import (
 
 var lockBucket = "bucket"
 var configBucket = "configBucket"
-var project = models.NewProject("owner/repo", "parent/child")
+var project = models.NewRepoProject(models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}, "parent/child")
 var workspace = "default"
 var pullNum = 1
 var lock = models.ProjectLock{
@@ -234,6 +237,16 @@ func TestLockingExistingLock(t *testing.T) {
 		Equals(t, newLock, currLock)
 	}
 
+	t.Log("...succeed if the new project has the same repoName on a different VCS host")
+	{
+		newLock := lock
+		newLock.Project.VCSHostname = "ghe.example.com"
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+		Equals(t, newLock, currLock)
+	}
+
 	t.Log("...not succeed if the new project only has a different pullNum")
 	{
 		newLock := lock
@@ -335,7 +348,7 @@ func TestUnlockByPullOne(t *testing.T) {
 
 	t.Log("...delete nothing when its the same repo but a different pull")
 	{
-		_, err := b.UnlockByPull(project.RepoFullName, pullNum+1)
+		_, err := b.UnlockByPull(project.RepoID(), pullNum+1)
 		Ok(t, err)
 		ls, err := b.List()
 		Ok(t, err)
@@ -343,7 +356,15 @@ func TestUnlockByPullOne(t *testing.T) {
 	}
 	t.Log("...delete nothing when its the same pull but a different repo")
 	{
-		_, err := b.UnlockByPull("different/repo", pullNum)
+		_, err := b.UnlockByPull("github.com/different/repo", pullNum)
+		Ok(t, err)
+		ls, err := b.List()
+		Ok(t, err)
+		Equals(t, 1, len(ls))
+	}
+	t.Log("...delete nothing when its the same repo and pull but a different VCS host")
+	{
+		_, err := b.UnlockByPull("ghe.example.com/owner/repo", pullNum)
 		Ok(t, err)
 		ls, err := b.List()
 		Ok(t, err)
@@ -351,7 +372,7 @@ func TestUnlockByPullOne(t *testing.T) {
 	}
 	t.Log("...delete the lock when its the same repo and pull")
 	{
-		_, err := b.UnlockByPull(project.RepoFullName, pullNum)
+		_, err := b.UnlockByPull(project.RepoID(), pullNum)
 		Ok(t, err)
 		ls, err := b.List()
 		Ok(t, err)
@@ -368,7 +389,7 @@ func TestUnlockByPullAfterUnlock(t *testing.T) {
 	_, err = b.Unlock(project, workspace)
 	Ok(t, err)
 
-	_, err = b.UnlockByPull(project.RepoFullName, pullNum)
+	_, err = b.UnlockByPull(project.RepoID(), pullNum)
 	Ok(t, err)
 	ls, err := b.List()
 	Ok(t, err)
@@ -398,13 +419,56 @@ func TestUnlockByPullMatching(t *testing.T) {
 	Equals(t, 3, len(ls))
 
 	// should all be unlocked
-	_, err = b.UnlockByPull(project.RepoFullName, pullNum)
+	_, err = b.UnlockByPull(project.RepoID(), pullNum)
 	Ok(t, err)
 	ls, err = b.List()
 	Ok(t, err)
 	Equals(t, 0, len(ls))
 }
 
+func TestNew_MigratesLockKeys(t *testing.T) {
+	t.Log("locks stored before lock keys included the VCS hostname should be moved to their current keys")
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	oldLock := lock
+	oldLock.Project = models.NewProject("owner/repo", "parent/child")
+	oldLock.Pull.BaseRepo = models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}
+	serializedLock, err := json.Marshal(oldLock)
+	Ok(t, err)
+	serializedQueue, err := json.Marshal([]models.ProjectLock{oldLock})
+	Ok(t, err)
+
+	boltDB, err := bolt.Open(filepath.Join(tmp, "atlantis.db"), 0600, nil)
+	Ok(t, err)
+	Ok(t, boltDB.Update(func(tx *bolt.Tx) error {
+		for name, value := range map[string][]byte{"runLocks": serializedLock, "lockQueues": serializedQueue} {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte("owner/repo/parent/child/default"), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	Ok(t, boltDB.Close())
+
+	b, err := db.New(tmp)
+	Ok(t, err)
+	l, err := b.GetLock(project, workspace)
+	Ok(t, err)
+	Assert(t, l != nil, "exp lock to be migrated")
+	Equals(t, "github.com", l.Project.VCSHostname)
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 1, len(queue))
+	Equals(t, project, queue[0].Project)
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
 func TestGetLockNotThere(t *testing.T) {
 	t.Log("getting a lock that doesn't exist should return a nil pointer")
 	db, b := newTestDB()
@@ -430,6 +494,199 @@ func TestGetLock(t *testing.T) {
 	Equals(t, lock.User, l.User)
 }
 
+func TestLockQueue_Enqueue(t *testing.T) {
+	t.Log("pull requests should be queued in order and only once")
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+
+	second := lock
+	second.Pull.Num = pullNum + 1
+	position, err := b.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+	position, err = b.EnqueueLock(second)
+	Ok(t, err)
+	Equals(t, 2, position)
+	position, err = b.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	queue, err = b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 2, len(queue))
+	Equals(t, pullNum, queue[0].Pull.Num)
+	Equals(t, pullNum+1, queue[1].Pull.Num)
+
+	// Other workspaces have their own queue.
+	queue, err = b.GetLockQueue(project, "other-workspace")
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+}
+
+func TestLockQueue_Dequeue(t *testing.T) {
+	t.Log("dequeuing should remove only that pull request from the queue")
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	second := lock
+	second.Pull.Num = pullNum + 1
+	_, err := b.EnqueueLock(lock)
+	Ok(t, err)
+	_, err = b.EnqueueLock(second)
+	Ok(t, err)
+
+	Ok(t, b.DequeueLock(project, workspace, pullNum))
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 1, len(queue))
+	Equals(t, pullNum+1, queue[0].Pull.Num)
+
+	// Dequeuing a pull request that isn't queued is a no-op.
+	Ok(t, b.DequeueLock(project, workspace, pullNum))
+	Ok(t, b.DequeueLock(project, workspace, pullNum+1))
+	queue, err = b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+}
+
+func TestLockQueue_DequeueLocksByPull(t *testing.T) {
+	t.Log("DequeueLocksByPull should remove the pull from all queues of its repo only")
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	otherWorkspace := lock
+	otherWorkspace.Workspace = "other-workspace"
+	otherRepo := lock
+	otherRepo.Project = models.NewProject("owner/repo-other", "parent/child")
+	for _, l := range []models.ProjectLock{lock, otherWorkspace, otherRepo} {
+		_, err := b.EnqueueLock(l)
+		Ok(t, err)
+	}
+
+	Ok(t, b.DequeueLocksByPull(project.RepoID(), pullNum))
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+	queue, err = b.GetLockQueue(project, "other-workspace")
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+	queue, err = b.GetLockQueue(otherRepo.Project, workspace)
+	Ok(t, err)
+	Equals(t, 1, len(queue))
+}
+
+func TestRepoDrift_UpdateGetList(t *testing.T) {
+	t.Log("drift should be replaced per repo")
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	drifts, err := b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 0, len(drifts))
+	drifts, err = b.GetRepoDrift("github.com/owner/repo")
+	Ok(t, err)
+	Equals(t, 0, len(drifts))
+
+	drifted := models.ProjectDrift{
+		RepoID:      "github.com/owner/repo",
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		Status:      models.DriftedStatus,
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}
+	other := models.ProjectDrift{
+		RepoID:     "github.com/owner/other",
+		RepoRelDir: ".",
+		Workspace:  "default",
+		Status:     models.NoDriftStatus,
+	}
+	Ok(t, b.UpdateRepoDrift(drifted.RepoID, []models.ProjectDrift{drifted}))
+	Ok(t, b.UpdateRepoDrift(other.RepoID, []models.ProjectDrift{other}))
+
+	drifts, err = b.GetRepoDrift(drifted.RepoID)
+	Ok(t, err)
+	Equals(t, []models.ProjectDrift{drifted}, drifts)
+	drifts, err = b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 2, len(drifts))
+
+	// The results of a repo replace its previous ones.
+	drifted.Status = models.NoDriftStatus
+	drifted.PlanSummary = ""
+	Ok(t, b.UpdateRepoDrift(drifted.RepoID, []models.ProjectDrift{drifted}))
+	drifts, err = b.GetRepoDrift(drifted.RepoID)
+	Ok(t, err)
+	Equals(t, []models.ProjectDrift{drifted}, drifts)
+	drifts, err = b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 2, len(drifts))
+}
+
+func TestClaimDriftDetection(t *testing.T) {
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	claimed, err := b.ClaimDriftDetection("github.com/owner/repo", now, now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	t.Log("the repo shouldn't be claimed again until the claim expires")
+	claimed, err = b.ClaimDriftDetection("github.com/owner/repo", now.Add(time.Minute), now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = b.ClaimDriftDetection("github.com/owner/other", now, now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+	claimed, err = b.ClaimDriftDetection("github.com/owner/repo", now.Add(time.Hour), now.Add(2*time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
+func TestAPIJob_UpdateGetDelete(t *testing.T) {
+	t.Log("finished API jobs should be deleted once they're old enough")
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	job, err := b.GetAPIJob("running")
+	Ok(t, err)
+	Assert(t, job == nil, "exp no job")
+
+	finishedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	running := command.APIJob{
+		ID:           "running",
+		Status:       command.RunningAPIJobStatus,
+		RepoFullName: "owner/repo",
+		CreatedAt:    finishedAt,
+	}
+	finished := command.APIJob{
+		ID:           "finished",
+		Status:       command.SucceededAPIJobStatus,
+		RepoFullName: "owner/repo",
+		Result:       &command.ProjectResult{ApplySuccess: "success"},
+		CreatedAt:    finishedAt,
+		FinishedAt:   &finishedAt,
+	}
+	Ok(t, b.UpdateAPIJob(running))
+	Ok(t, b.UpdateAPIJob(finished))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Equals(t, finished, *job)
+
+	// Jobs that finished after the cutoff are kept.
+	Ok(t, b.DeleteAPIJobsFinishedBefore(finishedAt))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Assert(t, job != nil, "exp job to be kept")
+
+	Ok(t, b.DeleteAPIJobsFinishedBefore(finishedAt.Add(time.Second)))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Assert(t, job == nil, "exp finished job to be deleted")
+	job, err = b.GetAPIJob("running")
+	Ok(t, err)
+	Equals(t, running, *job)
+}
+
 // Test we can create a status and then getCommandLock it.
 func TestPullStatus_UpdateGet(t *testing.T) {
 	b, cleanup := newTestDB2(t)
@@ -480,6 +737,61 @@ func TestPullStatus_UpdateGet(t *testing.T) {
 	}, status.Projects)
 }
 
+func TestPullStatus_List(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	statuses, err := b.ListPullStatuses()
+	Ok(t, err)
+	Equals(t, 0, len(statuses))
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			Owner:    "runatlantis",
+			Name:     "atlantis",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	other := pull
+	other.Num = 2
+	deleted := pull
+	deleted.Num = 3
+	for _, p := range []models.PullRequest{pull, other, deleted} {
+		_, err = b.UpdatePullWithResults(p, []command.ProjectResult{
+			{
+				Command:    command.Plan,
+				RepoRelDir: ".",
+				Workspace:  "default",
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				},
+			},
+		})
+		Ok(t, err)
+	}
+	Ok(t, b.DeletePullStatus(deleted))
+
+	statuses, err = b.ListPullStatuses()
+	Ok(t, err)
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Pull.Num < statuses[j].Pull.Num })
+	Equals(t, 2, len(statuses))
+	Equals(t, pull, statuses[0].Pull)
+	Equals(t, other, statuses[1].Pull)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		Status:      models.PlannedPlanStatus,
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}}, statuses[0].Projects)
+}
+
 // Test we can create a status, delete it, and then we shouldn't be able to getCommandLock
 // it.
 func TestPullStatus_UpdateDeleteGet(t *testing.T) {
@@ -809,3 +1121,147 @@ func cleanupDB(db *bolt.DB) {
 	os.Remove(db.Path()) // nolint: errcheck
 	db.Close()           // nolint: errcheck
 }
+
+func TestPullStatus_UpdatePolicyStatus(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			Owner:    "runatlantis",
+			Name:     "atlantis",
+		},
+	}
+	policyCheck := command.ProjectResult{
+		Command:    command.PolicyCheck,
+		RepoRelDir: ".",
+		Workspace:  "default",
+		Error:      errors.New("1 policy check failures"),
+		PolicySetResults: []models.PolicySetResult{
+			{
+				PolicySetName: "policies",
+				Rules: []models.PolicyRuleResult{
+					{Namespace: "main", Name: "deny", Messages: []string{"no"}},
+					{Namespace: "main", Name: "warn", Messages: []string{"careful", "really"}},
+					{Namespace: "main", Name: "deny_other"},
+				},
+			},
+		},
+	}
+	expPolicyStatus := []models.PolicySetStatus{
+		{PolicySetName: "policies", Passes: 1, Warnings: 2, Failures: 1},
+	}
+
+	// A new project gets the policy status.
+	status, err := b.UpdatePullWithResults(pull, []command.ProjectResult{policyCheck})
+	Ok(t, err)
+	Equals(t, expPolicyStatus, status.Projects[0].PolicyStatus)
+
+	// An existing project keeps it until it's planned again.
+	status, err = b.UpdatePullWithResults(pull, []command.ProjectResult{policyCheck})
+	Ok(t, err)
+	Equals(t, models.ErroredPolicyCheckStatus, status.Projects[0].Status)
+	Equals(t, expPolicyStatus, status.Projects[0].PolicyStatus)
+
+	status, err = b.UpdatePullWithResults(pull, []command.ProjectResult{
+		{
+			Command:     command.Plan,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "tf out"},
+		},
+	})
+	Ok(t, err)
+	Equals(t, []models.PolicySetStatus(nil), status.Projects[0].PolicyStatus)
+}
+
+func TestPullStatus_UpdatePolicyApprovals(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			Owner:    "runatlantis",
+			Name:     "atlantis",
+		},
+	}
+	approvals := []models.PolicySetApproval{
+		{PolicySetName: "policies", Approvers: []string{"alice"}},
+	}
+	_, err := b.UpdatePullWithResults(pull, []command.ProjectResult{
+		{
+			Command:    command.PolicyCheck,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Failure:    "policy sets still require approval from their owners",
+		},
+	})
+	Ok(t, err)
+
+	// Approvals are kept by the results of other commands.
+	status, err := b.UpdatePullWithResults(pull, []command.ProjectResult{
+		{
+			Command:         command.PolicyCheck,
+			RepoRelDir:      ".",
+			Workspace:       "default",
+			Failure:         "policy sets still require approval from their owners",
+			PolicyApprovals: approvals,
+		},
+	})
+	Ok(t, err)
+	Equals(t, approvals, status.Projects[0].PolicyApprovals)
+
+	status, err = b.UpdatePullWithResults(pull, []command.ProjectResult{
+		{
+			Command:    command.PolicyCheck,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Failure:    "contact policy owners to approve failing policies",
+		},
+	})
+	Ok(t, err)
+	Equals(t, approvals, status.Projects[0].PolicyApprovals)
+
+	// New commits invalidate them.
+	newPull := pull
+	newPull.HeadCommit = "newsha"
+	status, err = b.UpdatePullWithResults(newPull, []command.ProjectResult{
+		{
+			Command:    command.PolicyCheck,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Error:      errors.New("1 policy check failures"),
+		},
+	})
+	Ok(t, err)
+	Equals(t, []models.PolicySetApproval(nil), status.Projects[0].PolicyApprovals)
+
+	// And so do new plans.
+	_, err = b.UpdatePullWithResults(newPull, []command.ProjectResult{
+		{
+			Command:         command.PolicyCheck,
+			RepoRelDir:      ".",
+			Workspace:       "default",
+			PolicyApprovals: approvals,
+		},
+	})
+	Ok(t, err)
+	status, err = b.UpdatePullWithResults(newPull, []command.ProjectResult{
+		{
+			Command:     command.Plan,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "tf out"},
+		},
+	})
+	Ok(t, err)
+	Equals(t, []models.PolicySetApproval(nil), status.Projects[0].PolicyApprovals)
+}
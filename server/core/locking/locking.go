@@ -32,11 +32,33 @@ type Backend interface {
 	Unlock(project models.Project, workspace string) (*models.ProjectLock, error)
 	List() ([]models.ProjectLock, error)
 	GetLock(project models.Project, workspace string) (*models.ProjectLock, error)
-	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
+	// UnlockByPull deletes the locks of the pull request pullNum in the repo
+	// with ID repoID (see models.Repo.ID()) and returns them.
+	UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error)
 
 	LockCommand(cmdName command.Name, lockTime time.Time) (*command.Lock, error)
 	UnlockCommand(cmdName command.Name) error
 	CheckCommandLock(cmdName command.Name) (*command.Lock, error)
+
+	UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error
+	GetPullStatus(pull models.PullRequest) (*models.PullStatus, error)
+	ListPullStatuses() ([]models.PullStatus, error)
+	DeletePullStatus(pull models.PullRequest) error
+	UpdatePullWithResults(pull models.PullRequest, newResults []command.ProjectResult) (models.PullStatus, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_lock_queue_store.go LockQueueStore
+
+// LockQueueStore stores the queues of pull requests waiting for project locks.
+type LockQueueStore interface {
+	// EnqueueLock adds lock to the end of the queue for its project and
+	// workspace and returns its 1-based position in the queue.
+	EnqueueLock(lock models.ProjectLock) (int, error)
+	GetLockQueue(project models.Project, workspace string) ([]models.ProjectLock, error)
+	DequeueLock(project models.Project, workspace string, pullNum int) error
+	// DequeueLocksByPull removes the pull request pullNum in the repo with ID
+	// repoID from every queue it is in.
+	DequeueLocksByPull(repoID string, pullNum int) error
 }
 
 // TryLockResponse results from an attempted lock.
@@ -60,10 +82,29 @@ type Locker interface {
 	TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (TryLockResponse, error)
 	Unlock(key string) (*models.ProjectLock, error)
 	List() (map[string]models.ProjectLock, error)
-	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
+	UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error)
 	GetLock(key string) (*models.ProjectLock, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_lock_queue.go LockQueue
+
+// LockQueue queues the pull requests that are waiting for a project lock held
+// by another pull request.
+type LockQueue interface {
+	// Enqueue adds pull to the end of the queue for the project and workspace
+	// and returns its 1-based position in the queue. If pull is already queued
+	// its current position is returned.
+	Enqueue(p models.Project, workspace string, pull models.PullRequest, user models.User) (int, error)
+	// GetQueue returns the pull requests waiting for the project and
+	// workspace, in the order they were queued.
+	GetQueue(p models.Project, workspace string) ([]models.ProjectLock, error)
+	// Dequeue removes pullNum from the queue for the project and workspace.
+	Dequeue(p models.Project, workspace string, pullNum int) error
+	// DequeueByPull removes the pull request pullNum in the repo with ID
+	// repoID from every queue it is in.
+	DequeueByPull(repoID string, pullNum int) error
+}
+
 // NewClient returns a new locking client.
 func NewClient(backend Backend) *Client {
 	return &Client{
@@ -71,8 +112,8 @@ func NewClient(backend Backend) *Client {
 	}
 }
 
-// keyRegex matches and captures {repoFullName}/{path}/{workspace} where path can have multiple /'s in it.
-var keyRegex = regexp.MustCompile(`^(.*?\/.*?)\/(.*)\/(.*)$`)
+// keyRegex matches and captures {vcsHostname}/{repoFullName}/{path}/{workspace} where path can have multiple /'s in it.
+var keyRegex = regexp.MustCompile(`^([^/]*)\/(.*?\/.*?)\/(.*)\/(.*)$`)
 
 // TryLock attempts to acquire a lock to a project and workspace.
 func (c *Client) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (TryLockResponse, error) {
@@ -117,8 +158,8 @@ func (c *Client) List() (map[string]models.ProjectLock, error) {
 }
 
 // UnlockByPull deletes all locks associated with that pull request.
-func (c *Client) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
-	return c.backend.UnlockByPull(repoFullName, pullNum)
+func (c *Client) UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error) {
+	return c.backend.UnlockByPull(repoID, pullNum)
 }
 
 // GetLock attempts to get the lock stored at key. If successful,
@@ -139,17 +180,57 @@ func (c *Client) GetLock(key string) (*models.ProjectLock, error) {
 	return projectLock, nil
 }
 
+// QueueClient is used to queue pull requests for project locks.
+type QueueClient struct {
+	store LockQueueStore
+}
+
+// NewQueueClient returns a new lock queue client.
+func NewQueueClient(store LockQueueStore) *QueueClient {
+	return &QueueClient{
+		store: store,
+	}
+}
+
+// Enqueue adds pull to the end of the queue for the project and workspace and
+// returns its 1-based position in the queue.
+func (c *QueueClient) Enqueue(p models.Project, workspace string, pull models.PullRequest, user models.User) (int, error) {
+	lock := models.ProjectLock{
+		Workspace: workspace,
+		Time:      time.Now().Local(),
+		Project:   p,
+		User:      user,
+		Pull:      pull,
+	}
+	return c.store.EnqueueLock(lock)
+}
+
+// GetQueue returns the pull requests waiting for the project and workspace.
+func (c *QueueClient) GetQueue(p models.Project, workspace string) ([]models.ProjectLock, error) {
+	return c.store.GetLockQueue(p, workspace)
+}
+
+// Dequeue removes pullNum from the queue for the project and workspace.
+func (c *QueueClient) Dequeue(p models.Project, workspace string, pullNum int) error {
+	return c.store.DequeueLock(p, workspace, pullNum)
+}
+
+// DequeueByPull removes the pull request from every queue it is in.
+func (c *QueueClient) DequeueByPull(repoID string, pullNum int) error {
+	return c.store.DequeueLocksByPull(repoID, pullNum)
+}
+
 func (c *Client) key(p models.Project, workspace string) string {
-	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
+	return fmt.Sprintf("%s/%s/%s", p.RepoID(), p.Path, workspace)
 }
 
 func (c *Client) lockKeyToProjectWorkspace(key string) (models.Project, string, error) {
 	matches := keyRegex.FindStringSubmatch(key)
-	if len(matches) != 4 {
+	if len(matches) != 5 {
 		return models.Project{}, "", errors.New("invalid key format")
 	}
 
-	return models.Project{RepoFullName: matches[1], Path: matches[2]}, matches[3], nil
+	return models.Project{VCSHostname: matches[1], RepoFullName: matches[2], Path: matches[3]}, matches[4], nil
 }
 
 type NoOpLocker struct{}
@@ -180,7 +261,7 @@ func (c *NoOpLocker) List() (map[string]models.ProjectLock, error) {
 }
 
 // UnlockByPull deletes all locks associated with that pull request.
-func (c *NoOpLocker) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+func (c *NoOpLocker) UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error) {
 	return []models.ProjectLock{}, nil
 }
 
@@ -193,5 +274,5 @@ func (c *NoOpLocker) GetLock(key string) (*models.ProjectLock, error) {
 }
 
 func (c *NoOpLocker) key(p models.Project, workspace string) string {
-	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
+	return fmt.Sprintf("%s/%s/%s", p.RepoID(), p.Path, workspace)
 }
@@ -29,7 +29,7 @@ import (
 	. "github.com/runatlantis/atlantis/testing"
 )
 
-var project = models.NewProject("owner/repo", "path")
+var project = models.NewRepoProject(models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}, "path")
 var workspace = "workspace"
 var pull = models.PullRequest{}
 var user = models.User{}
@@ -55,7 +55,7 @@ func TestTryLock_Success(t *testing.T) {
 	l := locking.NewClient(backend)
 	r, err := l.TryLock(project, workspace, pull, user)
 	Ok(t, err)
-	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "owner/repo/path/workspace"}, r)
+	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "github.com/owner/repo/path/workspace"}, r)
 }
 
 func TestUnlock_InvalidKey(t *testing.T) {
@@ -73,7 +73,7 @@ func TestUnlock_Err(t *testing.T) {
 	backend := mocks.NewMockBackend()
 	When(backend.Unlock(matchers.AnyModelsProject(), AnyString())).ThenReturn(nil, errExpected)
 	l := locking.NewClient(backend)
-	_, err := l.Unlock("owner/repo/path/workspace")
+	_, err := l.Unlock("github.com/owner/repo/path/workspace")
 	Equals(t, err, err)
 	backend.VerifyWasCalledOnce().Unlock(project, "workspace")
 }
@@ -83,7 +83,7 @@ func TestUnlock(t *testing.T) {
 	backend := mocks.NewMockBackend()
 	When(backend.Unlock(matchers.AnyModelsProject(), AnyString())).ThenReturn(&pl, nil)
 	l := locking.NewClient(backend)
-	lock, err := l.Unlock("owner/repo/path/workspace")
+	lock, err := l.Unlock("github.com/owner/repo/path/workspace")
 	Ok(t, err)
 	Equals(t, &pl, lock)
 }
@@ -105,16 +105,16 @@ func TestList(t *testing.T) {
 	list, err := l.List()
 	Ok(t, err)
 	Equals(t, map[string]models.ProjectLock{
-		"owner/repo/path/workspace": pl,
+		"github.com/owner/repo/path/workspace": pl,
 	}, list)
 }
 
 func TestUnlockByPull(t *testing.T) {
 	RegisterMockTestingT(t)
 	backend := mocks.NewMockBackend()
-	When(backend.UnlockByPull("owner/repo", 1)).ThenReturn(nil, errExpected)
+	When(backend.UnlockByPull("github.com/owner/repo", 1)).ThenReturn(nil, errExpected)
 	l := locking.NewClient(backend)
-	_, err := l.UnlockByPull("owner/repo", 1)
+	_, err := l.UnlockByPull("github.com/owner/repo", 1)
 	Equals(t, errExpected, err)
 }
 
@@ -132,7 +132,7 @@ func TestGetLock_Err(t *testing.T) {
 	backend := mocks.NewMockBackend()
 	When(backend.GetLock(project, workspace)).ThenReturn(nil, errExpected)
 	l := locking.NewClient(backend)
-	_, err := l.GetLock("owner/repo/path/workspace")
+	_, err := l.GetLock("github.com/owner/repo/path/workspace")
 	Equals(t, errExpected, err)
 }
 
@@ -141,7 +141,7 @@ func TestGetLock(t *testing.T) {
 	backend := mocks.NewMockBackend()
 	When(backend.GetLock(project, workspace)).ThenReturn(&pl, nil)
 	l := locking.NewClient(backend)
-	lock, err := l.GetLock("owner/repo/path/workspace")
+	lock, err := l.GetLock("github.com/owner/repo/path/workspace")
 	Ok(t, err)
 	Equals(t, &pl, lock)
 }
@@ -152,12 +152,12 @@ func TestTryLock_NoOpLocker(t *testing.T) {
 	l := locking.NewNoOpLocker()
 	r, err := l.TryLock(project, workspace, pull, user)
 	Ok(t, err)
-	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "owner/repo/path/workspace"}, r)
+	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "github.com/owner/repo/path/workspace"}, r)
 }
 
 func TestUnlock_NoOpLocker(t *testing.T) {
 	l := locking.NewNoOpLocker()
-	lock, err := l.Unlock("owner/repo/path/workspace")
+	lock, err := l.Unlock("github.com/owner/repo/path/workspace")
 	Ok(t, err)
 	Equals(t, &models.ProjectLock{}, lock)
 }
@@ -171,13 +171,13 @@ func TestList_NoOpLocker(t *testing.T) {
 
 func TestUnlockByPull_NoOpLocker(t *testing.T) {
 	l := locking.NewNoOpLocker()
-	_, err := l.UnlockByPull("owner/repo", 1)
+	_, err := l.UnlockByPull("github.com/owner/repo", 1)
 	Ok(t, err)
 }
 
 func TestGetLock_NoOpLocker(t *testing.T) {
 	l := locking.NewNoOpLocker()
-	lock, err := l.GetLock("owner/repo/path/workspace")
+	lock, err := l.GetLock("github.com/owner/repo/path/workspace")
 	Ok(t, err)
 	var expected *models.ProjectLock
 	Equals(t, expected, lock)
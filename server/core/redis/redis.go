@@ -0,0 +1,739 @@
+// Package redis handles our remote database layer.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+var ctx = context.Background()
+
+// RedisDB is a database using Redis (or any store speaking the Redis
+// protocol). Unlike BoltDB it can be shared between multiple Atlantis
+// replicas.
+type RedisDB struct { // nolint: revive
+	client *redis.Client
+	// lockTTL is how long a project lock lives unless it's refreshed by its
+	// pull request trying to lock it again. 0 means locks don't expire.
+	lockTTL time.Duration
+}
+
+const (
+	locksKeyPrefix        = "lock/"
+	pullsKeyPrefix        = "pull/"
+	globalLocksKeyPrefix  = "global/"
+	lockQueuesKeyPrefix   = "queue/"
+	driftKeyPrefix        = "drift/"
+	driftClaimsKeyPrefix  = "driftclaim/"
+	apiJobsKeyPrefix      = "apijob/"
+	pullKeySeparator      = "::"
+	scanCount             = 100
+	maxTransactionRetries = 10
+)
+
+// New returns a new RedisDB connected to the server at hostname:port.
+// It pings the server so that misconfiguration is caught on startup.
+// Project locks expire after lockTTL unless it's 0.
+func New(hostname string, port int, password string, tlsEnabled bool, insecureSkipVerify bool, db int, lockTTL time.Duration) (*RedisDB, error) {
+	var tlsConfig *tls.Config
+	if tlsEnabled {
+		tlsConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: insecureSkipVerify, // nolint: gosec
+		}
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:      fmt.Sprintf("%s:%d", hostname, port),
+		Password:  password,
+		DB:        db,
+		TLSConfig: tlsConfig,
+	})
+
+	if _, err := rdb.Ping(ctx).Result(); err != nil {
+		return nil, errors.Wrap(err, "failed to connect to redis instance")
+	}
+
+	return &RedisDB{
+		client:  rdb,
+		lockTTL: lockTTL,
+	}, nil
+}
+
+// NewWithClient is used for testing.
+func NewWithClient(client *redis.Client, lockTTL time.Duration) (*RedisDB, error) {
+	return &RedisDB{
+		client:  client,
+		lockTTL: lockTTL,
+	}, nil
+}
+
+// TryLock attempts to create a new lock. If the lock is
+// acquired, it will return true and the lock returned will be newLock.
+// If the lock is not acquired, it will return false and the current
+// lock that is preventing this lock from being acquired.
+// If the current lock is held by newLock's pull request, its expiry is
+// refreshed.
+func (r *RedisDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock, error) {
+	key := r.lockKey(newLock.Project, newLock.Workspace)
+	newLockSerialized, _ := json.Marshal(newLock)
+
+	for i := 0; i < maxTransactionRetries; i++ {
+		acquired, err := r.client.SetNX(ctx, key, newLockSerialized, r.lockTTL).Result()
+		if err != nil {
+			return false, models.ProjectLock{}, errors.Wrap(err, "db transaction failed")
+		}
+		if acquired {
+			return true, newLock, nil
+		}
+
+		// Otherwise the lock fails, return to caller the run that's holding
+		// the lock.
+		currLock, err := r.getLock(key)
+		if err != nil {
+			return false, models.ProjectLock{}, err
+		}
+		// The lock may have been released or expired between our SETNX and
+		// our GET in which case we try again.
+		if currLock != nil {
+			if r.lockTTL > 0 && currLock.Pull.Num == newLock.Pull.Num {
+				if err := r.client.Expire(ctx, key, r.lockTTL).Err(); err != nil {
+					return false, models.ProjectLock{}, errors.Wrap(err, "db transaction failed")
+				}
+			}
+			return false, *currLock, nil
+		}
+	}
+	return false, models.ProjectLock{}, fmt.Errorf("db transaction failed: lock %q was modified concurrently too many times", key)
+}
+
+// Unlock attempts to unlock the project and workspace.
+// If there is no lock, then it will return a nil pointer.
+// If there is a lock, then it will delete it, and then return a pointer
+// to the deleted lock.
+func (r *RedisDB) Unlock(p models.Project, workspace string) (*models.ProjectLock, error) {
+	key := r.lockKey(p, workspace)
+	var lock *models.ProjectLock
+	err := r.transaction(func(tx *redis.Tx) error {
+		var err error
+		lock, err = r.getLockFromTx(tx, key)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return lock, nil
+}
+
+// List lists all current locks.
+func (r *RedisDB) List() ([]models.ProjectLock, error) {
+	return r.scanLocks(locksKeyPrefix + "*")
+}
+
+// LockCommand attempts to create a new lock for a CommandName.
+// If the lock doesn't exists, it will create a lock and return a pointer to it.
+// If the lock already exists, it will return an "lock already exists" error
+func (r *RedisDB) LockCommand(cmdName command.Name, lockTime time.Time) (*command.Lock, error) {
+	lock := command.Lock{
+		CommandName: cmdName,
+		LockMetadata: command.LockMetadata{
+			UnixTime: lockTime.Unix(),
+		},
+	}
+
+	newLockSerialized, _ := json.Marshal(lock)
+	acquired, err := r.client.SetNX(ctx, r.commandLockKey(cmdName), newLockSerialized, 0).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	if !acquired {
+		return nil, errors.New("db transaction failed: lock already exists")
+	}
+	return &lock, nil
+}
+
+// UnlockCommand removes CommandName lock if present.
+// If there are no lock it returns an error.
+func (r *RedisDB) UnlockCommand(cmdName command.Name) error {
+	deleted, err := r.client.Del(ctx, r.commandLockKey(cmdName)).Result()
+	if err != nil {
+		return errors.Wrap(err, "db transaction failed")
+	}
+	if deleted == 0 {
+		return errors.New("db transaction failed: no lock exists")
+	}
+	return nil
+}
+
+// CheckCommandLock checks if CommandName lock was set.
+// If the lock exists return the pointer to the lock object, otherwise return nil
+func (r *RedisDB) CheckCommandLock(cmdName command.Name) (*command.Lock, error) {
+	serialized, err := r.client.Get(ctx, r.commandLockKey(cmdName)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+
+	var cmdLock command.Lock
+	if err := json.Unmarshal(serialized, &cmdLock); err != nil {
+		return nil, errors.Wrap(err, "failed to deserialize command lock")
+	}
+	return &cmdLock, nil
+}
+
+// UnlockByPull deletes all locks associated with that pull request and returns them.
+func (r *RedisDB) UnlockByPull(repoID string, pullNum int) ([]models.ProjectLock, error) {
+	candidates, err := r.scanLocks(locksKeyPrefix + escapeGlob(repoID) + "/*")
+	if err != nil {
+		return nil, err
+	}
+
+	var locks []models.ProjectLock
+	for _, lock := range candidates {
+		if lock.Project.RepoID() == repoID && lock.Pull.Num == pullNum {
+			locks = append(locks, lock)
+		}
+	}
+
+	// delete the locks
+	for _, lock := range locks {
+		if _, err = r.Unlock(lock.Project, lock.Workspace); err != nil {
+			return locks, errors.Wrapf(err, "unlocking repo %s, path %s, workspace %s", lock.Project.RepoFullName, lock.Project.Path, lock.Workspace)
+		}
+	}
+	return locks, nil
+}
+
+// GetLock returns a pointer to the lock for that project and workspace.
+// If there is no lock, it returns a nil pointer.
+func (r *RedisDB) GetLock(p models.Project, workspace string) (*models.ProjectLock, error) {
+	return r.getLock(r.lockKey(p, workspace))
+}
+
+// EnqueueLock adds lock to the end of the queue of pull requests waiting for
+// its project and workspace and returns its 1-based position in the queue.
+// If lock's pull request is already queued, its position is returned and the
+// queue is left unchanged.
+func (r *RedisDB) EnqueueLock(lock models.ProjectLock) (int, error) {
+	key := r.lockQueueKey(lock.Project, lock.Workspace)
+	var position int
+	err := r.transaction(func(tx *redis.Tx) error {
+		queue, err := r.getLockQueueFromTx(tx, key)
+		if err != nil {
+			return err
+		}
+		for i, queued := range queue {
+			if queued.Pull.Num == lock.Pull.Num {
+				position = i + 1
+				return nil
+			}
+		}
+		queue = append(queue, lock)
+		position = len(queue)
+		return r.writeLockQueueToTx(tx, key, queue)
+	}, key)
+	return position, errors.Wrap(err, "db transaction failed")
+}
+
+// GetLockQueue returns the pull requests waiting for the lock on project and
+// workspace, in the order they were queued.
+func (r *RedisDB) GetLockQueue(p models.Project, workspace string) ([]models.ProjectLock, error) {
+	key := r.lockQueueKey(p, workspace)
+	var queue []models.ProjectLock
+	err := r.transaction(func(tx *redis.Tx) error {
+		var txErr error
+		queue, txErr = r.getLockQueueFromTx(tx, key)
+		return txErr
+	}, key)
+	return queue, errors.Wrap(err, "db transaction failed")
+}
+
+// DequeueLock removes pullNum from the queue for project and workspace.
+func (r *RedisDB) DequeueLock(p models.Project, workspace string, pullNum int) error {
+	return errors.Wrap(r.dequeue(r.lockQueueKey(p, workspace), pullNum), "db transaction failed")
+}
+
+// DequeueLocksByPull removes the pull request from every lock queue of the
+// repo it is waiting in.
+func (r *RedisDB) DequeueLocksByPull(repoID string, pullNum int) error {
+	iter := r.client.Scan(ctx, 0, lockQueuesKeyPrefix+escapeGlob(repoID)+"/*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		if err := r.dequeue(iter.Val(), pullNum); err != nil {
+			return errors.Wrap(err, "db transaction failed")
+		}
+	}
+	return errors.Wrap(iter.Err(), "db transaction failed")
+}
+
+// UpdateRepoDrift replaces the drift detection results of the repo with id
+// repoID with drifts.
+func (r *RedisDB) UpdateRepoDrift(repoID string, drifts []models.ProjectDrift) error {
+	serialized, err := json.Marshal(drifts)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	err = r.client.Set(ctx, driftKeyPrefix+repoID, serialized, 0).Err()
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// GetRepoDrift returns the drift detection results of the repo with id
+// repoID.
+func (r *RedisDB) GetRepoDrift(repoID string) ([]models.ProjectDrift, error) {
+	return r.getDrift(driftKeyPrefix + repoID)
+}
+
+// ListProjectDrifts returns the drift detection results of all repos.
+func (r *RedisDB) ListProjectDrifts() ([]models.ProjectDrift, error) {
+	var drifts []models.ProjectDrift
+	iter := r.client.Scan(ctx, 0, driftKeyPrefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		repoDrifts, err := r.getDrift(iter.Val())
+		if err != nil {
+			return drifts, err
+		}
+		drifts = append(drifts, repoDrifts...)
+	}
+	return drifts, errors.Wrap(iter.Err(), "db transaction failed")
+}
+
+// ClaimDriftDetection claims the drift detection of the repo with id repoID
+// until until. It returns false if it's already claimed at now. The claim
+// expires at until.
+func (r *RedisDB) ClaimDriftDetection(repoID string, now time.Time, until time.Time) (bool, error) {
+	serialized, err := until.MarshalText()
+	if err != nil {
+		return false, errors.Wrap(err, "serializing")
+	}
+	claimed, err := r.client.SetNX(ctx, driftClaimsKeyPrefix+repoID, serialized, until.Sub(now)).Result()
+	return claimed, errors.Wrap(err, "db transaction failed")
+}
+
+// UpdatePullWithResults updates pull's status with the latest project results.
+// It returns the new PullStatus object.
+func (r *RedisDB) UpdatePullWithResults(pull models.PullRequest, newResults []command.ProjectResult) (models.PullStatus, error) {
+	key, err := r.pullKey(pull)
+	if err != nil {
+		return models.PullStatus{}, err
+	}
+
+	var newStatus models.PullStatus
+	err = r.transaction(func(tx *redis.Tx) error {
+		currStatus, err := r.getPullFromTx(tx, key)
+		if err != nil {
+			return err
+		}
+
+		// If there is no pull OR if the pull we have is out of date, we
+		// just write a new pull.
+		if currStatus == nil || currStatus.Pull.HeadCommit != pull.HeadCommit {
+			var statuses []models.ProjectStatus
+			for _, res := range newResults {
+				statuses = append(statuses, r.projectResultToProject(res))
+			}
+			newStatus = models.PullStatus{
+				Pull:     pull,
+				Projects: statuses,
+			}
+		} else {
+			// If there's an existing pull at the right commit then we have to
+			// merge our project results with the existing ones. We do a merge
+			// because it's possible a user is just applying a single project
+			// in this command and so we don't want to delete our data about
+			// other projects that aren't affected by this command.
+			newStatus = *currStatus
+			for _, res := range newResults {
+				// First, check if we should update any existing projects.
+				updatedExisting := false
+				for i := range newStatus.Projects {
+					// NOTE: We're using a reference here because we are
+					// in-place updating its Status field.
+					proj := &newStatus.Projects[i]
+					if res.Workspace == proj.Workspace &&
+						res.RepoRelDir == proj.RepoRelDir &&
+						res.ProjectName == proj.ProjectName {
+
+						proj.Status = res.PlanStatus()
+						if res.PlanSuccess != nil {
+							proj.PlanSummary = res.PlanSuccess.Summary()
+						}
+						// A new plan invalidates the results of the last
+						// policy check.
+						if res.Command == command.Plan {
+							proj.PolicyStatus = nil
+							proj.PolicyApprovals = nil
+						} else if len(res.PolicySetResults) > 0 {
+							proj.PolicyStatus = res.PolicyStatus()
+						}
+						if res.PolicyApprovals != nil {
+							proj.PolicyApprovals = res.PolicyApprovals
+						}
+						updatedExisting = true
+						break
+					}
+				}
+
+				if !updatedExisting {
+					// If we didn't update an existing project, then we need to
+					// add this because it's a new one.
+					newStatus.Projects = append(newStatus.Projects, r.projectResultToProject(res))
+				}
+			}
+		}
+
+		// Now, we overwrite the key with our new status.
+		return r.writePullToTx(tx, key, newStatus)
+	}, key)
+	return newStatus, errors.Wrap(err, "db transaction failed")
+}
+
+// GetPullStatus returns the status for pull.
+// If there is no status, returns a nil pointer.
+func (r *RedisDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, error) {
+	key, err := r.pullKey(pull)
+	if err != nil {
+		return nil, err
+	}
+	var s *models.PullStatus
+	err = r.transaction(func(tx *redis.Tx) error {
+		var txErr error
+		s, txErr = r.getPullFromTx(tx, key)
+		return txErr
+	}, key)
+	return s, errors.Wrap(err, "db transaction failed")
+}
+
+// ListPullStatuses returns the statuses of all the pull requests in the DB.
+func (r *RedisDB) ListPullStatuses() ([]models.PullStatus, error) {
+	var statuses []models.PullStatus
+	iter := r.client.Scan(ctx, 0, pullsKeyPrefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		serialized, err := r.client.Get(ctx, key).Bytes()
+		// The key may have expired or been deleted since it was scanned.
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return statuses, errors.Wrapf(err, "getting pull at %q", key)
+		}
+		var p models.PullStatus
+		if err := json.Unmarshal(serialized, &p); err != nil {
+			return statuses, errors.Wrapf(err, "deserializing pull at %q with contents %q", key, serialized)
+		}
+		statuses = append(statuses, p)
+	}
+	return statuses, errors.Wrap(iter.Err(), "db transaction failed")
+}
+
+// DeletePullStatus deletes the status for pull.
+func (r *RedisDB) DeletePullStatus(pull models.PullRequest) error {
+	key, err := r.pullKey(pull)
+	if err != nil {
+		return err
+	}
+	err = r.client.Del(ctx, key).Err()
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// UpdateProjectStatus updates project status.
+func (r *RedisDB) UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error {
+	key, err := r.pullKey(pull)
+	if err != nil {
+		return err
+	}
+	err = r.transaction(func(tx *redis.Tx) error {
+		currStatusPtr, err := r.getPullFromTx(tx, key)
+		if err != nil {
+			return err
+		}
+		if currStatusPtr == nil {
+			return nil
+		}
+		currStatus := *currStatusPtr
+
+		// Update the status.
+		for i := range currStatus.Projects {
+			// NOTE: We're using a reference here because we are
+			// in-place updating its Status field.
+			proj := &currStatus.Projects[i]
+			if proj.Workspace == workspace && proj.RepoRelDir == repoRelDir {
+				proj.Status = newStatus
+				break
+			}
+		}
+		return r.writePullToTx(tx, key, currStatus)
+	}, key)
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// transaction runs fn inside an optimistic transaction that watches keys. If
+// any of the keys is modified by another client before fn's writes are
+// executed, fn is retried.
+func (r *RedisDB) transaction(fn func(tx *redis.Tx) error, keys ...string) error {
+	for i := 0; i < maxTransactionRetries; i++ {
+		err := r.client.Watch(ctx, fn, keys...)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return fmt.Errorf("keys %v were modified concurrently too many times", keys)
+}
+
+func (r *RedisDB) scanLocks(match string) ([]models.ProjectLock, error) {
+	var locks []models.ProjectLock
+	iter := r.client.Scan(ctx, 0, match, scanCount).Iterator()
+	for iter.Next(ctx) {
+		lock, err := r.getLock(iter.Val())
+		if err != nil {
+			return locks, err
+		}
+		// The key may have expired or been deleted since it was scanned.
+		if lock != nil {
+			locks = append(locks, *lock)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return locks, errors.Wrap(err, "db transaction failed")
+	}
+	return locks, nil
+}
+
+func (r *RedisDB) getLock(key string) (*models.ProjectLock, error) {
+	return r.deserializeLock(key, r.client.Get(ctx, key))
+}
+
+func (r *RedisDB) getLockFromTx(tx *redis.Tx, key string) (*models.ProjectLock, error) {
+	return r.deserializeLock(key, tx.Get(ctx, key))
+}
+
+func (r *RedisDB) deserializeLock(key string, cmd *redis.StringCmd) (*models.ProjectLock, error) {
+	serialized, err := cmd.Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "getting lock data")
+	}
+
+	var lock models.ProjectLock
+	if err := json.Unmarshal(serialized, &lock); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock at key %q", key)
+	}
+
+	// need to set it to Local after deserialization due to https://github.com/golang/go/issues/19486
+	lock.Time = lock.Time.Local()
+	return &lock, nil
+}
+
+func (r *RedisDB) getPullFromTx(tx *redis.Tx, key string) (*models.PullStatus, error) {
+	serialized, err := tx.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting pull at %q", key)
+	}
+
+	var p models.PullStatus
+	if err := json.Unmarshal(serialized, &p); err != nil {
+		return nil, errors.Wrapf(err, "deserializing pull at %q with contents %q", key, serialized)
+	}
+	return &p, nil
+}
+
+// writePullToTx writes pull to key. Any expiry already set on the key is
+// kept so operators can expire stale pull statuses from the store.
+func (r *RedisDB) writePullToTx(tx *redis.Tx, key string, pull models.PullStatus) error {
+	serialized, err := json.Marshal(pull)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, serialized, redis.KeepTTL)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisDB) dequeue(key string, pullNum int) error {
+	return r.transaction(func(tx *redis.Tx) error {
+		queue, err := r.getLockQueueFromTx(tx, key)
+		if err != nil {
+			return err
+		}
+		var newQueue []models.ProjectLock
+		for _, queued := range queue {
+			if queued.Pull.Num != pullNum {
+				newQueue = append(newQueue, queued)
+			}
+		}
+		if len(newQueue) == len(queue) {
+			return nil
+		}
+		if len(newQueue) == 0 {
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Del(ctx, key)
+				return nil
+			})
+			return err
+		}
+		return r.writeLockQueueToTx(tx, key, newQueue)
+	}, key)
+}
+
+func (r *RedisDB) getLockQueueFromTx(tx *redis.Tx, key string) ([]models.ProjectLock, error) {
+	serialized, err := tx.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting lock queue at %q", key)
+	}
+
+	var queue []models.ProjectLock
+	if err := json.Unmarshal(serialized, &queue); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock queue at %q", key)
+	}
+	return queue, nil
+}
+
+func (r *RedisDB) writeLockQueueToTx(tx *redis.Tx, key string, queue []models.ProjectLock) error {
+	serialized, err := json.Marshal(queue)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, serialized, 0)
+		return nil
+	})
+	return err
+}
+
+// UpdateAPIJob creates or replaces the API job with job's id.
+func (r *RedisDB) UpdateAPIJob(job command.APIJob) error {
+	serialized, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	err = r.client.Set(ctx, apiJobsKeyPrefix+job.ID, serialized, 0).Err()
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// GetAPIJob returns the API job with id or nil if there is no such job.
+func (r *RedisDB) GetAPIJob(id string) (*command.APIJob, error) {
+	return r.getAPIJob(apiJobsKeyPrefix + id)
+}
+
+// DeleteAPIJobsFinishedBefore deletes the API jobs that finished before t.
+func (r *RedisDB) DeleteAPIJobsFinishedBefore(t time.Time) error {
+	iter := r.client.Scan(ctx, 0, apiJobsKeyPrefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		job, err := r.getAPIJob(iter.Val())
+		if err != nil {
+			return err
+		}
+		if job != nil && job.FinishedAt != nil && job.FinishedAt.Before(t) {
+			if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
+				return errors.Wrap(err, "db transaction failed")
+			}
+		}
+	}
+	return errors.Wrap(iter.Err(), "db transaction failed")
+}
+
+// getAPIJob returns the API job at key or nil if the key doesn't exist.
+func (r *RedisDB) getAPIJob(key string) (*command.APIJob, error) {
+	serialized, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting API job at %q", key)
+	}
+	var job command.APIJob
+	if err := json.Unmarshal(serialized, &job); err != nil {
+		return nil, errors.Wrapf(err, "deserializing API job at %q with contents %q", key, serialized)
+	}
+	return &job, nil
+}
+
+// getDrift returns the drift detection results at key. The key may have been
+// deleted since it was scanned, so it's not an error if it doesn't exist.
+func (r *RedisDB) getDrift(key string) ([]models.ProjectDrift, error) {
+	serialized, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting drift at %q", key)
+	}
+	var drifts []models.ProjectDrift
+	if err := json.Unmarshal(serialized, &drifts); err != nil {
+		return nil, errors.Wrapf(err, "deserializing drift at %q with contents %q", key, serialized)
+	}
+	return drifts, nil
+}
+
+func (r *RedisDB) pullKey(pull models.PullRequest) (string, error) {
+	hostname := pull.BaseRepo.VCSHost.Hostname
+	if strings.Contains(hostname, pullKeySeparator) {
+		return "", fmt.Errorf("vcs hostname %q contains illegal string %q", hostname, pullKeySeparator)
+	}
+	repo := pull.BaseRepo.FullName
+	if strings.Contains(repo, pullKeySeparator) {
+		return "", fmt.Errorf("repo name %q contains illegal string %q", repo, pullKeySeparator)
+	}
+
+	return fmt.Sprintf("%s%s::%s::%d", pullsKeyPrefix, hostname, repo, pull.Num), nil
+}
+
+func (r *RedisDB) commandLockKey(cmdName command.Name) string {
+	return fmt.Sprintf("%s%s/lock", globalLocksKeyPrefix, cmdName)
+}
+
+func (r *RedisDB) lockKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", locksKeyPrefix, p.RepoID(), p.Path, workspace)
+}
+
+func (r *RedisDB) lockQueueKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", lockQueuesKeyPrefix, p.RepoID(), p.Path, workspace)
+}
+
+func (r *RedisDB) projectResultToProject(p command.ProjectResult) models.ProjectStatus {
+	var planSummary string
+	if p.PlanSuccess != nil {
+		planSummary = p.PlanSuccess.Summary()
+	}
+	return models.ProjectStatus{
+		Workspace:       p.Workspace,
+		RepoRelDir:      p.RepoRelDir,
+		ProjectName:     p.ProjectName,
+		Status:          p.PlanStatus(),
+		PlanSummary:     planSummary,
+		PolicyStatus:    p.PolicyStatus(),
+		PolicyApprovals: p.PolicyApprovals,
+	}
+}
+
+// escapeGlob escapes the characters that have special meaning in a Redis
+// SCAN MATCH pattern.
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,1101 @@
+package redis_test
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/redis"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var project = models.NewRepoProject(models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}, "parent/child")
+var workspace = "default"
+var pullNum = 1
+var lock = models.ProjectLock{
+	Pull: models.PullRequest{
+		Num: pullNum,
+	},
+	User: models.User{
+		Username: "lkysow",
+	},
+	Workspace: workspace,
+	Project:   project,
+	Time:      time.Now(),
+}
+
+func TestLockCommandNotSet(t *testing.T) {
+	t.Log("retrieving apply lock when there are none should return empty LockCommand")
+	b := newTestRedis(t)
+	exists, err := b.CheckCommandLock(command.Apply)
+	Ok(t, err)
+	Assert(t, exists == nil, "exp nil")
+}
+
+func TestLockCommandEnabled(t *testing.T) {
+	t.Log("setting the apply lock")
+	b := newTestRedis(t)
+	timeNow := time.Now()
+	_, err := b.LockCommand(command.Apply, timeNow)
+	Ok(t, err)
+
+	config, err := b.CheckCommandLock(command.Apply)
+	Ok(t, err)
+	Equals(t, true, config.IsLocked())
+}
+
+func TestLockCommandFail(t *testing.T) {
+	t.Log("setting the apply lock")
+	b := newTestRedis(t)
+	timeNow := time.Now()
+	_, err := b.LockCommand(command.Apply, timeNow)
+	Ok(t, err)
+
+	_, err = b.LockCommand(command.Apply, timeNow)
+	ErrEquals(t, "db transaction failed: lock already exists", err)
+}
+
+func TestUnlockCommandDisabled(t *testing.T) {
+	t.Log("unsetting the apply lock")
+	b := newTestRedis(t)
+	timeNow := time.Now()
+	_, err := b.LockCommand(command.Apply, timeNow)
+	Ok(t, err)
+
+	config, err := b.CheckCommandLock(command.Apply)
+	Ok(t, err)
+	Equals(t, true, config.IsLocked())
+
+	err = b.UnlockCommand(command.Apply)
+	Ok(t, err)
+
+	config, err = b.CheckCommandLock(command.Apply)
+	Ok(t, err)
+	Assert(t, config == nil, "exp nil object")
+}
+
+func TestUnlockCommandFail(t *testing.T) {
+	t.Log("setting the apply lock")
+	b := newTestRedis(t)
+	err := b.UnlockCommand(command.Apply)
+	ErrEquals(t, "db transaction failed: no lock exists", err)
+}
+
+func TestMixedLocksPresent(t *testing.T) {
+	b := newTestRedis(t)
+	timeNow := time.Now()
+	_, err := b.LockCommand(command.Apply, timeNow)
+	Ok(t, err)
+
+	_, _, err = b.TryLock(lock)
+	Ok(t, err)
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+func TestListNoLocks(t *testing.T) {
+	t.Log("listing locks when there are none should return an empty list")
+	b := newTestRedis(t)
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestListOneLock(t *testing.T) {
+	t.Log("listing locks when there is one should return it")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+func TestListMultipleLocks(t *testing.T) {
+	t.Log("listing locks when there are multiple should return them")
+	b := newTestRedis(t)
+
+	// add multiple locks
+	repos := []string{
+		"owner/repo1",
+		"owner/repo2",
+		"owner/repo3",
+		"owner/repo4",
+	}
+
+	for _, r := range repos {
+		newLock := lock
+		newLock.Project = models.NewProject(r, "path")
+		_, _, err := b.TryLock(newLock)
+		Ok(t, err)
+	}
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 4, len(ls))
+	for _, r := range repos {
+		found := false
+		for _, l := range ls {
+			if l.Project.RepoFullName == r {
+				found = true
+			}
+		}
+		Assert(t, found, "expected %s in %v", r, ls)
+	}
+}
+
+func TestListAddRemove(t *testing.T) {
+	t.Log("listing after adding and removing should return none")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	_, err = b.Unlock(project, workspace)
+	Ok(t, err)
+
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestLockingNoLocks(t *testing.T) {
+	t.Log("with no locks yet, lock should succeed")
+	b := newTestRedis(t)
+	acquired, currLock, err := b.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, lock, currLock)
+}
+
+func TestLockingExistingLock(t *testing.T) {
+	t.Log("if there is an existing lock, lock should...")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+
+	t.Log("...succeed if the new project has a different path")
+	{
+		newLock := lock
+		newLock.Project = models.NewProject(project.RepoFullName, "different/path")
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+		Equals(t, pullNum, currLock.Pull.Num)
+	}
+
+	t.Log("...succeed if the new project has a different workspace")
+	{
+		newLock := lock
+		newLock.Workspace = "different-workspace"
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+		Equals(t, newLock, currLock)
+	}
+
+	t.Log("...succeed if the new project has a different repoName")
+	{
+		newLock := lock
+		newLock.Project = models.NewProject("different/repo", project.Path)
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+		Equals(t, newLock, currLock)
+	}
+
+	t.Log("...succeed if the new project has the same repoName on a different VCS host")
+	{
+		newLock := lock
+		newLock.Project.VCSHostname = "ghe.example.com"
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+		Equals(t, newLock, currLock)
+	}
+
+	t.Log("...not succeed if the new project only has a different pullNum")
+	{
+		newLock := lock
+		newLock.Pull.Num = lock.Pull.Num + 1
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Equals(t, currLock.Pull.Num, pullNum)
+	}
+}
+
+func TestUnlockingNoLocks(t *testing.T) {
+	t.Log("unlocking with no locks should succeed")
+	b := newTestRedis(t)
+	_, err := b.Unlock(project, workspace)
+
+	Ok(t, err)
+}
+
+func TestUnlocking(t *testing.T) {
+	t.Log("unlocking with an existing lock should succeed")
+	b := newTestRedis(t)
+
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	_, err = b.Unlock(project, workspace)
+	Ok(t, err)
+
+	// should be no locks listed
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+
+	// should be able to re-lock that repo with a new pull num
+	newLock := lock
+	newLock.Pull.Num = lock.Pull.Num + 1
+	acquired, currLock, err := b.TryLock(newLock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, newLock, currLock)
+}
+
+func TestUnlockingMultiple(t *testing.T) {
+	t.Log("unlocking and locking multiple locks should succeed")
+	b := newTestRedis(t)
+
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+
+	new := lock
+	new.Project.RepoFullName = "new/repo"
+	_, _, err = b.TryLock(new)
+	Ok(t, err)
+
+	new2 := lock
+	new2.Project.Path = "new/path"
+	_, _, err = b.TryLock(new2)
+	Ok(t, err)
+
+	new3 := lock
+	new3.Workspace = "new-workspace"
+	_, _, err = b.TryLock(new3)
+	Ok(t, err)
+
+	// now try and unlock them
+	_, err = b.Unlock(new3.Project, new3.Workspace)
+	Ok(t, err)
+	_, err = b.Unlock(new2.Project, workspace)
+	Ok(t, err)
+	_, err = b.Unlock(new.Project, workspace)
+	Ok(t, err)
+	_, err = b.Unlock(project, workspace)
+	Ok(t, err)
+
+	// should be none left
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockByPullNone(t *testing.T) {
+	t.Log("UnlockByPull should be successful when there are no locks")
+	b := newTestRedis(t)
+
+	_, err := b.UnlockByPull("any/repo", 1)
+	Ok(t, err)
+}
+
+func TestUnlockByPullOne(t *testing.T) {
+	t.Log("with one lock, UnlockByPull should...")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+
+	t.Log("...delete nothing when its the same repo but a different pull")
+	{
+		_, err := b.UnlockByPull(project.RepoID(), pullNum+1)
+		Ok(t, err)
+		ls, err := b.List()
+		Ok(t, err)
+		Equals(t, 1, len(ls))
+	}
+	t.Log("...delete nothing when its the same pull but a different repo")
+	{
+		_, err := b.UnlockByPull("github.com/different/repo", pullNum)
+		Ok(t, err)
+		ls, err := b.List()
+		Ok(t, err)
+		Equals(t, 1, len(ls))
+	}
+	t.Log("...delete nothing when its the same repo and pull but a different VCS host")
+	{
+		_, err := b.UnlockByPull("ghe.example.com/owner/repo", pullNum)
+		Ok(t, err)
+		ls, err := b.List()
+		Ok(t, err)
+		Equals(t, 1, len(ls))
+	}
+	t.Log("...delete the lock when its the same repo and pull")
+	{
+		_, err := b.UnlockByPull(project.RepoID(), pullNum)
+		Ok(t, err)
+		ls, err := b.List()
+		Ok(t, err)
+		Equals(t, 0, len(ls))
+	}
+}
+
+func TestUnlockByPullAfterUnlock(t *testing.T) {
+	t.Log("after locking and unlocking, UnlockByPull should be successful")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	_, err = b.Unlock(project, workspace)
+	Ok(t, err)
+
+	_, err = b.UnlockByPull(project.RepoID(), pullNum)
+	Ok(t, err)
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockByPullMatching(t *testing.T) {
+	t.Log("UnlockByPull should delete all locks in that repo and pull num")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+
+	// add additional locks with the same repo and pull num but different paths/workspaces
+	new := lock
+	new.Project.Path = "dif/path"
+	_, _, err = b.TryLock(new)
+	Ok(t, err)
+	new2 := lock
+	new2.Workspace = "new-workspace"
+	_, _, err = b.TryLock(new2)
+	Ok(t, err)
+
+	// there should now be 3
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 3, len(ls))
+
+	// should all be unlocked
+	_, err = b.UnlockByPull(project.RepoID(), pullNum)
+	Ok(t, err)
+	ls, err = b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestGetLockNotThere(t *testing.T) {
+	t.Log("getting a lock that doesn't exist should return a nil pointer")
+	b := newTestRedis(t)
+	l, err := b.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+}
+
+func TestGetLock(t *testing.T) {
+	t.Log("getting a lock should return the lock")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+
+	l, err := b.GetLock(project, workspace)
+	Ok(t, err)
+	// can't compare against time so doing each field
+	Equals(t, lock.Project, l.Project)
+	Equals(t, lock.Workspace, l.Workspace)
+	Equals(t, lock.Pull, l.Pull)
+	Equals(t, lock.User, l.User)
+}
+
+// Test we can create a status and then getCommandLock it.
+func TestPullStatus_UpdateGet(t *testing.T) {
+	b := newTestRedis(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		URL:        "url",
+		HeadBranch: "head",
+		BaseBranch: "base",
+		Author:     "lkysow",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName:          "runatlantis/atlantis",
+			Owner:             "runatlantis",
+			Name:              "atlantis",
+			CloneURL:          "clone-url",
+			SanitizedCloneURL: "clone-url",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	status, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				Command:    command.Plan,
+				RepoRelDir: ".",
+				Workspace:  "default",
+				Failure:    "failure",
+			},
+		})
+	Ok(t, err)
+
+	maybeStatus, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, pull, maybeStatus.Pull) // nolint: staticcheck
+	Equals(t, []models.ProjectStatus{
+		{
+			Workspace:   "default",
+			RepoRelDir:  ".",
+			ProjectName: "",
+			Status:      models.ErroredPlanStatus,
+		},
+	}, status.Projects)
+}
+
+func TestPullStatus_List(t *testing.T) {
+	b := newTestRedis(t)
+
+	statuses, err := b.ListPullStatuses()
+	Ok(t, err)
+	Equals(t, 0, len(statuses))
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			Owner:    "runatlantis",
+			Name:     "atlantis",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	other := pull
+	other.Num = 2
+	deleted := pull
+	deleted.Num = 3
+	for _, p := range []models.PullRequest{pull, other, deleted} {
+		_, err = b.UpdatePullWithResults(p, []command.ProjectResult{
+			{
+				Command:    command.Plan,
+				RepoRelDir: ".",
+				Workspace:  "default",
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				},
+			},
+		})
+		Ok(t, err)
+	}
+	Ok(t, b.DeletePullStatus(deleted))
+
+	statuses, err = b.ListPullStatuses()
+	Ok(t, err)
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Pull.Num < statuses[j].Pull.Num })
+	Equals(t, 2, len(statuses))
+	Equals(t, pull, statuses[0].Pull)
+	Equals(t, other, statuses[1].Pull)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		Status:      models.PlannedPlanStatus,
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}}, statuses[0].Projects)
+}
+
+// Test we can create a status, delete it, and then we shouldn't be able to getCommandLock
+// it.
+func TestPullStatus_UpdateDeleteGet(t *testing.T) {
+	b := newTestRedis(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		URL:        "url",
+		HeadBranch: "head",
+		BaseBranch: "base",
+		Author:     "lkysow",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName:          "runatlantis/atlantis",
+			Owner:             "runatlantis",
+			Name:              "atlantis",
+			CloneURL:          "clone-url",
+			SanitizedCloneURL: "clone-url",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				Failure:    "failure",
+			},
+		})
+	Ok(t, err)
+
+	err = b.DeletePullStatus(pull)
+	Ok(t, err)
+
+	maybeStatus, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Assert(t, maybeStatus == nil, "exp nil")
+}
+
+// Test we can create a status, update a specific project's status within that
+// pull status, and when we getCommandLock all the project statuses, that specific project
+// should be updated.
+func TestPullStatus_UpdateProject(t *testing.T) {
+	b := newTestRedis(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		URL:        "url",
+		HeadBranch: "head",
+		BaseBranch: "base",
+		Author:     "lkysow",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName:          "runatlantis/atlantis",
+			Owner:             "runatlantis",
+			Name:              "atlantis",
+			CloneURL:          "clone-url",
+			SanitizedCloneURL: "clone-url",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				Failure:    "failure",
+			},
+			{
+				RepoRelDir:   ".",
+				Workspace:    "staging",
+				ApplySuccess: "success!",
+			},
+		})
+	Ok(t, err)
+
+	err = b.UpdateProjectStatus(pull, "default", ".", models.DiscardedPlanStatus)
+	Ok(t, err)
+
+	status, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, pull, status.Pull) // nolint: staticcheck
+	Equals(t, []models.ProjectStatus{
+		{
+			Workspace:   "default",
+			RepoRelDir:  ".",
+			ProjectName: "",
+			Status:      models.DiscardedPlanStatus,
+		},
+		{
+			Workspace:   "staging",
+			RepoRelDir:  ".",
+			ProjectName: "",
+			Status:      models.AppliedPlanStatus,
+		},
+	}, status.Projects) // nolint: staticcheck
+}
+
+// Test that if we update an existing pull status and our new status is for a
+// different HeadSHA, that we just overwrite the old status.
+func TestPullStatus_UpdateNewCommit(t *testing.T) {
+	b := newTestRedis(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		URL:        "url",
+		HeadBranch: "head",
+		BaseBranch: "base",
+		Author:     "lkysow",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName:          "runatlantis/atlantis",
+			Owner:             "runatlantis",
+			Name:              "atlantis",
+			CloneURL:          "clone-url",
+			SanitizedCloneURL: "clone-url",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				Failure:    "failure",
+			},
+		})
+	Ok(t, err)
+
+	pull.HeadCommit = "newsha"
+	status, err := b.UpdatePullWithResults(pull,
+		[]command.ProjectResult{
+			{
+				RepoRelDir:   ".",
+				Workspace:    "staging",
+				ApplySuccess: "success!",
+			},
+		})
+
+	Ok(t, err)
+	Equals(t, 1, len(status.Projects))
+
+	maybeStatus, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, pull, maybeStatus.Pull)
+	Equals(t, []models.ProjectStatus{
+		{
+			Workspace:   "staging",
+			RepoRelDir:  ".",
+			ProjectName: "",
+			Status:      models.AppliedPlanStatus,
+		},
+	}, maybeStatus.Projects)
+}
+
+// Test that if we update an existing pull status and our new status is for a
+// the same commit, that we merge the statuses.
+func TestPullStatus_UpdateMerge(t *testing.T) {
+	b := newTestRedis(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		URL:        "url",
+		HeadBranch: "head",
+		BaseBranch: "base",
+		Author:     "lkysow",
+		State:      models.OpenPullState,
+		BaseRepo: models.Repo{
+			FullName:          "runatlantis/atlantis",
+			Owner:             "runatlantis",
+			Name:              "atlantis",
+			CloneURL:          "clone-url",
+			SanitizedCloneURL: "clone-url",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				Command:    command.Plan,
+				RepoRelDir: "mergeme",
+				Workspace:  "default",
+				Failure:    "failure",
+			},
+			{
+				Command:     command.Plan,
+				RepoRelDir:  "projectname",
+				Workspace:   "default",
+				ProjectName: "projectname",
+				Failure:     "failure",
+			},
+			{
+				Command:    command.Plan,
+				RepoRelDir: "staythesame",
+				Workspace:  "default",
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "tf out",
+					LockURL:         "lock-url",
+					RePlanCmd:       "plan command",
+					ApplyCmd:        "apply command",
+				},
+			},
+		})
+	Ok(t, err)
+
+	updateStatus, err := b.UpdatePullWithResults(pull,
+		[]command.ProjectResult{
+			{
+				Command:      command.Apply,
+				RepoRelDir:   "mergeme",
+				Workspace:    "default",
+				ApplySuccess: "applied!",
+			},
+			{
+				Command:     command.Apply,
+				RepoRelDir:  "projectname",
+				Workspace:   "default",
+				ProjectName: "projectname",
+				Error:       errors.New("apply error"),
+			},
+			{
+				Command:      command.Apply,
+				RepoRelDir:   "newresult",
+				Workspace:    "default",
+				ApplySuccess: "success!",
+			},
+		})
+	Ok(t, err)
+
+	getStatus, err := b.GetPullStatus(pull)
+	Ok(t, err)
+
+	// Test both the pull state returned from the update call *and* the getCommandLock
+	// call.
+	for _, s := range []models.PullStatus{updateStatus, *getStatus} {
+		Equals(t, pull, s.Pull)
+		Equals(t, []models.ProjectStatus{
+			{
+				RepoRelDir: "mergeme",
+				Workspace:  "default",
+				Status:     models.AppliedPlanStatus,
+			},
+			{
+				RepoRelDir:  "projectname",
+				Workspace:   "default",
+				ProjectName: "projectname",
+				Status:      models.ErroredApplyStatus,
+			},
+			{
+				RepoRelDir: "staythesame",
+				Workspace:  "default",
+				Status:     models.PlannedPlanStatus,
+			},
+			{
+				RepoRelDir: "newresult",
+				Workspace:  "default",
+				Status:     models.AppliedPlanStatus,
+			},
+		}, updateStatus.Projects)
+	}
+}
+
+func TestUnlockByPullSharedRepoPrefix(t *testing.T) {
+	t.Log("UnlockByPull should not delete locks of repos that share a name prefix")
+	b := newTestRedis(t)
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	otherRepoLock := lock
+	otherRepoLock.Project = models.NewRepoProject(models.Repo{FullName: "owner/repo-other", VCSHost: models.VCSHost{Hostname: "github.com"}}, "parent/child")
+	_, _, err = b.TryLock(otherRepoLock)
+	Ok(t, err)
+
+	unlocked, err := b.UnlockByPull("github.com/owner/repo", pullNum)
+	Ok(t, err)
+	Equals(t, 1, len(unlocked))
+	Equals(t, "owner/repo", unlocked[0].Project.RepoFullName)
+
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+	Equals(t, "owner/repo-other", ls[0].Project.RepoFullName)
+}
+
+func TestLockQueue_Enqueue(t *testing.T) {
+	t.Log("pull requests should be queued in order and only once")
+	b := newTestRedis(t)
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+
+	second := lock
+	second.Pull.Num = pullNum + 1
+	position, err := b.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+	position, err = b.EnqueueLock(second)
+	Ok(t, err)
+	Equals(t, 2, position)
+	position, err = b.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	queue, err = b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 2, len(queue))
+	Equals(t, pullNum, queue[0].Pull.Num)
+	Equals(t, pullNum+1, queue[1].Pull.Num)
+
+	// Other workspaces have their own queue.
+	queue, err = b.GetLockQueue(project, "other-workspace")
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+}
+
+func TestLockQueue_Dequeue(t *testing.T) {
+	t.Log("dequeuing should remove only that pull request from the queue")
+	b := newTestRedis(t)
+	second := lock
+	second.Pull.Num = pullNum + 1
+	_, err := b.EnqueueLock(lock)
+	Ok(t, err)
+	_, err = b.EnqueueLock(second)
+	Ok(t, err)
+
+	Ok(t, b.DequeueLock(project, workspace, pullNum))
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 1, len(queue))
+	Equals(t, pullNum+1, queue[0].Pull.Num)
+
+	// Dequeuing a pull request that isn't queued is a no-op.
+	Ok(t, b.DequeueLock(project, workspace, pullNum))
+	Ok(t, b.DequeueLock(project, workspace, pullNum+1))
+	queue, err = b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+}
+
+func TestLockQueue_DequeueLocksByPull(t *testing.T) {
+	t.Log("DequeueLocksByPull should remove the pull from all queues of its repo only")
+	b := newTestRedis(t)
+	otherWorkspace := lock
+	otherWorkspace.Workspace = "other-workspace"
+	otherRepo := lock
+	otherRepo.Project = models.NewProject("owner/repo-other", "parent/child")
+	for _, l := range []models.ProjectLock{lock, otherWorkspace, otherRepo} {
+		_, err := b.EnqueueLock(l)
+		Ok(t, err)
+	}
+
+	Ok(t, b.DequeueLocksByPull(project.RepoID(), pullNum))
+	queue, err := b.GetLockQueue(project, workspace)
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+	queue, err = b.GetLockQueue(project, "other-workspace")
+	Ok(t, err)
+	Equals(t, 0, len(queue))
+	queue, err = b.GetLockQueue(otherRepo.Project, workspace)
+	Ok(t, err)
+	Equals(t, 1, len(queue))
+}
+
+func TestRepoDrift_UpdateGetList(t *testing.T) {
+	t.Log("drift should be replaced per repo")
+	b := newTestRedis(t)
+	drifts, err := b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 0, len(drifts))
+	drifts, err = b.GetRepoDrift("github.com/owner/repo")
+	Ok(t, err)
+	Equals(t, 0, len(drifts))
+
+	drifted := models.ProjectDrift{
+		RepoID:      "github.com/owner/repo",
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		Status:      models.DriftedStatus,
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}
+	other := models.ProjectDrift{
+		RepoID:     "github.com/owner/other",
+		RepoRelDir: ".",
+		Workspace:  "default",
+		Status:     models.NoDriftStatus,
+	}
+	Ok(t, b.UpdateRepoDrift(drifted.RepoID, []models.ProjectDrift{drifted}))
+	Ok(t, b.UpdateRepoDrift(other.RepoID, []models.ProjectDrift{other}))
+
+	drifts, err = b.GetRepoDrift(drifted.RepoID)
+	Ok(t, err)
+	Equals(t, []models.ProjectDrift{drifted}, drifts)
+	drifts, err = b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 2, len(drifts))
+
+	// The results of a repo replace its previous ones.
+	drifted.Status = models.NoDriftStatus
+	drifted.PlanSummary = ""
+	Ok(t, b.UpdateRepoDrift(drifted.RepoID, []models.ProjectDrift{drifted}))
+	drifts, err = b.GetRepoDrift(drifted.RepoID)
+	Ok(t, err)
+	Equals(t, []models.ProjectDrift{drifted}, drifts)
+	drifts, err = b.ListProjectDrifts()
+	Ok(t, err)
+	Equals(t, 2, len(drifts))
+}
+
+func TestClaimDriftDetection(t *testing.T) {
+	s := miniredis.RunT(t)
+	b := newTestRedisWithServer(t, s, 0)
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	claimed, err := b.ClaimDriftDetection("github.com/owner/repo", now, now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	t.Log("the repo shouldn't be claimed again until the claim expires")
+	claimed, err = b.ClaimDriftDetection("github.com/owner/repo", now, now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = b.ClaimDriftDetection("github.com/owner/other", now, now.Add(time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+	s.FastForward(time.Hour)
+	claimed, err = b.ClaimDriftDetection("github.com/owner/repo", now.Add(time.Hour), now.Add(2*time.Hour))
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
+func TestAPIJob_UpdateGetDelete(t *testing.T) {
+	t.Log("finished API jobs should be deleted once they're old enough")
+	b := newTestRedis(t)
+	job, err := b.GetAPIJob("running")
+	Ok(t, err)
+	Assert(t, job == nil, "exp no job")
+
+	finishedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	running := command.APIJob{
+		ID:           "running",
+		Status:       command.RunningAPIJobStatus,
+		RepoFullName: "owner/repo",
+		CreatedAt:    finishedAt,
+	}
+	finished := command.APIJob{
+		ID:           "finished",
+		Status:       command.SucceededAPIJobStatus,
+		RepoFullName: "owner/repo",
+		Result:       &command.ProjectResult{ApplySuccess: "success"},
+		CreatedAt:    finishedAt,
+		FinishedAt:   &finishedAt,
+	}
+	Ok(t, b.UpdateAPIJob(running))
+	Ok(t, b.UpdateAPIJob(finished))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Equals(t, finished, *job)
+
+	// Jobs that finished after the cutoff are kept.
+	Ok(t, b.DeleteAPIJobsFinishedBefore(finishedAt))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Assert(t, job != nil, "exp job to be kept")
+
+	Ok(t, b.DeleteAPIJobsFinishedBefore(finishedAt.Add(time.Second)))
+	job, err = b.GetAPIJob("finished")
+	Ok(t, err)
+	Assert(t, job == nil, "exp finished job to be deleted")
+	job, err = b.GetAPIJob("running")
+	Ok(t, err)
+	Equals(t, running, *job)
+}
+
+func TestLockingExpiredLock(t *testing.T) {
+	t.Log("a lock whose key has expired should be treated as released")
+	s := miniredis.RunT(t)
+	b := newTestRedisWithServer(t, s, time.Minute)
+	acquired, _, err := b.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+
+	s.FastForward(2 * time.Minute)
+
+	newLock := lock
+	newLock.Pull.Num = pullNum + 1
+	acquired, currLock, err := b.TryLock(newLock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, pullNum+1, currLock.Pull.Num)
+}
+
+func TestLockingRefreshesTTL(t *testing.T) {
+	t.Log("trying to lock again from the pull holding a lock should refresh its expiry")
+	s := miniredis.RunT(t)
+	b := newTestRedisWithServer(t, s, time.Hour)
+	key := "lock/github.com/owner/repo/parent/child/default"
+	acquired, _, err := b.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, time.Hour, s.TTL(key))
+
+	s.FastForward(time.Minute)
+	otherLock := lock
+	otherLock.Pull.Num = pullNum + 1
+	acquired, _, err = b.TryLock(otherLock)
+	Ok(t, err)
+	Equals(t, false, acquired)
+	Equals(t, 59*time.Minute, s.TTL(key))
+
+	acquired, currLock, err := b.TryLock(lock)
+	Ok(t, err)
+	Equals(t, false, acquired)
+	Equals(t, pullNum, currLock.Pull.Num)
+	Equals(t, time.Hour, s.TTL(key))
+}
+
+func TestPullStatus_UpdateKeepsTTL(t *testing.T) {
+	t.Log("updating a pull status should keep any expiry set on its key")
+	s := miniredis.RunT(t)
+	b := newTestRedisWithServer(t, s, 0)
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+			},
+		},
+	}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				RepoRelDir:  ".",
+				Workspace:   "default",
+				PlanSuccess: &models.PlanSuccess{},
+			},
+		})
+	Ok(t, err)
+
+	key := "pull/github.com::runatlantis/atlantis::1"
+	s.SetTTL(key, time.Hour)
+	err = b.UpdateProjectStatus(pull, "default", ".", models.AppliedPlanStatus)
+	Ok(t, err)
+	Equals(t, time.Hour, s.TTL(key))
+
+	status, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, models.AppliedPlanStatus, status.Projects[0].Status)
+}
+
+// newTestRedis returns a RedisDB backed by an in-process Redis server that
+// is shut down when the test completes.
+func newTestRedis(t *testing.T) *redis.RedisDB {
+	return newTestRedisWithServer(t, miniredis.RunT(t), 0)
+}
+
+func newTestRedisWithServer(t *testing.T, s *miniredis.Miniredis, lockTTL time.Duration) *redis.RedisDB {
+	port, err := strconv.Atoi(s.Port())
+	Ok(t, err)
+	r, err := redis.New(s.Host(), port, "", false, false, 0, lockTTL)
+	Ok(t, err)
+	return r
+}
@@ -14,6 +14,7 @@ import (
 	runtime_models "github.com/runatlantis/atlantis/server/core/runtime/models"
 	"github.com/runatlantis/atlantis/server/core/terraform"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -61,7 +62,7 @@ func (c ConftestTestCommandArgs) build() ([]string, error) {
 	}
 
 	// add hardcoded options
-	commandArgs = append(commandArgs, c.InputFile, "--no-color")
+	commandArgs = append(commandArgs, c.InputFile, "--no-color", "--output", "json")
 
 	// add extra args provided through server config
 	commandArgs = append(commandArgs, c.ExtraArgs...)
@@ -87,12 +88,15 @@ func (p *LocalSourceResolver) Resolve(policySet valid.PolicySet) (string, error)
 // SourceResolverProxy proxies to underlying source resolvers dynamically
 type SourceResolverProxy struct {
 	localSourceResolver SourceResolver
+	gitSourceResolver   SourceResolver
 }
 
 func (p *SourceResolverProxy) Resolve(policySet valid.PolicySet) (string, error) {
 	switch source := policySet.Source; source {
 	case valid.LocalPolicySet:
 		return p.localSourceResolver.Resolve(policySet)
+	case valid.GitPolicySet, valid.GithubPolicySet:
+		return p.gitSourceResolver.Resolve(policySet)
 	default:
 		return "", fmt.Errorf("unable to resolve policy set source %s", source)
 	}
@@ -132,7 +136,7 @@ type ConfTestExecutorWorkflow struct {
 	Exec                   runtime_models.Exec
 }
 
-func NewConfTestExecutorWorkflow(log logging.SimpleLogging, versionRootDir string, conftestDownloder terraform.Downloader) *ConfTestExecutorWorkflow {
+func NewConfTestExecutorWorkflow(log logging.SimpleLogging, versionRootDir string, conftestDownloder terraform.Downloader, gitSourceResolver SourceResolver) *ConfTestExecutorWorkflow {
 	downloader := ConfTestVersionDownloader{
 		downloader: conftestDownloder,
 	}
@@ -154,32 +158,90 @@ func NewConfTestExecutorWorkflow(log logging.SimpleLogging, versionRootDir strin
 		DefaultConftestVersion: version,
 		SourceResolver: &SourceResolverProxy{
 			localSourceResolver: &LocalSourceResolver{},
+			gitSourceResolver:   gitSourceResolver,
 		},
 		Exec: runtime_models.LocalExec{},
 	}
 }
 
+// Run runs conftest against the plan with each policy set and returns its
+// results formatted like conftest's text output. It returns an error if a
+// policy set that isn't warn-only failed. Conftest's JSON output is parsed so
+// that the result of each policy set is written to
+// ctx.GetPolicyCheckResultFileName() in workdir.
 func (c *ConfTestExecutorWorkflow) Run(ctx command.ProjectContext, executablePath string, envs map[string]string, workdir string, extraArgs []string) (string, error) {
-	policyArgs := []Arg{}
+	inputFile := filepath.Join(workdir, ctx.GetShowResultFileName())
 	policySetNames := []string{}
+	var output strings.Builder
+	var results []models.PolicySetResult
+	softFailed := false
+	var err error
 	ctx.Log.Debug("policy sets, %s ", ctx.PolicySets)
 	for _, policySet := range ctx.PolicySets.PolicySets {
-		path, err := c.SourceResolver.Resolve(policySet)
+		name := policySet.Name
+		if policySet.IsWarnOnly() {
+			name = fmt.Sprintf("%s (warn only)", policySet.Name)
+		}
+		policySetNames = append(policySetNames, name)
+		fmt.Fprintf(&output, "\n%s:\n", name)
+
+		var setErr error
+		path, resolveErr := c.SourceResolver.Resolve(policySet)
+		if resolveErr != nil {
+			// Skipping the policy set would let the plan pass without it.
+			setErr = errors.Wrapf(resolveErr, "resolving policy set %s", policySet.Name)
+			ctx.Log.Err("%s", setErr)
+			output.WriteString(setErr.Error() + "\n")
+			results = append(results, unresolvedPolicySetResult(policySet, setErr))
+		} else {
+			cmdOutput, runErr := c.runConftest([]Arg{NewPolicyArg(path)}, inputFile, executablePath, envs, workdir, extraArgs)
+			checkResults, parseErr := parseConftestOutput(cmdOutput)
+			if parseErr != nil {
+				// Errors running conftest, ex. invalid policies, aren't JSON so
+				// they're reported as is.
+				ctx.Log.Debug("conftest output of policy set %s isn't JSON: %s", policySet.Name, parseErr)
+				output.WriteString(cmdOutput)
+			} else {
+				output.WriteString(formatConftestResults(checkResults))
+				results = append(results, models.PolicySetResult{
+					PolicySetName: policySet.Name,
+					Rules:         conftestRuleResults(checkResults),
+					WarnOnly:      policySet.IsWarnOnly(),
+				})
+			}
+			setErr = runErr
+		}
 
-		// Let's not fail the whole step because of a single failure. Log and fail silently
-		if err != nil {
-			ctx.Log.Err("Error resolving policyset %s. err: %s", policySet.Name, err.Error())
+		if setErr == nil {
 			continue
 		}
+		if policySet.IsWarnOnly() {
+			ctx.Log.Info("warn only policy set %s failed: %s", policySet.Name, setErr)
+			softFailed = true
+			continue
+		}
+		err = setErr
+	}
 
-		policyArg := NewPolicyArg(path)
-		policyArgs = append(policyArgs, policyArg)
-
-		policySetNames = append(policySetNames, policySet.Name)
+	if len(policySetNames) == 0 {
+		ctx.Log.Warn("No policies have been configured")
+		return "", nil
+		// TODO: enable when we can pass policies in otherwise e2e tests with policy checks fail
+		// return "", errors.New("no policies specified")
 	}
 
-	inputFile := filepath.Join(workdir, ctx.GetShowResultFileName())
+	result := fmt.Sprintf("Checking plan against the following policies: \n  %s\n", strings.Join(policySetNames, "\n  ")) + output.String()
+	if writeErr := writePolicyCheckResults(filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()), models.PolicyCheckResults{
+		PolicySetResults: results,
+		SoftFailed:       softFailed,
+	}); writeErr != nil {
+		return c.sanitizeOutput(inputFile, result), writeErr
+	}
+	return c.sanitizeOutput(inputFile, result), err
+}
 
+// runConftest runs conftest with policyArgs against inputFile.
+func (c *ConfTestExecutorWorkflow) runConftest(policyArgs []Arg, inputFile string, executablePath string, envs map[string]string, workdir string, extraArgs []string) (string, error) {
 	args := ConftestTestCommandArgs{
 		PolicyArgs: policyArgs,
 		ExtraArgs:  extraArgs,
@@ -188,25 +250,27 @@ func (c *ConfTestExecutorWorkflow) Run(ctx command.ProjectContext, executablePat
 	}
 
 	serializedArgs, err := args.build()
-
 	if err != nil {
-		ctx.Log.Warn("No policies have been configured")
-		return "", nil
-		// TODO: enable when we can pass policies in otherwise e2e tests with policy checks fail
-		// return "", errors.Wrap(err, "building args")
+		return "", errors.Wrap(err, "building args")
 	}
-
-	initialOutput := fmt.Sprintf("Checking plan against the following policies: \n  %s\n", strings.Join(policySetNames, "\n  "))
-	cmdOutput, err := c.Exec.CombinedOutput(serializedArgs, envs, workdir)
-
-	return c.sanitizeOutput(inputFile, initialOutput+cmdOutput), err
-
+	return c.Exec.CombinedOutput(serializedArgs, envs, workdir)
 }
 
 func (c *ConfTestExecutorWorkflow) sanitizeOutput(inputFile string, output string) string {
 	return strings.Replace(output, inputFile, "<redacted plan file>", -1)
 }
 
+// unresolvedPolicySetResult returns the result of policySet if it couldn't be
+// resolved because of err. It has a single failed rule so that the policy set
+// fails instead of being skipped.
+func unresolvedPolicySetResult(policySet valid.PolicySet, err error) models.PolicySetResult {
+	return models.PolicySetResult{
+		PolicySetName: policySet.Name,
+		Rules:         []models.PolicyRuleResult{{Name: "resolve", Messages: []string{err.Error()}}},
+		WarnOnly:      policySet.IsWarnOnly(),
+	}
+}
+
 func (c *ConfTestExecutorWorkflow) EnsureExecutorVersion(log logging.SimpleLogging, v *version.Version) (string, error) {
 	// we have no information to proceed so fail hard
 	if c.DefaultConftestVersion == nil && v == nil {
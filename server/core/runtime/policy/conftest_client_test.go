@@ -1,8 +1,10 @@
 package policy
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,6 +18,7 @@ import (
 	conftest_mocks "github.com/runatlantis/atlantis/server/core/runtime/policy/mocks"
 	terraform_mocks "github.com/runatlantis/atlantis/server/core/terraform/mocks"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -145,7 +148,9 @@ func TestRun(t *testing.T) {
 	envs := map[string]string{
 		"key": "val",
 	}
-	workdir := "/some_workdir"
+	workdir, cleanup := TempDir(t)
+	defer cleanup()
+	inputFile := filepath.Join(workdir, "testproj-default.json")
 
 	policySet1 := valid.PolicySet{
 		Source: valid.LocalPolicySet,
@@ -171,105 +176,227 @@ func TestRun(t *testing.T) {
 		Log:         log,
 	}
 
+	expectedArgs := func(policyPath string, extraArgs ...string) []string {
+		return append([]string{executablePath, "test", "-p", policyPath, inputFile, "--no-color", "--output", "json"}, extraArgs...)
+	}
+	readResults := func(t *testing.T) models.PolicyCheckResults {
+		var results models.PolicyCheckResults
+		content, err := os.ReadFile(filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()))
+		Ok(t, err)
+		Ok(t, json.Unmarshal(content, &results))
+		return results
+	}
+	successOutput := fmt.Sprintf(`[{"filename": %q, "namespace": "main", "successes": 1}]`, inputFile)
+	failureOutput := fmt.Sprintf(`[{"filename": %q, "namespace": "main", "successes": 1, "failures": [{"msg": "failure", "metadata": {"query": "data.main.deny_buckets"}}]}]`, inputFile)
+
 	t.Run("success", func(t *testing.T) {
 		var extraArgs []string
 
-		expectedOutput := "Success"
-		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\nSuccess"
-		expectedArgs := []string{executablePath, "test", "-p", localPolicySetPath1, "-p", localPolicySetPath2, "/some_workdir/testproj-default.json", "--no-color"}
+		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\n" +
+			"\npolicy1:\n\n1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions\n" +
+			"\npolicy2:\n\n1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions\n"
 
 		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
 		When(mockResolver.Resolve(policySet2)).ThenReturn(localPolicySetPath2, nil)
 
-		When(mockExec.CombinedOutput(expectedArgs, envs, workdir)).ThenReturn(expectedOutput, nil)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(successOutput, nil)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath2), envs, workdir)).ThenReturn(successOutput, nil)
 
 		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
 
-		fmt.Println(result)
-
 		Ok(t, err)
-
-		Assert(t, result == expectedResult, "result is expected")
-
+		Equals(t, expectedResult, result)
+		results := readResults(t)
+		Equals(t, 2, len(results.PolicySetResults))
+		Equals(t, policySetName1, results.PolicySetResults[0].PolicySetName)
+		Equals(t, true, results.PolicySetResults[0].Passed())
+		Equals(t, 1, results.PolicySetResults[0].Passes())
 	})
 
 	t.Run("success extra args", func(t *testing.T) {
 		extraArgs := []string{"--all-namespaces"}
 
-		expectedOutput := "Success"
-		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\nSuccess"
-		expectedArgs := []string{executablePath, "test", "-p", localPolicySetPath1, "-p", localPolicySetPath2, "/some_workdir/testproj-default.json", "--no-color", "--all-namespaces"}
-
 		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
 		When(mockResolver.Resolve(policySet2)).ThenReturn(localPolicySetPath2, nil)
 
-		When(mockExec.CombinedOutput(expectedArgs, envs, workdir)).ThenReturn(expectedOutput, nil)
-
-		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1, extraArgs...), envs, workdir)).ThenReturn(successOutput, nil)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath2, extraArgs...), envs, workdir)).ThenReturn(successOutput, nil)
 
-		fmt.Println(result)
+		_, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
 
 		Ok(t, err)
-
-		Assert(t, result == expectedResult, "result is expected")
-
+		mockExec.VerifyWasCalledOnce().CombinedOutput(expectedArgs(localPolicySetPath1, extraArgs...), envs, workdir)
 	})
 
 	t.Run("error resolving one policy source", func(t *testing.T) {
 		var extraArgs []string
 
-		expectedOutput := "Success"
-		expectedResult := "Checking plan against the following policies: \n  policy1\nSuccess"
-		expectedArgs := []string{executablePath, "test", "-p", localPolicySetPath1, "/some_workdir/testproj-default.json", "--no-color"}
+		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\n" +
+			"\npolicy1:\n\n1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions\n" +
+			"\npolicy2:\nresolving policy set policy2: err\n"
 
 		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
 		When(mockResolver.Resolve(policySet2)).ThenReturn("", errors.New("err"))
 
-		When(mockExec.CombinedOutput(expectedArgs, envs, workdir)).ThenReturn(expectedOutput, nil)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(successOutput, nil)
 
 		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
 
-		Ok(t, err)
-
-		Assert(t, result == expectedResult, "result is expected")
-
+		ErrEquals(t, "resolving policy set policy2: err", err)
+		Equals(t, expectedResult, result)
+		results := readResults(t)
+		Equals(t, 2, len(results.PolicySetResults))
+		Equals(t, models.PolicySetResult{
+			PolicySetName: policySetName2,
+			Rules:         []models.PolicyRuleResult{{Name: "resolve", Messages: []string{"resolving policy set policy2: err"}}},
+		}, results.PolicySetResults[1])
+		Equals(t, false, results.PolicySetResults[1].Passed())
 	})
 
 	t.Run("error resolving both policy sources", func(t *testing.T) {
 		var extraArgs []string
 
-		expectedResult := "Success"
-		expectedArgs := []string{executablePath, "test", "-p", localPolicySetPath1, "/some_workdir/testproj-default.json", "--no-color"}
-
 		When(mockResolver.Resolve(policySet1)).ThenReturn("", errors.New("err"))
 		When(mockResolver.Resolve(policySet2)).ThenReturn("", errors.New("err"))
 
-		When(mockExec.CombinedOutput(expectedArgs, envs, workdir)).ThenReturn(expectedResult, nil)
+		_, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
 
-		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
+		Assert(t, err != nil, "exp policy check to fail")
+		results := readResults(t)
+		Equals(t, 2, len(results.PolicySetResults))
+		Equals(t, false, results.PolicySetResults[0].Passed())
+		Equals(t, false, results.PolicySetResults[1].Passed())
+	})
+
+	t.Run("error resolving warn only policy source", func(t *testing.T) {
+		var extraArgs []string
+
+		warnPolicySet := policySet2
+		warnPolicySet.Severity = valid.WarnPolicySetSeverity
+		warnCtx := ctx
+		warnCtx.PolicySets = valid.PolicySets{
+			PolicySets: []valid.PolicySet{policySet1, warnPolicySet},
+		}
+
+		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
+		When(mockResolver.Resolve(warnPolicySet)).ThenReturn("", errors.New("err"))
+
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(successOutput, nil)
+
+		_, err := subject.Run(warnCtx, executablePath, envs, workdir, extraArgs)
 
 		Ok(t, err)
+		results := readResults(t)
+		Equals(t, true, results.SoftFailed)
+		Equals(t, true, results.PolicySetResults[1].WarnOnly)
+		Equals(t, false, results.PolicySetResults[1].Passed())
+	})
+
+	t.Run("policy set fails", func(t *testing.T) {
+		var extraArgs []string
+
+		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\n" +
+			"\npolicy1:\nFAIL - <redacted plan file> - main - failure\n\n2 tests, 1 passed, 0 warnings, 1 failure, 0 exceptions\n" +
+			"\npolicy2:\n\n1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions\n"
+
+		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
+		When(mockResolver.Resolve(policySet2)).ThenReturn(localPolicySetPath2, nil)
 
-		Assert(t, result == "", "result is expected")
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(failureOutput, errors.New("exit status 1"))
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath2), envs, workdir)).ThenReturn(successOutput, nil)
 
+		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
+
+		ErrEquals(t, "exit status 1", err)
+		Equals(t, expectedResult, result)
+		results := readResults(t)
+		Equals(t, []models.PolicySetResult{
+			{
+				PolicySetName: policySetName1,
+				Rules: []models.PolicyRuleResult{
+					{Namespace: "main", Name: "deny_buckets", Messages: []string{"failure"}},
+					{Namespace: "main"},
+				},
+			},
+			{
+				PolicySetName: policySetName2,
+				Rules:         []models.PolicyRuleResult{{Namespace: "main"}},
+			},
+		}, results.PolicySetResults)
+		Equals(t, false, results.SoftFailed)
 	})
 
 	t.Run("error running cmd", func(t *testing.T) {
 		var extraArgs []string
 
-		expectedOutput := "FAIL - /some_workdir/testproj-default.json - failure"
-		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\nFAIL - <redacted plan file> - failure"
-		expectedArgs := []string{executablePath, "test", "-p", localPolicySetPath1, "-p", localPolicySetPath2, "/some_workdir/testproj-default.json", "--no-color"}
+		cmdOutput := "Error: running test: load: loading policies: get compiler: 1 error occurred"
+		expectedResult := "Checking plan against the following policies: \n  policy1\n  policy2\n" +
+			"\npolicy1:\n" + cmdOutput +
+			"\npolicy2:\nresolving policy set policy2: err\n"
 
 		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
-		When(mockResolver.Resolve(policySet2)).ThenReturn(localPolicySetPath2, nil)
+		When(mockResolver.Resolve(policySet2)).ThenReturn("", errors.New("err"))
 
-		When(mockExec.CombinedOutput(expectedArgs, envs, workdir)).ThenReturn(expectedOutput, errors.New("exit status code 1"))
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(cmdOutput, errors.New("exit status 1"))
 
 		result, err := subject.Run(ctx, executablePath, envs, workdir, extraArgs)
 
-		Assert(t, result == expectedResult, "rseult is expected")
+		Equals(t, expectedResult, result)
 		Assert(t, err != nil, "error is expected")
+		Equals(t, 1, len(readResults(t).PolicySetResults))
+	})
+
+	t.Run("warn only policy set fails", func(t *testing.T) {
+		var extraArgs []string
+
+		warnPolicySet := policySet2
+		warnPolicySet.Severity = valid.WarnPolicySetSeverity
+		warnCtx := ctx
+		warnCtx.PolicySets = valid.PolicySets{
+			PolicySets: []valid.PolicySet{policySet1, warnPolicySet},
+		}
+
+		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
+		When(mockResolver.Resolve(warnPolicySet)).ThenReturn(localPolicySetPath2, nil)
+
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath1), envs, workdir)).ThenReturn(successOutput, nil)
+		When(mockExec.CombinedOutput(expectedArgs(localPolicySetPath2), envs, workdir)).ThenReturn(failureOutput, errors.New("exit status 1"))
+
+		result, err := subject.Run(warnCtx, executablePath, envs, workdir, extraArgs)
 
+		Ok(t, err)
+		Equals(t, "Checking plan against the following policies: \n  policy1\n  policy2 (warn only)\n"+
+			"\npolicy1:\n\n1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions\n"+
+			"\npolicy2 (warn only):\nFAIL - <redacted plan file> - main - failure\n\n2 tests, 1 passed, 0 warnings, 1 failure, 0 exceptions\n", result)
+
+		results := readResults(t)
+		Equals(t, true, results.SoftFailed)
+		Equals(t, true, results.PolicySetResults[1].WarnOnly)
+		Equals(t, false, results.PolicySetResults[1].Passed())
 	})
 }
+
+func TestFormatConftestResults(t *testing.T) {
+	results, err := parseConftestOutput(`[
+  {"filename": "plan.json", "namespace": "main", "successes": 2,
+   "warnings": [{"msg": "warning", "metadata": {"query": "data.main.warn"}}],
+   "failures": [{"msg": "failure 1", "metadata": {"query": "data.main.deny"}}, {"msg": "failure 2", "metadata": {"query": "data.main.deny"}}]},
+  {"filename": "plan.json", "namespace": "other", "successes": 0,
+   "exceptions": [{"msg": "exception"}]}
+]`)
+	Ok(t, err)
+	Equals(t, "WARN - plan.json - main - warning\n"+
+		"FAIL - plan.json - main - failure 1\n"+
+		"FAIL - plan.json - main - failure 2\n"+
+		"EXCP - plan.json - other - exception\n"+
+		"\n6 tests, 2 passed, 1 warning, 2 failures, 1 exception\n", formatConftestResults(results))
+	Equals(t, []models.PolicyRuleResult{
+		{Namespace: "main", Name: "warn", Messages: []string{"warning"}},
+		{Namespace: "main", Name: "deny", Messages: []string{"failure 1", "failure 2"}},
+		{Namespace: "main"},
+		{Namespace: "main"},
+	}, conftestRuleResults(results))
+
+	_, err = parseConftestOutput("Error: no policies found")
+	Assert(t, err != nil, "expected error parsing text output")
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// conftestCheckResult is the result of a namespace of policies for a file in
+// conftest's JSON output.
+type conftestCheckResult struct {
+	Filename   string           `json:"filename"`
+	Namespace  string           `json:"namespace"`
+	Successes  int              `json:"successes"`
+	Skipped    []conftestResult `json:"skipped"`
+	Warnings   []conftestResult `json:"warnings"`
+	Failures   []conftestResult `json:"failures"`
+	Exceptions []conftestResult `json:"exceptions"`
+}
+
+// conftestResult is a message returned by a rule in conftest's JSON output.
+type conftestResult struct {
+	Msg      string                 `json:"msg"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// parseConftestOutput parses the output of conftest test --output json.
+func parseConftestOutput(output string) ([]conftestCheckResult, error) {
+	var results []conftestCheckResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		return nil, errors.Wrap(err, "parsing conftest output")
+	}
+	return results, nil
+}
+
+// formatConftestResults formats results like conftest's text output.
+func formatConftestResults(results []conftestCheckResult) string {
+	var b strings.Builder
+	var tests, passed, warnings, failures, exceptions int
+	for _, r := range results {
+		for _, w := range r.Warnings {
+			fmt.Fprintf(&b, "WARN - %s - %s - %s\n", r.Filename, r.Namespace, w.Msg)
+		}
+		for _, f := range r.Failures {
+			fmt.Fprintf(&b, "FAIL - %s - %s - %s\n", r.Filename, r.Namespace, f.Msg)
+		}
+		for _, e := range r.Exceptions {
+			fmt.Fprintf(&b, "EXCP - %s - %s - %s\n", r.Filename, r.Namespace, e.Msg)
+		}
+		tests += r.Successes + len(r.Skipped) + len(r.Warnings) + len(r.Failures) + len(r.Exceptions)
+		passed += r.Successes
+		warnings += len(r.Warnings)
+		failures += len(r.Failures)
+		exceptions += len(r.Exceptions)
+	}
+	fmt.Fprintf(&b, "\n%s, %d passed, %s, %s, %s\n",
+		pluralize(tests, "test"), passed, pluralize(warnings, "warning"), pluralize(failures, "failure"), pluralize(exceptions, "exception"))
+	return b.String()
+}
+
+// conftestRuleResults returns the result of each rule in results. Conftest
+// only reports how many rules passed, so those are returned without a name.
+func conftestRuleResults(results []conftestCheckResult) []models.PolicyRuleResult {
+	var rules []models.PolicyRuleResult
+	index := make(map[string]int)
+	add := func(namespace string, r conftestResult, defaultName string) {
+		name := conftestRuleName(namespace, r, defaultName)
+		key := namespace + "." + name
+		i, ok := index[key]
+		if !ok {
+			i = len(rules)
+			index[key] = i
+			rules = append(rules, models.PolicyRuleResult{Namespace: namespace, Name: name})
+		}
+		rules[i].Messages = append(rules[i].Messages, r.Msg)
+	}
+	for _, r := range results {
+		for _, w := range r.Warnings {
+			add(r.Namespace, w, "warn")
+		}
+		for _, f := range r.Failures {
+			add(r.Namespace, f, "deny")
+		}
+		for i := 0; i < r.Successes; i++ {
+			rules = append(rules, models.PolicyRuleResult{Namespace: r.Namespace})
+		}
+	}
+	return rules
+}
+
+// conftestRuleName returns the name of the rule that returned r from the
+// query in its metadata, ex. deny_public_buckets for
+// data.main.deny_public_buckets, or defaultName if it has none.
+func conftestRuleName(namespace string, r conftestResult, defaultName string) string {
+	query, _ := r.Metadata["query"].(string)
+	name := strings.TrimPrefix(query, fmt.Sprintf("data.%s.", namespace))
+	if name == "" || name == query {
+		return defaultName
+	}
+	return name
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// GitCredential is used to clone policy repos from Hostname over http(s).
+type GitCredential struct {
+	Hostname string
+	User     string
+	// Token returns the token to clone with. It's a function because some
+	// tokens, ex. GitHub app installation tokens, expire.
+	Token func() (string, error)
+}
+
+// gitClone is a policy repo cloned at a ref.
+type gitClone struct {
+	repoURL string
+	ref     string
+	// previousDir is the checkout that the last refresh replaced. Policy
+	// checks that resolved it before may still be reading it so it's only
+	// removed by the next refresh.
+	previousDir string
+}
+
+// GitSourceResolver resolves policy sets stored in git repos to a local fs
+// path. Each repo is checked out into Dir once per ref and the checkout is
+// reused until Run refreshes it. Refreshes fetch into a new checkout and then
+// swap the symlink that points to the current one, so paths that were
+// already resolved never change while they're being read.
+type GitSourceResolver struct {
+	// Dir is the directory the policy repos are cloned into.
+	Dir string
+	// GithubHostname is the hostname that the repos of "github" policy sets
+	// are cloned from, ex. github.com.
+	GithubHostname string
+	// Credentials are added to the clone URLs of repos on their hostname. They
+	// aren't stored in the clones.
+	Credentials []GitCredential
+	Logger      logging.SimpleLogging
+
+	mutex sync.Mutex
+	// clones are the policy repos that have been cloned, keyed by the symlink
+	// to their current checkout.
+	clones map[string]gitClone
+}
+
+// NewGitSourceResolver returns a GitSourceResolver that clones policy repos
+// into dir.
+func NewGitSourceResolver(dir string, githubHostname string, credentials []GitCredential, logger logging.SimpleLogging) *GitSourceResolver {
+	return &GitSourceResolver{
+		Dir:            dir,
+		GithubHostname: githubHostname,
+		Credentials:    credentials,
+		Logger:         logger,
+		clones:         make(map[string]gitClone),
+	}
+}
+
+// Resolve clones the repo of policySet at its ref if it hasn't been cloned
+// yet and returns the path to the policies in the current checkout.
+func (g *GitSourceResolver) Resolve(policySet valid.PolicySet) (string, error) {
+	repoURL, err := g.repoURL(policySet)
+	if err != nil {
+		return "", err
+	}
+	clone := gitClone{repoURL: repoURL, ref: policySet.Ref}
+	link := g.cloneDir(clone)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	dir, err := os.Readlink(link)
+	if err != nil {
+		if dir, err = g.checkout(link, clone); err != nil {
+			return "", errors.Wrapf(err, "cloning policy set %s", policySet.Name)
+		}
+	}
+	if _, ok := g.clones[link]; !ok {
+		g.clones[link] = clone
+	}
+	return filepath.Join(dir, policySet.Path), nil
+}
+
+// Run fetches the refs of all the policy repos that have been cloned again
+// so that policy sets that use a branch pick up its new commits. It
+// implements scheduled.Job.
+func (g *GitSourceResolver) Run() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for link, clone := range g.clones {
+		current, _ := os.Readlink(link)
+		if _, err := g.checkout(link, clone); err != nil {
+			g.Logger.Err("refreshing policy repo %s at %s: %s", clone.repoURL, clone.ref, err)
+			continue
+		}
+		if clone.previousDir != "" {
+			if err := os.RemoveAll(clone.previousDir); err != nil {
+				g.Logger.Warn("removing previous checkout of policy repo %s at %s: %s", clone.repoURL, clone.ref, err)
+			}
+		}
+		clone.previousDir = current
+		g.clones[link] = clone
+	}
+}
+
+// checkout fetches the ref of clone into a new dir next to link and then
+// atomically points link to it. It returns the new dir.
+func (g *GitSourceResolver) checkout(link string, clone gitClone) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(link), 0700); err != nil {
+		return "", err
+	}
+	// Refs can't start with a "." so the checkouts can't clash with the
+	// links of other refs.
+	dir, err := os.MkdirTemp(filepath.Dir(link), "."+filepath.Base(link)+"-")
+	if err != nil {
+		return "", err
+	}
+	if err := g.fetch(dir, clone); err != nil {
+		os.RemoveAll(dir) // nolint: errcheck
+		return "", err
+	}
+
+	tmpLink := dir + ".link"
+	if err := os.Symlink(dir, tmpLink); err != nil {
+		os.RemoveAll(dir) // nolint: errcheck
+		return "", err
+	}
+	// Clones made before checkouts were swapped are plain dirs, which a
+	// symlink can't be renamed over.
+	if fi, err := os.Lstat(link); err == nil && fi.IsDir() {
+		os.RemoveAll(link) // nolint: errcheck
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink) // nolint: errcheck
+		os.RemoveAll(dir)  // nolint: errcheck
+		return "", err
+	}
+	return dir, nil
+}
+
+// repoURL returns the URL to clone the repo of policySet from, without
+// credentials.
+func (g *GitSourceResolver) repoURL(policySet valid.PolicySet) (string, error) {
+	switch policySet.Source {
+	case valid.GitPolicySet:
+		return policySet.Repo, nil
+	case valid.GithubPolicySet:
+		return fmt.Sprintf("https://%s/%s.git", g.GithubHostname, policySet.Repo), nil
+	default:
+		return "", fmt.Errorf("unable to resolve policy set source %s", policySet.Source)
+	}
+}
+
+// cloneDir returns the path of the symlink to the current checkout of clone.
+func (g *GitSourceResolver) cloneDir(clone gitClone) string {
+	repo := clone.repoURL
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		repo = u.Host + u.Path
+	}
+	return filepath.Join(g.Dir, url.PathEscape(repo), url.PathEscape(clone.ref))
+}
+
+// fetch clones the ref of clone into the empty dir.
+func (g *GitSourceResolver) fetch(dir string, clone gitClone) error {
+	authURL, secret, err := g.authURL(clone.repoURL)
+	if err != nil {
+		return err
+	}
+
+	cmds := [][]string{
+		{"git", "init", "--quiet"},
+		{"git", "fetch", "--quiet", "--depth=1", "--force", authURL, clone.ref},
+		{"git", "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD"},
+		{"git", "clean", "-ffdx", "--quiet"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...) // #nosec
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			// Don't leak the credentials in the clone URL.
+			sanitize := func(s string) string {
+				if secret == "" {
+					return s
+				}
+				return strings.Replace(s, secret, "<redacted>", -1)
+			}
+			return fmt.Errorf("running %s: %s: %s", sanitize(strings.Join(args, " ")), sanitize(string(out)), err)
+		}
+	}
+	return nil
+}
+
+// authURL returns repoURL with the credentials for its hostname added, and the
+// token it contains. If there are no credentials for the hostname, repoURL is
+// returned as is.
+func (g *GitSourceResolver) authURL(repoURL string) (string, string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return repoURL, "", nil
+	}
+	for _, c := range g.Credentials {
+		if !strings.EqualFold(c.Hostname, u.Hostname()) || c.Token == nil {
+			continue
+		}
+		token, err := c.Token()
+		if err != nil {
+			return "", "", errors.Wrapf(err, "getting token for %s", c.Hostname)
+		}
+		if token == "" {
+			return repoURL, "", nil
+		}
+		u.User = url.UserPassword(c.User, token)
+		return u.String(), token, nil
+	}
+	return repoURL, "", nil
+}
@@ -0,0 +1,332 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// ConftestPolicyEngine runs the policy sets with a downloaded conftest
+	// binary.
+	ConftestPolicyEngine = "conftest"
+	// OPAPolicyEngine evaluates the policy sets in-process.
+	OPAPolicyEngine = "opa"
+
+	// defaultNamespace is the package that's evaluated if no namespace is
+	// passed, like conftest.
+	defaultNamespace = "main"
+)
+
+// ruleNameRegex matches the names of the rules we evaluate. It's the same
+// convention as conftest: deny, violation and warn rules, optionally followed
+// by a suffix, ex. deny_public_buckets.
+var ruleNameRegex = regexp.MustCompile(`^(deny|violation|warn)(_[a-zA-Z0-9_]+)*$`)
+
+// OPAExecutorWorkflow evaluates the Rego policies of the policy sets against
+// the plan in-process, without downloading or running conftest. It follows
+// conftest's conventions for rule names and namespaces so the same policies
+// work with both.
+type OPAExecutorWorkflow struct {
+	SourceResolver SourceResolver
+}
+
+func NewOPAExecutorWorkflow(gitSourceResolver SourceResolver) *OPAExecutorWorkflow {
+	return &OPAExecutorWorkflow{
+		SourceResolver: &SourceResolverProxy{
+			localSourceResolver: &LocalSourceResolver{},
+			gitSourceResolver:   gitSourceResolver,
+		},
+	}
+}
+
+// EnsureExecutorVersion is a no-op since there is no executable to download.
+func (o *OPAExecutorWorkflow) EnsureExecutorVersion(log logging.SimpleLogging, v *version.Version) (string, error) {
+	return "", nil
+}
+
+// Run evaluates the policy sets against the plan and returns the results
+// formatted as text. It returns an error if any rule of a policy set that
+// isn't warn-only failed. The structured results are written to
+// ctx.GetPolicyCheckResultFileName() in workdir.
+func (o *OPAExecutorWorkflow) Run(ctx command.ProjectContext, executablePath string, envs map[string]string, workdir string, extraArgs []string) (string, error) {
+	var policySetNames []string
+	for _, policySet := range ctx.PolicySets.PolicySets {
+		policySetNames = append(policySetNames, policySet.Name)
+	}
+
+	results, err := o.Evaluate(ctx, workdir, extraArgs)
+	if err != nil {
+		return "", err
+	}
+
+	failures := 0
+	softFailed := false
+	for _, r := range results {
+		if r.WarnOnly {
+			softFailed = softFailed || !r.Passed()
+			continue
+		}
+		failures += r.Failures()
+	}
+	if err := writePolicyCheckResults(filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()), models.PolicyCheckResults{
+		PolicySetResults: results,
+		SoftFailed:       softFailed,
+	}); err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		ctx.Log.Warn("No policies have been configured")
+		return "", nil
+	}
+
+	output := fmt.Sprintf("Checking plan against the following policies: \n  %s\n", strings.Join(policySetNames, "\n  "))
+	output += FormatPolicySetResults(results)
+
+	if failures > 0 {
+		return output, fmt.Errorf("%d policy check failures", failures)
+	}
+	return output, nil
+}
+
+// Evaluate evaluates each policy set against the plan in workdir and returns
+// the result of every rule. Policy sets that can't be resolved fail, like with
+// conftest.
+func (o *OPAExecutorWorkflow) Evaluate(ctx command.ProjectContext, workdir string, extraArgs []string) ([]models.PolicySetResult, error) {
+	namespaces, allNamespaces, err := parseNamespaceArgs(extraArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var input interface{}
+	inputFile := filepath.Join(workdir, ctx.GetShowResultFileName())
+	inputBytes, err := os.ReadFile(inputFile) // nolint: gosec
+	if err != nil {
+		return nil, errors.Wrap(err, "reading plan json")
+	}
+	if err := json.Unmarshal(inputBytes, &input); err != nil {
+		return nil, errors.Wrap(err, "parsing plan json")
+	}
+
+	var results []models.PolicySetResult
+	for _, policySet := range ctx.PolicySets.PolicySets {
+		path, err := o.SourceResolver.Resolve(policySet)
+		if err != nil {
+			// Skipping the policy set would let the plan pass without it.
+			err = errors.Wrapf(err, "resolving policy set %s", policySet.Name)
+			ctx.Log.Err("%s", err)
+			results = append(results, unresolvedPolicySetResult(policySet, err))
+			continue
+		}
+
+		rules, err := evaluatePolicies(path, input, namespaces, allNamespaces)
+		if err != nil {
+			return nil, errors.Wrapf(err, "evaluating policy set %s", policySet.Name)
+		}
+		results = append(results, models.PolicySetResult{
+			PolicySetName: policySet.Name,
+			Rules:         rules,
+			WarnOnly:      policySet.IsWarnOnly(),
+		})
+	}
+	return results, nil
+}
+
+// FormatPolicySetResults formats results like conftest's text output.
+func FormatPolicySetResults(results []models.PolicySetResult) string {
+	var b strings.Builder
+	for _, result := range results {
+		if result.WarnOnly {
+			fmt.Fprintf(&b, "\n%s (warn only):\n", result.PolicySetName)
+		} else {
+			fmt.Fprintf(&b, "\n%s:\n", result.PolicySetName)
+		}
+		for _, r := range result.Rules {
+			for _, msg := range r.Messages {
+				fmt.Fprintf(&b, "%s - %s - %s - %s\n", strings.ToUpper(string(r.Status())), r.Namespace, r.Name, msg)
+			}
+		}
+		fmt.Fprintf(&b, "\n%d tests, %d passed, %d warnings, %d failures\n", len(result.Rules), result.Passes(), result.Warnings(), result.Failures())
+	}
+	return b.String()
+}
+
+// writePolicyCheckResults writes results to path as JSON.
+func writePolicyCheckResults(path string, results models.PolicyCheckResults) error {
+	serialized, err := json.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err, "serializing policy check results")
+	}
+	return errors.Wrap(os.WriteFile(path, serialized, 0600), "writing policy check results")
+}
+
+// parseNamespaceArgs returns the namespaces set in extraArgs with conftest's
+// --namespace and --all-namespaces flags.
+func parseNamespaceArgs(extraArgs []string) ([]string, bool, error) {
+	var namespaces []string
+	allNamespaces := false
+	for i := 0; i < len(extraArgs); i++ {
+		arg := extraArgs[i]
+		switch {
+		case arg == "--all-namespaces":
+			allNamespaces = true
+		case arg == "--namespace" || arg == "-n":
+			if i+1 == len(extraArgs) {
+				return nil, false, fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			namespaces = append(namespaces, extraArgs[i])
+		case strings.HasPrefix(arg, "--namespace="):
+			namespaces = append(namespaces, strings.TrimPrefix(arg, "--namespace="))
+		default:
+			return nil, false, fmt.Errorf("unsupported argument %q: only --namespace and --all-namespaces are supported by the %s policy engine", arg, OPAPolicyEngine)
+		}
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{defaultNamespace}
+	}
+	return namespaces, allNamespaces, nil
+}
+
+// evaluatePolicies evaluates the deny, violation and warn rules of the .rego
+// files in path against input. Rules are only evaluated if their package is
+// one of namespaces, or if allNamespaces is true.
+func evaluatePolicies(path string, input interface{}, namespaces []string, allNamespaces bool) ([]models.PolicyRuleResult, error) {
+	modules, err := loadModules(path)
+	if err != nil {
+		return nil, err
+	}
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling policies")
+	}
+
+	// Rules can be defined incrementally in multiple files so we dedupe them.
+	type rule struct{ namespace, name string }
+	seen := make(map[rule]bool)
+	var rules []rule
+	for _, module := range compiler.Modules {
+		namespace := strings.TrimPrefix(module.Package.Path.String(), "data.")
+		if !allNamespaces && !containsString(namespaces, namespace) {
+			continue
+		}
+		for _, r := range module.Rules {
+			name := r.Head.Name.String()
+			key := rule{namespace, name}
+			if !ruleNameRegex.MatchString(name) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			rules = append(rules, key)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].namespace != rules[j].namespace {
+			return rules[i].namespace < rules[j].namespace
+		}
+		return rules[i].name < rules[j].name
+	})
+
+	var results []models.PolicyRuleResult
+	for _, r := range rules {
+		query := fmt.Sprintf("data.%s.%s", r.namespace, r.name)
+		rs, err := rego.New(
+			rego.Compiler(compiler),
+			rego.Query(query),
+			rego.Input(input),
+		).Eval(context.Background())
+		if err != nil {
+			return nil, errors.Wrapf(err, "evaluating %s", query)
+		}
+		var messages []string
+		for _, result := range rs {
+			for _, expr := range result.Expressions {
+				messages = append(messages, ruleMessages(r.name, expr.Value)...)
+			}
+		}
+		results = append(results, models.PolicyRuleResult{
+			Namespace: r.namespace,
+			Name:      r.name,
+			Messages:  messages,
+		})
+	}
+	return results, nil
+}
+
+// loadModules returns the contents of the .rego files in path, keyed by their
+// path. Test files are skipped, like with conftest.
+func loadModules(path string) (map[string]string, error) {
+	modules := make(map[string]string)
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != ".rego" || strings.HasSuffix(p, "_test.rego") {
+			return nil
+		}
+		content, err := os.ReadFile(p) // nolint: gosec
+		if err != nil {
+			return err
+		}
+		modules[p] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading policies from %s", path)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no policies found in %s", path)
+	}
+	return modules, nil
+}
+
+// ruleMessages returns the messages of the value of a rule. Rules return sets
+// of strings or of objects with a msg key. Boolean rules return a single
+// message with the rule's name if they're true.
+func ruleMessages(name string, value interface{}) []string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return []string{name}
+		}
+		return nil
+	case string:
+		return []string{v}
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return []string{msg}
+		}
+		b, _ := json.Marshal(v) // nolint: errcheck
+		return []string{string(b)}
+	case []interface{}:
+		var messages []string
+		for _, item := range v {
+			messages = append(messages, ruleMessages(name, item)...)
+		}
+		return messages
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
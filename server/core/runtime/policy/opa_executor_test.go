@@ -0,0 +1,237 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	conftest_mocks "github.com/runatlantis/atlantis/server/core/runtime/policy/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const nullResourcePolicy = `package main
+
+deny[msg] {
+	r := input.resource_changes[_]
+	r.type == "null_resource"
+	r.change.actions[_] == "create"
+	msg := sprintf("%s is not allowed", [r.address])
+}
+
+warn_random[{"msg": msg}] {
+	r := input.resource_changes[_]
+	r.type == "random_id"
+	msg := sprintf("%s is discouraged", [r.address])
+}
+
+deny_aws {
+	input.resource_changes[_].type == "aws_instance"
+}
+
+helper = true
+`
+
+const otherNamespacePolicy = `package other
+
+deny[msg] {
+	input.resource_changes[_].type == "null_resource"
+	msg := "other namespace"
+}
+`
+
+const planJSON = `{
+  "resource_changes": [
+    {"address": "null_resource.a", "type": "null_resource", "change": {"actions": ["create"]}},
+    {"address": "random_id.b", "type": "random_id", "change": {"actions": ["create"]}}
+  ]
+}`
+
+func TestOPAExecutorWorkflow_Evaluate(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego":       nullResourcePolicy,
+		"main_test.rego":  "package main\n\ntest_ignored { true }\n",
+		"nested/x.rego":   otherNamespacePolicy,
+		"not-a-policy.md": "ignored",
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	results, err := subject.Evaluate(ctx, workdir, nil)
+	Ok(t, err)
+	Equals(t, []models.PolicySetResult{
+		{
+			PolicySetName: "policies",
+			Rules: []models.PolicyRuleResult{
+				{Namespace: "main", Name: "deny", Messages: []string{"null_resource.a is not allowed"}},
+				{Namespace: "main", Name: "deny_aws"},
+				{Namespace: "main", Name: "warn_random", Messages: []string{"random_id.b is discouraged"}},
+			},
+		},
+	}, results)
+	Equals(t, 1, results[0].Passes())
+	Equals(t, 1, results[0].Warnings())
+	Equals(t, 1, results[0].Failures())
+}
+
+func TestOPAExecutorWorkflow_EvaluateNamespaces(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego":  nullResourcePolicy,
+		"other.rego": otherNamespacePolicy,
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	results, err := subject.Evaluate(ctx, workdir, []string{"--namespace", "other"})
+	Ok(t, err)
+	Equals(t, []models.PolicyRuleResult{
+		{Namespace: "other", Name: "deny", Messages: []string{"other namespace"}},
+	}, results[0].Rules)
+
+	results, err = subject.Evaluate(ctx, workdir, []string{"--all-namespaces"})
+	Ok(t, err)
+	Equals(t, 4, len(results[0].Rules))
+	Equals(t, 2, results[0].Failures())
+
+	_, err = subject.Evaluate(ctx, workdir, []string{"--update", "url"})
+	ErrEquals(t, `unsupported argument "--update": only --namespace and --all-namespaces are supported by the opa policy engine`, err)
+}
+
+func TestOPAExecutorWorkflow_RunFailure(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego": nullResourcePolicy,
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	output, err := subject.Run(ctx, "", nil, workdir, nil)
+	ErrEquals(t, "1 policy check failures", err)
+	Equals(t, "Checking plan against the following policies: \n"+`  policies
+
+policies:
+FAIL - main - deny - null_resource.a is not allowed
+WARN - main - warn_random - random_id.b is discouraged
+
+3 tests, 1 passed, 1 warnings, 1 failures
+`, output)
+
+	var results models.PolicyCheckResults
+	Ok(t, json.Unmarshal([]byte(readFile(t, filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()))), &results))
+	Equals(t, 1, len(results.PolicySetResults))
+	Equals(t, 1, results.PolicySetResults[0].Failures())
+	Equals(t, false, results.SoftFailed)
+}
+
+func TestOPAExecutorWorkflow_RunUnresolvedPolicySet(t *testing.T) {
+	RegisterMockTestingT(t)
+	workdir, ctx := newOPATestContext(t, planJSON, nil)
+	gitPolicySet := valid.PolicySet{Name: "policies", Source: valid.GitPolicySet, Repo: "https://github.com/org/policies.git", Ref: "main"}
+	ctx.PolicySets.PolicySets = []valid.PolicySet{gitPolicySet}
+	resolver := conftest_mocks.NewMockSourceResolver()
+	When(resolver.Resolve(gitPolicySet)).ThenReturn("", errors.New("clone failed"))
+	subject := NewOPAExecutorWorkflow(resolver)
+
+	_, err := subject.Run(ctx, "", nil, workdir, nil)
+	ErrEquals(t, "1 policy check failures", err)
+
+	var results models.PolicyCheckResults
+	Ok(t, json.Unmarshal([]byte(readFile(t, filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()))), &results))
+	Equals(t, 1, len(results.PolicySetResults))
+	Equals(t, false, results.PolicySetResults[0].Passed())
+	Equals(t, []string{"resolving policy set policies: clone failed"}, results.PolicySetResults[0].Rules[0].Messages)
+}
+
+func TestOPAExecutorWorkflow_RunWarnOnly(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego": nullResourcePolicy,
+	})
+	ctx.PolicySets.PolicySets[0].Severity = valid.WarnPolicySetSeverity
+	subject := NewOPAExecutorWorkflow(nil)
+
+	output, err := subject.Run(ctx, "", nil, workdir, nil)
+	Ok(t, err)
+	Equals(t, "Checking plan against the following policies: \n"+`  policies
+
+policies (warn only):
+FAIL - main - deny - null_resource.a is not allowed
+WARN - main - warn_random - random_id.b is discouraged
+
+3 tests, 1 passed, 1 warnings, 1 failures
+`, output)
+
+	var results models.PolicyCheckResults
+	Ok(t, json.Unmarshal([]byte(readFile(t, filepath.Join(workdir, ctx.GetPolicyCheckResultFileName()))), &results))
+	Equals(t, true, results.SoftFailed)
+	Equals(t, true, results.PolicySetResults[0].WarnOnly)
+}
+
+func TestOPAExecutorWorkflow_RunSuccess(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, `{"resource_changes": []}`, map[string]string{
+		"main.rego": nullResourcePolicy,
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	output, err := subject.Run(ctx, "", nil, workdir, nil)
+	Ok(t, err)
+	Equals(t, "Checking plan against the following policies: \n"+`  policies
+
+policies:
+
+3 tests, 3 passed, 0 warnings, 0 failures
+`, output)
+}
+
+func TestOPAExecutorWorkflow_RunInvalidPolicy(t *testing.T) {
+	workdir, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego": "package main\n\ndeny[msg] {",
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	_, err := subject.Run(ctx, "", nil, workdir, nil)
+	ErrContains(t, "evaluating policy set policies: compiling policies", err)
+}
+
+func TestOPAExecutorWorkflow_RunNoPlan(t *testing.T) {
+	_, ctx := newOPATestContext(t, planJSON, map[string]string{
+		"main.rego": nullResourcePolicy,
+	})
+	subject := NewOPAExecutorWorkflow(nil)
+
+	_, err := subject.Run(ctx, "", nil, tempDir(t), nil)
+	ErrContains(t, "reading plan json", err)
+}
+
+func TestOPAExecutorWorkflow_EnsureExecutorVersion(t *testing.T) {
+	path, err := NewOPAExecutorWorkflow(nil).EnsureExecutorVersion(logging.NewNoopLogger(t), nil)
+	Ok(t, err)
+	Equals(t, "", path)
+}
+
+// newOPATestContext writes plan as the show output of the project in a new
+// workdir and policies to a local policy set.
+func newOPATestContext(t *testing.T, plan string, policies map[string]string) (string, command.ProjectContext) {
+	t.Helper()
+	policyDir := tempDir(t)
+	for name, content := range policies {
+		path := filepath.Join(policyDir, name)
+		Ok(t, os.MkdirAll(filepath.Dir(path), 0700))
+		Ok(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	ctx := command.ProjectContext{
+		PolicySets: valid.PolicySets{
+			PolicySets: []valid.PolicySet{
+				{Name: "policies", Source: valid.LocalPolicySet, Path: policyDir},
+			},
+		},
+		ProjectName: "testproj",
+		Workspace:   "default",
+		Log:         logging.NewNoopLogger(t),
+	}
+	workdir := tempDir(t)
+	Ok(t, os.WriteFile(filepath.Join(workdir, ctx.GetShowResultFileName()), []byte(plan), 0600))
+	return workdir, ctx
+}
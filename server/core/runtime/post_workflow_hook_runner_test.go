@@ -65,8 +65,8 @@ func TestPostWorkflowHookRunner_Run(t *testing.T) {
 
 		RegisterMockTestingT(t)
 		terraform := mocks.NewMockClient()
-		When(terraform.EnsureVersion(matchers.AnyPtrToLoggingSimpleLogger(), matchers2.AnyPtrToGoVersionVersion())).
-			ThenReturn(nil)
+		When(terraform.EnsureVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), matchers2.AnyPtrToGoVersionVersion())).
+			ThenReturn("/path/dir", nil)
 
 		logger := logging.NewNoopLogger(t)
 
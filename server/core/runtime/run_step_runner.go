@@ -27,9 +27,11 @@ func (r *RunStepRunner) Run(ctx command.ProjectContext, command string, path str
 		tfVersion = ctx.TerraformVersion
 	}
 
-	err := r.TerraformExecutor.EnsureVersion(ctx.Log, tfVersion)
+	// binPathDir has the binary of the project's distribution, ex. tofu, at
+	// tfVersion so that it's run by the command.
+	binPathDir, err := r.TerraformExecutor.EnsureVersion(ctx.Log, ctx.TerraformDistribution, tfVersion)
 	if err != nil {
-		err = fmt.Errorf("%s: Downloading terraform Version %s", err, tfVersion.String())
+		err = fmt.Errorf("%s: ensuring version %s is available", err, tfVersion.String())
 		ctx.Log.Debug("error: %s", err)
 		return "", err
 	}
@@ -46,7 +48,7 @@ func (r *RunStepRunner) Run(ctx command.ProjectContext, command string, path str
 		"HEAD_COMMIT":                ctx.Pull.HeadCommit,
 		"HEAD_REPO_NAME":             ctx.HeadRepo.Name,
 		"HEAD_REPO_OWNER":            ctx.HeadRepo.Owner,
-		"PATH":                       fmt.Sprintf("%s:%s", os.Getenv("PATH"), r.TerraformBinDir),
+		"PATH":                       fmt.Sprintf("%s:%s:%s", binPathDir, os.Getenv("PATH"), r.TerraformBinDir),
 		"PLANFILE":                   filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName)),
 		"SHOWFILE":                   filepath.Join(path, ctx.GetShowResultFileName()),
 		"PROJECT_NAME":               ctx.ProjectName,
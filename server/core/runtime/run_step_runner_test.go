@@ -79,7 +79,7 @@ func TestRunStepRunner_Run(t *testing.T) {
 			ExpOut:  "user_name=acme-user\n",
 		}, {
 			Command: "echo $PATH",
-			ExpOut:  fmt.Sprintf("%s:%s\n", os.Getenv("PATH"), "/bin/dir"),
+			ExpOut:  fmt.Sprintf("%s:%s:%s\n", "/path/dir", os.Getenv("PATH"), "/bin/dir"),
 		},
 		{
 			Command: "echo args=$COMMENT_ARGS",
@@ -105,8 +105,8 @@ func TestRunStepRunner_Run(t *testing.T) {
 
 		RegisterMockTestingT(t)
 		terraform := mocks.NewMockClient()
-		When(terraform.EnsureVersion(matchers.AnyPtrToLoggingSimpleLogger(), matchers2.AnyPtrToGoVersionVersion())).
-			ThenReturn(nil)
+		When(terraform.EnsureVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), matchers2.AnyPtrToGoVersionVersion())).
+			ThenReturn("/path/dir", nil)
 
 		logger := logging.NewNoopLogger(t)
 		projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
@@ -158,8 +158,8 @@ func TestRunStepRunner_Run(t *testing.T) {
 			expOut := strings.Replace(c.ExpOut, "$DIR", tmpDir, -1)
 			Equals(t, expOut, out)
 
-			terraform.VerifyWasCalledOnce().EnsureVersion(logger, projVersion)
-			terraform.VerifyWasCalled(Never()).EnsureVersion(logger, defaultVersion)
+			terraform.VerifyWasCalledOnce().EnsureVersion(logger, "", projVersion)
+			terraform.VerifyWasCalled(Never()).EnsureVersion(logger, "", defaultVersion)
 
 		})
 	}
@@ -27,7 +27,7 @@ const (
 // without causing circular imports.
 type TerraformExec interface {
 	RunCommandWithVersion(ctx command.ProjectContext, path string, args []string, envs map[string]string, v *version.Version, workspace string) (string, error)
-	EnsureVersion(log logging.SimpleLogging, v *version.Version) error
+	EnsureVersion(log logging.SimpleLogging, distribution string, v *version.Version) (string, error)
 }
 
 // AsyncTFExec brings the interface from TerraformClient into this package
@@ -0,0 +1,107 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Distribution is a distribution of terraform, ex. HashiCorp's terraform or
+// OpenTofu, that Atlantis can download and run.
+type Distribution interface {
+	// BinName is the name of the distribution's binary, ex. terraform.
+	BinName() string
+	// ParseVersion extracts the version from the output of `<bin> version`.
+	ParseVersion(versionOutput string) (*version.Version, error)
+	// DownloadURL returns the go-getter URL of the zip file containing version
+	// v for this platform. It has the checksum to verify the file with.
+	DownloadURL(v *version.Version) string
+	// InstallURL is the URL of the distribution's install instructions.
+	InstallURL() string
+}
+
+// versionRegex extracts the version from `terraform version` output.
+//
+//	Terraform v0.12.0-alpha4 (2c36829d3265661d8edbd5014de8090ea7e2a076)
+//	=> 0.12.0-alpha4
+//
+//	Terraform v0.11.10
+//	=> 0.11.10
+var versionRegex = regexp.MustCompile("Terraform v(.*?)(\\s.*)?\n")
+
+// tofuVersionRegex extracts the version from `tofu version` output.
+//
+//	OpenTofu v1.6.0
+//	=> 1.6.0
+var tofuVersionRegex = regexp.MustCompile("OpenTofu v(.*?)(\\s.*)?\n")
+
+// TerraformDistribution is HashiCorp's terraform. Its releases are laid out
+// like https://releases.hashicorp.com.
+type TerraformDistribution struct {
+	// DownloadBaseURL is the URL of the releases, ex. https://releases.hashicorp.com.
+	DownloadBaseURL string
+}
+
+// See Distribution.BinName.
+func (d *TerraformDistribution) BinName() string {
+	return "terraform"
+}
+
+// See Distribution.ParseVersion.
+func (d *TerraformDistribution) ParseVersion(versionOutput string) (*version.Version, error) {
+	return parseVersion(d.BinName(), versionRegex, versionOutput)
+}
+
+// See Distribution.DownloadURL.
+func (d *TerraformDistribution) DownloadURL(v *version.Version) string {
+	urlPrefix := fmt.Sprintf("%s/terraform/%s/terraform_%s", d.DownloadBaseURL, v.String(), v.String())
+	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
+	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
+	return fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
+}
+
+// See Distribution.InstallURL.
+func (d *TerraformDistribution) InstallURL() string {
+	return "https://www.terraform.io/downloads.html"
+}
+
+// OpenTofuDistribution is OpenTofu. Its releases are laid out like the
+// releases of github.com/opentofu/opentofu.
+type OpenTofuDistribution struct {
+	// DownloadBaseURL is the URL of the releases, ex.
+	// https://github.com/opentofu/opentofu/releases/download.
+	DownloadBaseURL string
+}
+
+// See Distribution.BinName.
+func (d *OpenTofuDistribution) BinName() string {
+	return "tofu"
+}
+
+// See Distribution.ParseVersion.
+func (d *OpenTofuDistribution) ParseVersion(versionOutput string) (*version.Version, error) {
+	return parseVersion(d.BinName(), tofuVersionRegex, versionOutput)
+}
+
+// See Distribution.DownloadURL.
+func (d *OpenTofuDistribution) DownloadURL(v *version.Version) string {
+	urlPrefix := fmt.Sprintf("%s/v%s/tofu_%s", d.DownloadBaseURL, v.String(), v.String())
+	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
+	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
+	return fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
+}
+
+// See Distribution.InstallURL.
+func (d *OpenTofuDistribution) InstallURL() string {
+	return "https://opentofu.org/docs/intro/install"
+}
+
+func parseVersion(binName string, re *regexp.Regexp, versionOutput string) (*version.Version, error) {
+	match := re.FindStringSubmatch(versionOutput)
+	if len(match) <= 1 {
+		return nil, fmt.Errorf("could not parse %s version from %s", binName, versionOutput)
+	}
+	return version.NewVersion(match[1])
+}
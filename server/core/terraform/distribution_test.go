@@ -0,0 +1,51 @@
+package terraform_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDistribution_ParseVersion(t *testing.T) {
+	cases := []struct {
+		description  string
+		distribution terraform.Distribution
+		output       string
+		expVersion   string
+		expErr       string
+	}{
+		{
+			"terraform",
+			&terraform.TerraformDistribution{},
+			"Terraform v0.12.0-alpha4 (2c36829d3265661d8edbd5014de8090ea7e2a076)\n",
+			"0.12.0-alpha4",
+			"",
+		},
+		{
+			"opentofu",
+			&terraform.OpenTofuDistribution{},
+			"OpenTofu v1.6.0\non linux_amd64\n",
+			"1.6.0",
+			"",
+		},
+		{
+			"opentofu output parsed as terraform",
+			&terraform.TerraformDistribution{},
+			"OpenTofu v1.6.0\n",
+			"",
+			"could not parse terraform version from OpenTofu v1.6.0\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			v, err := c.distribution.ParseVersion(c.output)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expVersion, v.String())
+		})
+	}
+}
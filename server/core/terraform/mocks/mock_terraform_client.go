@@ -46,19 +46,23 @@ func (mock *MockClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 	return ret0, ret1
 }
 
-func (mock *MockClient) EnsureVersion(log logging.SimpleLogging, v *go_version.Version) error {
+func (mock *MockClient) EnsureVersion(log logging.SimpleLogging, distribution string, v *go_version.Version) (string, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockClient().")
 	}
-	params := []pegomock.Param{log, v}
-	result := pegomock.GetGenericMockFrom(mock).Invoke("EnsureVersion", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
-	var ret0 error
+	params := []pegomock.Param{log, distribution, v}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("EnsureVersion", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 string
+	var ret1 error
 	if len(result) != 0 {
 		if result[0] != nil {
-			ret0 = result[0].(error)
+			ret0 = result[0].(string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
 		}
 	}
-	return ret0
+	return ret0, ret1
 }
 
 func (mock *MockClient) VerifyWasCalledOnce() *VerifierMockClient {
@@ -145,8 +149,8 @@ func (c *MockClient_RunCommandWithVersion_OngoingVerification) GetAllCapturedArg
 	return
 }
 
-func (verifier *VerifierMockClient) EnsureVersion(log logging.SimpleLogging, v *go_version.Version) *MockClient_EnsureVersion_OngoingVerification {
-	params := []pegomock.Param{log, v}
+func (verifier *VerifierMockClient) EnsureVersion(log logging.SimpleLogging, distribution string, v *go_version.Version) *MockClient_EnsureVersion_OngoingVerification {
+	params := []pegomock.Param{log, distribution, v}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "EnsureVersion", params, verifier.timeout)
 	return &MockClient_EnsureVersion_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -156,21 +160,25 @@ type MockClient_EnsureVersion_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockClient_EnsureVersion_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, *go_version.Version) {
-	log, v := c.GetAllCapturedArguments()
-	return log[len(log)-1], v[len(v)-1]
+func (c *MockClient_EnsureVersion_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, string, *go_version.Version) {
+	log, distribution, v := c.GetAllCapturedArguments()
+	return log[len(log)-1], distribution[len(distribution)-1], v[len(v)-1]
 }
 
-func (c *MockClient_EnsureVersion_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []*go_version.Version) {
+func (c *MockClient_EnsureVersion_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []string, _param2 []*go_version.Version) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
 		_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
 		for u, param := range params[0] {
 			_param0[u] = param.(logging.SimpleLogging)
 		}
-		_param1 = make([]*go_version.Version, len(c.methodInvocations))
+		_param1 = make([]string, len(c.methodInvocations))
 		for u, param := range params[1] {
-			_param1[u] = param.(*go_version.Version)
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]*go_version.Version, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(*go_version.Version)
 		}
 	}
 	return
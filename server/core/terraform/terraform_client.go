@@ -19,8 +19,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"strings"
 	"sync"
 
@@ -29,6 +27,7 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime/models"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/terraform/ansi"
@@ -49,14 +48,22 @@ type Client interface {
 	// workspace which should be set as an environment variable.
 	RunCommandWithVersion(ctx command.ProjectContext, path string, args []string, envs map[string]string, v *version.Version, workspace string) (string, error)
 
-	// EnsureVersion makes sure that terraform version `v` is available to use
-	EnsureVersion(log logging.SimpleLogging, v *version.Version) error
+	// EnsureVersion makes sure that version `v` of distribution, ex. opentofu,
+	// is available to use. If distribution is empty, the default distribution
+	// is used. If v is nil, the default version of the distribution is used.
+	// It returns a directory in which the distribution's binary, ex. tofu,
+	// is that version so it can be added to $PATH.
+	EnsureVersion(log logging.SimpleLogging, distribution string, v *version.Version) (string, error)
 }
 
 type DefaultClient struct {
 	// defaultVersion is the default version of terraform to use if another
 	// version isn't specified.
 	defaultVersion *version.Version
+	// defaultVersions maps from the name of a distribution that isn't the
+	// default distribution to the version of its binary in $PATH, which is
+	// used if another version isn't specified.
+	defaultVersions map[string]*version.Version
 	// We will run terraform with the TF_PLUGIN_CACHE_DIR env var set to this
 	// directory inside our data dir.
 	terraformPluginCacheDir string
@@ -65,10 +72,16 @@ type DefaultClient struct {
 	// with another binary, ex. echo.
 	overrideTF string
 	// downloader downloads terraform versions.
-	downloader      Downloader
-	downloadBaseURL string
-	// versions maps from the string representation of a tf version (ex. 0.11.10)
-	// to the absolute path of that binary on disk (if it exists).
+	downloader Downloader
+	// defaultDistribution is the name of the distribution to use if another
+	// distribution isn't specified.
+	defaultDistribution string
+	// distributions maps from the name of a distribution (ex. opentofu) to
+	// the distribution.
+	distributions map[string]Distribution
+	// versions maps from the binary name and version of a distribution (ex.
+	// terraform0.11.10) to the absolute path of that binary on disk (if it
+	// exists).
 	// Use versionsLock to control access.
 	versions map[string]string
 
@@ -89,14 +102,6 @@ type Downloader interface {
 	GetAny(dst, src string, opts ...getter.ClientOption) error
 }
 
-// versionRegex extracts the version from `terraform version` output.
-//     Terraform v0.12.0-alpha4 (2c36829d3265661d8edbd5014de8090ea7e2a076)
-//	   => 0.12.0-alpha4
-//
-//     Terraform v0.11.10
-//	   => 0.11.10
-var versionRegex = regexp.MustCompile("Terraform v(.*?)(\\s.*)?\n")
-
 // NewClientWithDefaultVersion creates a new terraform client and pre-fetches the default version
 func NewClientWithDefaultVersion(
 	log logging.SimpleLogging,
@@ -106,7 +111,9 @@ func NewClientWithDefaultVersion(
 	tfeHostname string,
 	defaultVersionStr string,
 	defaultVersionFlagName string,
+	defaultDistributionName string,
 	tfDownloadURL string,
+	tofuDownloadURL string,
 	tfDownloader Downloader,
 	usePluginCache bool,
 	fetchAsync bool,
@@ -117,16 +124,28 @@ func NewClientWithDefaultVersion(
 	versions := make(map[string]string)
 	var versionsLock sync.Mutex
 
-	localPath, err := exec.LookPath("terraform")
+	distributions := map[string]Distribution{
+		valid.TerraformDistribution: &TerraformDistribution{DownloadBaseURL: tfDownloadURL},
+		valid.OpenTofuDistribution:  &OpenTofuDistribution{DownloadBaseURL: tofuDownloadURL},
+	}
+	if defaultDistributionName == "" {
+		defaultDistributionName = valid.TerraformDistribution
+	}
+	distribution, ok := distributions[defaultDistributionName]
+	if !ok {
+		return nil, fmt.Errorf("unknown distribution %q, must be %q or %q", defaultDistributionName, valid.TerraformDistribution, valid.OpenTofuDistribution)
+	}
+
+	localPath, err := exec.LookPath(distribution.BinName())
 	if err != nil && defaultVersionStr == "" {
-		return nil, fmt.Errorf("terraform not found in $PATH. Set --%s or download terraform from https://www.terraform.io/downloads.html", defaultVersionFlagName)
+		return nil, fmt.Errorf("%s not found in $PATH. Set --%s or download %s from %s", distribution.BinName(), defaultVersionFlagName, distribution.BinName(), distribution.InstallURL())
 	}
 	if err == nil {
-		localVersion, err = getVersion(localPath)
+		localVersion, err = getVersion(distribution, localPath)
 		if err != nil {
 			return nil, err
 		}
-		versions[localVersion.String()] = localPath
+		versions[distribution.BinName()+localVersion.String()] = localPath
 		if defaultVersionStr == "" {
 			// If they haven't set a default version, then whatever they had
 			// locally is now the default.
@@ -134,6 +153,26 @@ func NewClientWithDefaultVersion(
 		}
 	}
 
+	// The other distributions default to the version of their binary in
+	// $PATH, if any.
+	defaultVersions := make(map[string]*version.Version)
+	for name, d := range distributions {
+		if name == defaultDistributionName {
+			continue
+		}
+		localPath, err := exec.LookPath(d.BinName())
+		if err != nil {
+			continue
+		}
+		localVersion, err := getVersion(d, localPath)
+		if err != nil {
+			log.Warn("could not determine the version of %s: %s", localPath, err)
+			continue
+		}
+		versions[d.BinName()+localVersion.String()] = localPath
+		defaultVersions[name] = localVersion
+	}
+
 	if defaultVersionStr != "" {
 		defaultVersion, err := version.NewVersion(defaultVersionStr)
 		if err != nil {
@@ -144,10 +183,10 @@ func NewClientWithDefaultVersion(
 			// Since ensureVersion might end up downloading terraform,
 			// we call it asynchronously so as to not delay server startup.
 			versionsLock.Lock()
-			_, err := ensureVersion(log, tfDownloader, versions, defaultVersion, binDir, tfDownloadURL)
+			_, err := ensureVersion(log, tfDownloader, versions, distribution, defaultVersion, binDir)
 			versionsLock.Unlock()
 			if err != nil {
-				log.Err("could not download terraform %s: %s", defaultVersion.String(), err)
+				log.Err("could not download %s %s: %s", defaultDistributionName, defaultVersion.String(), err)
 			}
 		}
 
@@ -170,10 +209,12 @@ func NewClientWithDefaultVersion(
 	}
 	return &DefaultClient{
 		defaultVersion:          finalDefaultVersion,
+		defaultVersions:         defaultVersions,
 		terraformPluginCacheDir: cacheDir,
 		binDir:                  binDir,
 		downloader:              tfDownloader,
-		downloadBaseURL:         tfDownloadURL,
+		defaultDistribution:     defaultDistributionName,
+		distributions:           distributions,
 		versionsLock:            &versionsLock,
 		versions:                versions,
 		usePluginCache:          usePluginCache,
@@ -190,7 +231,9 @@ func NewTestClient(
 	tfeHostname string,
 	defaultVersionStr string,
 	defaultVersionFlagName string,
+	defaultDistributionName string,
 	tfDownloadURL string,
+	tofuDownloadURL string,
 	tfDownloader Downloader,
 	usePluginCache bool,
 	projectCmdOutputHandler jobs.ProjectCommandOutputHandler,
@@ -203,7 +246,9 @@ func NewTestClient(
 		tfeHostname,
 		defaultVersionStr,
 		defaultVersionFlagName,
+		defaultDistributionName,
 		tfDownloadURL,
+		tofuDownloadURL,
 		tfDownloader,
 		usePluginCache,
 		false,
@@ -227,7 +272,9 @@ func NewClient(
 	tfeHostname string,
 	defaultVersionStr string,
 	defaultVersionFlagName string,
+	defaultDistributionName string,
 	tfDownloadURL string,
+	tofuDownloadURL string,
 	tfDownloader Downloader,
 	usePluginCache bool,
 	projectCmdOutputHandler jobs.ProjectCommandOutputHandler,
@@ -240,7 +287,9 @@ func NewClient(
 		tfeHostname,
 		defaultVersionStr,
 		defaultVersionFlagName,
+		defaultDistributionName,
 		tfDownloadURL,
+		tofuDownloadURL,
 		tfDownloader,
 		usePluginCache,
 		true,
@@ -254,26 +303,55 @@ func (c *DefaultClient) DefaultVersion() *version.Version {
 	return c.defaultVersion
 }
 
+// DefaultVersions returns the default version of each distribution, ex.
+// opentofu, that has one.
+func (c *DefaultClient) DefaultVersions() map[string]*version.Version {
+	versions := map[string]*version.Version{
+		c.defaultDistribution: c.defaultVersion,
+	}
+	for name, v := range c.defaultVersions {
+		versions[name] = v
+	}
+	return versions
+}
+
 // TerraformBinDir returns the directory where we download Terraform binaries.
 func (c *DefaultClient) TerraformBinDir() string {
 	return c.binDir
 }
 
 // See Client.EnsureVersion.
-func (c *DefaultClient) EnsureVersion(log logging.SimpleLogging, v *version.Version) error {
-	if v == nil {
-		v = c.defaultVersion
+func (c *DefaultClient) EnsureVersion(log logging.SimpleLogging, distribution string, v *version.Version) (string, error) {
+	v, err := c.versionOrDefault(distribution, v)
+	if err != nil {
+		return "", err
+	}
+	d, err := c.distribution(distribution)
+	if err != nil {
+		return "", err
 	}
 
-	var err error
 	c.versionsLock.Lock()
-	_, err = ensureVersion(log, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
-	c.versionsLock.Unlock()
+	defer c.versionsLock.Unlock()
+	binPath, err := ensureVersion(log, c.downloader, c.versions, d, v, c.binDir)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	// The binary is named after its version, ex. tofu1.6.0, so we link it
+	// into its own directory under the distribution's binary name.
+	pathDir := filepath.Join(c.binDir, "path", d.BinName()+v.String())
+	link := filepath.Join(pathDir, d.BinName())
+	if _, err := os.Lstat(link); err == nil {
+		return pathDir, nil
+	}
+	if err := os.MkdirAll(pathDir, 0700); err != nil {
+		return "", errors.Wrapf(err, "creating %s", pathDir)
+	}
+	if err := os.Symlink(binPath, link); err != nil {
+		return "", errors.Wrapf(err, "linking %s to %s", link, binPath)
+	}
+	return pathDir, nil
 }
 
 // See Client.RunCommandWithVersion.
@@ -296,7 +374,7 @@ func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 		output = ansi.Strip(output)
 		return fmt.Sprintf("%s\n", output), err
 	}
-	tfCmd, cmd, err := c.prepExecCmd(ctx.Log, v, workspace, path, args)
+	tfCmd, cmd, err := c.prepExecCmd(ctx.Log, ctx.TerraformDistribution, v, workspace, path, args)
 	if err != nil {
 		return "", err
 	}
@@ -316,11 +394,11 @@ func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 	return ansi.Strip(string(out)), nil
 }
 
-// prepExecCmd builds a ready to execute command based on the version of terraform
-// v, and args. It returns a printable representation of the command that will
-// be run and the actual command.
-func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, v *version.Version, workspace string, path string, args []string) (string, *exec.Cmd, error) {
-	tfCmd, envVars, err := c.prepCmd(log, v, workspace, path, args)
+// prepExecCmd builds a ready to execute command based on the distribution and
+// version of terraform v, and args. It returns a printable representation of
+// the command that will be run and the actual command.
+func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, distribution string, v *version.Version, workspace string, path string, args []string) (string, *exec.Cmd, error) {
+	tfCmd, envVars, err := c.prepCmd(log, distribution, v, workspace, path, args)
 	if err != nil {
 		return "", nil, err
 	}
@@ -332,9 +410,10 @@ func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, v *version.Versio
 
 // prepCmd prepares a shell command (to be interpreted with `sh -c <cmd>`) and set of environment
 // variables for running terraform.
-func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, workspace string, path string, args []string) (string, []string, error) {
-	if v == nil {
-		v = c.defaultVersion
+func (c *DefaultClient) prepCmd(log logging.SimpleLogging, distribution string, v *version.Version, workspace string, path string, args []string) (string, []string, error) {
+	v, err := c.versionOrDefault(distribution, v)
+	if err != nil {
+		return "", nil, err
 	}
 
 	var binPath string
@@ -342,9 +421,12 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, w
 		// This is only set during testing.
 		binPath = c.overrideTF
 	} else {
-		var err error
+		d, err := c.distribution(distribution)
+		if err != nil {
+			return "", nil, err
+		}
 		c.versionsLock.Lock()
-		binPath, err = ensureVersion(log, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
+		binPath, err = ensureVersion(log, c.downloader, c.versions, d, v, c.binDir)
 		c.versionsLock.Unlock()
 		if err != nil {
 			return "", nil, err
@@ -378,7 +460,7 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, w
 // If any error is passed on the out channel, there will be no
 // further output (so callers are free to exit).
 func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (chan<- string, <-chan models.Line) {
-	cmd, envVars, err := c.prepCmd(ctx.Log, v, workspace, path, args)
+	cmd, envVars, err := c.prepCmd(ctx.Log, ctx.TerraformDistribution, v, workspace, path, args)
 	if err != nil {
 		// The signature of `RunCommandAsync` doesn't provide for returning an immediate error, only one
 		// once reading the output. Since we won't be spawning a process, simulate that by sending the
@@ -402,6 +484,38 @@ func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string,
 	return inCh, outCh
 }
 
+// distribution returns the distribution called name or the default
+// distribution if name is empty.
+func (c *DefaultClient) distribution(name string) (Distribution, error) {
+	if name == "" {
+		name = c.defaultDistribution
+	}
+	d, ok := c.distributions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown distribution %q", name)
+	}
+	return d, nil
+}
+
+// versionOrDefault returns v or, if v is nil, the default version of the
+// distribution called name.
+func (c *DefaultClient) versionOrDefault(name string, v *version.Version) (*version.Version, error) {
+	if v != nil {
+		return v, nil
+	}
+	if name == "" || name == c.defaultDistribution {
+		return c.defaultVersion, nil
+	}
+	if v, ok := c.defaultVersions[name]; ok {
+		return v, nil
+	}
+	d, err := c.distribution(name)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no default version of %s, set terraform_version for the project or add %s to $PATH", name, d.BinName())
+}
+
 // MustConstraint will parse one or more constraints from the given
 // constraint string. The string must be a comma-separated list of
 // constraints. It panics if there is an error.
@@ -413,19 +527,21 @@ func MustConstraint(v string) version.Constraints {
 	return c
 }
 
-// ensureVersion returns the path to a terraform binary of version v.
+// ensureVersion returns the path to a binary of version v of distribution d.
 // It will download this version if we don't have it.
-func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string]string, v *version.Version, binDir string, downloadURL string) (string, error) {
-	if binPath, ok := versions[v.String()]; ok {
+func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string]string, d Distribution, v *version.Version, binDir string) (string, error) {
+	// binFile is also the key of the version in the versions map, ex.
+	// terraform0.11.10.
+	binFile := d.BinName() + v.String()
+	if binPath, ok := versions[binFile]; ok {
 		return binPath, nil
 	}
 
-	// This tf version might not yet be in the versions map even though it
+	// This version might not yet be in the versions map even though it
 	// exists on disk. This would happen if users have manually added
 	// terraform{version} binaries. In this case we don't want to re-download.
-	binFile := "terraform" + v.String()
 	if binPath, err := exec.LookPath(binFile); err == nil {
-		versions[v.String()] = binPath
+		versions[binFile] = binPath
 		return binPath, nil
 	}
 
@@ -433,20 +549,17 @@ func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string
 	// This could happen if Atlantis was restarted without losing its disk.
 	dest := filepath.Join(binDir, binFile)
 	if _, err := os.Stat(dest); err == nil {
-		versions[v.String()] = dest
+		versions[binFile] = dest
 		return dest, nil
 	}
-	log.Info("could not find terraform version %s in PATH or %s, downloading from %s", v.String(), binDir, downloadURL)
-	urlPrefix := fmt.Sprintf("%s/terraform/%s/terraform_%s", downloadURL, v.String(), v.String())
-	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
-	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
-	fullSrcURL := fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
+	fullSrcURL := d.DownloadURL(v)
+	log.Info("could not find %s version %s in PATH or %s, downloading from %s", d.BinName(), v.String(), binDir, fullSrcURL)
 	if err := dl.GetFile(dest, fullSrcURL); err != nil {
-		return "", errors.Wrapf(err, "downloading terraform version %s at %q", v.String(), fullSrcURL)
+		return "", errors.Wrapf(err, "downloading %s version %s at %q", d.BinName(), v.String(), fullSrcURL)
 	}
 
-	log.Info("downloaded terraform %s to %s", v.String(), dest)
-	versions[v.String()] = dest
+	log.Info("downloaded %s %s to %s", d.BinName(), v.String(), dest)
+	versions[binFile] = dest
 	return dest, nil
 }
 
@@ -489,17 +602,13 @@ func isAsyncEligibleCommand(cmd string) bool {
 	return false
 }
 
-func getVersion(tfBinary string) (*version.Version, error) {
-	versionOutBytes, err := exec.Command(tfBinary, "version").Output() // #nosec
+func getVersion(d Distribution, binary string) (*version.Version, error) {
+	versionOutBytes, err := exec.Command(binary, "version").Output() // #nosec
 	versionOutput := string(versionOutBytes)
 	if err != nil {
-		return nil, errors.Wrapf(err, "running terraform version: %s", versionOutput)
-	}
-	match := versionRegex.FindStringSubmatch(versionOutput)
-	if len(match) <= 1 {
-		return nil, fmt.Errorf("could not parse terraform version from %s", versionOutput)
+		return nil, errors.Wrapf(err, "running %s version: %s", d.BinName(), versionOutput)
 	}
-	return version.NewVersion(match[1])
+	return d.ParseVersion(versionOutput)
 }
 
 // rcFileContents is a format string to be used with Sprintf that can be used
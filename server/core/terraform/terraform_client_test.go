@@ -77,7 +77,7 @@ is 0.11.13. You can update by downloading from www.terraform.io/downloads.html
 	Ok(t, err)
 	defer tempSetEnv(t, "PATH", fmt.Sprintf("%s:%s", tmp, os.Getenv("PATH")))()
 
-	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Ok(t, err)
@@ -112,7 +112,7 @@ is 0.11.13. You can update by downloading from www.terraform.io/downloads.html
 	Ok(t, err)
 	defer tempSetEnv(t, "PATH", fmt.Sprintf("%s:%s", tmp, os.Getenv("PATH")))()
 
-	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Ok(t, err)
@@ -134,7 +134,7 @@ func TestNewClient_NoTF(t *testing.T) {
 	// Set PATH to only include our empty directory.
 	defer tempSetEnv(t, "PATH", tmp)()
 
-	_, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	_, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	ErrEquals(t, "terraform not found in $PATH. Set --default-tf-version or download terraform from https://www.terraform.io/downloads.html", err)
 }
 
@@ -158,7 +158,7 @@ func TestNewClient_DefaultTFFlagInPath(t *testing.T) {
 	Ok(t, err)
 	defer tempSetEnv(t, "PATH", fmt.Sprintf("%s:%s", tmp, os.Getenv("PATH")))()
 
-	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Ok(t, err)
@@ -187,7 +187,7 @@ func TestNewClient_DefaultTFFlagInBinDir(t *testing.T) {
 	Ok(t, err)
 	defer tempSetEnv(t, "PATH", fmt.Sprintf("%s:%s", tmp, os.Getenv("PATH")))()
 
-	c, err := terraform.NewClient(logging.NewNoopLogger(t), binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logging.NewNoopLogger(t), binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Ok(t, err)
@@ -220,7 +220,7 @@ func TestNewClient_DefaultTFFlagDownload(t *testing.T) {
 		err := os.WriteFile(params[0].(string), []byte("#!/bin/sh\necho '\nTerraform v0.11.10\n'"), 0700) // #nosec G306
 		return []pegomock.ReturnValue{err}
 	})
-	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, "https://my-mirror.releases.mycompany.com", mockDownloader, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, "https://my-mirror.releases.mycompany.com", cmd.DefaultTofuDownloadURL, mockDownloader, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Ok(t, err)
@@ -247,7 +247,7 @@ func TestNewClient_BadVersion(t *testing.T) {
 	_, binDir, cacheDir, cleanup := mkSubDirs(t)
 	projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
 	defer cleanup()
-	_, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "malformed", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, nil, true, projectCmdOutputHandler)
+	_, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "malformed", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
 	ErrEquals(t, "Malformed version: malformed", err)
 }
 
@@ -277,7 +277,7 @@ func TestRunCommandWithVersion_DLsTF(t *testing.T) {
 		return []pegomock.ReturnValue{err}
 	})
 
-	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, mockDownloader, true, projectCmdOutputHandler)
+	c, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, mockDownloader, true, projectCmdOutputHandler)
 	Ok(t, err)
 	Equals(t, "0.11.10", c.DefaultVersion().String())
 
@@ -300,7 +300,7 @@ func TestEnsureVersion_downloaded(t *testing.T) {
 
 	mockDownloader := mocks.NewMockDownloader()
 
-	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, mockDownloader, true, projectCmdOutputHandler)
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, mockDownloader, true, projectCmdOutputHandler)
 	Ok(t, err)
 
 	Equals(t, "0.11.10", c.DefaultVersion().String())
@@ -308,9 +308,12 @@ func TestEnsureVersion_downloaded(t *testing.T) {
 	v, err := version.NewVersion("99.99.99")
 	Ok(t, err)
 
-	err = c.EnsureVersion(logger, v)
+	pathDir, err := c.EnsureVersion(logger, "", v)
 
 	Ok(t, err)
+	dest, err := os.Readlink(filepath.Join(pathDir, "terraform"))
+	Ok(t, err)
+	Equals(t, filepath.Join(tmp, "bin", "terraform99.99.99"), dest)
 
 	baseURL := fmt.Sprintf("%s/terraform/99.99.99", cmd.DefaultTFDownloadURL)
 	expURL := fmt.Sprintf("%s/terraform_99.99.99_%s_%s.zip?checksum=file:%s/terraform_99.99.99_SHA256SUMS",
@@ -321,6 +324,90 @@ func TestEnsureVersion_downloaded(t *testing.T) {
 	mockDownloader.VerifyWasCalledEventually(Once(), 2*time.Second).GetFile(filepath.Join(tmp, "bin", "terraform99.99.99"), expURL)
 }
 
+// Test the EnsureVersion downloads OpenTofu from its own releases.
+func TestEnsureVersion_downloadedOpenTofu(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, mockDownloader, true, projectCmdOutputHandler)
+	Ok(t, err)
+
+	v, err := version.NewVersion("1.6.0")
+	Ok(t, err)
+
+	pathDir, err := c.EnsureVersion(logger, "opentofu", v)
+	Ok(t, err)
+	dest, err := os.Readlink(filepath.Join(pathDir, "tofu"))
+	Ok(t, err)
+	Equals(t, filepath.Join(tmp, "bin", "tofu1.6.0"), dest)
+
+	baseURL := fmt.Sprintf("%s/v1.6.0", cmd.DefaultTofuDownloadURL)
+	expURL := fmt.Sprintf("%s/tofu_1.6.0_%s_%s.zip?checksum=file:%s/tofu_1.6.0_SHA256SUMS",
+		baseURL,
+		runtime.GOOS,
+		runtime.GOARCH,
+		baseURL)
+	mockDownloader.VerifyWasCalledEventually(Once(), 2*time.Second).GetFile(filepath.Join(tmp, "bin", "tofu1.6.0"), expURL)
+}
+
+// Test that a distribution other than the default one defaults to the version
+// of its binary in PATH.
+func TestNewClient_OtherDistributionLocalVersion(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	defer cleanup()
+
+	err := os.WriteFile(filepath.Join(tmp, "tofu"), []byte("#!/bin/sh\necho 'OpenTofu v1.6.2'"), 0700) // #nosec G306
+	Ok(t, err)
+	defer tempSetEnv(t, "PATH", fmt.Sprintf("%s:%s", tmp, os.Getenv("PATH")))()
+
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, mocks.NewMockDownloader(), true, projectCmdOutputHandler)
+	Ok(t, err)
+
+	versions := c.DefaultVersions()
+	Equals(t, "0.11.10", versions["terraform"].String())
+	Equals(t, "1.6.2", versions["opentofu"].String())
+
+	// Without a version, OpenTofu runs its own default version.
+	pathDir, err := c.EnsureVersion(logger, "opentofu", nil)
+	Ok(t, err)
+	dest, err := os.Readlink(filepath.Join(pathDir, "tofu"))
+	Ok(t, err)
+	Equals(t, filepath.Join(tmp, "tofu"), dest)
+}
+
+// Test that we get an error if a distribution other than the default one
+// has no version and isn't in PATH.
+func TestEnsureVersion_NoDefaultVersion(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	_, binDir, cacheDir, cleanup := mkSubDirs(t)
+	projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	defer cleanup()
+	defer tempSetEnv(t, "PATH", "")()
+
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDistribution, cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, mocks.NewMockDownloader(), true, projectCmdOutputHandler)
+	Ok(t, err)
+
+	_, err = c.EnsureVersion(logger, "opentofu", nil)
+	ErrEquals(t, "no default version of opentofu, set terraform_version for the project or add tofu to $PATH", err)
+}
+
+// Test that we get an error if the distribution is unknown.
+func TestNewClient_BadDistribution(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	_, binDir, cacheDir, cleanup := mkSubDirs(t)
+	projectCmdOutputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	defer cleanup()
+	_, err := terraform.NewClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, "terragrunt", cmd.DefaultTFDownloadURL, cmd.DefaultTofuDownloadURL, nil, true, projectCmdOutputHandler)
+	ErrEquals(t, `unknown distribution "terragrunt", must be "terraform" or "opentofu"`, err)
+}
+
 // tempSetEnv sets env var key to value. It returns a function that when called
 // will reset the env var to its original value.
 func tempSetEnv(t *testing.T, key string, value string) func() {
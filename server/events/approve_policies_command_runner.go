@@ -1,8 +1,6 @@
 package events
 
 import (
-	"fmt"
-
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 )
@@ -88,14 +86,8 @@ func (a *ApprovePoliciesCommandRunner) Run(ctx *command.Context, cmd *CommentCom
 }
 
 func (a *ApprovePoliciesCommandRunner) buildApprovePolicyCommandResults(ctx *command.Context, prjCmds []command.ProjectContext) (result command.Result) {
-	// Check if vcs user is in the owner list of the PolicySets. All projects
-	// share the same Owners list at this time so no reason to iterate over each
-	// project.
-	if len(prjCmds) > 0 && !prjCmds[0].PolicySets.IsOwner(ctx.User.Username) {
-		result.Error = fmt.Errorf("contact policy owners to approve failing policies")
-		return
-	}
-
+	// Whether the user owns the failing policy sets is checked per project
+	// since each policy set has its own owners.
 	var prjResults []command.ProjectResult
 
 	for _, prjCmd := range prjCmds {
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"time"
+)
+
+// APIJobStatus is the status of an asynchronous API job.
+type APIJobStatus string
+
+const (
+	// PendingAPIJobStatus means the job hasn't started running yet.
+	PendingAPIJobStatus APIJobStatus = "pending"
+	// RunningAPIJobStatus means the job is running.
+	RunningAPIJobStatus APIJobStatus = "running"
+	// SucceededAPIJobStatus means the job finished without errors.
+	SucceededAPIJobStatus APIJobStatus = "succeeded"
+	// FailedAPIJobStatus means the job finished with an error or failure.
+	FailedAPIJobStatus APIJobStatus = "failed"
+)
+
+// APIJob is the plan or apply of a single project started asynchronously
+// through the API. It's stored in the DB so that any replica sharing the DB
+// can return it.
+type APIJob struct {
+	// ID is the job id of the project's plan. It stays the same when an apply
+	// job moves on to applying.
+	ID           string
+	Status       APIJobStatus
+	Command      string
+	VCSHost      string
+	RepoFullName string
+	ProjectName  string
+	RepoRelDir   string
+	Workspace    string
+	// LogURL is the URL of the page that streams the Terraform output of the
+	// command that's currently running.
+	LogURL string
+	// Result is set once the job has finished. Its Error is always nil since
+	// errors can't be serialized, see Error instead.
+	Result *ProjectResult
+	// Error is the error the job finished with, if any.
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
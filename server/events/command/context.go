@@ -37,4 +37,10 @@ type Context struct {
 	PullStatus *models.PullStatus
 
 	Trigger Trigger
+
+	// APIResults, if set, collects the results that are commented on the
+	// pull request so that the API request that ran the command can respond
+	// with them. A plan can be followed by policy checks, so there can be
+	// more than one.
+	APIResults *[]Result
 }
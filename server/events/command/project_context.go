@@ -55,6 +55,9 @@ type ProjectContext struct {
 	PullReqStatus models.PullReqStatus
 	// CurrentProjectPlanStatus is the status of the current project prior to this command.
 	ProjectPlanStatus models.ProjectPlanStatus
+	// ProjectPlanSummary is the one line summary of the current project's last
+	// successful plan prior to this command.
+	ProjectPlanSummary string
 	// Pull is the pull request we're responding to.
 	Pull models.PullRequest
 	// ProjectName is the name of the project set in atlantis.yaml. If there was
@@ -75,6 +78,14 @@ type ProjectContext struct {
 	// commands for this project. This can be set to nil in which case we will
 	// use the default Atlantis terraform version.
 	TerraformVersion *version.Version
+	// TerraformDistribution is the distribution of terraform, ex. opentofu,
+	// we should use when executing commands for this project. If empty, we
+	// use the default Atlantis distribution.
+	TerraformDistribution string
+	// CheckoutDirs are the dirs, relative to the root of the repo, that need
+	// to be checked out to run commands for this project when using sparse
+	// checkouts. If nil, the whole repo is checked out.
+	CheckoutDirs []string
 	// Configuration metadata for a given project.
 	User models.User
 	// Verbose is true when the user would like verbose output.
@@ -91,6 +102,15 @@ type ProjectContext struct {
 	JobID string
 	// The index of order group. Before planning/applying it will use to sort projects. Default is 0.
 	ExecutionOrderGroup int
+	// ProjectPolicyStatus is the status of each policy set in the project's
+	// last policy check prior to this command.
+	ProjectPolicyStatus []models.PolicySetStatus
+	// ProjectPolicyApprovals are the approvals of the project's failing policy
+	// sets prior to this command.
+	ProjectPolicyApprovals []models.PolicySetApproval
+	// PolicySetTarget is the name of the policy set to approve with
+	// approve_policies. If empty, all failing policy sets are approved.
+	PolicySetTarget string
 }
 
 // SetScope sets the scope of the stats object field. Note: we deliberately set this on the value
@@ -108,6 +128,16 @@ func (p ProjectContext) GetShowResultFileName() string {
 	return fmt.Sprintf("%s-%s.json", projName, p.Workspace)
 }
 
+// GetPolicyCheckResultFileName returns the filename (not the path) to store
+// the structured policy check results in.
+func (p ProjectContext) GetPolicyCheckResultFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s.policies.json", p.Workspace)
+	}
+	projName := strings.Replace(p.ProjectName, "/", planfileSlashReplace, -1)
+	return fmt.Sprintf("%s-%s.policies.json", projName, p.Workspace)
+}
+
 // Gets a unique identifier for the current pull request as a single string
 func (p ProjectContext) PullInfo() string {
 	normalizedOwner := strings.ReplaceAll(p.BaseRepo.Owner, "/", "-")
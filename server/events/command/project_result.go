@@ -16,6 +16,13 @@ type ProjectResult struct {
 	ApplySuccess       string
 	VersionSuccess     string
 	ProjectName        string
+	// PolicySetResults are the structured results of a policy check. They're
+	// set whether the policy check passed or failed, if the policy engine
+	// reports them.
+	PolicySetResults []models.PolicySetResult
+	// PolicyApprovals are the approvals of the project's failing policy sets
+	// after approve_policies ran. They're nil for other commands.
+	PolicyApprovals []models.PolicySetApproval
 }
 
 // CommitStatus returns the vcs commit status of this project result.
@@ -26,6 +33,9 @@ func (p ProjectResult) CommitStatus() models.CommitStatus {
 	if p.Failure != "" {
 		return models.FailedCommitStatus
 	}
+	if p.PolicyCheckSuccess != nil && p.PolicyCheckSuccess.SoftFailed {
+		return models.NeutralCommitStatus
+	}
 	return models.SuccessCommitStatus
 }
 
@@ -59,6 +69,15 @@ func (p ProjectResult) PlanStatus() models.ProjectPlanStatus {
 	panic("PlanStatus() missing a combination")
 }
 
+// PolicyStatus returns the status of each policy set in PolicySetResults.
+func (p ProjectResult) PolicyStatus() []models.PolicySetStatus {
+	var statuses []models.PolicySetStatus
+	for _, r := range p.PolicySetResults {
+		statuses = append(statuses, r.Status())
+	}
+	return statuses
+}
+
 // IsSuccessful returns true if this project result had no errors.
 func (p ProjectResult) IsSuccessful() bool {
 	return p.PlanSuccess != nil || p.PolicyCheckSuccess != nil || p.ApplySuccess != ""
@@ -24,3 +24,14 @@ func (c Result) HasErrors() bool {
 	}
 	return false
 }
+
+// HasSoftFailures returns true if any project's policy check only failed
+// policy sets that are warn only.
+func (c Result) HasSoftFailures() bool {
+	for _, r := range c.ProjectResults {
+		if r.PolicyCheckSuccess != nil && r.PolicyCheckSuccess.SoftFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
 	"github.com/runatlantis/atlantis/server/recovery"
@@ -35,6 +36,10 @@ const (
 	ShutdownComment = "Atlantis server is shutting down, please try again later."
 )
 
+// ErrShuttingDown is returned when a command can't run because Atlantis is
+// shutting down.
+var ErrShuttingDown = errors.New("atlantis is shutting down, try again later")
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_command_runner.go CommandRunner
 
 // CommandRunner is the first step after a command request has been parsed.
@@ -46,6 +51,21 @@ type CommandRunner interface {
 	RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_api_command_runner.go APICommandRunner
+
+// APICommandRunner runs the commands that API requests run against pull
+// requests.
+type APICommandRunner interface {
+	// GetPullRequest looks up the pull request pullNum of baseRepo on its VCS
+	// host. headRepo is the repo the pull request branch is from.
+	GetPullRequest(baseRepo models.Repo, pullNum int) (pull models.PullRequest, headRepo models.Repo, err error)
+	// RunAPICommand runs cmd against pull like a comment would and returns
+	// the results it commented. The API has already authorized user to run
+	// cmd so the team allowlist isn't checked. It returns an error if cmd
+	// can't be run on pull.
+	RunAPICommand(headRepo models.Repo, pull models.PullRequest, user models.User, cmd *CommentCommand) ([]command.Result, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_github_pull_getter.go GithubPullGetter
 
 // GithubPullGetter makes API calls to get pull requests.
@@ -62,8 +82,25 @@ type AzureDevopsPullGetter interface {
 	GetPullRequest(repo models.Repo, pullNum int) (*azuredevops.GitPullRequest, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitea_pull_getter.go GiteaPullGetter
+
+// GiteaPullGetter makes API calls to get pull requests.
+type GiteaPullGetter interface {
+	// GetPullRequest gets the pull request with id pullNum for the repo.
+	GetPullRequest(repo models.Repo, pullNum int) (*gitea.PullRequest, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitlab_merge_request_getter.go GitlabMergeRequestGetter
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_bitbucket_pull_getter.go BitbucketPullGetter
+
+// BitbucketPullGetter makes API calls to get pull requests.
+type BitbucketPullGetter interface {
+	// GetPullRequest gets the pull request with id pullNum for the repo as
+	// returned by the API.
+	GetPullRequest(repo models.Repo, pullNum int) ([]byte, error)
+}
+
 // GitlabMergeRequestGetter makes API calls to get merge requests.
 type GitlabMergeRequestGetter interface {
 	// GetMergeRequest gets the pull request with the id pullNum for the repo.
@@ -96,11 +133,23 @@ type DefaultCommandRunner struct {
 	GithubPullGetter         GithubPullGetter
 	AzureDevopsPullGetter    AzureDevopsPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
-	DisableAutoplan          bool
-	EventParser              EventParsing
-	Logger                   logging.SimpleLogging
-	GlobalCfg                valid.GlobalCfg
-	StatsScope               tally.Scope
+	GiteaPullGetter          GiteaPullGetter
+	// BitbucketCloudPullGetter and BitbucketServerPullGetter are only used
+	// for API requests since Bitbucket webhooks include the pull request.
+	BitbucketCloudPullGetter  BitbucketPullGetter
+	BitbucketServerPullGetter BitbucketPullGetter
+	DisableAutoplan           bool
+	EventParser               EventParsing
+	Logger                    logging.SimpleLogging
+	GlobalCfg                 valid.GlobalCfg
+	StatsScope                tally.Scope
+	// HostGithubPullGetters, HostGitlabMergeRequestGetters and
+	// HostGiteaPullGetters are the getters of VCS hosts other than the
+	// default host of their type, keyed by hostname. Repos on other hosts
+	// use the getters above.
+	HostGithubPullGetters         map[string]GithubPullGetter
+	HostGitlabMergeRequestGetters map[string]GitlabMergeRequestGetter
+	HostGiteaPullGetters          map[string]GiteaPullGetter
 	// AllowForkPRs controls whether we operate on pull requests from forks.
 	AllowForkPRs bool
 	// ParallelPoolSize controls the size of the wait group used to run
@@ -156,7 +205,7 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 		PullStatus: status,
 		Trigger:    command.AutoTrigger,
 	}
-	if !c.validateCtxAndComment(ctx) {
+	if err := c.validateCtxAndComment(ctx); err != nil {
 		return
 	}
 	if c.DisableAutoplan {
@@ -281,11 +330,17 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		Trigger:    command.CommentTrigger,
 	}
 
-	if !c.validateCtxAndComment(ctx) {
-		return
+	c.runCommentCommand(ctx, cmd) // nolint: errcheck
+}
+
+// runCommentCommand runs cmd once its user has been allowed to run it. It
+// returns an error if cmd can't be run on the pull request of ctx.
+func (c *DefaultCommandRunner) runCommentCommand(ctx *command.Context, cmd *CommentCommand) error {
+	if err := c.validateCtxAndComment(ctx); err != nil {
+		return err
 	}
 
-	err = c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx)
+	err := c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx)
 
 	if err != nil {
 		ctx.Log.Err("Error running pre-workflow hooks %s. Proceeding with %s command.", err, cmd.Name.String())
@@ -300,13 +355,18 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	if err != nil {
 		ctx.Log.Err("Error running post-workflow hooks %s.", err)
 	}
+	return nil
 }
 
 func (c *DefaultCommandRunner) getGithubData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
-	if c.GithubPullGetter == nil {
+	getter := c.GithubPullGetter
+	if hostGetter, ok := c.HostGithubPullGetters[baseRepo.VCSHost.Hostname]; ok {
+		getter = hostGetter
+	}
+	if getter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support GitHub")
 	}
-	ghPull, err := c.GithubPullGetter.GetPullRequest(baseRepo, pullNum)
+	ghPull, err := getter.GetPullRequest(baseRepo, pullNum)
 	if err != nil {
 		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to GitHub")
 	}
@@ -318,17 +378,48 @@ func (c *DefaultCommandRunner) getGithubData(baseRepo models.Repo, pullNum int)
 }
 
 func (c *DefaultCommandRunner) getGitlabData(baseRepo models.Repo, pullNum int) (models.PullRequest, error) {
-	if c.GitlabMergeRequestGetter == nil {
-		return models.PullRequest{}, errors.New("Atlantis not configured to support GitLab")
-	}
-	mr, err := c.GitlabMergeRequestGetter.GetMergeRequest(baseRepo.FullName, pullNum)
+	mr, err := c.getGitlabMergeRequest(baseRepo, pullNum)
 	if err != nil {
-		return models.PullRequest{}, errors.Wrap(err, "making merge request API call to GitLab")
+		return models.PullRequest{}, err
 	}
 	pull := c.EventParser.ParseGitlabMergeRequest(mr, baseRepo)
 	return pull, nil
 }
 
+func (c *DefaultCommandRunner) getGitlabMergeRequest(baseRepo models.Repo, pullNum int) (*gitlab.MergeRequest, error) {
+	getter := c.GitlabMergeRequestGetter
+	if hostGetter, ok := c.HostGitlabMergeRequestGetters[baseRepo.VCSHost.Hostname]; ok {
+		getter = hostGetter
+	}
+	if getter == nil {
+		return nil, errors.New("Atlantis not configured to support GitLab")
+	}
+	mr, err := getter.GetMergeRequest(baseRepo.FullName, pullNum)
+	if err != nil {
+		return nil, errors.Wrap(err, "making merge request API call to GitLab")
+	}
+	return mr, nil
+}
+
+func (c *DefaultCommandRunner) getBitbucketData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	getter, parse := c.BitbucketCloudPullGetter, c.EventParser.ParseBitbucketCloudPull
+	if baseRepo.VCSHost.Type == models.BitbucketServer {
+		getter, parse = c.BitbucketServerPullGetter, c.EventParser.ParseBitbucketServerPull
+	}
+	if getter == nil {
+		return models.PullRequest{}, models.Repo{}, fmt.Errorf("Atlantis not configured to support %s", baseRepo.VCSHost.Type.String())
+	}
+	body, err := getter.GetPullRequest(baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, errors.Wrapf(err, "making pull request API call to %s", baseRepo.VCSHost.Type.String())
+	}
+	pull, _, headRepo, err := parse(body)
+	if err != nil {
+		return pull, headRepo, errors.Wrap(err, "extracting required fields from pull request data")
+	}
+	return pull, headRepo, nil
+}
+
 func (c *DefaultCommandRunner) getAzureDevopsData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.AzureDevopsPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support Azure DevOps")
@@ -344,6 +435,96 @@ func (c *DefaultCommandRunner) getAzureDevopsData(baseRepo models.Repo, pullNum
 	return pull, headRepo, nil
 }
 
+func (c *DefaultCommandRunner) getGiteaData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	getter := c.GiteaPullGetter
+	if hostGetter, ok := c.HostGiteaPullGetters[baseRepo.VCSHost.Hostname]; ok {
+		getter = hostGetter
+	}
+	if getter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support Gitea")
+	}
+	giteaPull, err := getter.GetPullRequest(baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to Gitea")
+	}
+	pull, _, headRepo, err := c.EventParser.ParseGiteaPull(giteaPull)
+	if err != nil {
+		return pull, headRepo, errors.Wrap(err, "extracting required fields from comment data")
+	}
+	return pull, headRepo, nil
+}
+
+// GetPullRequest looks up the pull request pullNum of baseRepo on its VCS
+// host.
+func (c *DefaultCommandRunner) GetPullRequest(baseRepo models.Repo, pullNum int) (pull models.PullRequest, headRepo models.Repo, err error) {
+	switch baseRepo.VCSHost.Type {
+	case models.Github:
+		pull, headRepo, err = c.getGithubData(baseRepo, pullNum)
+	case models.Gitlab:
+		var mr *gitlab.MergeRequest
+		if mr, err = c.getGitlabMergeRequest(baseRepo, pullNum); err != nil {
+			break
+		}
+		// Merge requests don't include the repo they're from, so only
+		// merge requests from the base repo itself are supported.
+		if mr.SourceProjectID != mr.TargetProjectID {
+			err = fmt.Errorf("merge request %d is from a fork which isn't supported", pullNum)
+			break
+		}
+		pull = c.EventParser.ParseGitlabMergeRequest(mr, baseRepo)
+		headRepo = baseRepo
+	case models.BitbucketCloud, models.BitbucketServer:
+		pull, headRepo, err = c.getBitbucketData(baseRepo, pullNum)
+	case models.AzureDevops:
+		pull, headRepo, err = c.getAzureDevopsData(baseRepo, pullNum)
+	case models.Gitea:
+		pull, headRepo, err = c.getGiteaData(baseRepo, pullNum)
+	default:
+		err = errors.New("Unknown VCS type–this is a bug")
+	}
+	return
+}
+
+// RunAPICommand runs cmd against pull for an API request.
+func (c *DefaultCommandRunner) RunAPICommand(headRepo models.Repo, pull models.PullRequest, user models.User, cmd *CommentCommand) ([]command.Result, error) {
+	if !c.Drainer.StartOp() {
+		return nil, ErrShuttingDown
+	}
+	defer c.Drainer.OpDone()
+
+	log := c.buildLogger(pull.BaseRepo.FullName, pull.Num)
+	defer c.logPanics(pull.BaseRepo, pull.Num, log)
+
+	scope := c.StatsScope.SubScope("api").SubScope(cmd.Name.String())
+	timer := scope.Timer(metrics.ExecutionTimeMetric).Start()
+	defer timer.Stop()
+
+	if err := c.checkVarFilesInPlanCommandAllowlisted(cmd); err != nil {
+		return nil, err
+	}
+
+	status, err := c.PullStatusFetcher.GetPullStatus(pull)
+	if err != nil {
+		log.Err("Unable to fetch pull status, this is likely a bug.", err)
+	}
+
+	var results []command.Result
+	ctx := &command.Context{
+		User:       user,
+		Log:        log,
+		Pull:       pull,
+		PullStatus: status,
+		HeadRepo:   headRepo,
+		Scope:      scope,
+		Trigger:    command.CommentTrigger,
+		APIResults: &results,
+	}
+	if err := c.runCommentCommand(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) logging.SimpleLogging {
 
 	return c.Logger.WithHistory(
@@ -377,6 +558,8 @@ func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 		pull = *maybePull
 	case models.AzureDevops:
 		pull, headRepo, err = c.getAzureDevopsData(baseRepo, pullNum)
+	case models.Gitea:
+		pull, headRepo, err = c.getGiteaData(baseRepo, pullNum)
 	default:
 		err = errors.New("Unknown VCS type–this is a bug")
 	}
@@ -391,33 +574,36 @@ func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 	return
 }
 
-func (c *DefaultCommandRunner) validateCtxAndComment(ctx *command.Context) bool {
+func (c *DefaultCommandRunner) validateCtxAndComment(ctx *command.Context) error {
 	if !c.AllowForkPRs && ctx.HeadRepo.Owner != ctx.Pull.BaseRepo.Owner {
+		err := errors.New("command was run on a fork pull request which is disallowed")
 		if c.SilenceForkPRErrors {
-			return false
+			return err
 		}
-		ctx.Log.Info("command was run on a fork pull request which is disallowed")
+		ctx.Log.Info(err.Error())
 		if err := c.VCSClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, fmt.Sprintf("Atlantis commands can't be run on fork pull requests. To enable, set --%s  or, to disable this message, set --%s", c.AllowForkPRsFlag, c.SilenceForkPRErrorsFlag), ""); err != nil {
 			ctx.Log.Err("unable to comment: %s", err)
 		}
-		return false
+		return err
 	}
 
 	if ctx.Pull.State != models.OpenPullState {
-		ctx.Log.Info("command was run on closed pull request")
+		err := errors.New("command was run on closed pull request")
+		ctx.Log.Info(err.Error())
 		if err := c.VCSClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, "Atlantis commands can't be run on closed pull requests", ""); err != nil {
 			ctx.Log.Err("unable to comment: %s", err)
 		}
-		return false
+		return err
 	}
 
 	repo := c.GlobalCfg.MatchingRepo(ctx.Pull.BaseRepo.ID())
 	if !repo.BranchMatches(ctx.Pull.BaseBranch) {
-		ctx.Log.Info("command was run on a pull request which doesn't match base branches")
+		err := errors.New("command was run on a pull request which doesn't match base branches")
+		ctx.Log.Info(err.Error())
 		// just ignore it to allow us to use any git workflows without malicious intentions.
-		return false
+		return err
 	}
-	return true
+	return nil
 }
 
 // logPanics logs and creates a comment on the pull request for panics.
@@ -172,3 +172,71 @@ func (m *MockCSU) UpdateCombined(repo models.Repo, pull models.PullRequest, stat
 func (m *MockCSU) UpdateProject(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string) error {
 	return nil
 }
+
+func TestPolicyCheckUpdateCommitStatus(t *testing.T) {
+	cases := map[string]struct {
+		pullStatus    models.PullStatus
+		softFailed    bool
+		expStatus     models.CommitStatus
+		expNumSuccess int
+		expNumTotal   int
+	}{
+		"policy check passed": {
+			pullStatus: models.PullStatus{
+				Projects: []models.ProjectStatus{
+					{
+						Status: models.PassedPolicyCheckStatus,
+					},
+				},
+			},
+			expStatus:     models.SuccessCommitStatus,
+			expNumSuccess: 1,
+			expNumTotal:   1,
+		},
+		"warn only policy set failed": {
+			pullStatus: models.PullStatus{
+				Projects: []models.ProjectStatus{
+					{
+						Status: models.PassedPolicyCheckStatus,
+					},
+				},
+			},
+			softFailed:    true,
+			expStatus:     models.NeutralCommitStatus,
+			expNumSuccess: 1,
+			expNumTotal:   1,
+		},
+		"policy check errored": {
+			pullStatus: models.PullStatus{
+				Projects: []models.ProjectStatus{
+					{
+						Status: models.PassedPolicyCheckStatus,
+					},
+					{
+						Status: models.ErroredPolicyCheckStatus,
+					},
+				},
+			},
+			softFailed:    true,
+			expStatus:     models.FailedCommitStatus,
+			expNumSuccess: 1,
+			expNumTotal:   2,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			csu := &MockCSU{}
+			cr := &PolicyCheckCommandRunner{
+				commitStatusUpdater: csu,
+			}
+			cr.updateCommitStatus(&command.Context{}, c.pullStatus, c.softFailed)
+			Equals(t, models.Repo{}, csu.CalledRepo)
+			Equals(t, models.PullRequest{}, csu.CalledPull)
+			Equals(t, c.expStatus, csu.CalledStatus)
+			Equals(t, command.PolicyCheck, csu.CalledCommand)
+			Equals(t, c.expNumSuccess, csu.CalledNumSuccess)
+			Equals(t, c.expNumTotal, csu.CalledNumTotal)
+		})
+	}
+}
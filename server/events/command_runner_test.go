@@ -38,6 +38,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
 	. "github.com/runatlantis/atlantis/testing"
+	gitlab "github.com/xanzy/go-gitlab"
 )
 
 var projectCommandBuilder *mocks.MockProjectCommandBuilder
@@ -133,6 +134,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 		parallelPoolSize,
 		SilenceNoProjects,
 		defaultBoltDB,
+		nil,
 	)
 
 	pullReqStatusFetcher := vcs.NewPullReqStatusFetcher(vcsClient)
@@ -235,6 +237,20 @@ func TestRunCommentCommand_GithubPullErr(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "`Error: making pull request API call to GitHub: err`", "")
 }
 
+func TestRunCommentCommand_HostGithubPullGetter(t *testing.T) {
+	t.Log("repos on other GitHub hosts should get the pull request from their host")
+	vcsClient := setup(t)
+	gheGetter := mocks.NewMockGithubPullGetter()
+	ch.HostGithubPullGetters = map[string]events.GithubPullGetter{"github.example.com": gheGetter}
+	gheRepo := fixtures.GithubRepo
+	gheRepo.VCSHost.Hostname = "github.example.com"
+	When(gheGetter.GetPullRequest(gheRepo, fixtures.Pull.Num)).ThenReturn(nil, errors.New("err"))
+
+	ch.RunCommentCommand(gheRepo, &gheRepo, nil, fixtures.User, fixtures.Pull.Num, nil)
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(matchers.AnyModelsRepo(), AnyInt())
+	vcsClient.VerifyWasCalledOnce().CreateComment(gheRepo, fixtures.Pull.Num, "`Error: making pull request API call to GitHub: err`", "")
+}
+
 func TestRunCommentCommand_GitlabMergeRequestErr(t *testing.T) {
 	t.Log("if getting the gitlab merge request fails an error should be logged")
 	vcsClient := setup(t)
@@ -507,7 +523,7 @@ func TestRunUnlockCommand_VCSComment(t *testing.T) {
 
 	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: command.Unlock})
 
-	deleteLockCommand.VerifyWasCalledOnce().DeleteLocksByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)
+	deleteLockCommand.VerifyWasCalledOnce().DeleteLocksByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "All Atlantis locks for this PR have been unlocked and plans discarded", "unlock")
 }
 
@@ -522,7 +538,7 @@ func TestRunUnlockCommandFail_VCSComment(t *testing.T) {
 	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
 	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
 	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
-	When(deleteLockCommand.DeleteLocksByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(0, errors.New("err"))
+	When(deleteLockCommand.DeleteLocksByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(0, errors.New("err"))
 
 	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: command.Unlock})
 
@@ -611,15 +627,19 @@ func TestFailedApprovalCreatesFailedStatusUpdate(t *testing.T) {
 	}, nil)
 
 	When(workingDir.GetPullDir(fixtures.GithubRepo, fixtures.Pull)).ThenReturn(tmp, nil)
+	When(projectCommandRunner.ApprovePolicies(matchers.AnyModelsProjectCommandContext())).ThenReturn(command.ProjectResult{
+		Command: command.PolicyCheck,
+		Failure: "contact policy owners to approve failing policies",
+	})
 
 	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: command.ApprovePolicies})
 	commitUpdater.VerifyWasCalledOnce().UpdateCombinedCount(
 		matchers.AnyModelsRepo(),
 		matchers.AnyModelsPullRequest(),
-		matchers.EqModelsCommitStatus(models.SuccessCommitStatus),
+		matchers.EqModelsCommitStatus(models.FailedCommitStatus),
 		matchers.EqModelsCommandName(command.PolicyCheck),
 		EqInt(0),
-		EqInt(0),
+		EqInt(2),
 	)
 }
 
@@ -811,6 +831,72 @@ func TestRunCommentCommand_DrainNotOngoing(t *testing.T) {
 	Equals(t, 0, drainer.GetStatus().InProgressOps)
 }
 
+func TestRunAPICommand_SkipsTeamAllowlist(t *testing.T) {
+	t.Log("API requests are authorized by their token so the team allowlist isn't checked")
+	vcsClient := setup(t)
+	checker, err := events.NewTeamAllowlistChecker("not-a-team:plan")
+	Ok(t, err)
+	ch.TeamAllowlistChecker = checker
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
+
+	results, err := ch.RunAPICommand(fixtures.GithubRepo, modelPull, fixtures.User, &events.CommentCommand{Name: command.Plan})
+	Ok(t, err)
+	Equals(t, 1, len(results))
+	Assert(t, !results[0].HasErrors(), "exp plan to succeed")
+	vcsClient.VerifyWasCalled(Never()).GetTeamNamesForUser(matchers.AnyModelsRepo(), matchers.AnyModelsUser())
+	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, "Ran Plan for 0 projects:\n\n\n\n", "plan")
+	// The pull request of the API request is used as is.
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(matchers.AnyModelsRepo(), AnyInt())
+}
+
+func TestRunAPICommand_ClosedPull(t *testing.T) {
+	t.Log("API requests on closed pull requests should return an error")
+	setup(t)
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.ClosedPullState, Num: fixtures.Pull.Num}
+	_, err := ch.RunAPICommand(fixtures.GithubRepo, modelPull, fixtures.User, &events.CommentCommand{Name: command.Plan})
+	ErrContains(t, "closed pull request", err)
+}
+
+func TestRunAPICommand_DrainOngoing(t *testing.T) {
+	t.Log("API requests should return an error if drain is ongoing")
+	vcsClient := setup(t)
+	drainer.ShutdownBlocking()
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
+	_, err := ch.RunAPICommand(fixtures.GithubRepo, modelPull, fixtures.User, &events.CommentCommand{Name: command.Plan})
+	Equals(t, events.ErrShuttingDown, err)
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestGetPullRequest_Bitbucket(t *testing.T) {
+	t.Log("Bitbucket pull requests should be fetched from the API and parsed")
+	setup(t)
+	bitbucketGetter := mocks.NewMockBitbucketPullGetter()
+	ch.BitbucketCloudPullGetter = bitbucketGetter
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.BitbucketCloud, Hostname: "bitbucket.org"}}
+	body := []byte(`{"id": 1}`)
+	modelPull := models.PullRequest{BaseRepo: repo, Num: 1, State: models.OpenPullState}
+	When(bitbucketGetter.GetPullRequest(repo, 1)).ThenReturn(body, nil)
+	When(eventParsing.ParseBitbucketCloudPull(body)).ThenReturn(modelPull, repo, repo, nil)
+
+	pull, headRepo, err := ch.GetPullRequest(repo, 1)
+	Ok(t, err)
+	Equals(t, modelPull, pull)
+	Equals(t, repo, headRepo)
+
+	// Bitbucket Server isn't configured.
+	repo.VCSHost.Type = models.BitbucketServer
+	_, _, err = ch.GetPullRequest(repo, 1)
+	ErrContains(t, "Atlantis not configured to support BitbucketServer", err)
+}
+
+func TestGetPullRequest_GitlabFork(t *testing.T) {
+	t.Log("GitLab merge requests from forks aren't supported since their repo is unknown")
+	setup(t)
+	When(gitlabGetter.GetMergeRequest(fixtures.GitlabRepo.FullName, 1)).ThenReturn(&gitlab.MergeRequest{SourceProjectID: 1, TargetProjectID: 2}, nil)
+	_, _, err := ch.GetPullRequest(fixtures.GitlabRepo, 1)
+	ErrContains(t, "merge request 1 is from a fork", err)
+}
+
 func TestRunAutoplanCommand_DrainOngoing(t *testing.T) {
 	t.Log("if drain is ongoing then a message should be displayed")
 	vcsClient := setup(t)
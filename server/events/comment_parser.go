@@ -41,6 +41,8 @@ const (
 	autoMergeDisabledFlagShort = ""
 	verboseFlagLong            = "verbose"
 	verboseFlagShort           = ""
+	policySetFlagLong          = "policy-set"
+	policySetFlagShort         = ""
 	atlantisExecutable         = "atlantis"
 )
 
@@ -78,7 +80,11 @@ type CommentParser struct {
 	GitlabUser      string
 	BitbucketUser   string
 	AzureDevopsUser string
-	ApplyDisabled   bool
+	GiteaUser       string
+	// VCSHostUsers are the users of VCS hosts other than the default host of
+	// their type, by type. Atlantis can be invoked using any of them.
+	VCSHostUsers  map[models.VCSHostType][]string
+	ApplyDisabled bool
 }
 
 // CommentParseResult describes the result of parsing a comment as a command.
@@ -144,8 +150,13 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		vcsUser = e.BitbucketUser
 	case models.AzureDevops:
 		vcsUser = e.AzureDevopsUser
+	case models.Gitea:
+		vcsUser = e.GiteaUser
 	}
 	executableNames := []string{"run", atlantisExecutable, "@" + vcsUser}
+	for _, user := range e.VCSHostUsers[vcsHost] {
+		executableNames = append(executableNames, "@"+user)
+	}
 	if !e.stringInSlice(args[0], executableNames) {
 		return CommentParseResult{Ignore: true}
 	}
@@ -180,6 +191,7 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	var workspace string
 	var dir string
 	var project string
+	var policySet string
 	var verbose, autoMergeDisabled bool
 	var flagSet *pflag.FlagSet
 	var name command.Name
@@ -207,6 +219,7 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		name = command.ApprovePolicies
 		flagSet = pflag.NewFlagSet(command.ApprovePolicies.String(), pflag.ContinueOnError)
 		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&policySet, policySetFlagLong, policySetFlagShort, "", "Approve only this failing policy set. Refers to the name of the policy set configured in the server-side repo config.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case command.Unlock.String():
 		name = command.Unlock
@@ -274,7 +287,7 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	}
 
 	return CommentParseResult{
-		Command: NewCommentCommand(dir, extraArgs, name, verbose, autoMergeDisabled, workspace, project),
+		Command: NewCommentCommand(dir, extraArgs, name, verbose, autoMergeDisabled, workspace, project, policySet),
 	}
 }
 
@@ -412,6 +425,7 @@ Commands:
            To unlock a specific plan you can use the Atlantis UI.
   approve_policies
            Approves all current policy checking failures for the PR.
+           To approve a specific policy set, use the --policy-set flag.
   version  Print the output of 'terraform version'
   help     View help.
 
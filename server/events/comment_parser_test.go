@@ -337,6 +337,17 @@ func TestParse_UsingProjectAtSameTimeAsWorkspaceOrDir(t *testing.T) {
 	}
 }
 
+func TestParse_ApprovePoliciesPolicySet(t *testing.T) {
+	r := commentParser.Parse("atlantis approve_policies --policy-set security", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, command.ApprovePolicies, r.Command.Name)
+	Equals(t, "security", r.Command.PolicySet)
+
+	r = commentParser.Parse("atlantis plan --policy-set security", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "Error: unknown flag: --policy-set"),
+		"exp --policy-set to be unsupported by plan, got %q", r.CommentResponse)
+}
+
 func TestParse_Parsing(t *testing.T) {
 	cases := []struct {
 		flags        string
@@ -736,6 +747,7 @@ Commands:
            To unlock a specific plan you can use the Atlantis UI.
   approve_policies
            Approves all current policy checking failures for the PR.
+           To approve a specific policy set, use the --policy-set flag.
   version  Print the output of 'terraform version'
   help     View help.
 
@@ -765,6 +777,7 @@ Commands:
            To unlock a specific plan you can use the Atlantis UI.
   approve_policies
            Approves all current policy checking failures for the PR.
+           To approve a specific policy set, use the --policy-set flag.
   version  Print the output of 'terraform version'
   help     View help.
 
@@ -789,6 +802,7 @@ func TestParse_VCSUsername(t *testing.T) {
 		GitlabUser:      "gl",
 		BitbucketUser:   "bb",
 		AzureDevopsUser: "ad",
+		GiteaUser:       "gt",
 	}
 	cases := []struct {
 		vcs  models.VCSHostType
@@ -814,6 +828,10 @@ func TestParse_VCSUsername(t *testing.T) {
 			vcs:  models.AzureDevops,
 			user: "ad",
 		},
+		{
+			vcs:  models.Gitea,
+			user: "gt",
+		},
 	}
 
 	for _, c := range cases {
@@ -824,6 +842,23 @@ func TestParse_VCSUsername(t *testing.T) {
 	}
 }
 
+// Atlantis should also be invoked by the users of other hosts of the same
+// type.
+func TestParse_VCSHostUsers(t *testing.T) {
+	cp := events.CommentParser{
+		GithubUser: "gh",
+		VCSHostUsers: map[models.VCSHostType][]string{
+			models.Github: {"ghe"},
+		},
+	}
+	r := cp.Parse("@ghe help", models.Github)
+	Equals(t, commentParser.HelpComment(false), r.CommentResponse)
+	r = cp.Parse("@gh help", models.Github)
+	Equals(t, commentParser.HelpComment(false), r.CommentResponse)
+	r = cp.Parse("@ghe help", models.Gitlab)
+	Assert(t, r.Ignore, "exp comment to be ignored")
+}
+
 var PlanUsage = `Usage of plan:
   -d, --dir string         Which directory to run plan in relative to root of repo,
                            ex. 'child/dir'.
@@ -846,7 +881,9 @@ var ApplyUsage = `Usage of apply:
 `
 
 var ApprovePolicyUsage = `Usage of approve_policies:
-      --verbose   Append Atlantis log to comment.
+      --policy-set string   Approve only this failing policy set. Refers to the name
+                            of the policy set configured in the server-side repo config.
+      --verbose             Append Atlantis log to comment.
 `
 var UnlockUsage = "`Usage of unlock:`\n\n ```cmake\n" +
 	`atlantis unlock
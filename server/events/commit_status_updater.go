@@ -46,7 +46,33 @@ type DefaultCommitStatusUpdater struct {
 }
 
 func (d *DefaultCommitStatusUpdater) UpdateCombined(repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name) error {
-	src := fmt.Sprintf("%s/%s", d.StatusName, cmdName.String())
+	return d.Client.UpdateStatus(repo, pull, status, statusSrc(d.StatusName, cmdName), statusDescription(cmdName, status), "")
+}
+
+func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name, numSuccess int, numTotal int) error {
+	return d.Client.UpdateStatus(repo, pull, status, statusSrc(d.StatusName, cmdName), countStatusDescription(cmdName, status, numSuccess, numTotal), "")
+}
+
+func (d *DefaultCommitStatusUpdater) UpdateProject(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string) error {
+	return d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, status, projectStatusSrc(d.StatusName, ctx, cmdName), statusDescription(cmdName, status), url)
+}
+
+// statusSrc is the name of the combined status of cmdName.
+func statusSrc(statusName string, cmdName command.Name) string {
+	return fmt.Sprintf("%s/%s", statusName, cmdName.String())
+}
+
+// projectStatusSrc is the name of the status of cmdName for the project
+// represented by ctx.
+func projectStatusSrc(statusName string, ctx command.ProjectContext, cmdName command.Name) string {
+	projectID := ctx.ProjectName
+	if projectID == "" {
+		projectID = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
+	}
+	return fmt.Sprintf("%s/%s: %s", statusName, cmdName.String(), projectID)
+}
+
+func statusDescription(cmdName command.Name, status models.CommitStatus) string {
 	var descripWords string
 	switch status {
 	case models.PendingCommitStatus:
@@ -55,13 +81,13 @@ func (d *DefaultCommitStatusUpdater) UpdateCombined(repo models.Repo, pull model
 		descripWords = "failed."
 	case models.SuccessCommitStatus:
 		descripWords = "succeeded."
+	case models.NeutralCommitStatus:
+		descripWords = "succeeded with warnings."
 	}
-	descrip := fmt.Sprintf("%s %s", strings.Title(cmdName.String()), descripWords)
-	return d.Client.UpdateStatus(repo, pull, status, src, descrip, "")
+	return fmt.Sprintf("%s %s", strings.Title(cmdName.String()), descripWords)
 }
 
-func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name, numSuccess int, numTotal int) error {
-	src := fmt.Sprintf("%s/%s", d.StatusName, cmdName.String())
+func countStatusDescription(cmdName command.Name, status models.CommitStatus, numSuccess int, numTotal int) string {
 	cmdVerb := "unknown"
 
 	switch cmdName {
@@ -73,25 +99,8 @@ func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(repo models.Repo, pull
 		cmdVerb = "applied"
 	}
 
-	return d.Client.UpdateStatus(repo, pull, status, src, fmt.Sprintf("%d/%d projects %s successfully.", numSuccess, numTotal, cmdVerb), "")
-}
-
-func (d *DefaultCommitStatusUpdater) UpdateProject(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string) error {
-	projectID := ctx.ProjectName
-	if projectID == "" {
-		projectID = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
-	}
-	src := fmt.Sprintf("%s/%s: %s", d.StatusName, cmdName.String(), projectID)
-	var descripWords string
-	switch status {
-	case models.PendingCommitStatus:
-		descripWords = "in progress..."
-	case models.FailedCommitStatus:
-		descripWords = "failed."
-	case models.SuccessCommitStatus:
-		descripWords = "succeeded."
+	if status == models.NeutralCommitStatus {
+		return fmt.Sprintf("%d/%d projects %s successfully, with warnings.", numSuccess, numTotal, cmdVerb)
 	}
-
-	descrip := fmt.Sprintf("%s %s", strings.Title(cmdName.String()), descripWords)
-	return d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, status, src, descrip, url)
+	return fmt.Sprintf("%d/%d projects %s successfully.", numSuccess, numTotal, cmdVerb)
 }
@@ -61,6 +61,11 @@ func TestUpdateCombined(t *testing.T) {
 			command:    command.Apply,
 			expDescrip: "Apply succeeded.",
 		},
+		{
+			status:     models.NeutralCommitStatus,
+			command:    command.PolicyCheck,
+			expDescrip: "Policy_check succeeded with warnings.",
+		},
 	}
 
 	for _, c := range cases {
@@ -127,6 +132,13 @@ func TestUpdateCombinedCount(t *testing.T) {
 			numTotal:   2,
 			expDescrip: "2/2 projects applied successfully.",
 		},
+		{
+			status:     models.NeutralCommitStatus,
+			command:    command.PolicyCheck,
+			numSuccess: 2,
+			numTotal:   2,
+			expDescrip: "2/2 projects policies checked successfully, with warnings.",
+		},
 	}
 
 	for _, c := range cases {
@@ -1,9 +1,9 @@
 package events
 
 import (
-	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -12,7 +12,7 @@ import (
 // DeleteLockCommand is the first step after a command request has been parsed.
 type DeleteLockCommand interface {
 	DeleteLock(id string) (*models.ProjectLock, error)
-	DeleteLocksByPull(repoFullName string, pullNum int) (int, error)
+	DeleteLocksByPull(repoID string, pullNum int) (int, error)
 }
 
 // DefaultDeleteLockCommand deletes a specific lock after a request from the LocksController.
@@ -21,7 +21,12 @@ type DefaultDeleteLockCommand struct {
 	Logger           logging.SimpleLogging
 	WorkingDir       WorkingDir
 	WorkingDirLocker WorkingDirLocker
-	DB               *db.BoltDB
+	DB               locking.Backend
+	// LockQueueProcessor, if set, hands the deleted locks to the pull requests
+	// queued for them.
+	LockQueueProcessor LockQueueProcessor
+	// Webhooks, if set, sends a webhook for each deleted lock.
+	Webhooks WebhooksSender
 }
 
 // DeleteLock handles deleting the lock at id
@@ -35,12 +40,17 @@ func (l *DefaultDeleteLockCommand) DeleteLock(id string) (*models.ProjectLock, e
 	}
 
 	l.deleteWorkingDir(*lock)
+	l.sendUnlockWebhook(*lock)
+	if l.LockQueueProcessor != nil {
+		l.LockQueueProcessor.ProcessReleasedLocks([]models.ProjectLock{*lock})
+	}
 	return lock, nil
 }
 
-// DeleteLocksByPull handles deleting all locks for the pull request
-func (l *DefaultDeleteLockCommand) DeleteLocksByPull(repoFullName string, pullNum int) (int, error) {
-	locks, err := l.Locker.UnlockByPull(repoFullName, pullNum)
+// DeleteLocksByPull handles deleting all locks for the pull request pullNum
+// in the repo with ID repoID.
+func (l *DefaultDeleteLockCommand) DeleteLocksByPull(repoID string, pullNum int) (int, error) {
+	locks, err := l.Locker.UnlockByPull(repoID, pullNum)
 	numLocks := len(locks)
 	if err != nil {
 		return numLocks, err
@@ -53,6 +63,10 @@ func (l *DefaultDeleteLockCommand) DeleteLocksByPull(repoFullName string, pullNu
 	for i := 0; i < numLocks; i++ {
 		lock := locks[i]
 		l.deleteWorkingDir(lock)
+		l.sendUnlockWebhook(lock)
+	}
+	if l.LockQueueProcessor != nil {
+		l.LockQueueProcessor.ProcessReleasedLocks(locks)
 	}
 
 	return numLocks, nil
@@ -66,7 +80,7 @@ func (l *DefaultDeleteLockCommand) deleteWorkingDir(lock models.ProjectLock) {
 		l.Logger.Debug("Not deleting the working dir.")
 		return
 	}
-	unlock, err := l.WorkingDirLocker.TryLock(lock.Pull.BaseRepo.FullName, lock.Pull.Num, lock.Workspace, lock.Project.Path)
+	unlock, err := l.WorkingDirLocker.TryLock(lock.Pull.BaseRepo.ID(), lock.Pull.Num, lock.Workspace, lock.Project.Path)
 	if err != nil {
 		l.Logger.Err("unable to obtain working dir lock when trying to delete old plans: %s", err)
 	} else {
@@ -80,3 +94,7 @@ func (l *DefaultDeleteLockCommand) deleteWorkingDir(lock models.ProjectLock) {
 		l.Logger.Err("unable to delete project status: %s", err)
 	}
 }
+
+func (l *DefaultDeleteLockCommand) sendUnlockWebhook(lock models.ProjectLock) {
+	sendWebhook(l.Webhooks, l.Logger, lockWebhookEvent(webhooks.UnlockEvent, lock.Pull, lock.User, lock.Workspace, lock.Project))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/db"
 	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -133,3 +134,22 @@ func TestDeleteLocksByPull_OldFormat(t *testing.T) {
 	_, err := dlc.DeleteLocksByPull(repoName, pullNum)
 	Ok(t, err)
 }
+
+func TestDeleteLocksByPull_ProcessesLockQueue(t *testing.T) {
+	t.Log("The deleted locks are handed to the pull requests queued for them")
+	repoName := "reponame"
+	pullNum := 2
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	processor := mocks.NewMockLockQueueProcessor()
+	locks := []models.ProjectLock{{Workspace: "default"}}
+	When(l.UnlockByPull(repoName, pullNum)).ThenReturn(locks, nil)
+	dlc := events.DefaultDeleteLockCommand{
+		Locker:             l,
+		Logger:             logging.NewNoopLogger(t),
+		LockQueueProcessor: processor,
+	}
+	_, err := dlc.DeleteLocksByPull(repoName, pullNum)
+	Ok(t, err)
+	processor.VerifyWasCalledOnce().ProcessReleasedLocks(locks)
+}
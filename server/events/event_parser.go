@@ -27,6 +27,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -103,6 +104,9 @@ type CommentCommand struct {
 	// project specified in an atlantis.yaml file.
 	// If empty then the comment specified no project.
 	ProjectName string
+	// PolicySet is the name of the policy set to approve with approve_policies.
+	// If empty then the comment specified no policy set.
+	PolicySet string
 }
 
 // IsForSpecificProject returns true if the command is for a specific dir, workspace
@@ -133,7 +137,7 @@ func (c CommentCommand) String() string {
 }
 
 // NewCommentCommand constructs a CommentCommand, setting all missing fields to defaults.
-func NewCommentCommand(repoRelDir string, flags []string, name command.Name, verbose, autoMergeDisabled bool, workspace string, project string) *CommentCommand {
+func NewCommentCommand(repoRelDir string, flags []string, name command.Name, verbose, autoMergeDisabled bool, workspace string, project string, policySet string) *CommentCommand {
 	// If repoRelDir was empty we want to keep it that way to indicate that it
 	// wasn't specified in the comment.
 	if repoRelDir != "" {
@@ -150,6 +154,7 @@ func NewCommentCommand(repoRelDir string, flags []string, name command.Name, ver
 		Workspace:         workspace,
 		AutoMergeDisabled: autoMergeDisabled,
 		ProjectName:       project,
+		PolicySet:         policySet,
 	}
 }
 
@@ -239,6 +244,14 @@ type EventParsing interface {
 		pull models.PullRequest, baseRepo models.Repo,
 		headRepo models.Repo, user models.User, comment string, err error)
 
+	// ParseBitbucketCloudPull parses the response from the Bitbucket Cloud
+	// API endpoint (not from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseBitbucketCloudPull(body []byte) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
 	// GetBitbucketCloudPullEventType returns the type of the pull request
 	// event given the Bitbucket Cloud header.
 	GetBitbucketCloudPullEventType(eventTypeHeader string) models.PullRequestEventType
@@ -264,6 +277,14 @@ type EventParsing interface {
 		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo,
 		user models.User, comment string, err error)
 
+	// ParseBitbucketServerPull parses the response from the Bitbucket Server
+	// API endpoint (not from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseBitbucketServerPull(body []byte) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
 	// GetBitbucketServerPullEventType returns the type of the pull request
 	// event given the Bitbucket Server header.
 	GetBitbucketServerPullEventType(eventTypeHeader string) models.PullRequestEventType
@@ -289,6 +310,31 @@ type EventParsing interface {
 	// ParseAzureDevopsRepo parses the response from the Azure DevOps API endpoint that
 	// returns a repo into the Atlantis model.
 	ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (models.Repo, error)
+
+	// ParseGiteaPullEvent parses Gitea pull request events.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the user that triggered the event.
+	ParseGiteaPullEvent(pullEvent *gitea.PullRequestEvent) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+	// baseRepo is the repo that the pull request will be merged into.
+	// user is the user that commented.
+	// pullNum is the number of the pull request that triggered the webhook.
+	ParseGiteaIssueCommentEvent(comment *gitea.IssueCommentEvent) (
+		baseRepo models.Repo, user models.User, pullNum int, err error)
+
+	// ParseGiteaPull parses the response from the Gitea API endpoint (not
+	// from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseGiteaPull(giteaPull *gitea.PullRequest) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
 }
 
 // EventParser parses VCS events.
@@ -303,14 +349,42 @@ type EventParser struct {
 	BitbucketServerURL string
 	AzureDevopsToken   string
 	AzureDevopsUser    string
+	GiteaUser          string
+	GiteaToken         string
+	// VCSHostCredentials are the credentials of VCS hosts other than the
+	// default host of their type, ex. a GitHub Enterprise host alongside
+	// github.com. Repos on other hosts use the credentials of their type above.
+	VCSHostCredentials map[models.VCSHost]VCSHostCredentials
+}
+
+// VCSHostCredentials are the credentials of the API user of a VCS host.
+type VCSHostCredentials struct {
+	User  string
+	Token string
+}
+
+// newRepo is like models.NewRepo but uses the credentials in
+// e.VCSHostCredentials instead of vcsUser and vcsToken if cloneURL is on one
+// of those hosts.
+func (e *EventParser) newRepo(vcsHostType models.VCSHostType, repoFullName string, cloneURL string, vcsUser string, vcsToken string) (models.Repo, error) {
+	if parsed, err := url.Parse(cloneURL); err == nil {
+		if creds, ok := e.VCSHostCredentials[models.VCSHost{Type: vcsHostType, Hostname: parsed.Hostname()}]; ok {
+			vcsUser, vcsToken = creds.User, creds.Token
+		}
+	}
+	return models.NewRepo(vcsHostType, repoFullName, cloneURL, vcsUser, vcsToken)
 }
 
 func (e *EventParser) ParseAPIPlanRequest(vcsHostType models.VCSHostType, repoFullName string, cloneURL string) (models.Repo, error) {
 	switch vcsHostType {
 	case models.Github:
-		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.GithubUser, e.GithubToken)
+		return e.newRepo(vcsHostType, repoFullName, cloneURL, e.GithubUser, e.GithubToken)
 	case models.Gitlab:
-		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.GitlabUser, e.GitlabToken)
+		return e.newRepo(vcsHostType, repoFullName, cloneURL, e.GitlabUser, e.GitlabToken)
+	case models.Gitea:
+		return e.newRepo(vcsHostType, repoFullName, cloneURL, e.GiteaUser, e.GiteaToken)
+	case models.BitbucketCloud:
+		return e.newRepo(vcsHostType, repoFullName, cloneURL, e.BitbucketUser, e.BitbucketToken)
 	}
 	return models.Repo{}, fmt.Errorf("not implemented")
 }
@@ -415,6 +489,33 @@ func (e *EventParser) ParseBitbucketCloudPullEvent(body []byte) (pull models.Pul
 	return
 }
 
+// ParseBitbucketCloudPull parses the response from the Bitbucket Cloud API
+// endpoint (not from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseBitbucketCloudPull(body []byte) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	var pr bitbucketcloud.PullRequest
+	if err = json.Unmarshal(body, &pr); err != nil {
+		err = errors.Wrap(err, "parsing json")
+		return
+	}
+	if err = validator.New().Struct(pr); err != nil {
+		err = errors.Wrapf(err, "API response %q was missing fields", string(body))
+		return
+	}
+	if pr.Author.AccountID == nil {
+		err = fmt.Errorf("API response %q was missing the author's account id", string(body))
+		return
+	}
+	// The pull request is parsed like the data of an event by its author on
+	// the repo it will be merged into.
+	pull, baseRepo, headRepo, _, err = e.parseCommonBitbucketCloudEventData(bitbucketcloud.CommonEventData{
+		Actor:       &bitbucketcloud.Actor{AccountID: pr.Author.AccountID},
+		Repository:  pr.Destination.Repository,
+		PullRequest: &pr,
+	})
+	return
+}
+
 // ParseGithubIssueCommentEvent parses GitHub pull request comment events.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGithubIssueCommentEvent(comment *github.IssueCommentEvent) (baseRepo models.Repo, user models.User, pullNum int, err error) {
@@ -553,7 +654,7 @@ func (e *EventParser) ParseGithubPull(pull *github.PullRequest) (pullModel model
 // returns a repo into the Atlantis model.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGithubRepo(ghRepo *github.Repository) (models.Repo, error) {
-	return models.NewRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), e.GithubUser, e.GithubToken)
+	return e.newRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), e.GithubUser, e.GithubToken)
 }
 
 // ParseGitlabMergeRequestUpdateEvent dives deeper into Gitlab merge request update events
@@ -597,11 +698,11 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 	// GitLab also has a "merged" state, but we map that to Closed so we don't
 	// need to check for it.
 
-	baseRepo, err = models.NewRepo(models.Gitlab, event.Project.PathWithNamespace, event.Project.GitHTTPURL, e.GitlabUser, e.GitlabToken)
+	baseRepo, err = e.newRepo(models.Gitlab, event.Project.PathWithNamespace, event.Project.GitHTTPURL, e.GitlabUser, e.GitlabToken)
 	if err != nil {
 		return
 	}
-	headRepo, err = models.NewRepo(models.Gitlab, event.ObjectAttributes.Source.PathWithNamespace, event.ObjectAttributes.Source.GitHTTPURL, e.GitlabUser, e.GitlabToken)
+	headRepo, err = e.newRepo(models.Gitlab, event.ObjectAttributes.Source.PathWithNamespace, event.ObjectAttributes.Source.GitHTTPURL, e.GitlabUser, e.GitlabToken)
 	if err != nil {
 		return
 	}
@@ -642,7 +743,7 @@ func (e *EventParser) ParseGitlabMergeRequestCommentEvent(event gitlab.MergeComm
 	// Parse the base repo first.
 	repoFullName := event.Project.PathWithNamespace
 	cloneURL := event.Project.GitHTTPURL
-	baseRepo, err = models.NewRepo(models.Gitlab, repoFullName, cloneURL, e.GitlabUser, e.GitlabToken)
+	baseRepo, err = e.newRepo(models.Gitlab, repoFullName, cloneURL, e.GitlabUser, e.GitlabToken)
 	if err != nil {
 		return
 	}
@@ -653,7 +754,7 @@ func (e *EventParser) ParseGitlabMergeRequestCommentEvent(event gitlab.MergeComm
 	// Now parse the head repo.
 	headRepoFullName := event.MergeRequest.Source.PathWithNamespace
 	headCloneURL := event.MergeRequest.Source.GitHTTPURL
-	headRepo, err = models.NewRepo(models.Gitlab, headRepoFullName, headCloneURL, e.GitlabUser, e.GitlabToken)
+	headRepo, err = e.newRepo(models.Gitlab, headRepoFullName, headCloneURL, e.GitlabUser, e.GitlabToken)
 	return
 }
 
@@ -786,6 +887,31 @@ func (e *EventParser) ParseBitbucketServerPullEvent(body []byte) (pull models.Pu
 	return
 }
 
+// ParseBitbucketServerPull parses the response from the Bitbucket Server API
+// endpoint (not from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseBitbucketServerPull(body []byte) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	var pr bitbucketserver.PullRequest
+	if err = json.Unmarshal(body, &pr); err != nil {
+		err = errors.Wrap(err, "parsing json")
+		return
+	}
+	if err = validator.New().Struct(pr); err != nil {
+		err = errors.Wrapf(err, "API response %q was missing fields", string(body))
+		return
+	}
+	if pr.Author == nil || pr.Author.User.Username == nil {
+		err = fmt.Errorf("API response %q was missing the author's name", string(body))
+		return
+	}
+	// The pull request is parsed like the data of an event by its author.
+	pull, baseRepo, headRepo, _, err = e.parseCommonBitbucketServerEventData(bitbucketserver.CommonEventData{
+		Actor:       pr.Author.User,
+		PullRequest: &pr,
+	})
+	return
+}
+
 // ParseAzureDevopsPullEvent parses Azure DevOps pull request events.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseAzureDevopsPullEvent(event azuredevops.Event) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
@@ -938,3 +1064,79 @@ func (e *EventParser) ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (m
 	fullName := fmt.Sprintf("%s/%s/%s", owner, project, repo)
 	return models.NewRepo(models.AzureDevops, fullName, cloneURL, e.AzureDevopsUser, e.AzureDevopsToken)
 }
+
+// ParseGiteaPullEvent parses Gitea pull request events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPullEvent(pullEvent *gitea.PullRequestEvent) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	pull, baseRepo, headRepo, err = e.ParseGiteaPull(pullEvent.PullRequest)
+	if err != nil {
+		return
+	}
+	switch *pullEvent.Action {
+	case "opened":
+		pullEventType = models.OpenedPullEvent
+	case "synchronized":
+		pullEventType = models.UpdatedPullEvent
+	case "closed":
+		pullEventType = models.ClosedPullEvent
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	user = models.User{Username: *pullEvent.Sender.Login}
+	return
+}
+
+// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaIssueCommentEvent(comment *gitea.IssueCommentEvent) (baseRepo models.Repo, user models.User, pullNum int, err error) {
+	if !comment.IsPull {
+		err = errors.New("comment is not on a pull request")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(comment.Repository)
+	if err != nil {
+		return
+	}
+	user = models.User{Username: *comment.Comment.User.Login}
+	pullNum = *comment.Issue.Number
+	return
+}
+
+// ParseGiteaPull parses the response from the Gitea API endpoint (not
+// from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPull(pull *gitea.PullRequest) (pullModel models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	if err = validator.New().Struct(pull); err != nil {
+		err = errors.Wrap(err, "pull request was missing fields")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(pull.Base.Repo)
+	if err != nil {
+		return
+	}
+	headRepo, err = e.parseGiteaRepo(pull.Head.Repo)
+	if err != nil {
+		return
+	}
+
+	pullState := models.ClosedPullState
+	if *pull.State == "open" {
+		pullState = models.OpenPullState
+	}
+
+	pullModel = models.PullRequest{
+		Author:     *pull.User.Login,
+		HeadBranch: *pull.Head.Ref,
+		HeadCommit: *pull.Head.SHA,
+		URL:        *pull.HTMLURL,
+		Num:        *pull.Number,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+		BaseBranch: *pull.Base.Ref,
+	}
+	return
+}
+
+func (e *EventParser) parseGiteaRepo(repo *gitea.Repository) (models.Repo, error) {
+	return e.newRepo(models.Gitea, *repo.FullName, *repo.CloneURL, e.GiteaUser, e.GiteaToken)
+}
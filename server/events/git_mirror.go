@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"golang.org/x/sync/semaphore"
+)
+
+// GitMirrorDirName is the name of the dir inside our data dir where the git
+// mirrors are stored.
+const GitMirrorDirName = "mirrors"
+
+// gitMirrorLastUsedFile is the file inside a mirror whose modification time is
+// the last time the mirror was used for a clone.
+const gitMirrorLastUsedFile = "atlantis-last-used"
+
+var mirrorLocks sync.Map
+
+// GitMirrorCache keeps a bare mirror of each repo that is cloned. The mirrors
+// are keyed by repo ID, see models.Repo.ID(), so that repos with the same name
+// on different VCS hosts have separate mirrors. They are fetched
+// incrementally and used as the --reference of the working dir clones so that
+// each clone only needs to fetch the objects that aren't already in the
+// mirror and doesn't store its own copy of them.
+type GitMirrorCache struct {
+	// Dir is the dir the mirrors are stored in.
+	Dir string
+	// WorkingDataDirs are the data dirs of the FileWorkspaces that use this
+	// cache. A mirror is only deleted if none of them have a working dir for
+	// its repo since those working dirs read their objects from the mirror.
+	WorkingDataDirs []string
+}
+
+// Update creates the mirror of repo if it doesn't exist and fetches all
+// branches of repo from cloneURL into it. It returns the path to the mirror.
+// cloneURL is never stored in the mirror since it may contain credentials.
+func (c *GitMirrorCache) Update(log logging.SimpleLogging, repo models.Repo, cloneURL string) (string, error) {
+	if repo.FullName == "" {
+		return "", errors.New("repo has no name")
+	}
+	mirrorDir := c.mirrorDir(repo.ID())
+
+	sem := c.lock(mirrorDir)
+	if err := sem.Acquire(context.TODO(), 1); err != nil {
+		return "", errors.Wrap(err, "waiting for mirror to be updated")
+	}
+	defer sem.Release(1)
+
+	var cmds [][]string
+	if _, err := os.Stat(filepath.Join(mirrorDir, "HEAD")); err != nil {
+		log.Info("creating git mirror %q", mirrorDir)
+		if err := os.MkdirAll(mirrorDir, 0700); err != nil {
+			return "", errors.Wrap(err, "creating mirror dir")
+		}
+		cmds = append(cmds,
+			[]string{"git", "init", "--bare", "-q"},
+			// The working dir clones reference the mirror's objects so the
+			// mirror must never prune them.
+			[]string{"git", "config", "gc.auto", "0"},
+			[]string{"git", "config", "maintenance.auto", "false"},
+		)
+	}
+	cmds = append(cmds, []string{"git", "fetch", "-q", "--prune", "--no-tags", cloneURL, "+refs/heads/*:refs/heads/*"})
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
+		cmd.Dir = mirrorDir
+		cmdStr := sanitizeURL(strings.Join(cmd.Args, " "), repo, cloneURL)
+		output, err := cmd.CombinedOutput()
+		sanitizedOutput := sanitizeURL(string(output), repo, cloneURL)
+		if err != nil {
+			sanitizedErrMsg := sanitizeURL(err.Error(), repo, cloneURL)
+			return "", fmt.Errorf("running %s: %s: %s", cmdStr, sanitizedOutput, sanitizedErrMsg)
+		}
+		log.Debug("ran: %s. Output: %s", cmdStr, strings.TrimSuffix(sanitizedOutput, "\n"))
+	}
+
+	if err := touch(filepath.Join(mirrorDir, gitMirrorLastUsedFile)); err != nil {
+		return "", errors.Wrap(err, "marking mirror as used")
+	}
+	return mirrorDir, nil
+}
+
+// DeleteUnused deletes the mirrors that haven't been used for maxAge and
+// whose repos have no working dirs. It returns the IDs of the repos whose
+// mirrors were deleted.
+func (c *GitMirrorCache) DeleteUnused(log logging.SimpleLogging, maxAge time.Duration, now time.Time) ([]string, error) {
+	repoIDs, err := c.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, repoID := range repoIDs {
+		mirrorDir := c.mirrorDir(repoID)
+		// A mirror that's being updated is in use.
+		sem := c.lock(mirrorDir)
+		if !sem.TryAcquire(1) {
+			continue
+		}
+		removed, err := c.deleteIfUnused(repoID, mirrorDir, maxAge, now)
+		sem.Release(1)
+		if err != nil {
+			log.Err("deleting git mirror %q: %s", mirrorDir, err)
+			continue
+		}
+		if removed {
+			log.Info("deleted git mirror %q that was unused for %s", mirrorDir, maxAge)
+			deleted = append(deleted, repoID)
+		}
+	}
+	return deleted, nil
+}
+
+func (c *GitMirrorCache) deleteIfUnused(repoID string, mirrorDir string, maxAge time.Duration, now time.Time) (bool, error) {
+	info, err := os.Stat(filepath.Join(mirrorDir, gitMirrorLastUsedFile))
+	if err == nil && now.Sub(info.ModTime()) < maxAge {
+		return false, nil
+	}
+	for _, dataDir := range c.WorkingDataDirs {
+		// Working dirs are stored under the repo ID too.
+		if _, err := os.Stat(filepath.Join(dataDir, workingDirPrefix, filepath.FromSlash(repoID))); err == nil {
+			return false, nil
+		}
+	}
+	return true, os.RemoveAll(mirrorDir)
+}
+
+// list returns the IDs of the repos that have a mirror.
+func (c *GitMirrorCache) list() ([]string, error) {
+	var repoIDs []string
+	err := filepath.Walk(c.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == c.Dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.IsDir() || !strings.HasSuffix(path, ".git") {
+			return nil
+		}
+		rel, err := filepath.Rel(c.Dir, strings.TrimSuffix(path, ".git"))
+		if err != nil {
+			return err
+		}
+		repoIDs = append(repoIDs, filepath.ToSlash(rel))
+		return filepath.SkipDir
+	})
+	return repoIDs, errors.Wrap(err, "listing git mirrors")
+}
+
+func (c *GitMirrorCache) mirrorDir(repoID string) string {
+	return filepath.Join(c.Dir, filepath.FromSlash(repoID)+".git")
+}
+
+func (c *GitMirrorCache) lock(mirrorDir string) *semaphore.Weighted {
+	value, _ := mirrorLocks.LoadOrStore(mirrorDir, semaphore.NewWeighted(1))
+	return value.(*semaphore.Weighted)
+}
+
+// sanitizeURL replaces cloneURL, which may contain credentials, in s with the
+// sanitized clone url of repo.
+func sanitizeURL(s string, repo models.Repo, cloneURL string) string {
+	if repo.SanitizedCloneURL == "" {
+		return s
+	}
+	return strings.Replace(s, cloneURL, repo.SanitizedCloneURL, -1)
+}
+
+func touch(path string) error {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
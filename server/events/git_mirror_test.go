@@ -0,0 +1,111 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGitMirrorCache_Update(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	expCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+	c := &events.GitMirrorCache{Dir: dataDir}
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}
+
+	mirrorDir, err := c.Update(logging.NewNoopLogger(t), repo, repoDir)
+	Ok(t, err)
+	Equals(t, filepath.Join(dataDir, "github.com", "owner", "repo.git"), mirrorDir)
+	Equals(t, expCommit, runCmd(t, mirrorDir, "git", "rev-parse", "refs/heads/branch"))
+	Equals(t, "0\n", runCmd(t, mirrorDir, "git", "config", "gc.auto"))
+
+	// The next update only fetches.
+	runCmd(t, repoDir, "git", "commit", "--allow-empty", "-m", "second commit")
+	expCommit = runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+	_, err = c.Update(logging.NewNoopLogger(t), repo, repoDir)
+	Ok(t, err)
+	Equals(t, expCommit, runCmd(t, mirrorDir, "git", "rev-parse", "refs/heads/master"))
+
+	// The clone url isn't stored in the mirror.
+	config, err := os.ReadFile(filepath.Join(mirrorDir, "config"))
+	Ok(t, err)
+	Assert(t, !strings.Contains(string(config), repoDir), "expected mirror config not to contain the clone url, got %q", string(config))
+}
+
+// Test that repos with the same name on different VCS hosts have separate
+// mirrors.
+func TestGitMirrorCache_UpdateDifferentVCSHosts(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+	c := &events.GitMirrorCache{Dir: dataDir}
+
+	githubDir, err := c.Update(logging.NewNoopLogger(t), models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}, repoDir)
+	Ok(t, err)
+	gheDir, err := c.Update(logging.NewNoopLogger(t), models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.example.com"}}, repoDir)
+	Ok(t, err)
+	Equals(t, filepath.Join(dataDir, "github.com", "owner", "repo.git"), githubDir)
+	Equals(t, filepath.Join(dataDir, "github.example.com", "owner", "repo.git"), gheDir)
+}
+
+func TestGitMirrorCache_UpdateNoRepoName(t *testing.T) {
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	c := &events.GitMirrorCache{Dir: dataDir}
+
+	_, err := c.Update(logging.NewNoopLogger(t), models.Repo{}, "file:///repo")
+	ErrEquals(t, "repo has no name", err)
+}
+
+func TestGitMirrorCache_DeleteUnused(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	c := &events.GitMirrorCache{
+		Dir:             filepath.Join(dataDir, events.GitMirrorDirName),
+		WorkingDataDirs: []string{dataDir},
+	}
+	log := logging.NewNoopLogger(t)
+	for _, name := range []string{"owner/unused", "owner/other", "group/subgroup/cloned"} {
+		_, err := c.Update(log, models.Repo{FullName: name, VCSHost: models.VCSHost{Hostname: "gitlab.com"}}, repoDir)
+		Ok(t, err)
+	}
+	// group/subgroup/cloned still has a working dir.
+	Ok(t, os.MkdirAll(filepath.Join(dataDir, "repos", "gitlab.com", "group", "subgroup", "cloned", "1", "default"), 0700))
+
+	// None of the mirrors have been unused for long enough.
+	deleted, err := c.DeleteUnused(log, time.Hour, time.Now().Add(30*time.Minute))
+	Ok(t, err)
+	Equals(t, 0, len(deleted))
+
+	deleted, err = c.DeleteUnused(log, time.Hour, time.Now().Add(2*time.Hour))
+	Ok(t, err)
+	Equals(t, []string{"gitlab.com/owner/other", "gitlab.com/owner/unused"}, deleted)
+	_, err = os.Stat(filepath.Join(c.Dir, "gitlab.com", "owner", "unused.git"))
+	Assert(t, os.IsNotExist(err), "expected mirror to be deleted")
+	_, err = os.Stat(filepath.Join(c.Dir, "gitlab.com", "group", "subgroup", "cloned.git"))
+	Ok(t, err)
+}
+
+func TestGitMirrorCache_DeleteUnusedNoMirrors(t *testing.T) {
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	c := &events.GitMirrorCache{Dir: filepath.Join(dataDir, events.GitMirrorDirName)}
+
+	deleted, err := c.DeleteUnused(logging.NewNoopLogger(t), time.Hour, time.Now())
+	Ok(t, err)
+	Equals(t, 0, len(deleted))
+}
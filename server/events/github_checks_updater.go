@@ -0,0 +1,291 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
+)
+
+// The identifiers of the actions on project check runs.
+const (
+	ApplyCheckRunAction  = "apply"
+	UnlockCheckRunAction = "unlock"
+)
+
+// checkRunTextOverhead is the number of chars reserved in check run outputs
+// for the code fences and the truncation note.
+const checkRunTextOverhead = 200
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_github_checks_client.go GithubChecksClient
+
+// GithubChecksClient creates and updates GitHub check runs.
+type GithubChecksClient interface {
+	UpdateCheckRun(repo models.Repo, pull models.PullRequest, checkRun vcs.GithubCheckRun) error
+}
+
+// CheckRunExternalID is the external ID of the project check runs created by
+// GithubChecksUpdater. It's sent back in the check_run webhook when users
+// request an action so the action can be run as a comment command.
+type CheckRunExternalID struct {
+	PullNum int `json:"pull"`
+	// ApplyCmd is the comment command that applies the project's plan.
+	ApplyCmd string `json:"apply_cmd,omitempty"`
+}
+
+// Comment returns the comment command that runs the action with identifier,
+// or an empty string if there's no such action.
+func (c CheckRunExternalID) Comment(identifier string) string {
+	switch identifier {
+	case ApplyCheckRunAction:
+		return c.ApplyCmd
+	case UnlockCheckRunAction:
+		return fmt.Sprintf("%s %s", atlantisExecutable, command.Unlock.String())
+	}
+	return ""
+}
+
+// GithubChecksUpdater is a CommitStatusUpdater that creates GitHub check runs
+// instead of commit statuses for the repos on Hostname. Project check runs
+// show the output of the command while it runs and its result once it's
+// done, and have actions to apply the plan and unlock the pull request.
+type GithubChecksUpdater struct {
+	// CommitStatusUpdater updates the statuses of repos that aren't on
+	// Hostname.
+	CommitStatusUpdater
+	Client GithubChecksClient
+	// Hostname is the GitHub host whose repos get check runs. Check runs can
+	// only be created by GitHub Apps so this is the host configured with
+	// flags.
+	Hostname string
+	// StatusName is the name used to identify Atlantis in check run names.
+	StatusName string
+	// ApplyDisabled is true if the apply action shouldn't be shown.
+	ApplyDisabled bool
+	// OutputUpdateInterval is the minimum time between updates of an
+	// in-progress project check run with the output of its command.
+	OutputUpdateInterval time.Duration
+
+	outputsLock sync.Mutex
+	// outputs are the outputs of the in-progress project commands by job ID.
+	outputs map[string]*checkRunOutput
+}
+
+// checkRunOutput is the output of an in-progress project command.
+type checkRunOutput struct {
+	sync.Mutex
+	repo       models.Repo
+	pull       models.PullRequest
+	name       string
+	title      string
+	lines      []string
+	size       int
+	lastUpdate time.Time
+	done       bool
+}
+
+func (g *GithubChecksUpdater) UpdateCombined(repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name) error {
+	if !g.usesChecks(repo) {
+		return g.CommitStatusUpdater.UpdateCombined(repo, pull, status, cmdName)
+	}
+	return g.Client.UpdateCheckRun(repo, pull, vcs.GithubCheckRun{
+		Name:   statusSrc(g.StatusName, cmdName),
+		Status: status,
+		Title:  statusDescription(cmdName, status),
+	})
+}
+
+func (g *GithubChecksUpdater) UpdateCombinedCount(repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name, numSuccess int, numTotal int) error {
+	if !g.usesChecks(repo) {
+		return g.CommitStatusUpdater.UpdateCombinedCount(repo, pull, status, cmdName, numSuccess, numTotal)
+	}
+	return g.Client.UpdateCheckRun(repo, pull, vcs.GithubCheckRun{
+		Name:   statusSrc(g.StatusName, cmdName),
+		Status: status,
+		Title:  countStatusDescription(cmdName, status, numSuccess, numTotal),
+	})
+}
+
+// UpdateProject updates the check run of the project represented by ctx. When
+// the status is pending, the output of the project's command is added to the
+// check run as it's sent to OutputHandler.
+func (g *GithubChecksUpdater) UpdateProject(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string) error {
+	if !g.usesChecks(ctx.BaseRepo) {
+		return g.CommitStatusUpdater.UpdateProject(ctx, cmdName, status, url)
+	}
+	name := projectStatusSrc(g.StatusName, ctx, cmdName)
+	title := statusDescription(cmdName, status)
+	if status == models.PendingCommitStatus {
+		g.startOutput(ctx, name, title)
+	} else {
+		g.endOutput(ctx.JobID)
+	}
+	return g.Client.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, vcs.GithubCheckRun{
+		Name:       name,
+		Status:     status,
+		Title:      title,
+		DetailsURL: url,
+	})
+}
+
+// UpdateProjectResult adds the result of the project command to the project's
+// check run.
+func (g *GithubChecksUpdater) UpdateProjectResult(ctx command.ProjectContext, cmdName command.Name, result command.ProjectResult) error {
+	if !g.usesChecks(ctx.BaseRepo) {
+		return nil
+	}
+	status := result.CommitStatus()
+	checkRun := vcs.GithubCheckRun{
+		Name:   projectStatusSrc(g.StatusName, ctx, cmdName),
+		Status: status,
+		Title:  statusDescription(cmdName, status),
+	}
+	switch {
+	case result.Error != nil:
+		checkRun.Summary = "**Error**"
+		checkRun.Text = checkRunCodeBlock("", result.Error.Error())
+	case result.Failure != "":
+		checkRun.Summary = "**Failed**: " + truncateCheckRunText(result.Failure)
+	case result.PlanSuccess != nil:
+		checkRun.Summary = result.PlanSuccess.Summary()
+		checkRun.Text = checkRunCodeBlock("diff", result.PlanSuccess.DiffMarkdownFormattedTerraformOutput())
+		externalID := CheckRunExternalID{PullNum: ctx.Pull.Num}
+		if !g.ApplyDisabled {
+			externalID.ApplyCmd = result.PlanSuccess.ApplyCmd
+			checkRun.Actions = append(checkRun.Actions, vcs.GithubCheckRunAction{
+				Label:       "Apply",
+				Description: "Apply this plan",
+				Identifier:  ApplyCheckRunAction,
+			})
+		}
+		checkRun.Actions = append(checkRun.Actions, vcs.GithubCheckRunAction{
+			Label:       "Unlock",
+			Description: "Discard all plans and unlock the pull",
+			Identifier:  UnlockCheckRunAction,
+		})
+		externalIDJSON, err := json.Marshal(externalID)
+		if err != nil {
+			return err
+		}
+		checkRun.ExternalID = string(externalIDJSON)
+	case result.ApplySuccess != "":
+		checkRun.Text = checkRunCodeBlock("diff", result.ApplySuccess)
+	}
+	return g.Client.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, checkRun)
+}
+
+// OutputHandler returns a jobs.ProjectCommandOutputHandler that sends the
+// output of project commands to handler and to their in-progress check runs.
+func (g *GithubChecksUpdater) OutputHandler(handler jobs.ProjectCommandOutputHandler) jobs.ProjectCommandOutputHandler {
+	return &checkRunOutputHandler{
+		ProjectCommandOutputHandler: handler,
+		updater:                     g,
+	}
+}
+
+func (g *GithubChecksUpdater) usesChecks(repo models.Repo) bool {
+	return repo.VCSHost.Type == models.Github && repo.VCSHost.Hostname == g.Hostname
+}
+
+func (g *GithubChecksUpdater) startOutput(ctx command.ProjectContext, name string, title string) {
+	g.outputsLock.Lock()
+	defer g.outputsLock.Unlock()
+	if g.outputs == nil {
+		g.outputs = make(map[string]*checkRunOutput)
+	}
+	g.outputs[ctx.JobID] = &checkRunOutput{
+		repo:       ctx.BaseRepo,
+		pull:       ctx.Pull,
+		name:       name,
+		title:      title,
+		lastUpdate: time.Now(),
+	}
+}
+
+// endOutput stops updating the check run of jobID with its output. It waits
+// for an update in progress so it can't overwrite the final status.
+func (g *GithubChecksUpdater) endOutput(jobID string) {
+	g.outputsLock.Lock()
+	output, ok := g.outputs[jobID]
+	delete(g.outputs, jobID)
+	g.outputsLock.Unlock()
+	if !ok {
+		return
+	}
+	output.Lock()
+	output.done = true
+	output.Unlock()
+}
+
+// addOutput adds line to the output of the in-progress command of ctx and
+// updates its check run if OutputUpdateInterval has passed since the last
+// update. Only the end of the output is kept if it's too long for a check
+// run.
+func (g *GithubChecksUpdater) addOutput(ctx command.ProjectContext, line string) {
+	g.outputsLock.Lock()
+	output, ok := g.outputs[ctx.JobID]
+	g.outputsLock.Unlock()
+	if !ok {
+		return
+	}
+
+	output.Lock()
+	defer output.Unlock()
+	if output.done {
+		return
+	}
+	output.lines = append(output.lines, line)
+	output.size += len(line) + 1
+	for output.size > vcs.MaxCheckRunOutputLength-checkRunTextOverhead && len(output.lines) > 1 {
+		output.size -= len(output.lines[0]) + 1
+		output.lines = output.lines[1:]
+	}
+	if time.Since(output.lastUpdate) < g.OutputUpdateInterval {
+		return
+	}
+	output.lastUpdate = time.Now()
+	err := g.Client.UpdateCheckRun(output.repo, output.pull, vcs.GithubCheckRun{
+		Name:   output.name,
+		Status: models.PendingCommitStatus,
+		Title:  output.title,
+		Text:   checkRunCodeBlock("", strings.Join(output.lines, "\n")),
+	})
+	if err != nil {
+		ctx.Log.Warn("unable to update check run %q with output: %s", output.name, err)
+	}
+}
+
+// checkRunOutputHandler adds the output of project commands to their
+// in-progress check runs.
+type checkRunOutputHandler struct {
+	jobs.ProjectCommandOutputHandler
+	updater *GithubChecksUpdater
+}
+
+func (c *checkRunOutputHandler) Send(ctx command.ProjectContext, msg string, operationComplete bool) {
+	c.ProjectCommandOutputHandler.Send(ctx, msg, operationComplete)
+	if operationComplete {
+		c.updater.endOutput(ctx.JobID)
+		return
+	}
+	c.updater.addOutput(ctx, msg)
+}
+
+func checkRunCodeBlock(language string, text string) string {
+	return fmt.Sprintf("```%s\n%s\n```", language, truncateCheckRunText(text))
+}
+
+// truncateCheckRunText truncates text so it fits in a check run's output.
+func truncateCheckRunText(text string) string {
+	maxLen := vcs.MaxCheckRunOutputLength - checkRunTextOverhead
+	if len(text) <= maxLen {
+		return text
+	}
+	return text[:maxLen] + "\n\n... truncated, see the pull request comments for the full output"
+}
@@ -0,0 +1,189 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	jobmocks "github.com/runatlantis/atlantis/server/jobs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var checksRepo = models.Repo{
+	FullName: "owner/repo",
+	VCSHost:  models.VCSHost{Type: models.Github, Hostname: "github.com"},
+}
+
+func checksProjectContext(t *testing.T) command.ProjectContext {
+	return command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		BaseRepo:    checksRepo,
+		Pull:        models.PullRequest{Num: 2, HeadCommit: "sha", BaseRepo: checksRepo},
+		ProjectName: "project",
+		JobID:       "job",
+	}
+}
+
+// Repos on other hosts should get commit statuses.
+func TestGithubChecksUpdater_OtherHost(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockGithubChecksClient()
+	statusUpdater := mocks.NewMockCommitStatusUpdater()
+	updater := &events.GithubChecksUpdater{
+		CommitStatusUpdater: statusUpdater,
+		Client:              client,
+		Hostname:            "github.com",
+		StatusName:          "atlantis",
+	}
+	gheRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Github, Hostname: "github.example.com"},
+	}
+
+	Ok(t, updater.UpdateCombined(gheRepo, models.PullRequest{}, models.SuccessCommitStatus, command.Plan))
+	Ok(t, updater.UpdateCombined(checksRepo, models.PullRequest{}, models.SuccessCommitStatus, command.Plan))
+
+	statusUpdater.VerifyWasCalledOnce().UpdateCombined(gheRepo, models.PullRequest{}, models.SuccessCommitStatus, command.Plan)
+	client.VerifyWasCalledOnce().UpdateCheckRun(checksRepo, models.PullRequest{}, vcs.GithubCheckRun{
+		Name:   "atlantis/plan",
+		Status: models.SuccessCommitStatus,
+		Title:  "Plan succeeded.",
+	})
+}
+
+func TestGithubChecksUpdater_UpdateProjectResult(t *testing.T) {
+	ctx := checksProjectContext(t)
+	planSuccess := &models.PlanSuccess{
+		TerraformOutput: "  + null_resource.test\n\nPlan: 1 to add, 0 to change, 0 to destroy.",
+		ApplyCmd:        "atlantis apply -p project",
+	}
+	cases := map[string]struct {
+		applyDisabled bool
+		result        command.ProjectResult
+		exp           vcs.GithubCheckRun
+	}{
+		"plan": {
+			false,
+			command.ProjectResult{PlanSuccess: planSuccess},
+			vcs.GithubCheckRun{
+				Name:       "atlantis/plan: project",
+				Status:     models.SuccessCommitStatus,
+				Title:      "Plan succeeded.",
+				Summary:    "Plan: 1 to add, 0 to change, 0 to destroy.",
+				Text:       "```diff\n  + null_resource.test\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n```",
+				ExternalID: `{"pull":2,"apply_cmd":"atlantis apply -p project"}`,
+				Actions: []vcs.GithubCheckRunAction{
+					{Label: "Apply", Description: "Apply this plan", Identifier: events.ApplyCheckRunAction},
+					{Label: "Unlock", Description: "Discard all plans and unlock the pull", Identifier: events.UnlockCheckRunAction},
+				},
+			},
+		},
+		"plan with apply disabled": {
+			true,
+			command.ProjectResult{PlanSuccess: planSuccess},
+			vcs.GithubCheckRun{
+				Name:       "atlantis/plan: project",
+				Status:     models.SuccessCommitStatus,
+				Title:      "Plan succeeded.",
+				Summary:    "Plan: 1 to add, 0 to change, 0 to destroy.",
+				Text:       "```diff\n  + null_resource.test\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n```",
+				ExternalID: `{"pull":2}`,
+				Actions: []vcs.GithubCheckRunAction{
+					{Label: "Unlock", Description: "Discard all plans and unlock the pull", Identifier: events.UnlockCheckRunAction},
+				},
+			},
+		},
+		"failure": {
+			false,
+			command.ProjectResult{Failure: "pull request must be approved"},
+			vcs.GithubCheckRun{
+				Name:    "atlantis/plan: project",
+				Status:  models.FailedCommitStatus,
+				Title:   "Plan failed.",
+				Summary: "**Failed**: pull request must be approved",
+			},
+		},
+		"error": {
+			false,
+			command.ProjectResult{Error: errors.New("exit status 1")},
+			vcs.GithubCheckRun{
+				Name:    "atlantis/plan: project",
+				Status:  models.FailedCommitStatus,
+				Title:   "Plan failed.",
+				Summary: "**Error**",
+				Text:    "```\nexit status 1\n```",
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			client := mocks.NewMockGithubChecksClient()
+			updater := &events.GithubChecksUpdater{
+				Client:        client,
+				Hostname:      "github.com",
+				StatusName:    "atlantis",
+				ApplyDisabled: c.applyDisabled,
+			}
+			Ok(t, updater.UpdateProjectResult(ctx, command.Plan, c.result))
+			client.VerifyWasCalledOnce().UpdateCheckRun(ctx.BaseRepo, ctx.Pull, c.exp)
+		})
+	}
+}
+
+// The output sent while the project's command runs should be added to its
+// check run until the command is done.
+func TestGithubChecksUpdater_OutputHandler(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := checksProjectContext(t)
+	client := mocks.NewMockGithubChecksClient()
+	updater := &events.GithubChecksUpdater{
+		Client:     client,
+		Hostname:   "github.com",
+		StatusName: "atlantis",
+	}
+	outputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	handler := updater.OutputHandler(outputHandler)
+
+	// Output of commands without an in-progress check run is ignored.
+	handler.Send(ctx, "ignored", false)
+
+	Ok(t, updater.UpdateProject(ctx, command.Plan, models.PendingCommitStatus, "https://atlantis.example.com/jobs/job"))
+	handler.Send(ctx, "line 1", false)
+	handler.Send(ctx, "line 2", false)
+	Ok(t, updater.UpdateProject(ctx, command.Plan, models.SuccessCommitStatus, "https://atlantis.example.com/jobs/job"))
+	handler.Send(ctx, "line 3", false)
+
+	outputHandler.VerifyWasCalledOnce().Send(ctx, "line 3", false)
+	inProgress := vcs.GithubCheckRun{
+		Name:   "atlantis/plan: project",
+		Status: models.PendingCommitStatus,
+		Title:  "Plan in progress...",
+	}
+	client.VerifyWasCalledOnce().UpdateCheckRun(ctx.BaseRepo, ctx.Pull, vcs.GithubCheckRun{
+		Name:       inProgress.Name,
+		Status:     inProgress.Status,
+		Title:      inProgress.Title,
+		DetailsURL: "https://atlantis.example.com/jobs/job",
+	})
+	inProgress.Text = "```\nline 1\n```"
+	client.VerifyWasCalledOnce().UpdateCheckRun(ctx.BaseRepo, ctx.Pull, inProgress)
+	inProgress.Text = "```\nline 1\nline 2\n```"
+	client.VerifyWasCalledOnce().UpdateCheckRun(ctx.BaseRepo, ctx.Pull, inProgress)
+	client.VerifyWasCalled(Times(4)).UpdateCheckRun(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyVcsGithubCheckRun())
+}
+
+func TestCheckRunExternalID_Comment(t *testing.T) {
+	externalID := events.CheckRunExternalID{PullNum: 2, ApplyCmd: "atlantis apply -p project"}
+	Equals(t, "atlantis apply -p project", externalID.Comment(events.ApplyCheckRunAction))
+	Equals(t, "atlantis unlock", externalID.Comment(events.UnlockCheckRunAction))
+	Equals(t, "", externalID.Comment("other"))
+}
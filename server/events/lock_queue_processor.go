@@ -0,0 +1,102 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_lock_queue_processor.go LockQueueProcessor
+
+// LockQueueProcessor hands released project locks to the pull requests that
+// are queued waiting for them.
+type LockQueueProcessor interface {
+	// ProcessReleasedLocks gives each of the released locks to the first pull
+	// request in its queue and re-plans that pull request.
+	ProcessReleasedLocks(locks []models.ProjectLock)
+	// RemovePull removes the pull request pullNum in the repo with ID repoID
+	// from every lock queue it is in.
+	RemovePull(repoID string, pullNum int) error
+}
+
+// DefaultLockQueueProcessor implements LockQueueProcessor.
+type DefaultLockQueueProcessor struct {
+	Locker locking.Locker
+	Queue  locking.LockQueue
+	// CommandRunner runs the plan for the pull request that acquired the lock.
+	CommandRunner CommandRunner
+	// PullGetter looks up the pull request that acquired the lock.
+	PullGetter APICommandRunner
+	Logger     logging.SimpleLogging
+	// Drainer makes shutdown wait for the plans of the pull requests that
+	// acquired locks.
+	Drainer *Drainer
+}
+
+// ProcessReleasedLocks implements LockQueueProcessor.ProcessReleasedLocks.
+func (p *DefaultLockQueueProcessor) ProcessReleasedLocks(locks []models.ProjectLock) {
+	for _, lock := range locks {
+		p.processReleasedLock(lock)
+	}
+}
+
+// RemovePull implements LockQueueProcessor.RemovePull.
+func (p *DefaultLockQueueProcessor) RemovePull(repoID string, pullNum int) error {
+	return p.Queue.DequeueByPull(repoID, pullNum)
+}
+
+func (p *DefaultLockQueueProcessor) processReleasedLock(released models.ProjectLock) {
+	queue, err := p.Queue.GetQueue(released.Project, released.Workspace)
+	if err != nil {
+		p.Logger.Err("getting lock queue for dir %q workspace %q: %s", released.Project.Path, released.Workspace, err)
+		return
+	}
+	if len(queue) == 0 {
+		return
+	}
+	next := queue[0]
+
+	// We start the operation before taking the lock so that if Atlantis is
+	// shutting down, the pull request keeps its place in the queue.
+	if !p.Drainer.StartOp() {
+		p.Logger.Info("not giving dir %q workspace %q to queued pull %d because Atlantis is shutting down", next.Project.Path, next.Workspace, next.Pull.Num)
+		return
+	}
+
+	// We acquire the lock on behalf of the next pull request before planning
+	// so that no other pull request can take it in the meantime. If another
+	// pull request has already taken it, the next pull request stays at the
+	// front of the queue until that lock is released.
+	lockAttempt, err := p.Locker.TryLock(next.Project, next.Workspace, next.Pull, next.User)
+	if err != nil {
+		p.Logger.Err("locking dir %q workspace %q for queued pull %d: %s", next.Project.Path, next.Workspace, next.Pull.Num, err)
+		p.Drainer.OpDone()
+		return
+	}
+	if !lockAttempt.LockAcquired && lockAttempt.CurrLock.Pull.Num != next.Pull.Num {
+		p.Logger.Info("dir %q workspace %q was locked by pull %d before queued pull %d could lock it", next.Project.Path, next.Workspace, lockAttempt.CurrLock.Pull.Num, next.Pull.Num)
+		p.Drainer.OpDone()
+		return
+	}
+	if err := p.Queue.Dequeue(next.Project, next.Workspace, next.Pull.Num); err != nil {
+		p.Logger.Err("removing pull %d from lock queue: %s", next.Pull.Num, err)
+	}
+
+	p.Logger.Info("lock %q given to queued pull %d, planning", lockAttempt.LockKey, next.Pull.Num)
+	cmd := NewCommentCommand(next.Project.Path, nil, command.Plan, false, false, next.Workspace, "", "")
+	// We plan asynchronously because locks are usually released while
+	// handling a request that shouldn't wait for the plan to finish.
+	go func() {
+		defer p.Drainer.OpDone()
+		// The queue stores the pull request as it was when it was queued so
+		// we look it up for its latest commit and the repo it's from, which
+		// some VCS hosts, ex. Bitbucket, need to be given.
+		pull, headRepo, err := p.PullGetter.GetPullRequest(next.Pull.BaseRepo, next.Pull.Num)
+		if err != nil {
+			p.Logger.Err("looking up queued pull %d: %s", next.Pull.Num, err)
+			return
+		}
+		p.CommandRunner.RunCommentCommand(pull.BaseRepo, &headRepo, &pull, next.User, pull.Num, cmd)
+	}()
+}
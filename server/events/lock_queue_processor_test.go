@@ -0,0 +1,143 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestLockQueueProcessor(t *testing.T) (*events.DefaultLockQueueProcessor, *lockmocks.MockLocker, *lockmocks.MockLockQueue, *mocks.MockCommandRunner, *mocks.MockAPICommandRunner) {
+	RegisterMockTestingT(t)
+	locker := lockmocks.NewMockLocker()
+	queue := lockmocks.NewMockLockQueue()
+	commandRunner := mocks.NewMockCommandRunner()
+	pullGetter := mocks.NewMockAPICommandRunner()
+	return &events.DefaultLockQueueProcessor{
+		Locker:        locker,
+		Queue:         queue,
+		CommandRunner: commandRunner,
+		PullGetter:    pullGetter,
+		Logger:        logging.NewNoopLogger(t),
+		Drainer:       &events.Drainer{},
+	}, locker, queue, commandRunner, pullGetter
+}
+
+func TestDefaultLockQueueProcessor_EmptyQueue(t *testing.T) {
+	p, locker, queue, commandRunner, _ := newTestLockQueueProcessor(t)
+	project := models.NewProject(fixtures.GithubRepo.FullName, "dir")
+	When(queue.GetQueue(project, "default")).ThenReturn(nil, nil)
+
+	p.ProcessReleasedLocks([]models.ProjectLock{{Project: project, Workspace: "default"}})
+
+	locker.VerifyWasCalled(Never()).TryLock(matchers.AnyModelsProject(), AnyString(), matchers.AnyModelsPullRequest(), matchers.AnyModelsUser())
+	commandRunner.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+func TestDefaultLockQueueProcessor_PlansNextPull(t *testing.T) {
+	p, locker, queue, commandRunner, pullGetter := newTestLockQueueProcessor(t)
+	project := models.NewProject(fixtures.GithubRepo.FullName, "dir")
+	pull := fixtures.Pull
+	pull.Num = 2
+	pull.BaseRepo = fixtures.GithubRepo
+	next := models.ProjectLock{
+		Project:   project,
+		Workspace: "default",
+		Pull:      pull,
+		User:      fixtures.User,
+	}
+	other := next
+	other.Pull.Num = 3
+	When(queue.GetQueue(project, "default")).ThenReturn([]models.ProjectLock{next, other}, nil)
+	When(locker.TryLock(project, "default", pull, fixtures.User)).ThenReturn(locking.TryLockResponse{
+		LockAcquired: true,
+		CurrLock:     next,
+		LockKey:      "key",
+	}, nil)
+
+	// The pull request has a new commit since it was queued.
+	latestPull := pull
+	latestPull.HeadCommit = "new-sha"
+	headRepo := fixtures.GithubRepo
+	headRepo.FullName = "fork/repo"
+	When(pullGetter.GetPullRequest(fixtures.GithubRepo, 2)).ThenReturn(latestPull, headRepo, nil)
+
+	p.ProcessReleasedLocks([]models.ProjectLock{{Project: project, Workspace: "default"}})
+
+	queue.VerifyWasCalledOnce().Dequeue(project, "default", 2)
+	_, maybeHeadRepo, maybePull, user, pullNum, cmd := commandRunner.VerifyWasCalledEventually(Once(), time.Second).RunCommentCommand(
+		matchers.EqModelsRepo(fixtures.GithubRepo),
+		matchers.AnyPtrToModelsRepo(),
+		matchers.AnyPtrToModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		EqInt(2),
+		matchers.AnyPtrToEventsCommentCommand(),
+	).GetCapturedArguments()
+	Equals(t, headRepo, *maybeHeadRepo)
+	Equals(t, latestPull, *maybePull)
+	Equals(t, fixtures.User, user)
+	Equals(t, 2, pullNum)
+	Equals(t, command.Plan, cmd.Name)
+	Equals(t, "dir", cmd.RepoRelDir)
+	Equals(t, "default", cmd.Workspace)
+}
+
+func TestDefaultLockQueueProcessor_LockTakenByOtherPull(t *testing.T) {
+	p, locker, queue, commandRunner, _ := newTestLockQueueProcessor(t)
+	project := models.NewProject(fixtures.GithubRepo.FullName, "dir")
+	pull := fixtures.Pull
+	pull.Num = 2
+	next := models.ProjectLock{
+		Project:   project,
+		Workspace: "default",
+		Pull:      pull,
+		User:      fixtures.User,
+	}
+	When(queue.GetQueue(project, "default")).ThenReturn([]models.ProjectLock{next}, nil)
+	When(locker.TryLock(project, "default", pull, fixtures.User)).ThenReturn(locking.TryLockResponse{
+		LockAcquired: false,
+		CurrLock:     models.ProjectLock{Pull: models.PullRequest{Num: 5}},
+	}, nil)
+
+	p.ProcessReleasedLocks([]models.ProjectLock{{Project: project, Workspace: "default"}})
+
+	// The pull request stays at the front of the queue.
+	queue.VerifyWasCalled(Never()).Dequeue(matchers.AnyModelsProject(), AnyString(), AnyInt())
+	commandRunner.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+func TestDefaultLockQueueProcessor_ShuttingDown(t *testing.T) {
+	p, locker, queue, commandRunner, _ := newTestLockQueueProcessor(t)
+	project := models.NewProject(fixtures.GithubRepo.FullName, "dir")
+	next := models.ProjectLock{
+		Project:   project,
+		Workspace: "default",
+		Pull:      fixtures.Pull,
+		User:      fixtures.User,
+	}
+	When(queue.GetQueue(project, "default")).ThenReturn([]models.ProjectLock{next}, nil)
+	p.Drainer.ShutdownBlocking()
+
+	p.ProcessReleasedLocks([]models.ProjectLock{{Project: project, Workspace: "default"}})
+
+	// The pull request keeps its place in the queue.
+	locker.VerifyWasCalled(Never()).TryLock(matchers.AnyModelsProject(), AnyString(), matchers.AnyModelsPullRequest(), matchers.AnyModelsUser())
+	queue.VerifyWasCalled(Never()).Dequeue(matchers.AnyModelsProject(), AnyString(), AnyInt())
+	commandRunner.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+func TestDefaultLockQueueProcessor_RemovePull(t *testing.T) {
+	p, _, queue, _, _ := newTestLockQueueProcessor(t)
+	Ok(t, p.RemovePull("github.com/owner/repo", 1))
+	queue.VerifyWasCalledOnce().DequeueByPull("github.com/owner/repo", 1)
+}
@@ -183,6 +183,9 @@ func (m *MarkdownRenderer) renderProjectResults(results []command.ProjectResult,
 		} else {
 			resultData.Rendered = "Found no template. This is a bug!"
 		}
+		if len(result.PolicySetResults) > 0 {
+			resultData.Rendered = m.renderTemplate(policySetResultsTmpl, result.PolicySetResults) + "\n" + resultData.Rendered
+		}
 		resultsTmplData = append(resultsTmplData, resultData)
 	}
 
@@ -334,6 +337,12 @@ var policyCheckSuccessWrappedTmpl = template.Must(template.New("").Parse(
 		"</details>" +
 		"{{ if .HasDiverged }}\n\n:warning: The branch we're merging into is ahead, it is recommended to pull new commits first.{{end}}"))
 
+// policySetResultsTmpl summarizes the results of each policy set as a table.
+var policySetResultsTmpl = template.Must(template.New("").Parse(
+	"| Policy Set | Status | Passed | Warnings | Failures |\n" +
+		"|------------|--------|--------|----------|----------|\n" +
+		"{{ range . }}| `{{.PolicySetName}}` | {{ if .Passed }}:white_check_mark: Passed{{ else if .WarnOnly }}:warning: Warning{{ else }}:x: Failed{{ end }} | {{.Passes}} | {{.Warnings}} | {{.Failures}} |\n{{ end }}"))
+
 // policyCheckNextSteps are instructions appended after successful plans as to what
 // to do next.
 var policyCheckNextSteps = "* :arrow_forward: To **apply** this plan, comment:\n" +
@@ -287,6 +287,146 @@ $$$
     * $atlantis apply$
 * :put_litter_in_its_place: To delete all plans and locks for the PR, comment:
     * $atlantis unlock$
+`,
+		},
+		{
+			"single policy check with policy set results",
+			command.PolicyCheck,
+			[]command.ProjectResult{
+				{
+					PolicyCheckSuccess: &models.PolicyCheckSuccess{
+						PolicyCheckOutput: "policy output",
+						LockURL:           "lock-url",
+						RePlanCmd:         "atlantis plan -d path -w workspace",
+						ApplyCmd:          "atlantis apply -d path -w workspace",
+					},
+					PolicySetResults: []models.PolicySetResult{
+						{
+							PolicySetName: "policies",
+							Rules: []models.PolicyRuleResult{
+								{Namespace: "main", Name: "deny"},
+								{Namespace: "main", Name: "warn", Messages: []string{"careful"}},
+							},
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Policy Check for dir: $path$ workspace: $workspace$
+
+| Policy Set | Status | Passed | Warnings | Failures |
+|------------|--------|--------|----------|----------|
+| $policies$ | :white_check_mark: Passed | 1 | 1 | 0 |
+
+$$$diff
+policy output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d path -w workspace$
+* :put_litter_in_its_place: To **delete** this plan click [here](lock-url)
+* :repeat: To re-run policies **plan** this project again by commenting:
+    * $atlantis plan -d path -w workspace$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this pull request, comment:
+    * $atlantis apply$
+* :put_litter_in_its_place: To delete all plans and locks for the PR, comment:
+    * $atlantis unlock$
+`,
+		},
+		{
+			"single policy check with failed warn only policy set",
+			command.PolicyCheck,
+			[]command.ProjectResult{
+				{
+					PolicyCheckSuccess: &models.PolicyCheckSuccess{
+						PolicyCheckOutput: "policy output",
+						LockURL:           "lock-url",
+						RePlanCmd:         "atlantis plan -d path -w workspace",
+						ApplyCmd:          "atlantis apply -d path -w workspace",
+						SoftFailed:        true,
+					},
+					PolicySetResults: []models.PolicySetResult{
+						{
+							PolicySetName: "policies",
+							Rules: []models.PolicyRuleResult{
+								{Namespace: "main", Name: "deny", Messages: []string{"no"}},
+							},
+							WarnOnly: true,
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Policy Check for dir: $path$ workspace: $workspace$
+
+| Policy Set | Status | Passed | Warnings | Failures |
+|------------|--------|--------|----------|----------|
+| $policies$ | :warning: Warning | 0 | 0 | 1 |
+
+$$$diff
+policy output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d path -w workspace$
+* :put_litter_in_its_place: To **delete** this plan click [here](lock-url)
+* :repeat: To re-run policies **plan** this project again by commenting:
+    * $atlantis plan -d path -w workspace$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this pull request, comment:
+    * $atlantis apply$
+* :put_litter_in_its_place: To delete all plans and locks for the PR, comment:
+    * $atlantis unlock$
+`,
+		},
+		{
+			"single failed policy check with policy set results",
+			command.PolicyCheck,
+			[]command.ProjectResult{
+				{
+					Error: errors.New("1 policy check failures"),
+					PolicySetResults: []models.PolicySetResult{
+						{
+							PolicySetName: "policies",
+							Rules: []models.PolicyRuleResult{
+								{Namespace: "main", Name: "deny", Messages: []string{"no"}},
+							},
+						},
+						{
+							PolicySetName: "other",
+							Rules: []models.PolicyRuleResult{
+								{Namespace: "main", Name: "deny"},
+							},
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Policy Check for dir: $path$ workspace: $workspace$
+
+| Policy Set | Status | Passed | Warnings | Failures |
+|------------|--------|--------|----------|----------|
+| $policies$ | :x: Failed | 0 | 0 | 1 |
+| $other$ | :white_check_mark: Passed | 1 | 0 | 0 |
+
+**Policy Check Error**
+$$$
+1 policy check failures
+$$$
+* :heavy_check_mark: To **approve** failing policies an authorized approver can comment:
+    * $atlantis approve_policies$
+* :repeat: Or, address the policy failure by modifying the codebase and re-planning.
+
+
 `,
 		},
 		{
@@ -60,6 +60,21 @@ func (mock *MockPendingPlanFinder) DeletePlans(pullDir string) error {
 	return ret0
 }
 
+func (mock *MockPendingPlanFinder) DeleteProjectPlans(pullDir string, workspace string, repoRelDir string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPendingPlanFinder().")
+	}
+	params := []pegomock.Param{pullDir, workspace, repoRelDir}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("DeleteProjectPlans", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockPendingPlanFinder) VerifyWasCalledOnce() *VerifierMockPendingPlanFinder {
 	return &VerifierMockPendingPlanFinder{
 		mock:                   mock,
@@ -150,3 +165,38 @@ func (c *MockPendingPlanFinder_DeletePlans_OngoingVerification) GetAllCapturedAr
 	}
 	return
 }
+
+func (verifier *VerifierMockPendingPlanFinder) DeleteProjectPlans(pullDir string, workspace string, repoRelDir string) *MockPendingPlanFinder_DeleteProjectPlans_OngoingVerification {
+	params := []pegomock.Param{pullDir, workspace, repoRelDir}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteProjectPlans", params, verifier.timeout)
+	return &MockPendingPlanFinder_DeleteProjectPlans_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPendingPlanFinder_DeleteProjectPlans_OngoingVerification struct {
+	mock              *MockPendingPlanFinder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPendingPlanFinder_DeleteProjectPlans_OngoingVerification) GetCapturedArguments() (string, string, string) {
+	pullDir, workspace, repoRelDir := c.GetAllCapturedArguments()
+	return pullDir[len(pullDir)-1], workspace[len(workspace)-1], repoRelDir[len(repoRelDir)-1]
+}
+
+func (c *MockPendingPlanFinder_DeleteProjectPlans_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
@@ -106,6 +106,21 @@ func (mock *MockWorkingDir) Delete(r models.Repo, p models.PullRequest) error {
 	return ret0
 }
 
+func (mock *MockWorkingDir) ExpandCheckout(log logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string, dirs []string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
+	}
+	params := []pegomock.Param{log, r, p, workspace, dirs}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ExpandCheckout", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockWorkingDir) DeleteForWorkspace(r models.Repo, p models.PullRequest, workspace string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
@@ -344,6 +359,49 @@ func (c *MockWorkingDir_Delete_OngoingVerification) GetAllCapturedArguments() (_
 	return
 }
 
+func (verifier *VerifierMockWorkingDir) ExpandCheckout(log logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string, dirs []string) *MockWorkingDir_ExpandCheckout_OngoingVerification {
+	params := []pegomock.Param{log, r, p, workspace, dirs}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ExpandCheckout", params, verifier.timeout)
+	return &MockWorkingDir_ExpandCheckout_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDir_ExpandCheckout_OngoingVerification struct {
+	mock              *MockWorkingDir
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDir_ExpandCheckout_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.Repo, models.PullRequest, string, []string) {
+	log, r, p, workspace, dirs := c.GetAllCapturedArguments()
+	return log[len(log)-1], r[len(r)-1], p[len(p)-1], workspace[len(workspace)-1], dirs[len(dirs)-1]
+}
+
+func (c *MockWorkingDir_ExpandCheckout_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.Repo, _param2 []models.PullRequest, _param3 []string, _param4 [][]string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(logging.SimpleLogging)
+		}
+		_param1 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.Repo)
+		}
+		_param2 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.PullRequest)
+		}
+		_param3 = make([]string, len(c.methodInvocations))
+		for u, param := range params[3] {
+			_param3[u] = param.(string)
+		}
+		_param4 = make([][]string, len(c.methodInvocations))
+		for u, param := range params[4] {
+			_param4[u] = param.([]string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockWorkingDir) DeleteForWorkspace(r models.Repo, p models.PullRequest, workspace string) *MockWorkingDir_DeleteForWorkspace_OngoingVerification {
 	params := []pegomock.Param{r, p, workspace}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteForWorkspace", params, verifier.timeout)
@@ -16,13 +16,17 @@ package models
 // CommitStatus is the result of executing an Atlantis command for the commit.
 // In Github the options are: error, failure, pending, success.
 // In Gitlab the options are: failed, canceled, pending, running, success.
-// We only support Failed, Pending, Success.
+// We only support Failed, Pending, Success and Neutral.
 type CommitStatus int
 
 const (
 	PendingCommitStatus CommitStatus = iota
 	SuccessCommitStatus
 	FailedCommitStatus
+	// NeutralCommitStatus means the command succeeded with failures that
+	// don't block the pull request, ex. of warn-only policy sets. VCS hosts
+	// without a neutral status report it as a success.
+	NeutralCommitStatus
 )
 
 func (s CommitStatus) String() string {
@@ -33,6 +37,8 @@ func (s CommitStatus) String() string {
 		return "success"
 	case FailedCommitStatus:
 		return "failed"
+	case NeutralCommitStatus:
+		return "neutral"
 	}
 	return "failed"
 }
@@ -12,6 +12,7 @@ func TestStatus_String(t *testing.T) {
 		models.PendingCommitStatus: "pending",
 		models.SuccessCommitStatus: "success",
 		models.FailedCommitStatus:  "failed",
+		models.NeutralCommitStatus: "neutral",
 	}
 	for k, v := range cases {
 		Equals(t, v, k.String())
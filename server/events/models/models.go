@@ -240,6 +240,10 @@ type ProjectLock struct {
 // Terraform projects in a single repo we also include Path to the project
 // root relative to the repo root.
 type Project struct {
+	// VCSHostname is the hostname of the VCS host of the repo, ex. "github.com".
+	// It's part of the project's lock key so that repos with the same name on
+	// different VCS hosts don't share locks.
+	VCSHostname string
 	// RepoFullName is the owner and repo name, ex. "runatlantis/atlantis"
 	RepoFullName string
 	// Path to project root in the repo.
@@ -255,6 +259,11 @@ func (p Project) String() string {
 	return fmt.Sprintf("repofullname=%s path=%s", p.RepoFullName, p.Path)
 }
 
+// RepoID returns the ID of the project's repo in the same form as Repo.ID().
+func (p Project) RepoID() string {
+	return fmt.Sprintf("%s/%s", p.VCSHostname, p.RepoFullName)
+}
+
 // Plan is the result of running an Atlantis plan command.
 // This model is used to represent a plan on disk.
 type Plan struct {
@@ -278,6 +287,13 @@ func NewProject(repoFullName string, path string) Project {
 	}
 }
 
+// NewRepoProject constructs a Project for the project at path in repo.
+func NewRepoProject(repo Repo, path string) Project {
+	p := NewProject(repo.FullName, path)
+	p.VCSHostname = repo.VCSHost.Hostname
+	return p
+}
+
 // VCSHost is a Git hosting provider, for example GitHub.
 type VCSHost struct {
 	// Hostname is the hostname of the VCS provider, ex. "github.com" or
@@ -296,6 +312,7 @@ const (
 	BitbucketCloud
 	BitbucketServer
 	AzureDevops
+	Gitea
 )
 
 func (h VCSHostType) String() string {
@@ -310,6 +327,8 @@ func (h VCSHostType) String() string {
 		return "BitbucketServer"
 	case AzureDevops:
 		return "AzureDevops"
+	case Gitea:
+		return "Gitea"
 	}
 	return "<missing String() implementation>"
 }
@@ -326,6 +345,8 @@ func NewVCSHostType(t string) (VCSHostType, error) {
 		return BitbucketServer, nil
 	case "AzureDevops":
 		return AzureDevops, nil
+	case "Gitea":
+		return Gitea, nil
 	}
 
 	return -1, fmt.Errorf("%q is not a valid type", t)
@@ -405,6 +426,147 @@ type PolicyCheckSuccess struct {
 	// branch we're merging into has been updated since we cloned and merged
 	// it.
 	HasDiverged bool
+	// SoftFailed is true if policy sets with the warn severity failed. Their
+	// failures don't fail the policy check.
+	SoftFailed bool
+}
+
+// PolicyCheckResults are the structured results of a policy check that policy
+// engines write to the project's policy check results file.
+type PolicyCheckResults struct {
+	// PolicySetResults are the results of each policy set. Policy sets
+	// whose results couldn't be parsed, ex. because conftest failed to run,
+	// are missing.
+	PolicySetResults []PolicySetResult
+	// SoftFailed is true if policy sets with the warn severity failed.
+	SoftFailed bool
+}
+
+// PolicyRuleStatus is the status of a single policy rule after it was run
+// against a plan.
+type PolicyRuleStatus string
+
+const (
+	PassedPolicyRuleStatus  PolicyRuleStatus = "pass"
+	WarningPolicyRuleStatus PolicyRuleStatus = "warn"
+	FailedPolicyRuleStatus  PolicyRuleStatus = "fail"
+)
+
+// PolicyRuleResult is the result of running a single policy rule against a
+// plan.
+type PolicyRuleResult struct {
+	// Namespace is the package of the rule, ex. main.
+	Namespace string
+	// Name is the name of the rule, ex. deny_public_buckets.
+	Name string
+	// Messages are the messages returned by the rule. A rule with no messages
+	// passed.
+	Messages []string
+}
+
+// Status returns the status of the rule. Rules whose name starts with warn
+// only warn, all others fail.
+func (r PolicyRuleResult) Status() PolicyRuleStatus {
+	switch {
+	case len(r.Messages) == 0:
+		return PassedPolicyRuleStatus
+	case strings.HasPrefix(r.Name, "warn"):
+		return WarningPolicyRuleStatus
+	default:
+		return FailedPolicyRuleStatus
+	}
+}
+
+// PolicySetResult is the result of running the rules of a policy set against
+// a plan.
+type PolicySetResult struct {
+	PolicySetName string
+	Rules         []PolicyRuleResult
+	// WarnOnly is true if the policy set's failures don't fail the policy
+	// check.
+	WarnOnly bool
+}
+
+// Passes returns the number of rules that passed.
+func (p PolicySetResult) Passes() int {
+	passes := 0
+	for _, r := range p.Rules {
+		if r.Status() == PassedPolicyRuleStatus {
+			passes++
+		}
+	}
+	return passes
+}
+
+// Warnings returns the number of warnings, counting each message separately.
+func (p PolicySetResult) Warnings() int {
+	return p.countMessages(WarningPolicyRuleStatus)
+}
+
+// Failures returns the number of failures, counting each message separately.
+func (p PolicySetResult) Failures() int {
+	return p.countMessages(FailedPolicyRuleStatus)
+}
+
+// Passed returns true if none of the rules failed.
+func (p PolicySetResult) Passed() bool {
+	return p.Failures() == 0
+}
+
+// Status returns the summary of the result that's stored in ProjectStatus.
+func (p PolicySetResult) Status() PolicySetStatus {
+	return PolicySetStatus{
+		PolicySetName: p.PolicySetName,
+		Passes:        p.Passes(),
+		Warnings:      p.Warnings(),
+		Failures:      p.Failures(),
+		WarnOnly:      p.WarnOnly,
+	}
+}
+
+func (p PolicySetResult) countMessages(status PolicyRuleStatus) int {
+	count := 0
+	for _, r := range p.Rules {
+		if r.Status() == status {
+			count += len(r.Messages)
+		}
+	}
+	return count
+}
+
+// PolicySetStatus is the summary of the last policy check of a policy set for
+// a project.
+type PolicySetStatus struct {
+	PolicySetName string
+	Passes        int
+	Warnings      int
+	Failures      int
+	// WarnOnly is true if the policy set's failures don't fail the policy
+	// check.
+	WarnOnly bool
+}
+
+// Passed returns true if none of the rules of the policy set failed.
+func (p PolicySetStatus) Passed() bool {
+	return p.Failures == 0
+}
+
+// PolicySetApproval records the owners that approved a failing policy set of
+// a project with approve_policies.
+type PolicySetApproval struct {
+	PolicySetName string
+	// Approvers are the usernames of the owners that approved the policy set.
+	Approvers []string
+}
+
+// HasApprover returns true if username already approved the policy set.
+func (p PolicySetApproval) HasApprover(username string) bool {
+	for _, approver := range p.Approvers {
+		if strings.EqualFold(approver, username) {
+			return true
+		}
+	}
+	return false
 }
 
 type VersionSuccess struct {
@@ -437,6 +599,16 @@ type ProjectStatus struct {
 	ProjectName string
 	// Status is the status of where this project is at in the planning cycle.
 	Status ProjectPlanStatus
+	// PlanSummary is the one line summary of the project's last successful
+	// plan, ex. "Plan: 1 to add, 0 to change, 0 to destroy.".
+	PlanSummary string
+	// PolicyStatus is the status of each policy set in the project's last
+	// policy check. It's only set by policy engines that report structured
+	// results.
+	PolicyStatus []PolicySetStatus
+	// PolicyApprovals are the approvals of the project's failing policy sets
+	// since its last plan.
+	PolicyApprovals []PolicySetApproval
 }
 
 // ProjectPlanStatus is the status of where this project is at in the planning
@@ -489,6 +661,60 @@ func (p ProjectPlanStatus) String() string {
 	}
 }
 
+// DriftStatus is the result of checking a project on the default branch of
+// its repo for drift.
+type DriftStatus int
+
+const (
+	// NoDriftStatus means that planning the project had no changes.
+	NoDriftStatus DriftStatus = iota
+	// DriftedStatus means that planning the project had changes, so the
+	// infrastructure no longer matches the default branch.
+	DriftedStatus
+	// ErroredDriftStatus means that the project couldn't be planned.
+	ErroredDriftStatus
+)
+
+// String returns a string representation of the status.
+func (d DriftStatus) String() string {
+	switch d {
+	case NoDriftStatus:
+		return "no_drift"
+	case DriftedStatus:
+		return "drifted"
+	case ErroredDriftStatus:
+		return "errored"
+	default:
+		panic("missing String() impl for DriftStatus")
+	}
+}
+
+// ProjectDrift is the result of the last drift detection run for a project.
+type ProjectDrift struct {
+	// RepoID is the ID of the repo the project is in, ex.
+	// "github.com/runatlantis/atlantis".
+	RepoID       string
+	RepoFullName string
+	ProjectName  string
+	RepoRelDir   string
+	Workspace    string
+	// Branch is the default branch of the repo that was planned.
+	Branch string
+	// Commit is the commit of Branch that was planned.
+	Commit string
+	Status DriftStatus
+	// PlanSummary is the one line summary of the plan, ex. "Plan: 1 to add,
+	// 0 to change, 0 to destroy.". It's only set if the project drifted.
+	PlanSummary string
+	// Error is why the project couldn't be planned.
+	Error string
+	// CheckedAt is when the project was planned.
+	CheckedAt time.Time
+	// IssueURL is the URL of the issue that was opened when the project
+	// drifted. It's kept until the project no longer drifts.
+	IssueURL string
+}
+
 // WorkflowHookCommandContext defines the context for a pre and post worklfow_hooks that will
 // be executed before workflows.
 type WorkflowHookCommandContext struct {
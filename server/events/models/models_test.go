@@ -218,6 +218,10 @@ func TestVCSHostType_ToString(t *testing.T) {
 			models.AzureDevops,
 			"AzureDevops",
 		},
+		{
+			models.Gitea,
+			"Gitea",
+		},
 	}
 
 	for _, c := range cases {
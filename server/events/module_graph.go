@@ -0,0 +1,327 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// maxCachedModuleGraphs is the number of module graphs ModuleGraphCache keeps.
+const maxCachedModuleGraphs = 100
+
+// ModuleGraphCache caches the module graphs of repos by the git tree they were
+// built from so that each commit's graph is only built once.
+type ModuleGraphCache struct {
+	mu     sync.Mutex
+	graphs map[string]*moduleGraph
+	// keys are the keys of graphs in the order they were added so we can
+	// evict the oldest graph.
+	keys []string
+}
+
+// get returns the module graph of the commit checked out in absRepoDir.
+func (c *ModuleGraphCache) get(absRepoDir string) (*moduleGraph, error) {
+	treeHash, err := gitOutput(absRepoDir, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return nil, err
+	}
+	treeHash = strings.TrimSpace(treeHash)
+
+	c.mu.Lock()
+	g, ok := c.graphs[treeHash]
+	c.mu.Unlock()
+	if ok {
+		return g, nil
+	}
+	// The graph is built without holding the lock since it runs git. If
+	// another caller built the same graph in the meantime, we use theirs.
+	g, err = newModuleGraph(absRepoDir, treeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.graphs[treeHash]; ok {
+		return cached, nil
+	}
+	if c.graphs == nil {
+		c.graphs = make(map[string]*moduleGraph)
+	}
+	if len(c.keys) >= maxCachedModuleGraphs {
+		delete(c.graphs, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.graphs[treeHash] = g
+	c.keys = append(c.keys, treeHash)
+	return g, nil
+}
+
+// moduleGraph is the graph of the local Terraform modules of a commit: the
+// module blocks of each dir with a local source, ex. "../modules/vpc", point
+// to the dir of that module. It reads the files from git rather than the
+// working dir so that it works with sparse checkouts. The Terraform files are
+// all read when the graph is built since the clone it was built from can be
+// deleted while the graph is cached.
+type moduleGraph struct {
+	// files maps the paths of the Terraform files of the commit to their
+	// contents.
+	files map[string][]byte
+	// dirs maps the dirs of the commit to their entries.
+	dirs map[string][]os.FileInfo
+
+	mu sync.Mutex
+	// sources maps the dirs that have been loaded to the local modules they
+	// source directly.
+	sources map[string][]string
+}
+
+func newModuleGraph(absRepoDir string, treeHash string) (*moduleGraph, error) {
+	out, err := gitOutput(absRepoDir, "ls-tree", "-r", "-t", "-z", treeHash)
+	if err != nil {
+		return nil, err
+	}
+	g := &moduleGraph{
+		files:   make(map[string][]byte),
+		dirs:    map[string][]os.FileInfo{".": nil},
+		sources: make(map[string][]string),
+	}
+	var tfFiles, tfHashes []string
+	// Each entry is "<mode> <type> <hash>\t<path>".
+	for _, entry := range strings.Split(out, "\x00") {
+		tab := strings.Index(entry, "\t")
+		if tab == -1 {
+			continue
+		}
+		fields := strings.Fields(entry[:tab])
+		if len(fields) != 3 {
+			continue
+		}
+		p := entry[tab+1:]
+		info := gitFileInfo{name: path.Base(p)}
+		switch fields[1] {
+		case "tree":
+			info.dir = true
+			if _, ok := g.dirs[p]; !ok {
+				g.dirs[p] = nil
+			}
+		case "blob":
+			if isTerraformFile(p) {
+				tfFiles = append(tfFiles, p)
+				tfHashes = append(tfHashes, fields[2])
+			}
+		default:
+			continue
+		}
+		g.dirs[path.Dir(p)] = append(g.dirs[path.Dir(p)], info)
+	}
+
+	blobs, err := readBlobs(absRepoDir, tfHashes)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range tfFiles {
+		g.files[p] = blobs[i]
+	}
+	return g, nil
+}
+
+// isTerraformFile returns true if p is a file tfconfig reads.
+func isTerraformFile(p string) bool {
+	return strings.HasSuffix(p, ".tf") || strings.HasSuffix(p, ".tf.json")
+}
+
+// readBlobs returns the contents of the git blobs with hashes, in the same
+// order, using a single git cat-file process.
+func readBlobs(absRepoDir string, hashes []string) ([][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "cat-file", "--batch") // nolint: gosec
+	cmd.Dir = absRepoDir
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "running git cat-file --batch: %s", stderr.String())
+	}
+
+	// Each blob is "<hash> <type> <size>\n<contents>\n".
+	blobs := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		nl := bytes.IndexByte(out, '\n')
+		if nl == -1 {
+			return nil, fmt.Errorf("reading blob %s: unexpected end of git cat-file output", hash)
+		}
+		header := strings.Fields(string(out[:nl]))
+		if len(header) != 3 || header[1] != "blob" {
+			return nil, fmt.Errorf("reading blob %s: unexpected git cat-file output %q", hash, string(out[:nl]))
+		}
+		size, err := strconv.Atoi(header[2])
+		if err != nil || len(out) < nl+1+size+1 {
+			return nil, fmt.Errorf("reading blob %s: unexpected git cat-file output %q", hash, string(out[:nl]))
+		}
+		blobs = append(blobs, out[nl+1:nl+1+size])
+		out = out[nl+1+size+1:]
+	}
+	return blobs, nil
+}
+
+// dependencies returns the dirs of the local modules that dir uses, directly
+// or through other modules.
+func (g *moduleGraph) dependencies(log logging.SimpleLogging, dir string) []string {
+	var deps []string
+	seen := map[string]bool{path.Clean(dir): true}
+	queue := []string{path.Clean(dir)}
+	for len(queue) > 0 {
+		for _, src := range g.moduleSources(log, queue[0]) {
+			if !seen[src] {
+				seen[src] = true
+				deps = append(deps, src)
+				queue = append(queue, src)
+			}
+		}
+		queue = queue[1:]
+	}
+	return deps
+}
+
+// rootModules returns the dirs with Terraform files that aren't used as a
+// module by any other dir.
+func (g *moduleGraph) rootModules(log logging.SimpleLogging) []string {
+	var tfDirs []string
+	for dir := range g.dirs {
+		if tfconfig.IsModuleDirOnFilesystem(g, dir) {
+			tfDirs = append(tfDirs, dir)
+		}
+	}
+	used := make(map[string]bool)
+	for _, dir := range tfDirs {
+		for _, src := range g.moduleSources(log, dir) {
+			used[src] = true
+		}
+	}
+	var roots []string
+	for _, dir := range tfDirs {
+		if !used[dir] {
+			roots = append(roots, dir)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// moduleSources returns the dirs of the local modules that dir sources
+// directly.
+func (g *moduleGraph) moduleSources(log logging.SimpleLogging, dir string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if sources, ok := g.sources[dir]; ok {
+		return sources
+	}
+
+	var sources []string
+	if tfconfig.IsModuleDirOnFilesystem(g, dir) {
+		module, diags := tfconfig.LoadModuleFromFilesystem(g, dir)
+		if diags.HasErrors() {
+			log.Debug("loading modules of dir %q: %s", dir, diags.Error())
+		}
+		for _, call := range module.ModuleCalls {
+			// Terraform only treats sources starting with ./ or ../ as local
+			// paths.
+			if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+				continue
+			}
+			src := path.Join(dir, call.Source)
+			if src == ".." || strings.HasPrefix(src, "../") {
+				continue
+			}
+			sources = append(sources, src)
+		}
+	}
+	g.sources[dir] = sources
+	return sources
+}
+
+// Open implements tfconfig.FS.
+func (g *moduleGraph) Open(name string) (tfconfig.File, error) {
+	contents, err := g.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &gitFile{
+		Reader: bytes.NewReader(contents),
+		info:   gitFileInfo{name: path.Base(name), size: int64(len(contents))},
+	}, nil
+}
+
+// ReadFile implements tfconfig.FS.
+func (g *moduleGraph) ReadFile(name string) ([]byte, error) {
+	contents, ok := g.files[path.Clean(name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return contents, nil
+}
+
+// ReadDir implements tfconfig.FS.
+func (g *moduleGraph) ReadDir(dirname string) ([]os.FileInfo, error) {
+	entries, ok := g.dirs[path.Clean(dirname)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return entries, nil
+}
+
+// gitFile is a file read from git.
+type gitFile struct {
+	*bytes.Reader
+	info gitFileInfo
+}
+
+func (f *gitFile) Stat() (os.FileInfo, error) { return f.info, nil }
+func (f *gitFile) Close() error               { return nil }
+
+// gitFileInfo is the os.FileInfo of an entry of a git tree.
+type gitFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i gitFileInfo) Name() string       { return i.name }
+func (i gitFileInfo) Size() int64        { return i.size }
+func (i gitFileInfo) ModTime() time.Time { return time.Time{} }
+func (i gitFileInfo) IsDir() bool        { return i.dir }
+func (i gitFileInfo) Sys() interface{}   { return nil }
+func (i gitFileInfo) Mode() os.FileMode {
+	if i.dir {
+		return os.ModeDir | 0700
+	}
+	return 0600
+}
+
+// gitOutput runs git with args in dir and returns its output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) // nolint: gosec
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "running git %s: %s", strings.Join(args, " "), stderr.String())
+	}
+	return string(out), nil
+}
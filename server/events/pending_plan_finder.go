@@ -15,6 +15,7 @@ import (
 type PendingPlanFinder interface {
 	Find(pullDir string) ([]PendingPlan, error)
 	DeletePlans(pullDir string) error
+	DeleteProjectPlans(pullDir string, workspace string, repoRelDir string) error
 }
 
 // DefaultPendingPlanFinder finds unapplied plans.
@@ -98,3 +99,21 @@ func (p *DefaultPendingPlanFinder) DeletePlans(pullDir string) error {
 	}
 	return nil
 }
+
+// DeleteProjectPlans deletes the plans in pullDir for the projects in
+// repoRelDir and workspace.
+func (p *DefaultPendingPlanFinder) DeleteProjectPlans(pullDir string, workspace string, repoRelDir string) error {
+	plans, absPaths, err := p.findWithAbsPaths(pullDir)
+	if err != nil {
+		return err
+	}
+	for i, plan := range plans {
+		if plan.Workspace != workspace || filepath.Clean(plan.RepoRelDir) != filepath.Clean(repoRelDir) {
+			continue
+		}
+		if err := os.Remove(absPaths[i]); err != nil {
+			return errors.Wrapf(err, "delete plan at %s", absPaths[i])
+		}
+	}
+	return nil
+}
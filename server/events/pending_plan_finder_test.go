@@ -278,6 +278,57 @@ func TestPendingPlanFinder_DeletePlans(t *testing.T) {
 	Equals(t, 0, len(foundPlans))
 }
 
+func TestPendingPlanFinder_DeleteProjectPlans(t *testing.T) {
+	files := map[string]interface{}{
+		"default": map[string]interface{}{
+			"dir1": map[string]interface{}{
+				"default.tfplan": nil,
+			},
+			"dir2": map[string]interface{}{
+				"default.tfplan": nil,
+			},
+		},
+		"staging": map[string]interface{}{
+			"dir1": map[string]interface{}{
+				"staging.tfplan": nil,
+			},
+		},
+	}
+	tmp, cleanup := DirStructure(t,
+		files)
+	defer cleanup()
+
+	// Create a git repo in each workspace directory.
+	for dirname, contents := range files {
+		// If contents is nil then this isn't a directory.
+		if contents != nil {
+			runCmd(t, filepath.Join(tmp, dirname), "git", "init")
+		}
+	}
+
+	pf := &events.DefaultPendingPlanFinder{}
+	Ok(t, pf.DeleteProjectPlans(tmp, "default", "dir1"))
+
+	_, err := os.Stat(filepath.Join(tmp, "default/dir1/default.tfplan"))
+	ErrContains(t, "no such file or directory", err)
+
+	// The plans of other projects and workspaces should be kept.
+	foundPlans, err := pf.Find(tmp)
+	Ok(t, err)
+	Equals(t, []events.PendingPlan{
+		{
+			RepoDir:    filepath.Join(tmp, "default"),
+			RepoRelDir: "dir2",
+			Workspace:  "default",
+		},
+		{
+			RepoDir:    filepath.Join(tmp, "staging"),
+			RepoRelDir: "dir1",
+			Workspace:  "staging",
+		},
+	}, foundPlans)
+}
+
 func runCmd(t *testing.T, dir string, name string, args ...string) string {
 	t.Helper()
 	cpCmd := exec.Command(name, args...)
@@ -4,6 +4,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 )
 
 func NewPlanCommandRunner(
@@ -22,6 +23,7 @@ func NewPlanCommandRunner(
 	parallelPoolSize int,
 	SilenceNoProjects bool,
 	pullStatusFetcher PullStatusFetcher,
+	webhooks WebhooksSender,
 ) *PlanCommandRunner {
 	return &PlanCommandRunner{
 		silenceVCSStatusNoPlans:    silenceVCSStatusNoPlans,
@@ -39,6 +41,7 @@ func NewPlanCommandRunner(
 		parallelPoolSize:           parallelPoolSize,
 		SilenceNoProjects:          SilenceNoProjects,
 		pullStatusFetcher:          pullStatusFetcher,
+		webhooks:                   webhooks,
 	}
 }
 
@@ -64,6 +67,7 @@ type PlanCommandRunner struct {
 	autoMerger                 *AutoMerger
 	parallelPoolSize           int
 	pullStatusFetcher          PullStatusFetcher
+	webhooks                   WebhooksSender
 }
 
 func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
@@ -76,6 +80,7 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 			ctx.Log.Warn("unable to update commit status: %s", statusErr)
 		}
 		p.pullUpdater.updatePull(ctx, AutoplanCommand{}, command.Result{Error: err})
+		p.sendAutoplanWebhook(ctx, err.Error())
 		return
 	}
 
@@ -122,6 +127,11 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 	}
 
 	p.pullUpdater.updatePull(ctx, AutoplanCommand{}, result)
+	if result.HasErrors() {
+		p.sendAutoplanWebhook(ctx, "one or more plans failed")
+	} else {
+		p.sendAutoplanWebhook(ctx, "")
+	}
 
 	pullStatus, err := p.dbUpdater.updateDB(ctx, ctx.Pull, result.ProjectResults)
 	if err != nil {
@@ -146,6 +156,19 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 	}
 }
 
+// sendAutoplanWebhook sends the webhook for autoplanning the pull request. If
+// errMsg is set, autoplanning failed.
+func (p *PlanCommandRunner) sendAutoplanWebhook(ctx *command.Context, errMsg string) {
+	sendWebhook(p.webhooks, ctx.Log, webhooks.Event{
+		Type:    webhooks.AutoplanEvent,
+		Repo:    ctx.Pull.BaseRepo,
+		Pull:    ctx.Pull,
+		User:    ctx.User,
+		Success: errMsg == "",
+		Error:   errMsg,
+	})
+}
+
 func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 	var err error
 	baseRepo := ctx.Pull.BaseRepo
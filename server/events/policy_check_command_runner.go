@@ -69,10 +69,10 @@ func (p *PolicyCheckCommandRunner) Run(ctx *command.Context, cmds []command.Proj
 		ctx.Log.Err("writing results: %s", err)
 	}
 
-	p.updateCommitStatus(ctx, pullStatus)
+	p.updateCommitStatus(ctx, pullStatus, result.HasSoftFailures())
 }
 
-func (p *PolicyCheckCommandRunner) updateCommitStatus(ctx *command.Context, pullStatus models.PullStatus) {
+func (p *PolicyCheckCommandRunner) updateCommitStatus(ctx *command.Context, pullStatus models.PullStatus, softFailed bool) {
 	var numSuccess int
 	var numErrored int
 	status := models.SuccessCommitStatus
@@ -82,6 +82,8 @@ func (p *PolicyCheckCommandRunner) updateCommitStatus(ctx *command.Context, pull
 
 	if numErrored > 0 {
 		status = models.FailedCommitStatus
+	} else if softFailed {
+		status = models.NeutralCommitStatus
 	}
 
 	if err := p.commitStatusUpdater.UpdateCombinedCount(ctx.Pull.BaseRepo, ctx.Pull, status, command.PolicyCheck, numSuccess, len(pullStatus.Projects)); err != nil {
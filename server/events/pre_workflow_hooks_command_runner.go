@@ -1,6 +1,7 @@
 package events
 
 import (
+	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/command"
@@ -47,7 +48,7 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(
 
 	log.Debug("pre-hooks configured, running...")
 
-	unlockFn, err := w.WorkingDirLocker.TryLock(baseRepo.FullName, pull.Num, DefaultWorkspace, DefaultRepoRelDir)
+	unlockFn, err := w.WorkingDirLocker.TryLock(baseRepo.ID(), pull.Num, DefaultWorkspace, DefaultRepoRelDir)
 	if err != nil {
 		return err
 	}
@@ -58,6 +59,11 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(
 	if err != nil {
 		return err
 	}
+	// Hooks can read any file of the repo, ex. to generate the config, so
+	// they need the whole repo checked out.
+	if err := w.WorkingDir.ExpandCheckout(log, baseRepo, pull, DefaultWorkspace, nil); err != nil {
+		return errors.Wrap(err, "checking out repo for pre workflow hooks")
+	}
 
 	err = w.runHooks(
 		models.WorkflowHookCommandContext{
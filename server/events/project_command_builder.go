@@ -85,7 +85,14 @@ func NewProjectCommandBuilder(
 	scope tally.Scope,
 	logger logging.SimpleLogging,
 ) *DefaultProjectCommandBuilder {
+	// Share the module graphs with the project finder so they're only built
+	// once per commit.
+	moduleGraphs := &ModuleGraphCache{}
+	if finder, ok := projectFinder.(*DefaultProjectFinder); ok && finder.ModuleGraphs != nil {
+		moduleGraphs = finder.ModuleGraphs
+	}
 	return &DefaultProjectCommandBuilder{
+		ModuleGraphs:       moduleGraphs,
 		ParserValidator:    parserValidator,
 		ProjectFinder:      projectFinder,
 		VCSClient:          vcsClient,
@@ -159,6 +166,9 @@ type DefaultProjectCommandBuilder struct {
 	EnableRegExpCmd              bool
 	AutoplanFileList             string
 	EnableDiffMarkdownFormat     bool
+	// ModuleGraphs caches the module graphs used to check out the local
+	// modules of projects with sparse checkouts.
+	ModuleGraphs *ModuleGraphCache
 }
 
 // See ProjectCommandBuilder.BuildAutoplanCommands.
@@ -197,7 +207,14 @@ func (p *DefaultProjectCommandBuilder) BuildApplyCommands(ctx *command.Context,
 }
 
 func (p *DefaultProjectCommandBuilder) BuildApprovePoliciesCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
-	return p.buildAllProjectCommands(ctx, cmd)
+	cmds, err := p.buildAllProjectCommands(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cmds {
+		cmds[i].PolicySetTarget = cmd.PolicySet
+	}
+	return cmds, nil
 }
 
 func (p *DefaultProjectCommandBuilder) BuildVersionCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
@@ -235,7 +252,9 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 				return nil, err
 			}
 			ctx.Log.Info("%d projects are changed on MR %q based on their when_modified config", len(matchingProjects), ctx.Pull.Num)
-			if len(matchingProjects) == 0 {
+			// Discovered projects and projects using modified modules are
+			// only known once we've cloned the repo.
+			if len(matchingProjects) == 0 && !repoCfg.Autodiscover.Enabled && !p.ProjectFinder.NeedsRepoDir() {
 				ctx.Log.Info("skipping repo clone since no project was modified")
 				return []command.ProjectContext{}, nil
 			}
@@ -248,7 +267,7 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 	// Need to lock the workspace we're about to clone to.
 	workspace := DefaultWorkspace
 
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, workspace, DefaultRepoRelDir)
 	if err != nil {
 		ctx.Log.Warn("workspace was locked")
 		return nil, err
@@ -260,6 +279,11 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 	if err != nil {
 		return nil, err
 	}
+	// If the clone is a sparse checkout, we need the modified dirs to find
+	// the modified projects.
+	if err := p.WorkingDir.ExpandCheckout(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, workspace, modifiedDirs(modifiedFiles)); err != nil {
+		return nil, errors.Wrap(err, "checking out modified dirs")
+	}
 
 	// Parse config file if it exists.
 	hasRepoCfg, err := p.ParserValidator.HasRepoCfg(repoDir)
@@ -277,6 +301,19 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 			return nil, errors.Wrapf(err, "parsing %s", config.AtlantisYAMLFilename)
 		}
 		ctx.Log.Info("successfully parsed %s file", config.AtlantisYAMLFilename)
+		// The dirs of the projects need to be checked out before we can
+		// check that they still exist.
+		modifiedProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, modifiedFiles, repoCfg, "")
+		if err != nil {
+			return nil, err
+		}
+		var projectDirs []string
+		for _, mp := range modifiedProjects {
+			projectDirs = append(projectDirs, mp.Dir)
+		}
+		if err := p.WorkingDir.ExpandCheckout(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, workspace, projectDirs); err != nil {
+			return nil, errors.Wrap(err, "checking out project dirs")
+		}
 		matchingProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, modifiedFiles, repoCfg, repoDir)
 		if err != nil {
 			return nil, err
@@ -288,7 +325,7 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 			mergedCfg := p.GlobalCfg.MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, repoCfg)
 
 			projCtxs = append(projCtxs,
-				p.ProjectCommandContextBuilder.BuildProjectContext(
+				withCheckoutDirs(p.ProjectCommandContextBuilder.BuildProjectContext(
 					ctx,
 					command.Plan,
 					mergedCfg,
@@ -299,7 +336,7 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 					repoCfg.ParallelApply,
 					repoCfg.ParallelPlan,
 					verbose,
-				)...)
+				), p.withModuleDirs(ctx.Log, repoDir, mp.Dir, projectCheckoutDirs(mp)))...)
 		}
 	} else {
 		// If there is no config file, then we'll plan each project that
@@ -313,9 +350,15 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 		for _, mp := range modifiedProjects {
 			ctx.Log.Debug("determining config for project at dir: %q", mp.Path)
 			pCfg := p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, DefaultWorkspace)
+			// Without a config file, the project's dir and the local modules
+			// it uses are checked out.
+			var checkoutDirs []string
+			if mp.Path != DefaultRepoRelDir {
+				checkoutDirs = p.withModuleDirs(ctx.Log, repoDir, mp.Path, []string{mp.Path})
+			}
 
 			projCtxs = append(projCtxs,
-				p.ProjectCommandContextBuilder.BuildProjectContext(
+				withCheckoutDirs(p.ProjectCommandContextBuilder.BuildProjectContext(
 					ctx,
 					command.Plan,
 					pCfg,
@@ -326,7 +369,7 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *command.Context
 					DefaultParallelApplyEnabled,
 					DefaultParallelPlanEnabled,
 					verbose,
-				)...)
+				), checkoutDirs)...)
 		}
 	}
 
@@ -347,7 +390,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *command.Cont
 
 	var pcc []command.ProjectContext
 	ctx.Log.Debug("building plan command")
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, workspace, DefaultRepoRelDir)
 	if err != nil {
 		return pcc, err
 	}
@@ -440,7 +483,7 @@ func (p *DefaultProjectCommandBuilder) getCfg(ctx *command.Context, projectName
 func (p *DefaultProjectCommandBuilder) buildAllProjectCommands(ctx *command.Context, commentCmd *CommentCommand) ([]command.ProjectContext, error) {
 	// Lock all dirs in this pull request (instead of a single dir) because we
 	// don't know how many dirs we'll need to run the command in.
-	unlockFn, err := p.WorkingDirLocker.TryLockPull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	unlockFn, err := p.WorkingDirLocker.TryLockPull(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num)
 	if err != nil {
 		return nil, err
 	}
@@ -488,7 +531,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectApplyCommand(ctx *command.Con
 	}
 
 	var projCtx []command.ProjectContext
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, workspace, DefaultRepoRelDir)
 	if err != nil {
 		return projCtx, err
 	}
@@ -529,7 +572,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectVersionCommand(ctx *command.C
 	}
 
 	var projCtx []command.ProjectContext
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, workspace, DefaultRepoRelDir)
 	if err != nil {
 		return projCtx, err
 	}
@@ -598,7 +641,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *command.Conte
 			projCfg = p.GlobalCfg.MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, *repoCfgPtr)
 
 			projCtxs = append(projCtxs,
-				p.ProjectCommandContextBuilder.BuildProjectContext(
+				withCheckoutDirs(p.ProjectCommandContextBuilder.BuildProjectContext(
 					ctx,
 					cmd,
 					projCfg,
@@ -609,9 +652,11 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *command.Conte
 					parallelApply,
 					parallelPlan,
 					verbose,
-				)...)
+				), p.withModuleDirs(ctx.Log, repoDir, mp.Dir, projectCheckoutDirs(mp)))...)
 		}
 	} else {
+		// The dir isn't a project in the config file so we don't know what
+		// it needs and check out the whole repo.
 		projCfg = p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), repoRelDir, workspace)
 		projCtxs = append(projCtxs,
 			p.ProjectCommandContextBuilder.BuildProjectContext(
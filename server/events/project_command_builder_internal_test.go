@@ -131,6 +131,7 @@ projects:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -186,6 +187,7 @@ projects:
 				ApplyRequirements: []string{"approved", "mergeable"},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -249,6 +251,7 @@ projects:
 				ApplyRequirements: []string{"approved"},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -399,6 +402,7 @@ workflows:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -458,6 +462,7 @@ projects:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -520,6 +525,7 @@ workflows:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -566,6 +572,7 @@ projects:
 				ApplyRequirements: []string{"approved"},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"project1"},
 				RepoRelDir:        "project1",
 				User:              models.User{},
 				Verbose:           true,
@@ -770,6 +777,7 @@ projects:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -p myproject_1 -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -996,6 +1004,7 @@ workflows:
 				ApplyRequirements: []string{},
 				RepoConfigVersion: 3,
 				RePlanCmd:         "atlantis plan -d project1 -w myworkspace -- flag",
+				CheckoutDirs:      []string{"modules", "project1"},
 				RepoRelDir:        "project1",
 				TerraformVersion:  mustVersion("10.0"),
 				User:              models.User{},
@@ -182,6 +182,120 @@ projects:
 	}
 }
 
+// Test that autoplanning checks out the modified dirs to find the modified
+// projects and sets the dirs each project needs.
+func TestDefaultProjectCommandBuilder_BuildAutoplanCommands_CheckoutDirs(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"envs": map[string]interface{}{
+			"dev": map[string]interface{}{
+				"main.tf": nil,
+			},
+			"prod": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"modules": map[string]interface{}{
+			"vpc": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+	})
+	defer cleanup()
+	atlantisYAML := `
+version: 3
+projects:
+- dir: envs/dev
+- dir: envs/prod
+  autoplan:
+    when_modified: ["*.tf", "../../modules/**/*.tf"]
+`
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, config.AtlantisYAMLFilename), []byte(atlantisYAML), 0600))
+
+	logger := logging.NewNoopLogger(t)
+	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"modules/vpc/main.tf"}, nil)
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&config.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		vcsClient,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl",
+		scope,
+		logger,
+	)
+
+	ctxs, err := builder.BuildAutoplanCommands(&command.Context{
+		PullRequestStatus: models.PullReqStatus{
+			Mergeable: true,
+		},
+		Log:   logger,
+		Scope: scope,
+	})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+	Equals(t, "envs/prod", ctxs[0].RepoRelDir)
+	Equals(t, []string{"envs/prod", "modules"}, ctxs[0].CheckoutDirs)
+
+	_, _, _, _, dirs := workingDir.VerifyWasCalled(Times(2)).ExpandCheckout(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), EqString(events.DefaultWorkspace), AnyStringSlice()).GetAllCapturedArguments()
+	Equals(t, [][]string{{"modules/vpc"}, {"envs/prod"}}, dirs)
+}
+
+// Test that without a config file, the local modules a project uses are
+// checked out with it.
+func TestDefaultProjectCommandBuilder_BuildAutoplanCommands_CheckoutModuleDirs(t *testing.T) {
+	RegisterMockTestingT(t)
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+
+	logger := logging.NewNoopLogger(t)
+	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(repoDir, false, nil)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"envs/dev/main.tf"}, nil)
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&config.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		vcsClient,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl",
+		scope,
+		logger,
+	)
+
+	ctxs, err := builder.BuildAutoplanCommands(&command.Context{
+		PullRequestStatus: models.PullReqStatus{
+			Mergeable: true,
+		},
+		Log:   logger,
+		Scope: scope,
+	})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+	Equals(t, "envs/dev", ctxs[0].RepoRelDir)
+	Equals(t, []string{"envs/dev", "modules/app", "modules/vpc"}, ctxs[0].CheckoutDirs)
+}
+
 // Test building a plan and apply command for one project.
 func TestDefaultProjectCommandBuilder_BuildSinglePlanApplyCommand(t *testing.T) {
 	cases := []struct {
@@ -1108,6 +1222,78 @@ projects:
 	workingDir.VerifyWasCalled(Never()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
 }
 
+// Test that we still clone the repo if no project was modified based on the
+// atlantis.yaml file but projects using modified modules are autoplanned.
+func TestDefaultProjectCommandBuilder_SkipCloneNoChangesAutoplanModules(t *testing.T) {
+	atlantisYAML := `
+version: 3
+projects:
+- dir: dir1`
+
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"dir1": map[string]interface{}{
+			"main.tf": `
+module "foo" {
+  source = "../modules/foo"
+}`,
+		},
+		"modules": map[string]interface{}{
+			"foo": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		config.AtlantisYAMLFilename: atlantisYAML,
+	})
+	defer cleanup()
+	// The module graph is read from the clone's git tree.
+	runCmd(t, tmpDir, "git", "init")
+	runCmd(t, tmpDir, "git", "add", ".")
+	runCmd(t, tmpDir, "git", "-c", "user.email=atlantisbot@runatlantis.io", "-c", "user.name=atlantisbot", "-c", "commit.gpgsign=false", "commit", "-m", "initial commit")
+
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"modules/foo/main.tf"}, nil)
+	When(vcsClient.SupportsSingleFileDownload(matchers.AnyModelsRepo())).ThenReturn(true)
+	When(vcsClient.DownloadRepoConfigFile(matchers.AnyModelsPullRequest())).ThenReturn(true, []byte(atlantisYAML), nil)
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+
+	logger := logging.NewNoopLogger(t)
+	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&config.ParserValidator{},
+		&events.DefaultProjectFinder{AutoplanModules: true},
+		vcsClient,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: true}),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		true,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl",
+		scope,
+		logger,
+	)
+
+	actCtxs, err := builder.BuildAutoplanCommands(&command.Context{
+		HeadRepo: models.Repo{},
+		Pull:     models.PullRequest{},
+		User:     models.User{},
+		Log:      logger,
+		Scope:    scope,
+		PullRequestStatus: models.PullReqStatus{
+			Mergeable: true,
+		},
+	})
+	Ok(t, err)
+	Equals(t, 1, len(actCtxs))
+	Equals(t, "dir1", actCtxs[0].RepoRelDir)
+	workingDir.VerifyWasCalled(Once()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+}
+
 func TestDefaultProjectCommandBuilder_WithPolicyCheckEnabled_BuildAutoplanCommand(t *testing.T) {
 	RegisterMockTestingT(t)
 	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
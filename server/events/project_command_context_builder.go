@@ -219,18 +219,20 @@ func newProjectCommandContext(ctx *command.Context,
 ) command.ProjectContext {
 
 	var projectPlanStatus models.ProjectPlanStatus
+	var projectPlanSummary string
+	var projectPolicyStatus []models.PolicySetStatus
+	var projectPolicyApprovals []models.PolicySetApproval
 
 	if ctx.PullStatus != nil {
 		for _, project := range ctx.PullStatus.Projects {
 
 			// if name is not used, let's match the directory
-			if projCfg.Name == "" && project.RepoRelDir == projCfg.RepoRelDir {
-				projectPlanStatus = project.Status
-				break
-			}
-
-			if projCfg.Name != "" && project.ProjectName == projCfg.Name {
+			if (projCfg.Name == "" && project.RepoRelDir == projCfg.RepoRelDir) ||
+				(projCfg.Name != "" && project.ProjectName == projCfg.Name) {
 				projectPlanStatus = project.Status
+				projectPlanSummary = project.PlanSummary
+				projectPolicyStatus = project.PolicyStatus
+				projectPolicyApprovals = project.PolicyApprovals
 				break
 			}
 		}
@@ -252,6 +254,7 @@ func newProjectCommandContext(ctx *command.Context,
 		Log:                        ctx.Log,
 		Scope:                      scope,
 		ProjectPlanStatus:          projectPlanStatus,
+		ProjectPlanSummary:         projectPlanSummary,
 		Pull:                       ctx.Pull,
 		ProjectName:                projCfg.Name,
 		ApplyRequirements:          projCfg.ApplyRequirements,
@@ -259,6 +262,7 @@ func newProjectCommandContext(ctx *command.Context,
 		RepoRelDir:                 projCfg.RepoRelDir,
 		RepoConfigVersion:          projCfg.RepoCfgVersion,
 		TerraformVersion:           projCfg.TerraformVersion,
+		TerraformDistribution:      projCfg.TerraformDistribution,
 		User:                       ctx.User,
 		Verbose:                    verbose,
 		Workspace:                  projCfg.Workspace,
@@ -266,6 +270,8 @@ func newProjectCommandContext(ctx *command.Context,
 		PullReqStatus:              pullStatus,
 		JobID:                      uuid.New().String(),
 		ExecutionOrderGroup:        projCfg.ExecutionOrderGroup,
+		ProjectPolicyStatus:        projectPolicyStatus,
+		ProjectPolicyApprovals:     projectPolicyApprovals,
 	}
 }
 
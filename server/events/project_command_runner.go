@@ -14,16 +14,20 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 )
@@ -83,7 +87,15 @@ type MultiEnvStepRunner interface {
 // WebhooksSender sends webhook.
 type WebhooksSender interface {
 	// Send sends the webhook.
-	Send(log logging.SimpleLogging, res webhooks.ApplyResult) error
+	Send(log logging.SimpleLogging, event webhooks.Event) error
+}
+
+// sendWebhook sends event with sender if it's set.
+func sendWebhook(sender WebhooksSender, log logging.SimpleLogging, event webhooks.Event) {
+	if sender == nil {
+		return
+	}
+	sender.Send(log, event) // nolint: errcheck
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_command_runner.go ProjectCommandRunner
@@ -113,6 +125,15 @@ type ProjectVersionCommandRunner interface {
 	Version(ctx command.ProjectContext) command.ProjectResult
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_drift_command_runner.go ProjectDriftCommandRunner
+
+type ProjectDriftCommandRunner interface {
+	// DetectDrift runs the plan workflow for the project described by ctx in
+	// repoDir, which must already be cloned, and returns whether the plan
+	// had changes along with its output.
+	DetectDrift(ctx command.ProjectContext, repoDir string) (drifted bool, output string, err error)
+}
+
 // ProjectCommandRunner runs project commands. A project command is a command
 // for a specific TF project.
 type ProjectCommandRunner interface {
@@ -131,6 +152,14 @@ type JobURLSetter interface {
 	SetJobURLWithStatus(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus) error
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_result_updater.go ProjectResultUpdater
+
+type ProjectResultUpdater interface {
+	// UpdateProjectResult adds the result of the project command to the
+	// project's status, ex. the plan to its GitHub check run.
+	UpdateProjectResult(ctx command.ProjectContext, cmdName command.Name, projectResult command.ProjectResult) error
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_job_message_sender.go JobMessageSender
 
 type JobMessageSender interface {
@@ -143,6 +172,9 @@ type ProjectOutputWrapper struct {
 	ProjectCommandRunner
 	JobMessageSender JobMessageSender
 	JobURLSetter     JobURLSetter
+	// ProjectResultUpdater, if set, is given the result of each project
+	// command after its status is updated.
+	ProjectResultUpdater ProjectResultUpdater
 }
 
 func (p *ProjectOutputWrapper) Plan(ctx command.ProjectContext) command.ProjectResult {
@@ -172,6 +204,7 @@ func (p *ProjectOutputWrapper) updateProjectPRStatus(commandName command.Name, c
 		if err := p.JobURLSetter.SetJobURLWithStatus(ctx, commandName, models.FailedCommitStatus); err != nil {
 			ctx.Log.Err("updating project PR status", err)
 		}
+		p.updateProjectResult(commandName, ctx, result)
 
 		return result
 	}
@@ -179,10 +212,20 @@ func (p *ProjectOutputWrapper) updateProjectPRStatus(commandName command.Name, c
 	if err := p.JobURLSetter.SetJobURLWithStatus(ctx, commandName, models.SuccessCommitStatus); err != nil {
 		ctx.Log.Err("updating project PR status", err)
 	}
+	p.updateProjectResult(commandName, ctx, result)
 
 	return result
 }
 
+func (p *ProjectOutputWrapper) updateProjectResult(commandName command.Name, ctx command.ProjectContext, result command.ProjectResult) {
+	if p.ProjectResultUpdater == nil {
+		return
+	}
+	if err := p.ProjectResultUpdater.UpdateProjectResult(ctx, commandName, result); err != nil {
+		ctx.Log.Err("updating project PR status with result: %s", err)
+	}
+}
+
 // DefaultProjectCommandRunner implements ProjectCommandRunner.
 type DefaultProjectCommandRunner struct {
 	Locker                     ProjectLocker
@@ -201,12 +244,17 @@ type DefaultProjectCommandRunner struct {
 	Webhooks                   WebhooksSender
 	WorkingDirLocker           WorkingDirLocker
 	AggregateApplyRequirements ApplyRequirement
+	// VcsClient is used to look up the teams of users approving policies.
+	VcsClient vcs.Client
+	// DefaultTFVersions maps from the name of a distribution, ex. opentofu, to
+	// the version projects of that distribution run if they don't set one.
+	DefaultTFVersions map[string]*version.Version
 }
 
 // Plan runs terraform plan for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectResult {
 	planSuccess, failure, err := p.doPlan(ctx)
-	return command.ProjectResult{
+	result := command.ProjectResult{
 		Command:     command.Plan,
 		PlanSuccess: planSuccess,
 		Error:       err,
@@ -215,12 +263,18 @@ func (p *DefaultProjectCommandRunner) Plan(ctx command.ProjectContext) command.P
 		Workspace:   ctx.Workspace,
 		ProjectName: ctx.ProjectName,
 	}
+	planSummary := ""
+	if planSuccess != nil {
+		planSummary = planSuccess.Summary()
+	}
+	p.sendWebhook(ctx, webhooks.PlanEvent, result, planSummary)
+	return result
 }
 
 // PolicyCheck evaluates policies defined with Rego for the project described by ctx.
 func (p *DefaultProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) command.ProjectResult {
-	policySuccess, failure, err := p.doPolicyCheck(ctx)
-	return command.ProjectResult{
+	policySuccess, policySetResults, failure, err := p.doPolicyCheck(ctx)
+	result := command.ProjectResult{
 		Command:            command.PolicyCheck,
 		PolicyCheckSuccess: policySuccess,
 		Error:              err,
@@ -228,13 +282,16 @@ func (p *DefaultProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) co
 		RepoRelDir:         ctx.RepoRelDir,
 		Workspace:          ctx.Workspace,
 		ProjectName:        ctx.ProjectName,
+		PolicySetResults:   policySetResults,
 	}
+	p.sendWebhook(ctx, webhooks.PolicyCheckEvent, result, ctx.ProjectPlanSummary)
+	return result
 }
 
 // Apply runs terraform apply for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectResult {
 	applyOut, failure, err := p.doApply(ctx)
-	return command.ProjectResult{
+	result := command.ProjectResult{
 		Command:      command.Apply,
 		Failure:      failure,
 		Error:        err,
@@ -243,11 +300,12 @@ func (p *DefaultProjectCommandRunner) Apply(ctx command.ProjectContext) command.
 		Workspace:    ctx.Workspace,
 		ProjectName:  ctx.ProjectName,
 	}
+	return result
 }
 
 func (p *DefaultProjectCommandRunner) ApprovePolicies(ctx command.ProjectContext) command.ProjectResult {
-	approvedOut, failure, err := p.doApprovePolicies(ctx)
-	return command.ProjectResult{
+	approvedOut, approvals, failure, err := p.doApprovePolicies(ctx)
+	result := command.ProjectResult{
 		Command:            command.PolicyCheck,
 		Failure:            failure,
 		Error:              err,
@@ -255,12 +313,15 @@ func (p *DefaultProjectCommandRunner) ApprovePolicies(ctx command.ProjectContext
 		RepoRelDir:         ctx.RepoRelDir,
 		Workspace:          ctx.Workspace,
 		ProjectName:        ctx.ProjectName,
+		PolicyApprovals:    approvals,
 	}
+	p.sendWebhook(ctx, webhooks.ApprovePoliciesEvent, result, ctx.ProjectPlanSummary)
+	return result
 }
 
 func (p *DefaultProjectCommandRunner) Version(ctx command.ProjectContext) command.ProjectResult {
 	versionOut, failure, err := p.doVersion(ctx)
-	return command.ProjectResult{
+	result := command.ProjectResult{
 		Command:        command.Version,
 		Failure:        failure,
 		Error:          err,
@@ -269,41 +330,184 @@ func (p *DefaultProjectCommandRunner) Version(ctx command.ProjectContext) comman
 		Workspace:      ctx.Workspace,
 		ProjectName:    ctx.ProjectName,
 	}
+	p.sendWebhook(ctx, webhooks.VersionEvent, result, "")
+	return result
 }
 
-func (p *DefaultProjectCommandRunner) doApprovePolicies(ctx command.ProjectContext) (*models.PolicyCheckSuccess, string, error) {
+// sendWebhook sends the webhook of type eventType for the result of running a
+// command for the project described by ctx.
+func (p *DefaultProjectCommandRunner) sendWebhook(ctx command.ProjectContext, eventType string, result command.ProjectResult, planSummary string) {
+	event := webhooks.Event{
+		Type:        eventType,
+		Workspace:   ctx.Workspace,
+		User:        ctx.User,
+		Repo:        ctx.Pull.BaseRepo,
+		Pull:        ctx.Pull,
+		Success:     result.Error == nil && result.Failure == "",
+		Directory:   ctx.RepoRelDir,
+		ProjectName: ctx.ProjectName,
+		PlanSummary: planSummary,
+	}
+	if result.Error != nil {
+		event.Error = result.Error.Error()
+	} else if result.Failure != "" {
+		event.Error = result.Failure
+	}
+	sendWebhook(p.Webhooks, ctx.Log, event)
+}
 
-	// TODO: Make this a bit smarter
-	// without checking some sort of state that the policy check has indeed passed this is likely to cause issues
+// doApprovePolicies approves the failing policy sets of the project that the
+// user owns. Owners of all the policy sets can approve any of them. The policy
+// check passes once each failing policy set has been approved by as many of
+// its owners as it requires. It returns the approvals of the project if the
+// user approved any policy set.
+func (p *DefaultProjectCommandRunner) doApprovePolicies(ctx command.ProjectContext) (*models.PolicyCheckSuccess, []models.PolicySetApproval, string, error) {
+	if ctx.PolicySetTarget != "" && !hasPolicySet(ctx.PolicySets, ctx.PolicySetTarget) {
+		return nil, nil, fmt.Sprintf("policy set %q is not configured", ctx.PolicySetTarget), nil
+	}
 
+	// Only look up the teams of the user if any teams are owners since it's
+	// an extra call to the VCS host.
+	var userTeams []string
+	hostType := ctx.Pull.BaseRepo.VCSHost.Type
+	if ctx.PolicySets.HasTeamOwners() && !vcs.TeamsSupported(hostType) {
+		ctx.Log.Warn("policy owner teams aren't supported on %s so only owner users can approve policies", hostType.String())
+	} else if ctx.PolicySets.HasTeamOwners() {
+		teams, err := p.VcsClient.GetTeamNamesForUser(ctx.Pull.BaseRepo, ctx.User)
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "getting teams of user")
+		}
+		userTeams = teams
+	}
+	isOwnerOfAll := ctx.PolicySets.IsOwner(ctx.User.Username, userTeams)
+
+	failing := failingPolicySets(ctx)
+	approvals := make([]models.PolicySetApproval, 0, len(ctx.ProjectPolicyApprovals))
+	for _, approval := range ctx.ProjectPolicyApprovals {
+		approval.Approvers = append([]string(nil), approval.Approvers...)
+		approvals = append(approvals, approval)
+	}
+	approved := false
+	for _, policySet := range failing {
+		if ctx.PolicySetTarget != "" && policySet.Name != ctx.PolicySetTarget {
+			continue
+		}
+		if !isOwnerOfAll && !policySet.IsOwner(ctx.User.Username, userTeams) {
+			continue
+		}
+		approval := findPolicySetApproval(&approvals, policySet.Name)
+		if !approval.HasApprover(ctx.User.Username) {
+			approval.Approvers = append(approval.Approvers, ctx.User.Username)
+		}
+		approved = true
+	}
+
+	var pending []string
+	for _, policySet := range failing {
+		required := policySet.ApproveCount
+		if required < 1 {
+			required = 1
+		}
+		count := 0
+		for _, approval := range approvals {
+			if approval.PolicySetName == policySet.Name {
+				count = len(approval.Approvers)
+			}
+		}
+		if count < required {
+			pending = append(pending, fmt.Sprintf("  %s: %d/%d approvals", policySet.Name, count, required))
+		}
+	}
+
+	if !approved {
+		if len(pending) == 0 {
+			return &models.PolicyCheckSuccess{
+				PolicyCheckOutput: "Policies approved",
+			}, nil, "", nil
+		}
+		return nil, nil, fmt.Sprintf("contact policy owners to approve failing policies:\n%s", strings.Join(pending, "\n")), nil
+	}
+	if len(pending) > 0 {
+		return nil, approvals, fmt.Sprintf("policy sets still require approval from their owners:\n%s", strings.Join(pending, "\n")), nil
+	}
 	return &models.PolicyCheckSuccess{
 		PolicyCheckOutput: "Policies approved",
-	}, "", nil
+	}, approvals, "", nil
+}
+
+// failingPolicySets returns the enforced policy sets that failed the project's
+// last policy check. A policy set without a status, ex. because conftest
+// failed to run its policies, is considered to have failed if the policy
+// check failed since it's unknown whether it passed.
+func failingPolicySets(ctx command.ProjectContext) []valid.PolicySet {
+	var failing []valid.PolicySet
+	for _, policySet := range ctx.PolicySets.PolicySets {
+		if policySet.IsWarnOnly() {
+			continue
+		}
+		status, ok := findPolicySetStatus(ctx.ProjectPolicyStatus, policySet.Name)
+		if (ok && !status.Passed()) || (!ok && ctx.ProjectPlanStatus == models.ErroredPolicyCheckStatus) {
+			failing = append(failing, policySet)
+		}
+	}
+	return failing
+}
+
+// findPolicySetStatus returns the status of the policy set named name in
+// statuses.
+func findPolicySetStatus(statuses []models.PolicySetStatus, name string) (models.PolicySetStatus, bool) {
+	for _, status := range statuses {
+		if status.PolicySetName == name {
+			return status, true
+		}
+	}
+	return models.PolicySetStatus{}, false
+}
+
+// findPolicySetApproval returns the approval of the policy set named name in
+// approvals, adding it if there's none.
+func findPolicySetApproval(approvals *[]models.PolicySetApproval, name string) *models.PolicySetApproval {
+	for i := range *approvals {
+		if (*approvals)[i].PolicySetName == name {
+			return &(*approvals)[i]
+		}
+	}
+	*approvals = append(*approvals, models.PolicySetApproval{PolicySetName: name})
+	return &(*approvals)[len(*approvals)-1]
 }
 
-func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext) (*models.PolicyCheckSuccess, string, error) {
+func hasPolicySet(policySets valid.PolicySets, name string) bool {
+	for _, policySet := range policySets.PolicySets {
+		if policySet.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext) (*models.PolicyCheckSuccess, []models.PolicySetResult, string, error) {
 	// Acquire Atlantis lock for this repo/dir/workspace.
 	// This should already be acquired from the prior plan operation.
 	// if for some reason an unlock happens between the plan and policy check step
 	// we will attempt to capture the lock here but fail to get the working directory
 	// at which point we will unlock again to preserve functionality
 	// If we fail to capture the lock here (super unlikely) then we error out and the user is forced to replan
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir))
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewRepoProject(ctx.Pull.BaseRepo, ctx.RepoRelDir))
 
 	if err != nil {
-		return nil, "", errors.Wrap(err, "acquiring lock")
+		return nil, nil, "", errors.Wrap(err, "acquiring lock")
 	}
 	if !lockAttempt.LockAcquired {
-		return nil, lockAttempt.LockFailureReason, nil
+		return nil, nil, lockAttempt.LockFailureReason, nil
 	}
 	ctx.Log.Debug("acquired lock for project")
 
 	// Acquire internal lock for the directory we're going to operate in.
 	// We should refactor this to keep the lock for the duration of plan and policy check since as of now
 	// there is a small gap where we don't have the lock and if we can't get this here, we should just unlock the PR.
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	defer unlockFn()
 
@@ -318,9 +522,9 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 		}
 
 		if os.IsNotExist(err) {
-			return nil, "", errors.New("project has not been cloned–did you run plan?")
+			return nil, nil, "", errors.New("project has not been cloned–did you run plan?")
 		}
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
 	if _, err = os.Stat(absPath); os.IsNotExist(err) {
@@ -330,14 +534,25 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
 		}
 
-		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+		return nil, nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	// Remove the results of the previous policy check so we don't report
+	// them if the policy engine doesn't write new ones.
+	resultsFile := filepath.Join(absPath, ctx.GetPolicyCheckResultFileName())
+	if err := os.Remove(resultsFile); err != nil && !os.IsNotExist(err) {
+		return nil, nil, "", errors.Wrap(err, "removing previous policy check results")
 	}
 
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	results, readErr := readPolicyCheckResults(resultsFile)
+	if readErr != nil {
+		ctx.Log.Warn("unable to read policy check results: %s", readErr)
+	}
 	if err != nil {
 		// Note: we are explicitly not unlocking the pr here since a failing policy check will require
 		// approval
-		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		return nil, results.PolicySetResults, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
 
 	return &models.PolicyCheckSuccess{
@@ -349,12 +564,30 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 		// set this to false right now because we don't have this information
 		// TODO: refactor the templates in a sane way so we don't need this
 		HasDiverged: false,
-	}, "", nil
+		SoftFailed:  results.SoftFailed,
+	}, results.PolicySetResults, "", nil
+}
+
+// readPolicyCheckResults reads the structured policy check results written by
+// the policy engine to path. It returns empty results if there are none.
+func readPolicyCheckResults(path string) (models.PolicyCheckResults, error) {
+	var results models.PolicyCheckResults
+	serialized, err := os.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return results, nil
+	}
+	if err != nil {
+		return results, err
+	}
+	if err := json.Unmarshal(serialized, &results); err != nil {
+		return models.PolicyCheckResults{}, errors.Wrapf(err, "parsing %s", path)
+	}
+	return results, nil
 }
 
 func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*models.PlanSuccess, string, error) {
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir))
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewRepoProject(ctx.Pull.BaseRepo, ctx.RepoRelDir))
 	if err != nil {
 		return nil, "", errors.Wrap(err, "acquiring lock")
 	}
@@ -364,7 +597,7 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*model
 	ctx.Log.Debug("acquired lock for project")
 
 	// Acquire internal lock for the directory we're going to operate in.
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
 	if err != nil {
 		return nil, "", err
 	}
@@ -378,6 +611,12 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*model
 		}
 		return nil, "", cloneErr
 	}
+	if err := p.WorkingDir.ExpandCheckout(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace, ctx.CheckoutDirs); err != nil {
+		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
+			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
+		}
+		return nil, "", errors.Wrap(err, "checking out project")
+	}
 	projAbsPath := filepath.Join(repoDir, ctx.RepoRelDir)
 	if _, err = os.Stat(projAbsPath); os.IsNotExist(err) {
 		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
@@ -401,6 +640,54 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*model
 	}, "", nil
 }
 
+// ErrProjectLocked is returned by DetectDrift if the project is locked by a
+// pull request.
+var ErrProjectLocked = errors.New("project is locked")
+
+// DetectDrift implements ProjectDriftCommandRunner. It runs the plan steps
+// with -detailed-exitcode so terraform exits with 2 if the plan has changes.
+// The project is locked while it's planned so that drift detection doesn't
+// run at the same time as a pull request's apply, and ErrProjectLocked is
+// returned if a pull request holds the lock.
+func (p *DefaultProjectCommandRunner) DetectDrift(ctx command.ProjectContext, repoDir string) (bool, string, error) {
+	projAbsPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err := os.Stat(projAbsPath); os.IsNotExist(err) {
+		return false, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewRepoProject(ctx.Pull.BaseRepo, ctx.RepoRelDir))
+	if err != nil {
+		return false, "", errors.Wrap(err, "acquiring lock")
+	}
+	if !lockAttempt.LockAcquired {
+		return false, "", ErrProjectLocked
+	}
+	defer func() {
+		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
+			ctx.Log.Err("error unlocking state after drift detection: %v", unlockErr)
+		}
+	}()
+
+	var steps []valid.Step
+	for _, step := range ctx.Steps {
+		if step.StepName == "plan" {
+			step.ExtraArgs = append(append([]string{}, step.ExtraArgs...), "-detailed-exitcode")
+		}
+		steps = append(steps, step)
+	}
+
+	outputs, err := p.runSteps(steps, ctx, projAbsPath)
+	output := strings.Join(outputs, "\n")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return true, output, nil
+		}
+		return false, output, err
+	}
+	return false, output, nil
+}
+
 func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (applyOut string, failure string, err error) {
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
@@ -420,27 +707,22 @@ func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (apply
 	}
 
 	// Acquire internal lock for the directory we're going to operate in.
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
 	if err != nil {
 		return "", "", err
 	}
 	defer unlockFn()
 
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	if err != nil {
+		err = fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
 
-	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
-		Workspace: ctx.Workspace,
-		User:      ctx.User,
-		Repo:      ctx.Pull.BaseRepo,
-		Pull:      ctx.Pull,
-		Success:   err == nil,
-		Directory: ctx.RepoRelDir,
-	})
+	p.sendWebhook(ctx, webhooks.ApplyEvent, command.ProjectResult{Error: err}, ctx.ProjectPlanSummary)
 
 	if err != nil {
-		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		return "", "", err
 	}
-
 	return strings.Join(outputs, "\n"), "", nil
 }
 
@@ -458,7 +740,7 @@ func (p *DefaultProjectCommandRunner) doVersion(ctx command.ProjectContext) (ver
 	}
 
 	// Acquire internal lock for the directory we're going to operate in.
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.ID(), ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
 	if err != nil {
 		return "", "", err
 	}
@@ -475,6 +757,16 @@ func (p *DefaultProjectCommandRunner) doVersion(ctx command.ProjectContext) (ver
 func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx command.ProjectContext, absPath string) ([]string, error) {
 	var outputs []string
 
+	// The step runners default to the version of the default distribution so
+	// projects of another distribution need the version of theirs.
+	if ctx.TerraformVersion == nil && ctx.TerraformDistribution != "" {
+		v, ok := p.DefaultTFVersions[ctx.TerraformDistribution]
+		if !ok {
+			return nil, fmt.Errorf("no default version of %s, set terraform_version for the project", ctx.TerraformDistribution)
+		}
+		ctx.TerraformVersion = v
+	}
+
 	envs := make(map[string]string)
 	for _, step := range steps {
 		var out string
@@ -14,9 +14,12 @@
 package events_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/go-version"
@@ -30,6 +33,8 @@ import (
 	eventmocks "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	jobmocks "github.com/runatlantis/atlantis/server/jobs/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -193,11 +198,13 @@ func TestProjectOutputWrapper(t *testing.T) {
 			mockJobURLSetter := eventmocks.NewMockJobURLSetter()
 			mockJobMessageSender := eventmocks.NewMockJobMessageSender()
 			mockProjectCommandRunner := mocks.NewMockProjectCommandRunner()
+			mockProjectResultUpdater := eventmocks.NewMockProjectResultUpdater()
 
 			runner := &events.ProjectOutputWrapper{
 				JobURLSetter:         mockJobURLSetter,
 				JobMessageSender:     mockJobMessageSender,
 				ProjectCommandRunner: mockProjectCommandRunner,
+				ProjectResultUpdater: mockProjectResultUpdater,
 			}
 
 			if c.Success {
@@ -230,6 +237,7 @@ func TestProjectOutputWrapper(t *testing.T) {
 
 			mockJobURLSetter.VerifyWasCalled(Once()).SetJobURLWithStatus(ctx, c.CommandName, models.PendingCommitStatus)
 			mockJobURLSetter.VerifyWasCalled(Once()).SetJobURLWithStatus(ctx, c.CommandName, expCommitStatus)
+			mockProjectResultUpdater.VerifyWasCalledOnce().UpdateProjectResult(ctx, c.CommandName, prjResult)
 
 			switch c.CommandName {
 			case command.Plan:
@@ -243,6 +251,261 @@ func TestProjectOutputWrapper(t *testing.T) {
 
 // Test what happens if there's no working dir. This signals that the project
 // was never planned.
+func TestDefaultProjectCommandRunner_PolicyCheckResults(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPolicyCheck := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                mockLocker,
+		LockURLGenerator:      mockURLGenerator{},
+		PolicyCheckStepRunner: mockPolicyCheck,
+		WorkingDir:            mockWorkingDir,
+		WorkingDirLocker:      events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Steps:      []valid.Step{{StepName: "policy_check"}},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	resultsFile := filepath.Join(repoDir, ctx.GetPolicyCheckResultFileName())
+	When(mockWorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+	}, nil)
+
+	expResults := []models.PolicySetResult{
+		{
+			PolicySetName: "policies",
+			Rules: []models.PolicyRuleResult{
+				{Namespace: "main", Name: "deny", Messages: []string{"no"}},
+			},
+		},
+	}
+	When(mockPolicyCheck.Run(ctx, nil, repoDir, map[string]string{})).Then(func(params []Param) ReturnValues {
+		serialized, err := json.Marshal(models.PolicyCheckResults{PolicySetResults: expResults})
+		Ok(t, err)
+		Ok(t, os.WriteFile(resultsFile, serialized, 0600))
+		return ReturnValues{"policy output", errors.New("1 policy check failures")}
+	})
+
+	// The results are reported when the policy check fails.
+	res := runner.PolicyCheck(ctx)
+	ErrContains(t, "1 policy check failures", res.Error)
+	Equals(t, expResults, res.PolicySetResults)
+
+	// Results from a previous policy check aren't reported again.
+	When(mockPolicyCheck.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("policy output", nil)
+	res = runner.PolicyCheck(ctx)
+	Ok(t, res.Error)
+	Equals(t, "policy output", res.PolicyCheckSuccess.PolicyCheckOutput)
+	Equals(t, []models.PolicySetResult(nil), res.PolicySetResults)
+	Equals(t, false, res.PolicyCheckSuccess.SoftFailed)
+
+	// Failures of warn only policy sets don't fail the policy check.
+	When(mockPolicyCheck.Run(ctx, nil, repoDir, map[string]string{})).Then(func(params []Param) ReturnValues {
+		serialized, err := json.Marshal(models.PolicyCheckResults{PolicySetResults: expResults, SoftFailed: true})
+		Ok(t, err)
+		Ok(t, os.WriteFile(resultsFile, serialized, 0600))
+		return ReturnValues{"policy output", nil}
+	})
+	res = runner.PolicyCheck(ctx)
+	Ok(t, res.Error)
+	Equals(t, true, res.PolicyCheckSuccess.SoftFailed)
+	Equals(t, models.NeutralCommitStatus, res.CommitStatus())
+}
+
+func TestDefaultProjectCommandRunner_ApprovePolicies(t *testing.T) {
+	policySets := valid.PolicySets{
+		Owners: valid.PolicyOwners{
+			Users: []string{"admin"},
+		},
+		PolicySets: []valid.PolicySet{
+			{
+				Name:         "security",
+				ApproveCount: 2,
+				Owners: valid.PolicyOwners{
+					Users: []string{"alice", "bob"},
+					Teams: []string{"security-team"},
+				},
+			},
+			{
+				Name:         "cost",
+				ApproveCount: 1,
+				Owners: valid.PolicyOwners{
+					Users: []string{"carol"},
+				},
+			},
+		},
+	}
+	bothFailed := []models.PolicySetStatus{
+		{PolicySetName: "security", Failures: 1},
+		{PolicySetName: "cost", Failures: 2},
+	}
+
+	cases := []struct {
+		description  string
+		user         string
+		userTeams    []string
+		hostType     models.VCSHostType
+		target       string
+		planStatus   models.ProjectPlanStatus
+		policyStatus []models.PolicySetStatus
+		approvals    []models.PolicySetApproval
+		expFailure   string
+		expApprovals []models.PolicySetApproval
+	}{
+		{
+			description:  "not an owner",
+			user:         "mallory",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			expFailure:   "contact policy owners to approve failing policies:\n  security: 0/2 approvals\n  cost: 0/1 approvals",
+		},
+		{
+			description:  "owner of one policy set",
+			user:         "carol",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			expFailure:   "policy sets still require approval from their owners:\n  security: 0/2 approvals",
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+			},
+		},
+		{
+			description:  "owner through team",
+			user:         "dave",
+			userTeams:    []string{"Security-Team"},
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			approvals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+				{PolicySetName: "security", Approvers: []string{"alice"}},
+			},
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+				{PolicySetName: "security", Approvers: []string{"alice", "dave"}},
+			},
+		},
+		{
+			description:  "same owner approving twice",
+			user:         "alice",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			approvals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+				{PolicySetName: "security", Approvers: []string{"alice"}},
+			},
+			expFailure: "policy sets still require approval from their owners:\n  security: 1/2 approvals",
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+				{PolicySetName: "security", Approvers: []string{"alice"}},
+			},
+		},
+		{
+			description:  "owner of all policy sets with target",
+			user:         "admin",
+			target:       "cost",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			expFailure:   "policy sets still require approval from their owners:\n  security: 0/2 approvals",
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"admin"}},
+			},
+		},
+		{
+			description:  "only failing policy sets need approval",
+			user:         "carol",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: []models.PolicySetStatus{{PolicySetName: "security", Passes: 1}, {PolicySetName: "cost", Failures: 1}},
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+			},
+		},
+		{
+			description:  "policy set without status",
+			user:         "carol",
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: []models.PolicySetStatus{{PolicySetName: "cost", Failures: 1}},
+			expFailure:   "policy sets still require approval from their owners:\n  security: 0/2 approvals",
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+			},
+		},
+		{
+			description:  "teams not supported",
+			user:         "dave",
+			userTeams:    []string{"security-team"},
+			hostType:     models.Gitlab,
+			planStatus:   models.ErroredPolicyCheckStatus,
+			policyStatus: bothFailed,
+			expFailure:   "contact policy owners to approve failing policies:\n  security: 0/2 approvals\n  cost: 0/1 approvals",
+		},
+		{
+			description: "no status per policy set",
+			user:        "carol",
+			planStatus:  models.ErroredPolicyCheckStatus,
+			expFailure:  "policy sets still require approval from their owners:\n  security: 0/2 approvals",
+			expApprovals: []models.PolicySetApproval{
+				{PolicySetName: "cost", Approvers: []string{"carol"}},
+			},
+		},
+		{
+			description: "policy check passed",
+			user:        "mallory",
+			planStatus:  models.PassedPolicyCheckStatus,
+		},
+		{
+			description: "unknown target",
+			user:        "admin",
+			target:      "unknown",
+			planStatus:  models.ErroredPolicyCheckStatus,
+			expFailure:  `policy set "unknown" is not configured`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			vcsClient := vcsmocks.NewMockClient()
+			runner := &events.DefaultProjectCommandRunner{
+				VcsClient: vcsClient,
+			}
+			ctx := command.ProjectContext{
+				Log:                    logging.NewNoopLogger(t),
+				User:                   models.User{Username: c.user},
+				Pull:                   models.PullRequest{BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: c.hostType}}},
+				PolicySets:             policySets,
+				PolicySetTarget:        c.target,
+				ProjectPlanStatus:      c.planStatus,
+				ProjectPolicyStatus:    c.policyStatus,
+				ProjectPolicyApprovals: c.approvals,
+			}
+			When(vcsClient.GetTeamNamesForUser(ctx.Pull.BaseRepo, ctx.User)).ThenReturn(c.userTeams, nil)
+
+			res := runner.ApprovePolicies(ctx)
+			Ok(t, res.Error)
+			Equals(t, c.expFailure, res.Failure)
+			Equals(t, c.expApprovals, res.PolicyApprovals)
+			if c.expFailure == "" {
+				Equals(t, models.PassedPolicyCheckStatus, res.PlanStatus())
+			} else {
+				Equals(t, models.ErroredPolicyCheckStatus, res.PlanStatus())
+			}
+		})
+	}
+}
+
 func TestDefaultProjectCommandRunner_ApplyNotCloned(t *testing.T) {
 	mockWorkingDir := mocks.NewMockWorkingDir()
 	runner := &events.DefaultProjectCommandRunner{
@@ -529,10 +792,148 @@ func TestDefaultProjectCommandRunner_ApplyRunStepFailure(t *testing.T) {
 	Assert(t, res.ApplySuccess == "", "exp apply failure")
 
 	mockApply.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
+
+	_, event := mockSender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetCapturedArguments()
+	Equals(t, webhooks.ApplyEvent, event.Type)
+	Equals(t, false, event.Success)
+	Equals(t, "something went wrong\napply", event.Error)
+}
+
+// Test that no apply webhook is sent if apply fails before running its steps.
+func TestDefaultProjectCommandRunner_ApplyNotClonedNoWebhook(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockSender := mocks.NewMockWebhooksSender()
+	runner := events.DefaultProjectCommandRunner{
+		WorkingDir: mockWorkingDir,
+		Webhooks:   mockSender,
+	}
+	When(mockWorkingDir.GetWorkingDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn("", os.ErrNotExist)
+
+	res := runner.Apply(command.ProjectContext{Log: logging.NewNoopLogger(t)})
+	ErrEquals(t, "project has not been cloned–did you run plan?", res.Error)
+	mockSender.VerifyWasCalled(Never()).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent())
 }
 
 // Test run and env steps. We don't use mocks for this test since we're
 // not running any Terraform.
+// Test that drift is detected from the exit code of the plan step.
+func TestDefaultProjectCommandRunner_DetectDrift(t *testing.T) {
+	exitErr := func(code int) error {
+		err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", code)).Run() // #nosec
+		return fmt.Errorf("running plan: %w", err)
+	}
+	cases := []struct {
+		description string
+		planErr     error
+		expDrifted  bool
+		expErr      bool
+	}{
+		{
+			description: "no changes",
+		},
+		{
+			description: "changes",
+			planErr:     exitErr(2),
+			expDrifted:  true,
+		},
+		{
+			description: "plan failed",
+			planErr:     exitErr(1),
+			expErr:      true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockInit := mocks.NewMockStepRunner()
+			mockPlan := mocks.NewMockStepRunner()
+			mockLocker := mocks.NewMockProjectLocker()
+			runner := events.DefaultProjectCommandRunner{
+				Locker:         mockLocker,
+				InitStepRunner: mockInit,
+				PlanStepRunner: mockPlan,
+			}
+			unlocked := false
+			When(mockLocker.TryLock(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsPullRequest(),
+				matchers.AnyModelsUser(),
+				AnyString(),
+				matchers.AnyModelsProject(),
+			)).ThenReturn(&events.TryLockResponse{
+				LockAcquired: true,
+				UnlockFn: func() error {
+					unlocked = true
+					return nil
+				},
+			}, nil)
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+
+			ctx := command.ProjectContext{
+				Log: logging.NewNoopLogger(t),
+				Steps: []valid.Step{
+					{StepName: "init"},
+					{StepName: "plan", ExtraArgs: []string{"-var", "a=b"}},
+				},
+				Workspace:  "default",
+				RepoRelDir: ".",
+			}
+			expEnvs := map[string]string{}
+			expPlanArgs := []string{"-var", "a=b", "-detailed-exitcode"}
+			When(mockInit.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("init", nil)
+			When(mockPlan.Run(ctx, expPlanArgs, repoDir, expEnvs)).ThenReturn("plan", c.planErr)
+
+			drifted, output, err := runner.DetectDrift(ctx, repoDir)
+			Equals(t, c.expDrifted, drifted)
+			Equals(t, "init\nplan", output)
+			Equals(t, c.expErr, err != nil)
+			mockPlan.VerifyWasCalledOnce().Run(ctx, expPlanArgs, repoDir, expEnvs)
+			// The project's steps must not be modified.
+			Equals(t, []string{"-var", "a=b"}, ctx.Steps[1].ExtraArgs)
+			Equals(t, true, unlocked)
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_DetectDriftLocked(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockLocker := mocks.NewMockProjectLocker()
+	runner := events.DefaultProjectCommandRunner{
+		Locker:         mockLocker,
+		PlanStepRunner: mockPlan,
+	}
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired:      false,
+		LockFailureReason: "locked",
+	}, nil)
+
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Steps:      []valid.Step{{StepName: "plan"}},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	_, _, err := runner.DetectDrift(ctx, repoDir)
+	Equals(t, events.ErrProjectLocked, err)
+	mockPlan.VerifyWasCalled(Never()).Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), AnyString(), matchers.AnyMapOfStringToString())
+}
+
 func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 	RegisterMockTestingT(t)
 	tfClient := tmocks.NewMockClient()
@@ -624,6 +1025,78 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 	Equals(t, "var=\n\nvar=value\n\ndynamic_var=dynamic_value\n\ndynamic_var=overridden\n", res.PlanSuccess.TerraformOutput)
 }
 
+// Test that projects of a distribution other than the default one run the
+// default version of their distribution.
+func TestDefaultProjectCommandRunner_DistributionDefaultVersion(t *testing.T) {
+	RegisterMockTestingT(t)
+	tfClient := tmocks.NewMockClient()
+	tfVersion, err := version.NewVersion("1.5.7")
+	Ok(t, err)
+	tofuVersion, err := version.NewVersion("1.6.2")
+	Ok(t, err)
+	run := runtime.RunStepRunner{
+		TerraformExecutor:       tfClient,
+		DefaultTFVersion:        tfVersion,
+		ProjectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		RunStepRunner:    &run,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		DefaultTFVersions: map[string]*version.Version{
+			"terraform": tfVersion,
+			"opentofu":  tofuVersion,
+		},
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+
+	ctx := command.ProjectContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			{
+				StepName:   "run",
+				RunCommand: "echo version=$ATLANTIS_TERRAFORM_VERSION",
+			},
+		},
+		Workspace:             "default",
+		RepoRelDir:            ".",
+		TerraformDistribution: "opentofu",
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, "version=1.6.2\n", res.PlanSuccess.TerraformOutput)
+	tfClient.VerifyWasCalledOnce().EnsureVersion(ctx.Log, "opentofu", tofuVersion)
+
+	// Without a default version for the distribution, the steps aren't run.
+	runner.DefaultTFVersions = map[string]*version.Version{"terraform": tfVersion}
+	res = runner.Plan(ctx)
+	ErrContains(t, "no default version of opentofu, set terraform_version for the project", res.Error)
+}
+
 type mockURLGenerator struct{}
 
 func (m mockURLGenerator) GenerateLockURL(lockID string) string {
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 
 	"github.com/moby/moby/pkg/fileutils"
@@ -38,13 +39,29 @@ type ProjectFinder interface {
 	// based on modifiedFiles and the repo's config.
 	// absRepoDir is the path to the cloned repo on disk.
 	DetermineProjectsViaConfig(log logging.SimpleLogging, modifiedFiles []string, config valid.RepoCfg, absRepoDir string) ([]valid.Project, error)
+	// NeedsRepoDir returns true if the modified projects can't be fully
+	// determined without absRepoDir, ex. because projects using modified
+	// modules are planned too.
+	NeedsRepoDir() bool
 }
 
 // ignoredFilenameFragments contains filename fragments to ignore while looking at changes
 var ignoredFilenameFragments = []string{"terraform.tfstate", "terraform.tfstate.backup", "tflint.hcl"}
 
 // DefaultProjectFinder implements ProjectFinder.
-type DefaultProjectFinder struct{}
+type DefaultProjectFinder struct {
+	// AutoplanModules is true if projects should also be planned when a local
+	// module they use, directly or through other modules, is modified.
+	AutoplanModules bool
+	// ModuleGraphs, if set, caches the module graphs of the repos so they're
+	// only built once per commit.
+	ModuleGraphs *ModuleGraphCache
+}
+
+// See ProjectFinder.NeedsRepoDir.
+func (p *DefaultProjectFinder) NeedsRepoDir() bool {
+	return p.AutoplanModules
+}
 
 // See ProjectFinder.DetermineProjects.
 func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modifiedFiles []string, repoFullName string, absRepoDir string, autoplanFileList string) []models.Project {
@@ -64,6 +81,15 @@ func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modi
 			dirs = append(dirs, projectDir)
 		}
 	}
+	if graph := p.moduleGraph(log, absRepoDir); graph != nil {
+		// Plan the root modules that use the modified modules.
+		for _, root := range graph.rootModules(log) {
+			if module := modifiedModule(graph.dependencies(log, root), modifiedTerraformFiles); module != "" {
+				log.Debug("dir %q uses modified module %q", root, module)
+				dirs = append(dirs, root)
+			}
+		}
+	}
 	uniqueDirs := p.unique(dirs)
 
 	// The list of modified files will include files that were deleted. We still
@@ -83,7 +109,10 @@ func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modi
 // See ProjectFinder.DetermineProjectsViaConfig.
 func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogging, modifiedFiles []string, config valid.RepoCfg, absRepoDir string) ([]valid.Project, error) {
 	var projects []valid.Project
-	for _, project := range config.Projects {
+	graph := p.moduleGraph(log, absRepoDir)
+	configProjects := append([]valid.Project{}, config.Projects...)
+	configProjects = append(configProjects, p.discoverProjects(log, config, absRepoDir)...)
+	for _, project := range configProjects {
 		log.Debug("checking if project at dir %q workspace %q was modified", project.Dir, project.Workspace)
 		var whenModifiedRelToRepoRoot []string
 		for _, wm := range project.Autoplan.WhenModified {
@@ -112,6 +141,7 @@ func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogg
 
 		// If any of the modified files matches the pattern then this project is
 		// considered modified.
+		modified := false
 		for _, file := range modifiedFiles {
 			match, err := pm.Matches(file)
 			if err != nil {
@@ -120,30 +150,114 @@ func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogg
 			}
 			if match {
 				log.Debug("file %q matched pattern", file)
-				// If we're checking using an atlantis.yaml file we downloaded
-				// directly from the repo (when doing a no-clone check) then
-				// absRepoDir will be empty. Since we didn't clone the repo
-				// yet we can't do this check. If there was a file modified
-				// in a deleted directory then when we finally do clone the repo
-				// we'll call this function again and then we'll detect the
-				// directory was deleted.
-				if absRepoDir != "" {
-					_, err := os.Stat(filepath.Join(absRepoDir, project.Dir))
-					if err == nil {
-						projects = append(projects, project)
-					} else {
-						log.Debug("project at dir %q not included because dir does not exist", project.Dir)
-					}
-				} else {
-					projects = append(projects, project)
-				}
+				modified = true
 				break
 			}
 		}
+		// The project is also modified if a module it uses was modified.
+		if !modified && graph != nil {
+			if module := modifiedModule(graph.dependencies(log, project.Dir), modifiedFiles); module != "" {
+				log.Debug("project at dir %q uses modified module %q", project.Dir, module)
+				modified = true
+			}
+		}
+		if !modified {
+			continue
+		}
+
+		// If we're checking using an atlantis.yaml file we downloaded
+		// directly from the repo (when doing a no-clone check) then
+		// absRepoDir will be empty. Since we didn't clone the repo
+		// yet we can't do this check. If there was a file modified
+		// in a deleted directory then when we finally do clone the repo
+		// we'll call this function again and then we'll detect the
+		// directory was deleted.
+		if absRepoDir != "" && !p.dirExists(absRepoDir, project.Dir) {
+			log.Debug("project at dir %q not included because dir does not exist", project.Dir)
+			continue
+		}
+		projects = append(projects, project)
 	}
 	return projects, nil
 }
 
+// moduleGraph returns the module graph of the repo at absRepoDir or nil if
+// we're not autoplanning modules or the graph can't be built.
+func (p *DefaultProjectFinder) moduleGraph(log logging.SimpleLogging, absRepoDir string) *moduleGraph {
+	if !p.AutoplanModules || absRepoDir == "" {
+		return nil
+	}
+	graph, err := p.repoGraph(absRepoDir)
+	if err != nil {
+		log.Warn("not planning projects that use modified modules, could not build module graph: %s", err)
+		return nil
+	}
+	return graph
+}
+
+// repoGraph returns the module graph of the repo at absRepoDir.
+func (p *DefaultProjectFinder) repoGraph(absRepoDir string) (*moduleGraph, error) {
+	cache := p.ModuleGraphs
+	if cache == nil {
+		cache = &ModuleGraphCache{}
+	}
+	return cache.get(absRepoDir)
+}
+
+// discoverProjects returns a project for each Terraform root module of the
+// repo at absRepoDir if config has autodiscover enabled. Root modules in a dir
+// that config already has projects for, or that match one of the
+// ignore_paths patterns, are skipped since the explicit config wins.
+func (p *DefaultProjectFinder) discoverProjects(log logging.SimpleLogging, config valid.RepoCfg, absRepoDir string) []valid.Project {
+	if !config.Autodiscover.Enabled || absRepoDir == "" {
+		return nil
+	}
+	graph, err := p.repoGraph(absRepoDir)
+	if err != nil {
+		log.Warn("not discovering projects, could not build module graph: %s", err)
+		return nil
+	}
+	ignore, err := fileutils.NewPatternMatcher(config.Autodiscover.IgnorePaths)
+	if err != nil {
+		log.Warn("not discovering projects, invalid ignore_paths %v: %s", config.Autodiscover.IgnorePaths, err)
+		return nil
+	}
+
+	var projects []valid.Project
+	for _, dir := range graph.rootModules(log) {
+		if len(config.FindProjectsByDir(dir)) > 0 {
+			continue
+		}
+		if match, err := ignore.Matches(dir); err != nil || match {
+			log.Debug("not discovering project at dir %q since it matches ignore_paths", dir)
+			continue
+		}
+		projects = append(projects, valid.Project{
+			Dir:       dir,
+			Workspace: DefaultWorkspace,
+			Autoplan: valid.Autoplan{
+				WhenModified: raw.DefaultAutoPlanWhenModified,
+				Enabled:      valid.DefaultAutoPlanEnabled,
+			},
+		})
+	}
+	log.Debug("discovered %d project(s) not in the config", len(projects))
+	return projects
+}
+
+// modifiedModule returns the first of moduleDirs that contains one of
+// modifiedFiles or an empty string if none do.
+func modifiedModule(moduleDirs []string, modifiedFiles []string) string {
+	for _, dir := range moduleDirs {
+		for _, file := range modifiedFiles {
+			if strings.HasPrefix(file, dir+"/") {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
 // filterToFileList filters out files not included in the file list
 func (p *DefaultProjectFinder) filterToFileList(log logging.SimpleLogging, files []string, fileList string) []string {
 	var filtered []string
@@ -260,10 +374,19 @@ func (p *DefaultProjectFinder) unique(strs []string) []string {
 func (p *DefaultProjectFinder) removeNonExistingDirs(relativePaths []string, absRepoDir string) []string {
 	var filtered []string
 	for _, pth := range relativePaths {
-		absPath := filepath.Join(absRepoDir, pth)
-		if _, err := os.Stat(absPath); !os.IsNotExist(err) {
+		if p.dirExists(absRepoDir, pth) {
 			filtered = append(filtered, pth)
 		}
 	}
 	return filtered
 }
+
+// dirExists returns true if dir, relative to absRepoDir, exists. With sparse
+// checkouts, dirs that aren't checked out exist if they're in the commit.
+func (p *DefaultProjectFinder) dirExists(absRepoDir string, dir string) bool {
+	if _, err := os.Stat(filepath.Join(absRepoDir, dir)); !os.IsNotExist(err) {
+		return true
+	}
+	_, err := gitOutput(absRepoDir, "cat-file", "-e", "HEAD:"+path.Clean(dir))
+	return err == nil
+}
@@ -16,6 +16,7 @@ package events_test
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/runatlantis/atlantis/server/core/config/valid"
@@ -514,3 +515,199 @@ func TestDefaultProjectFinder_DetermineProjectsViaConfig(t *testing.T) {
 		})
 	}
 }
+
+// initModulesRepo creates a git repo whose projects use local modules:
+// envs/prod uses modules/vpc, envs/dev uses modules/app which uses modules/vpc
+// and envs/staging only uses a registry module.
+func initModulesRepo(t *testing.T) (string, func()) {
+	repoDir, cleanup := DirStructure(t, map[string]interface{}{
+		"envs": map[string]interface{}{
+			"prod": map[string]interface{}{
+				"main.tf": nil,
+			},
+			"dev": map[string]interface{}{
+				"main.tf": nil,
+			},
+			"staging": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"modules": map[string]interface{}{
+			"app": map[string]interface{}{
+				"main.tf": nil,
+			},
+			"vpc": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+	})
+	files := map[string]string{
+		"envs/prod/main.tf":    `module "vpc" { source = "../../modules/vpc" }`,
+		"envs/dev/main.tf":     `module "app" { source = "../../modules/app" }`,
+		"envs/staging/main.tf": `module "vpc" { source = "terraform-aws-modules/vpc/aws" }`,
+		"modules/app/main.tf":  `module "vpc" { source = "../vpc" }`,
+		"modules/vpc/main.tf":  `resource "null_resource" "vpc" {}`,
+	}
+	for name, contents := range files {
+		Ok(t, os.WriteFile(filepath.Join(repoDir, name), []byte(contents), 0600))
+	}
+	runCmd(t, repoDir, "git", "init")
+	runCmd(t, repoDir, "git", "add", ".")
+	runCmd(t, repoDir, "git", "-c", "user.email=atlantisbot@runatlantis.io", "-c", "user.name=atlantisbot", "-c", "commit.gpgsign=false", "commit", "-m", "initial commit")
+	return repoDir, cleanup
+}
+
+func TestDefaultProjectFinder_DetermineProjectsViaConfig_Modules(t *testing.T) {
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+	var projects []valid.Project
+	for _, dir := range []string{"envs/dev", "envs/prod", "envs/staging"} {
+		projects = append(projects, valid.Project{
+			Dir: dir,
+			Autoplan: valid.Autoplan{
+				Enabled:      true,
+				WhenModified: []string{"**/*.tf*"},
+			},
+		})
+	}
+	config := valid.RepoCfg{Projects: projects}
+	logger := logging.NewNoopLogger(t)
+
+	cases := map[string]struct {
+		modified []string
+		exp      []string
+	}{
+		"direct dependency": {
+			modified: []string{"modules/app/main.tf"},
+			exp:      []string{"envs/dev"},
+		},
+		"transitive dependency": {
+			modified: []string{"modules/vpc/main.tf"},
+			exp:      []string{"envs/dev", "envs/prod"},
+		},
+		"project and module": {
+			modified: []string{"envs/staging/main.tf", "modules/app/main.tf"},
+			exp:      []string{"envs/dev", "envs/staging"},
+		},
+		"unused file": {
+			modified: []string{"README.md"},
+			exp:      nil,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			pf := &events.DefaultProjectFinder{AutoplanModules: true, ModuleGraphs: &events.ModuleGraphCache{}}
+			actProjs, err := pf.DetermineProjectsViaConfig(logger, c.modified, config, repoDir)
+			Ok(t, err)
+			var actDirs []string
+			for _, p := range actProjs {
+				actDirs = append(actDirs, p.Dir)
+			}
+			Equals(t, c.exp, actDirs)
+		})
+	}
+
+	// Without autoplanning modules, only when_modified is used.
+	actProjs, err := m.DetermineProjectsViaConfig(logger, []string{"modules/vpc/main.tf"}, config, repoDir)
+	Ok(t, err)
+	Equals(t, 0, len(actProjs))
+}
+
+// Test that the module graph is read from git so projects that aren't checked
+// out, like with sparse checkouts, are still found.
+func TestDefaultProjectFinder_DetermineProjectsViaConfig_ModulesNotCheckedOut(t *testing.T) {
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+	Ok(t, os.RemoveAll(filepath.Join(repoDir, "envs")))
+
+	pf := &events.DefaultProjectFinder{AutoplanModules: true}
+	actProjs, err := pf.DetermineProjectsViaConfig(logging.NewNoopLogger(t), []string{"modules/vpc/main.tf"}, valid.RepoCfg{
+		Projects: []valid.Project{{Dir: "envs/prod"}},
+	}, repoDir)
+	Ok(t, err)
+	Equals(t, 1, len(actProjs))
+	Equals(t, "envs/prod", actProjs[0].Dir)
+}
+
+func TestDefaultProjectFinder_DetermineProjects_Modules(t *testing.T) {
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+
+	pf := &events.DefaultProjectFinder{AutoplanModules: true, ModuleGraphs: &events.ModuleGraphCache{}}
+	actProjs := pf.DetermineProjects(logging.NewNoopLogger(t), []string{"modules/vpc/main.tf"}, modifiedRepo, repoDir, "**/*.tf")
+	var actPaths []string
+	for _, p := range actProjs {
+		actPaths = append(actPaths, p.Path)
+	}
+	sort.Strings(actPaths)
+	Equals(t, []string{"envs/dev", "envs/prod"}, actPaths)
+
+	// Without autoplanning modules, a change to a shared module isn't planned.
+	Equals(t, 0, len(m.DetermineProjects(logging.NewNoopLogger(t), []string{"modules/vpc/main.tf"}, modifiedRepo, repoDir, "**/*.tf")))
+}
+
+func TestDefaultProjectFinder_DetermineProjectsViaConfig_Autodiscover(t *testing.T) {
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+	config := valid.RepoCfg{
+		Projects: []valid.Project{
+			{
+				Dir:       "envs/prod",
+				Workspace: "prod",
+				Autoplan: valid.Autoplan{
+					Enabled:      true,
+					WhenModified: []string{"*.tf"},
+				},
+			},
+		},
+		Autodiscover: valid.Autodiscover{
+			Enabled:     true,
+			IgnorePaths: []string{"envs/staging"},
+		},
+	}
+	modified := []string{"envs/dev/main.tf", "envs/prod/main.tf", "envs/staging/main.tf", "modules/vpc/main.tf"}
+	logger := logging.NewNoopLogger(t)
+
+	// The modules aren't discovered since they're used by other dirs and the
+	// explicitly configured envs/prod project wins over the discovered one.
+	actProjs, err := m.DetermineProjectsViaConfig(logger, modified, config, repoDir)
+	Ok(t, err)
+	Equals(t, []valid.Project{
+		config.Projects[0],
+		{
+			Dir:       "envs/dev",
+			Workspace: events.DefaultWorkspace,
+			Autoplan: valid.Autoplan{
+				Enabled:      true,
+				WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+			},
+		},
+	}, actProjs)
+
+	// Without autodiscover, only the configured projects are planned.
+	config.Autodiscover.Enabled = false
+	actProjs, err = m.DetermineProjectsViaConfig(logger, modified, config, repoDir)
+	Ok(t, err)
+	Equals(t, config.Projects, actProjs)
+}
+
+// Test that a cached module graph still works after the clone it was built
+// from is deleted.
+func TestDefaultProjectFinder_DetermineProjectsViaConfig_ModulesCloneDeleted(t *testing.T) {
+	repoDir, cleanup := initModulesRepo(t)
+	defer cleanup()
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	runCmd(t, repoDir, "git", "clone", repoDir, cloneDir)
+
+	pf := &events.DefaultProjectFinder{AutoplanModules: true, ModuleGraphs: &events.ModuleGraphCache{}}
+	config := valid.RepoCfg{Projects: []valid.Project{{Dir: "envs/prod"}}}
+	actProjs, err := pf.DetermineProjectsViaConfig(logging.NewNoopLogger(t), []string{"modules/vpc/main.tf"}, config, cloneDir)
+	Ok(t, err)
+	Equals(t, 1, len(actProjs))
+	Ok(t, os.RemoveAll(cloneDir))
+
+	actProjs, err = pf.DetermineProjectsViaConfig(logging.NewNoopLogger(t), []string{"modules/vpc/main.tf"}, config, repoDir)
+	Ok(t, err)
+	Equals(t, 1, len(actProjs))
+	Equals(t, "envs/prod", actProjs[0].Dir)
+}
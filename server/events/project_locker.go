@@ -19,6 +19,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -40,6 +41,14 @@ type ProjectLocker interface {
 type DefaultProjectLocker struct {
 	Locker    locking.Locker
 	VCSClient vcs.Client
+	// Queue, if set, queues pull requests that fail to acquire a lock so that
+	// they're re-planned once the lock is released.
+	Queue locking.LockQueue
+	// LockQueueProcessor, if set, hands locks released by UnlockFn to the
+	// next queued pull request.
+	LockQueueProcessor LockQueueProcessor
+	// Webhooks, if set, sends webhooks when locks are acquired or released.
+	Webhooks WebhooksSender
 }
 
 // TryLockResponse is the result of trying to lock a project.
@@ -63,11 +72,34 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 	if err != nil {
 		return nil, err
 	}
-	if !lockAttempt.LockAcquired && lockAttempt.CurrLock.Pull.Num != pull.Num {
+	if !lockAttempt.LockAcquired && !lockHeldBy(lockAttempt.CurrLock, pull, user) {
+		event := lockWebhookEvent(webhooks.LockEvent, pull, user, workspace, project)
+		event.Success = false
+		event.Error = fmt.Sprintf("locked by pull request %d", lockAttempt.CurrLock.Pull.Num)
+		sendWebhook(p.Webhooks, log, event)
+
 		link, err := p.VCSClient.MarkdownPullLink(lockAttempt.CurrLock.Pull)
 		if err != nil {
 			return nil, err
 		}
+		// Drift detection locks projects on the default branch without a pull
+		// request so it can't wait in the queue and is just told the project
+		// is locked.
+		if p.Queue != nil && pull.Num != 0 {
+			position, err := p.Queue.Enqueue(project, workspace, pull, user)
+			if err != nil {
+				return nil, err
+			}
+			log.Info("queued for lock held by pull %d at position %d", lockAttempt.CurrLock.Pull.Num, position)
+			failureMsg := fmt.Sprintf(
+				"This project is currently locked by an unapplied plan from pull %s. This pull request is number %d in the queue for the lock and will be re-planned automatically once the lock is released.",
+				link,
+				position)
+			return &TryLockResponse{
+				LockAcquired:      false,
+				LockFailureReason: failureMsg,
+			}, nil
+		}
 		failureMsg := fmt.Sprintf(
 			"This project is currently locked by an unapplied plan from pull %s. To continue, delete the lock from %s or apply that plan and merge the pull request.\n\nOnce the lock is released, comment `atlantis plan` here to re-plan.",
 			link,
@@ -78,12 +110,63 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 		}, nil
 	}
 	log.Info("acquired lock with id %q", lockAttempt.LockKey)
+	// The lock is only acquired the first time the pull request locks the
+	// project. We don't send webhooks when it locks it again.
+	if lockAttempt.LockAcquired {
+		sendWebhook(p.Webhooks, log, lockWebhookEvent(webhooks.LockEvent, pull, user, workspace, project))
+	}
+	if p.Queue != nil {
+		// The pull request may have been queued for this lock before it was
+		// able to acquire it.
+		if err := p.Queue.Dequeue(project, workspace, pull.Num); err != nil {
+			log.Warn("removing pull from lock queue: %s", err)
+		}
+	}
 	return &TryLockResponse{
 		LockAcquired: true,
 		UnlockFn: func() error {
-			_, err := p.Locker.Unlock(lockAttempt.LockKey)
+			lock, err := p.Locker.Unlock(lockAttempt.LockKey)
+			if err == nil && lock != nil {
+				sendWebhook(p.Webhooks, log, lockWebhookEvent(webhooks.UnlockEvent, pull, user, workspace, project))
+				if p.LockQueueProcessor != nil {
+					p.LockQueueProcessor.ProcessReleasedLocks([]models.ProjectLock{*lock})
+				}
+			}
 			return err
 		},
 		LockKey: lockAttempt.LockKey,
 	}, nil
 }
+
+// lockHeldBy returns true if lock is held by pull and user. The locks of pull
+// requests are held by the pull request, whoever locked it. Drift detection
+// and API requests that aren't for a pull request lock with pull number 0 so
+// their locks are told apart by their user.
+func lockHeldBy(lock models.ProjectLock, pull models.PullRequest, user models.User) bool {
+	if lock.Pull.Num != pull.Num {
+		return false
+	}
+	return pull.Num != 0 || lock.User.Username == user.Username
+}
+
+// SendUnlockWebhooks sends an unlock webhook with sender for each of the
+// released locks if sender is set.
+func SendUnlockWebhooks(sender WebhooksSender, log logging.SimpleLogging, locks []models.ProjectLock) {
+	for _, lock := range locks {
+		sendWebhook(sender, log, lockWebhookEvent(webhooks.UnlockEvent, lock.Pull, lock.User, lock.Workspace, lock.Project))
+	}
+}
+
+// lockWebhookEvent returns a successful event of type eventType for the lock
+// of project and workspace held by pull.
+func lockWebhookEvent(eventType string, pull models.PullRequest, user models.User, workspace string, project models.Project) webhooks.Event {
+	return webhooks.Event{
+		Type:      eventType,
+		Workspace: workspace,
+		Repo:      pull.BaseRepo,
+		Pull:      pull,
+		User:      user,
+		Success:   true,
+		Directory: project.Path,
+	}
+}
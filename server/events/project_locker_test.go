@@ -21,15 +21,18 @@ import (
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
+	eventmocks "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
 func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -65,7 +68,7 @@ func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -101,10 +104,42 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 	mockLocker.VerifyWasCalledOnce().Unlock(lockKey)
 }
 
+func TestDefaultProjectLocker_TryLockWhenLockedWithoutPullByOtherUser(t *testing.T) {
+	t.Log("locks without a pull request, ex. of drift detection and API requests, should only be shared by the same user")
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockLocker := mocks.NewMockLocker()
+	locker := events.DefaultProjectLocker{
+		Locker:    mockLocker,
+		VCSClient: mockClient,
+	}
+	expProject := models.Project{}
+	expWorkspace := "default"
+	expPull := models.PullRequest{}
+	expUser := models.User{Username: "atlantis-drift-detection"}
+
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: false,
+			CurrLock: models.ProjectLock{
+				Pull: models.PullRequest{},
+				User: models.User{Username: "atlantis-api"},
+			},
+			LockKey: "key",
+		},
+		nil,
+	)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject)
+	Ok(t, err)
+	Equals(t, false, res.LockAcquired)
+	Assert(t, res.UnlockFn == nil, "exp no unlock func for a lock held by another user")
+}
+
 func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -139,3 +174,166 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	Ok(t, err)
 	mockLocker.VerifyWasCalledOnce().Unlock(lockKey)
 }
+
+func TestDefaultProjectLocker_TryLockWhenLockedQueues(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockLocker := mocks.NewMockLocker()
+	mockQueue := mocks.NewMockLockQueue()
+	locker := events.DefaultProjectLocker{
+		Locker:    mockLocker,
+		VCSClient: mockClient,
+		Queue:     mockQueue,
+	}
+	expProject := models.Project{}
+	expWorkspace := "default"
+	expPull := models.PullRequest{Num: 1}
+	expUser := models.User{}
+
+	lockingPull := models.PullRequest{
+		Num: 2,
+	}
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: false,
+			CurrLock: models.ProjectLock{
+				Pull: lockingPull,
+			},
+		},
+		nil,
+	)
+	When(mockQueue.Enqueue(expProject, expWorkspace, expPull, expUser)).ThenReturn(3, nil)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject)
+	link, _ := mockClient.MarkdownPullLink(lockingPull)
+	Ok(t, err)
+	Equals(t, &events.TryLockResponse{
+		LockAcquired:      false,
+		LockFailureReason: fmt.Sprintf("This project is currently locked by an unapplied plan from pull %s. This pull request is number 3 in the queue for the lock and will be re-planned automatically once the lock is released.", link),
+	}, res)
+	mockQueue.VerifyWasCalledOnce().Enqueue(expProject, expWorkspace, expPull, expUser)
+}
+
+func TestDefaultProjectLocker_TryLockUnlockedWithQueue(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockLocker := mocks.NewMockLocker()
+	mockQueue := mocks.NewMockLockQueue()
+	mockProcessor := eventmocks.NewMockLockQueueProcessor()
+	locker := events.DefaultProjectLocker{
+		Locker:             mockLocker,
+		VCSClient:          mockClient,
+		Queue:              mockQueue,
+		LockQueueProcessor: mockProcessor,
+	}
+	expProject := models.Project{}
+	expWorkspace := "default"
+	expPull := models.PullRequest{Num: 2}
+	expUser := models.User{}
+
+	lockKey := "key"
+	lock := models.ProjectLock{
+		Pull:      expPull,
+		Workspace: expWorkspace,
+	}
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: true,
+			CurrLock:     lock,
+			LockKey:      lockKey,
+		},
+		nil,
+	)
+	When(mockLocker.Unlock(lockKey)).ThenReturn(&lock, nil)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject)
+	Ok(t, err)
+	Equals(t, true, res.LockAcquired)
+	mockQueue.VerifyWasCalledOnce().Dequeue(expProject, expWorkspace, expPull.Num)
+
+	// Unlocking should hand the lock to the next queued pull request.
+	err = res.UnlockFn()
+	Ok(t, err)
+	mockProcessor.VerifyWasCalledOnce().ProcessReleasedLocks([]models.ProjectLock{lock})
+}
+
+func TestDefaultProjectLocker_TryLockSendsWebhooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockLocker := mocks.NewMockLocker()
+	mockSender := eventmocks.NewMockWebhooksSender()
+	locker := events.DefaultProjectLocker{
+		Locker:    mockLocker,
+		VCSClient: mockClient,
+		Webhooks:  mockSender,
+	}
+	expProject := models.NewProject("owner/repo", "dir")
+	expWorkspace := "default"
+	expPull := models.PullRequest{Num: 2}
+	expUser := models.User{Username: "user"}
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: true,
+			CurrLock:     models.ProjectLock{Pull: expPull},
+			LockKey:      "key",
+		},
+		nil,
+	)
+	When(mockLocker.Unlock("key")).ThenReturn(&models.ProjectLock{Pull: expPull}, nil)
+
+	log := logging.NewNoopLogger(t)
+	res, err := locker.TryLock(log, expPull, expUser, expWorkspace, expProject)
+	Ok(t, err)
+	Ok(t, res.UnlockFn())
+
+	_, events := mockSender.VerifyWasCalled(Twice()).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, []webhooks.Event{
+		{
+			Type:      webhooks.LockEvent,
+			Workspace: expWorkspace,
+			Pull:      expPull,
+			User:      expUser,
+			Success:   true,
+			Directory: "dir",
+		},
+		{
+			Type:      webhooks.UnlockEvent,
+			Workspace: expWorkspace,
+			Pull:      expPull,
+			User:      expUser,
+			Success:   true,
+			Directory: "dir",
+		},
+	}, events)
+}
+
+func TestDefaultProjectLocker_TryLockWhenLockedSendsWebhook(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockLocker := mocks.NewMockLocker()
+	mockSender := eventmocks.NewMockWebhooksSender()
+	locker := events.DefaultProjectLocker{
+		Locker:    mockLocker,
+		VCSClient: mockClient,
+		Webhooks:  mockSender,
+	}
+	expProject := models.NewProject("owner/repo", "dir")
+	expPull := models.PullRequest{Num: 1}
+	When(mockLocker.TryLock(expProject, "default", expPull, models.User{})).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: false,
+			CurrLock:     models.ProjectLock{Pull: models.PullRequest{Num: 2}},
+		},
+		nil,
+	)
+
+	_, err := locker.TryLock(logging.NewNoopLogger(t), expPull, models.User{}, "default", expProject)
+	Ok(t, err)
+
+	_, event := mockSender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetCapturedArguments()
+	Equals(t, webhooks.LockEvent, event.Type)
+	Equals(t, false, event.Success)
+	Equals(t, "locked by pull request 2", event.Error)
+}
@@ -21,14 +21,13 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/runatlantis/atlantis/server/core/db"
-
 	"github.com/runatlantis/atlantis/server/logging"
 
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/jobs"
 )
 
@@ -54,9 +53,15 @@ type PullClosedExecutor struct {
 	VCSClient                vcs.Client
 	WorkingDir               WorkingDir
 	Logger                   logging.SimpleLogging
-	DB                       *db.BoltDB
+	DB                       locking.Backend
 	PullClosedTemplate       PullCleanupTemplate
 	LogStreamResourceCleaner ResourceCleaner
+	// LockQueueProcessor, if set, removes the pull request from the lock
+	// queues and hands its locks to the pull requests queued for them.
+	LockQueueProcessor LockQueueProcessor
+	// Webhooks, if set, sends a webhook for each released lock and once the
+	// pull request is cleaned up.
+	Webhooks WebhooksSender
 }
 
 type templatedProject struct {
@@ -81,6 +86,21 @@ func (t *PullClosedEventTemplate) Execute(wr io.Writer, data interface{}) error
 
 // CleanUpPull cleans up after a closed pull request.
 func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullRequest) error {
+	err := p.cleanUpPull(repo, pull)
+	event := webhooks.Event{
+		Type:    webhooks.PullClosedEvent,
+		Repo:    repo,
+		Pull:    pull,
+		Success: err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	sendWebhook(p.Webhooks, p.Logger, event)
+	return err
+}
+
+func (p *PullClosedExecutor) cleanUpPull(repo models.Repo, pull models.PullRequest) error {
 	pullStatus, err := p.DB.GetPullStatus(pull)
 	if err != nil {
 		// Log and continue to clean up other resources.
@@ -103,13 +123,26 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 		return errors.Wrap(err, "cleaning workspace")
 	}
 
+	// The pull request won't need the locks it's queued for anymore. We
+	// remove it from the queues before releasing its own locks so they
+	// can't be handed back to it.
+	if p.LockQueueProcessor != nil {
+		if err := p.LockQueueProcessor.RemovePull(repo.ID(), pull.Num); err != nil {
+			p.Logger.Err("removing pull from lock queues: %s", err)
+		}
+	}
+
 	// Finally, delete locks. We do this last because when someone
 	// unlocks a project, right now we don't actually delete the plan
 	// so we might have plans laying around but no locks.
-	locks, err := p.Locker.UnlockByPull(repo.FullName, pull.Num)
+	locks, err := p.Locker.UnlockByPull(repo.ID(), pull.Num)
 	if err != nil {
 		return errors.Wrap(err, "cleaning up locks")
 	}
+	SendUnlockWebhooks(p.Webhooks, p.Logger, locks)
+	if p.LockQueueProcessor != nil {
+		p.LockQueueProcessor.ProcessReleasedLocks(locks)
+	}
 
 	// Delete pull from DB.
 	if err := p.DB.DeletePullStatus(pull); err != nil {
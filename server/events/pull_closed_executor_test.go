@@ -32,6 +32,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
 	loggermocks "github.com/runatlantis/atlantis/server/logging/mocks"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -71,7 +73,7 @@ func TestCleanUpPullUnlockErr(t *testing.T) {
 		PullClosedTemplate: &events.PullClosedEventTemplate{},
 	}
 	err = errors.New("err")
-	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, err)
+	When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(nil, err)
 	actualErr := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
 	Equals(t, "cleaning up locks: err", actualErr.Error())
 }
@@ -92,12 +94,115 @@ func TestCleanUpPullNoLocks(t *testing.T) {
 		WorkingDir: w,
 		DB:         db,
 	}
-	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
+	When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(nil, nil)
 	err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
 	Ok(t, err)
 	cp.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
 }
 
+func TestCleanUpPullLockQueue(t *testing.T) {
+	t.Log("the pull should be removed from the lock queues and its locks handed to the queued pulls")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	cp := vcsmocks.NewMockClient()
+	processor := mocks.NewMockLockQueueProcessor()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:             l,
+		VCSClient:          cp,
+		WorkingDir:         w,
+		DB:                 db,
+		LockQueueProcessor: processor,
+	}
+	locks := []models.ProjectLock{
+		{
+			Project:   models.NewProject(fixtures.GithubRepo.FullName, "dir"),
+			Workspace: "default",
+		},
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(locks, nil)
+	err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	inOrder := new(InOrderContext)
+	processor.VerifyWasCalledInOrder(Once(), inOrder).RemovePull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)
+	processor.VerifyWasCalledInOrder(Once(), inOrder).ProcessReleasedLocks(locks)
+}
+
+func TestCleanUpPullSendsWebhook(t *testing.T) {
+	t.Log("a webhook should be sent once the pull has been cleaned up")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	sender := mocks.NewMockWebhooksSender()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:     l,
+		WorkingDir: w,
+		DB:         db,
+		Logger:     logging.NewNoopLogger(t),
+		Webhooks:   sender,
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(nil, errors.New("err"))
+	Assert(t, pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull) != nil, "expected error")
+
+	_, event := sender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetCapturedArguments()
+	Equals(t, webhooks.Event{
+		Type:  webhooks.PullClosedEvent,
+		Repo:  fixtures.GithubRepo,
+		Pull:  fixtures.Pull,
+		Error: "cleaning up locks: err",
+	}, event)
+}
+
+func TestCleanUpPullSendsUnlockWebhooks(t *testing.T) {
+	t.Log("an unlock webhook should be sent for each released lock")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	client := vcsmocks.NewMockClient()
+	sender := mocks.NewMockWebhooksSender()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:     l,
+		WorkingDir: w,
+		VCSClient:  client,
+		DB:         db,
+		Logger:     logging.NewNoopLogger(t),
+		Webhooks:   sender,
+	}
+	locks := []models.ProjectLock{
+		{
+			Project:   models.NewProject(fixtures.GithubRepo.FullName, "dir1"),
+			Workspace: "default",
+			Pull:      fixtures.Pull,
+		},
+		{
+			Project:   models.NewProject(fixtures.GithubRepo.FullName, "dir2"),
+			Workspace: "default",
+			Pull:      fixtures.Pull,
+		},
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(locks, nil)
+	Ok(t, pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull))
+
+	_, sent := sender.VerifyWasCalled(Times(3)).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, webhooks.UnlockEvent, sent[0].Type)
+	Equals(t, "dir1", sent[0].Directory)
+	Equals(t, webhooks.UnlockEvent, sent[1].Type)
+	Equals(t, "dir2", sent[1].Directory)
+	Equals(t, webhooks.PullClosedEvent, sent[2].Type)
+}
+
 func TestCleanUpPullComments(t *testing.T) {
 	t.Log("should comment correctly")
 	RegisterMockTestingT(t)
@@ -179,7 +284,7 @@ func TestCleanUpPullComments(t *testing.T) {
 				DB:         db,
 			}
 			t.Log("testing: " + c.Description)
-			When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(c.Locks, nil)
+			When(l.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(c.Locks, nil)
 			err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
 			Ok(t, err)
 			_, _, comment, _ := cp.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString()).GetCapturedArguments()
@@ -267,7 +372,7 @@ func TestCleanUpLogStreaming(t *testing.T) {
 				Workspace: "default",
 			},
 		}
-		When(locker.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(locks, nil)
+		When(locker.UnlockByPull(fixtures.GithubRepo.ID(), fixtures.Pull.Num)).ThenReturn(locks, nil)
 
 		// Clean up.
 		err = pullClosedExecutor.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
@@ -1,6 +1,8 @@
 package events
 
 import (
+	"fmt"
+
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 )
@@ -9,9 +11,17 @@ type PullUpdater struct {
 	HidePrevPlanComments bool
 	VCSClient            vcs.Client
 	MarkdownRenderer     *MarkdownRenderer
+	// PlanDiscussionPoster, if set, posts the plan of each project in its own
+	// discussion if the VCS host supports it, and resolves it once the
+	// project is applied.
+	PlanDiscussionPoster vcs.PlanDiscussionPoster
 }
 
 func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res command.Result) {
+	if ctx.APIResults != nil {
+		*ctx.APIResults = append(*ctx.APIResults, res)
+	}
+
 	// Log if we got any errors or failures.
 	if res.Error != nil {
 		ctx.Log.Err(res.Error.Error())
@@ -28,8 +38,49 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 		}
 	}
 
+	// Errors that aren't specific to a project are still commented.
+	if cmd.CommandName() == command.Plan && res.Error == nil && res.Failure == "" && len(res.ProjectResults) > 0 &&
+		c.PlanDiscussionPoster != nil && c.PlanDiscussionPoster.PostsPlanDiscussions(ctx.Pull.BaseRepo) {
+		c.postPlanDiscussions(ctx, cmd, res)
+		return
+	}
+
 	comment := c.MarkdownRenderer.Render(res, cmd.CommandName(), ctx.Log.GetHistory(), cmd.IsVerbose(), ctx.Pull.BaseRepo.VCSHost.Type)
 	if err := c.VCSClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, comment, cmd.CommandName().String()); err != nil {
 		ctx.Log.Err("unable to comment: %s", err)
 	}
 }
+
+// postPlanDiscussions posts the plan of each project in res in the project's
+// plan discussion so that planning some of the projects again doesn't replace
+// the plans of the others.
+func (c *PullUpdater) postPlanDiscussions(ctx *command.Context, cmd PullCommand, res command.Result) {
+	for _, projectResult := range res.ProjectResults {
+		comment := c.MarkdownRenderer.Render(command.Result{ProjectResults: []command.ProjectResult{projectResult}}, cmd.CommandName(), ctx.Log.GetHistory(), cmd.IsVerbose(), ctx.Pull.BaseRepo.VCSHost.Type)
+		if err := c.PlanDiscussionPoster.CreateOrUpdatePlanDiscussion(ctx.Pull.BaseRepo, ctx.Pull.Num, planDiscussionKey(projectResult), comment); err != nil {
+			ctx.Log.Err("unable to post plan discussion: %s", err)
+		}
+	}
+}
+
+// resolvePlanDiscussions resolves the plan discussions of the projects that
+// were applied successfully.
+func (c *PullUpdater) resolvePlanDiscussions(ctx *command.Context, results []command.ProjectResult) {
+	if c.PlanDiscussionPoster == nil {
+		return
+	}
+	for _, projectResult := range results {
+		if projectResult.ApplySuccess == "" {
+			continue
+		}
+		if err := c.PlanDiscussionPoster.ResolvePlanDiscussion(ctx.Pull.BaseRepo, ctx.Pull.Num, planDiscussionKey(projectResult)); err != nil {
+			ctx.Log.Err("unable to resolve plan discussion: %s", err)
+		}
+	}
+}
+
+// planDiscussionKey returns the key of the plan discussion of the project of
+// result.
+func planDiscussionKey(result command.ProjectResult) string {
+	return fmt.Sprintf("dir: %s workspace: %s project: %s", result.RepoRelDir, result.Workspace, result.ProjectName)
+}
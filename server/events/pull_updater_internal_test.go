@@ -0,0 +1,82 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakePlanDiscussionPoster records the plan discussions it's asked to post
+// and resolve.
+type fakePlanDiscussionPoster struct {
+	posted   map[string]string
+	resolved []string
+}
+
+func (f *fakePlanDiscussionPoster) PostsPlanDiscussions(models.Repo) bool {
+	return true
+}
+
+func (f *fakePlanDiscussionPoster) CreateOrUpdatePlanDiscussion(_ models.Repo, _ int, key string, comment string) error {
+	f.posted[key] = comment
+	return nil
+}
+
+func (f *fakePlanDiscussionPoster) ResolvePlanDiscussion(_ models.Repo, _ int, key string) error {
+	f.resolved = append(f.resolved, key)
+	return nil
+}
+
+func TestPullUpdater_PlanDiscussions(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	poster := &fakePlanDiscussionPoster{posted: make(map[string]string)}
+	updater := &PullUpdater{
+		VCSClient:            vcsClient,
+		MarkdownRenderer:     &MarkdownRenderer{},
+		PlanDiscussionPoster: poster,
+	}
+	ctx := &command.Context{Log: logging.NewNoopLogger(t)}
+	results := []command.ProjectResult{
+		{
+			Command:     command.Plan,
+			RepoRelDir:  "dir1",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "plan1"},
+		},
+		{
+			Command:     command.Plan,
+			RepoRelDir:  "dir2",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "plan2"},
+		},
+	}
+
+	t.Log("each project's plan should be posted in its own discussion")
+	updater.updatePull(ctx, &CommentCommand{Name: command.Plan}, command.Result{ProjectResults: results})
+	Equals(t, 2, len(poster.posted))
+	Assert(t, strings.Contains(poster.posted["dir: dir1 workspace: default project: "], "plan1"), "exp dir1 discussion to contain its plan")
+	Assert(t, !strings.Contains(poster.posted["dir: dir1 workspace: default project: "], "plan2"), "exp dir1 discussion not to contain the plan of dir2")
+	Assert(t, strings.Contains(poster.posted["dir: dir2 workspace: default project: "], "plan2"), "exp dir2 discussion to contain its plan")
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+
+	t.Log("errors that aren't specific to a project should be commented")
+	updater.updatePull(ctx, &CommentCommand{Name: command.Plan}, command.Result{Failure: "failure"})
+	Equals(t, 2, len(poster.posted))
+	vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+
+	t.Log("only the discussions of applied projects should be resolved")
+	updater.resolvePlanDiscussions(ctx, []command.ProjectResult{
+		{Command: command.Apply, RepoRelDir: "dir1", Workspace: "default", ApplySuccess: "applied"},
+		{Command: command.Apply, RepoRelDir: "dir2", Workspace: "default", Error: errors.New("apply failed")},
+	})
+	Equals(t, []string{"dir: dir1 workspace: default project: "}, poster.resolved)
+}
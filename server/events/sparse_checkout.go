@@ -0,0 +1,117 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// globChars are the characters that start a pattern in a path.
+const globChars = "*?[\\"
+
+// modifiedDirs returns the dirs that contain modifiedFiles. Files in the root
+// of the repo are always checked out so the root isn't included.
+func modifiedDirs(modifiedFiles []string) []string {
+	dirs := make(map[string]bool)
+	for _, file := range modifiedFiles {
+		if dir := filepath.Dir(file); dir != "." {
+			dirs[dir] = true
+		}
+	}
+	return sortedKeys(dirs)
+}
+
+// projectCheckoutDirs returns the dirs that need to be checked out to run
+// commands for project: its dir and the dirs its when_modified patterns point
+// to, ex. the dir of shared modules. It returns nil if the whole repo needs to
+// be checked out.
+func projectCheckoutDirs(project valid.Project) []string {
+	dirs := map[string]bool{filepath.Clean(project.Dir): true}
+	for _, wm := range project.Autoplan.WhenModified {
+		wm = strings.TrimSpace(wm)
+		// Exclusions don't add any files.
+		if wm == "" || wm[0] == '!' {
+			continue
+		}
+		dir := patternDir(filepath.Join(project.Dir, wm))
+		// Patterns outside the repo can't match any of its files.
+		if dir == ".." || strings.HasPrefix(dir, "../") {
+			continue
+		}
+		dirs[dir] = true
+	}
+	if dirs["."] {
+		return nil
+	}
+	return sortedKeys(dirs)
+}
+
+// patternDir returns the dir that contains all the files matching pattern:
+// the part of pattern before the first element with a glob character. If
+// there is none, pattern is a file and its dir is returned.
+func patternDir(pattern string) string {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, elem := range elems {
+		if strings.ContainsAny(elem, globChars) {
+			return filepath.Join(append([]string{"."}, elems[:i]...)...)
+		}
+	}
+	return filepath.Dir(pattern)
+}
+
+// withModuleDirs adds the dirs of the local modules that the project in dir
+// uses, directly or through other modules, to dirs since they're needed to
+// init the project. If the modules can't be determined, it returns nil so the
+// whole repo is checked out.
+func (p *DefaultProjectCommandBuilder) withModuleDirs(log logging.SimpleLogging, absRepoDir string, dir string, dirs []string) []string {
+	if dirs == nil {
+		return nil
+	}
+	// If the repo isn't a git clone, it can't be a sparse checkout.
+	if _, err := os.Stat(filepath.Join(absRepoDir, ".git")); os.IsNotExist(err) {
+		return dirs
+	}
+	graphs := p.ModuleGraphs
+	if graphs == nil {
+		graphs = &ModuleGraphCache{}
+	}
+	graph, err := graphs.get(absRepoDir)
+	if err != nil {
+		log.Warn("checking out the whole repo for project at dir %q, could not build module graph: %s", dir, err)
+		return nil
+	}
+	deps := graph.dependencies(log, dir)
+	if len(deps) == 0 {
+		return dirs
+	}
+	all := make(map[string]bool)
+	for _, d := range dirs {
+		all[d] = true
+	}
+	for _, d := range deps {
+		all[d] = true
+	}
+	return sortedKeys(all)
+}
+
+// withCheckoutDirs sets the dirs to check out for projCtxs to dirs.
+func withCheckoutDirs(projCtxs []command.ProjectContext, dirs []string) []command.ProjectContext {
+	for i := range projCtxs {
+		projCtxs[i].CheckoutDirs = dirs
+	}
+	return projCtxs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestModifiedDirs(t *testing.T) {
+	Equals(t, []string{"modules/vpc", "project1"}, modifiedDirs([]string{
+		"project1/main.tf",
+		"project1/variables.tf",
+		"modules/vpc/main.tf",
+		"README.md",
+	}))
+	Equals(t, []string{}, modifiedDirs(nil))
+}
+
+func TestProjectCheckoutDirs(t *testing.T) {
+	cases := map[string]struct {
+		dir          string
+		whenModified []string
+		exp          []string
+	}{
+		"default patterns": {
+			dir:          "envs/prod",
+			whenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+			exp:          []string{"envs/prod"},
+		},
+		"shared modules": {
+			dir:          "envs/prod",
+			whenModified: []string{"*.tf", "../../modules/**/*.tf", "!../../modules/README.md"},
+			exp:          []string{"envs/prod", "modules"},
+		},
+		"file pattern": {
+			dir:          "envs/prod",
+			whenModified: []string{"../common/versions.tf"},
+			exp:          []string{"envs/common", "envs/prod"},
+		},
+		"pattern outside repo": {
+			dir:          "project1",
+			whenModified: []string{"../../other/*.tf"},
+			exp:          []string{"project1"},
+		},
+		"root project": {
+			dir:          ".",
+			whenModified: []string{"**/*.tf*"},
+			exp:          nil,
+		},
+		"pattern covering repo": {
+			dir:          "project1",
+			whenModified: []string{"../**/*.tf"},
+			exp:          nil,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			Equals(t, c.exp, projectCheckoutDirs(valid.Project{
+				Dir:      c.dir,
+				Autoplan: valid.Autoplan{WhenModified: c.whenModified},
+			}))
+		})
+	}
+}
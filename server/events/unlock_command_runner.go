@@ -33,7 +33,7 @@ func (u *UnlockCommandRunner) Run(
 	pullNum := ctx.Pull.Num
 
 	vcsMessage := "All Atlantis locks for this PR have been unlocked and plans discarded"
-	numLocks, err := u.deleteLockCommand.DeleteLocksByPull(baseRepo.FullName, pullNum)
+	numLocks, err := u.deleteLockCommand.DeleteLocksByPull(baseRepo.ID(), pullNum)
 	if err != nil {
 		vcsMessage = "Failed to delete PR locks"
 		ctx.Log.Err("failed to delete locks by pull %s", err.Error())
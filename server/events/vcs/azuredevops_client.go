@@ -233,7 +233,7 @@ func (g *AzureDevopsClient) UpdateStatus(repo models.Repo, pull models.PullReque
 	switch state {
 	case models.PendingCommitStatus:
 		adState = azuredevops.GitPending.String()
-	case models.SuccessCommitStatus:
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
 		adState = azuredevops.GitSucceeded.String()
 	case models.FailedCommitStatus:
 		adState = azuredevops.GitFailed.String()
@@ -168,7 +168,7 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	switch status {
 	case models.PendingCommitStatus:
 		bbState = "INPROGRESS"
-	case models.SuccessCommitStatus:
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
 		bbState = "SUCCESSFUL"
 	case models.FailedCommitStatus:
 		bbState = "FAILED"
@@ -207,6 +207,13 @@ func (b *Client) MergePull(pull models.PullRequest, pullOptions models.PullReque
 	return err
 }
 
+// GetPullRequest returns the pull request pullNum of repo as returned by the
+// Bitbucket Cloud API.
+func (b *Client) GetPullRequest(repo models.Repo, pullNum int) ([]byte, error) {
+	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d", b.BaseURL, repo.FullName, pullNum)
+	return b.makeRequest("GET", path, nil)
+}
+
 // MarkdownPullLink specifies the character used in a pull request comment.
 func (b *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return fmt.Sprintf("#%d", pull.Num), nil
@@ -267,6 +274,19 @@ func (b *Client) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte,
 	return false, []byte{}, fmt.Errorf("Not Implemented")
 }
 
+// GetCloneURL returns the clone URL of the repo with full name repo.
 func (b *Client) GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error) {
-	return "", fmt.Errorf("not yet implemented")
+	path := fmt.Sprintf("%s/2.0/repositories/%s", b.BaseURL, repo)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+	var repoResp Repository
+	if err := json.Unmarshal(resp, &repoResp); err != nil {
+		return "", errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(repoResp); err != nil {
+		return "", errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return *repoResp.Links.HTML.HREF, nil
 }
@@ -345,6 +345,53 @@ func TestClient_PullIsMergeable(t *testing.T) {
 
 }
 
+func TestClient_GetPullRequest(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/2.0/repositories/owner/repo/pullrequests/1":
+			w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+
+	body, err := client.GetPullRequest(repo, 1)
+	Ok(t, err)
+	Equals(t, `{"id": 1}`, string(body))
+	_, err = client.GetPullRequest(repo, 2)
+	ErrContains(t, "unexpected status code: 404", err)
+}
+
+func TestClient_GetCloneURL(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/2.0/repositories/owner/repo":
+			w.Write([]byte(`{"full_name": "owner/repo", "links": {"html": {"href": "https://bitbucket.org/owner/repo"}}}`)) // nolint: errcheck
+		case "/2.0/repositories/owner/missing-fields":
+			w.Write([]byte(`{"full_name": "owner/missing-fields"}`)) // nolint: errcheck
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+
+	cloneURL, err := client.GetCloneURL(models.BitbucketCloud, "owner/repo")
+	Ok(t, err)
+	Equals(t, "https://bitbucket.org/owner/repo", cloneURL)
+	_, err = client.GetCloneURL(models.BitbucketCloud, "owner/missing-fields")
+	ErrContains(t, "was missing fields", err)
+	_, err = client.GetCloneURL(models.BitbucketCloud, "owner/other")
+	ErrContains(t, "unexpected status code: 404", err)
+}
+
 func TestClient_MarkdownPullLink(t *testing.T) {
 	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
 	pull := models.PullRequest{Num: 1}
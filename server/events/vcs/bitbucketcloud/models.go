@@ -84,5 +84,6 @@ type CommentContent struct {
 	Raw *string `json:"raw,omitempty" validate:"required"`
 }
 type Author struct {
-	UUID *string `json:"uuid,omitempty" validate:"required"`
+	UUID      *string `json:"uuid,omitempty" validate:"required"`
+	AccountID *string `json:"account_id,omitempty"`
 }
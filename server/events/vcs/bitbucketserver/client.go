@@ -222,7 +222,7 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	switch status {
 	case models.PendingCommitStatus:
 		bbState = "INPROGRESS"
-	case models.SuccessCommitStatus:
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
 		bbState = "SUCCESSFUL"
 	case models.FailedCommitStatus:
 		bbState = "FAILED"
@@ -289,6 +289,17 @@ func (b *Client) MergePull(pull models.PullRequest, pullOptions models.PullReque
 	return err
 }
 
+// GetPullRequest returns the pull request pullNum of repo as returned by the
+// Bitbucket Server API.
+func (b *Client) GetPullRequest(repo models.Repo, pullNum int) ([]byte, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pullNum)
+	return b.makeRequest("GET", path, nil)
+}
+
 // MarkdownPullLink specifies the character used in a pull request comment.
 func (b *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return fmt.Sprintf("#%d", pull.Num), nil
@@ -244,6 +244,33 @@ func TestClient_MergePullDeleteSourceBranch(t *testing.T) {
 	Ok(t, err)
 }
 
+func TestClient_GetPullRequest(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1":
+			w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	repo := models.Repo{
+		FullName:          "owner/repo",
+		Owner:             "owner",
+		Name:              "repo",
+		SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+	}
+
+	body, err := client.GetPullRequest(repo, 1)
+	Ok(t, err)
+	Equals(t, `{"id": 1}`, string(body))
+	_, err = client.GetPullRequest(repo, 2)
+	ErrContains(t, "unexpected status code: 404", err)
+}
+
 func TestClient_MarkdownPullLink(t *testing.T) {
 	client, err := bitbucketserver.NewClient(nil, "u", "p", "https://base-url", "atlantis-url")
 	Ok(t, err)
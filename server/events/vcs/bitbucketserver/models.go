@@ -33,6 +33,11 @@ type PullRequest struct {
 	Reviewers []struct {
 		Approved *bool `json:"approved,omitempty" validate:"required"`
 	} `json:"reviewers,omitempty" validate:"required"`
+	Author *Participant `json:"author,omitempty"`
+}
+
+type Participant struct {
+	User *Actor `json:"user,omitempty" validate:"required"`
 }
 
 type Ref struct {
@@ -17,6 +17,12 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 )
 
+// TeamsSupported returns true if the client for hostType looks up the teams
+// of users in GetTeamNamesForUser. The other clients return no teams.
+func TeamsSupported(hostType models.VCSHostType) bool {
+	return hostType == models.Github || hostType == models.Gitea
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_client.go Client
 
 // Client is used to make API calls to a VCS host like GitHub or GitLab.
@@ -47,3 +53,26 @@ type Client interface {
 	SupportsSingleFileDownload(repo models.Repo) bool
 	GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error)
 }
+
+// IssueCreator is implemented by the clients of VCS hosts that Atlantis can
+// open issues on.
+type IssueCreator interface {
+	// CreateIssue opens an issue in repo and returns its URL.
+	CreateIssue(repo models.Repo, title string, body string) (string, error)
+}
+
+// PlanDiscussionPoster is implemented by the clients of VCS hosts that can
+// post the plan of each project in its own discussion that's resolved once
+// the project is applied. Projects are identified by a key that's the same
+// for every plan of the project.
+type PlanDiscussionPoster interface {
+	// PostsPlanDiscussions returns true if the plans of pull requests in repo
+	// are posted in discussions instead of comments.
+	PostsPlanDiscussions(repo models.Repo) bool
+	// CreateOrUpdatePlanDiscussion posts comment in the plan discussion of
+	// the project with key, replacing its previous plan.
+	CreateOrUpdatePlanDiscussion(repo models.Repo, pullNum int, key string, comment string) error
+	// ResolvePlanDiscussion resolves the plan discussion of the project with
+	// key, if any.
+	ResolvePlanDiscussion(repo models.Repo, pullNum int, key string) error
+}
@@ -0,0 +1,315 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/events/models"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// pageSize is the number of items requested per page from list endpoints. It's
+// the default maximum page size of Gitea.
+const pageSize = 50
+
+// maxPages is the maximum number of pages read from list endpoints as a
+// safety measure.
+const maxPages = 1000
+
+type Client struct {
+	HTTPClient *http.Client
+	Token      string
+	// BaseURL is the URL of the Gitea installation, ex.
+	// https://gitea.example.com, without the API path.
+	BaseURL string
+}
+
+// NewClient builds a Gitea client. Returns an error if the baseURL is
+// malformed. httpClient is the client to use to make the requests, token is
+// the access token of the API user and baseURL is the URL of the Gitea
+// installation, ex. https://gitea.example.com.
+func NewClient(httpClient *http.Client, token string, baseURL string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", baseURL)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	return &Client{
+		HTTPClient: httpClient,
+		Token:      token,
+		BaseURL:    strings.TrimRight(parsedURL.String(), "/"),
+	}, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the pull
+// request relative to the repo root, e.g. parent/child/file.txt.
+func (c *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var files []string
+	hash := make(map[string]bool)
+	for page := 1; page <= maxPages; page++ {
+		path := fmt.Sprintf("%s/repos/%s/pulls/%d/files?page=%d&limit=%d", c.apiURL(), repo.FullName, pull.Num, page, pageSize)
+		var changedFiles []ChangedFile
+		if err := c.getJSON(path, &changedFiles); err != nil {
+			return nil, err
+		}
+		for _, f := range changedFiles {
+			// Renamed files are returned under both their old and new names
+			// so that projects in either dir are planned.
+			for _, name := range []string{f.PreviousFilename, f.Filename} {
+				if name != "" && !hash[name] {
+					files = append(files, name)
+					hash[name] = true
+				}
+			}
+		}
+		if len(changedFiles) < pageSize {
+			break
+		}
+	}
+	return files, nil
+}
+
+// CreateComment creates a comment on the pull request.
+func (c *Client) CreateComment(repo models.Repo, pullNum int, comment string, command string) error {
+	path := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiURL(), repo.FullName, pullNum)
+	_, _, err := c.makeRequest("POST", path, map[string]string{"body": comment})
+	return err
+}
+
+func (c *Client) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+	return nil
+}
+
+// PullIsApproved returns true if the latest review of any user other than the
+// author of the pull request approves it.
+func (c *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (approvalStatus models.ApprovalStatus, err error) {
+	// Only the latest review of each user counts, ex. a user can approve and
+	// then request changes.
+	latestStates := make(map[string]string)
+	var users []string
+	for page := 1; page <= maxPages; page++ {
+		path := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews?page=%d&limit=%d", c.apiURL(), repo.FullName, pull.Num, page, pageSize)
+		var reviews []Review
+		if err := c.getJSON(path, &reviews); err != nil {
+			return approvalStatus, err
+		}
+		for _, review := range reviews {
+			// Comments don't change the state of a review.
+			if review.User == nil || review.User.Login == nil || review.Dismissed || review.State == "COMMENT" || review.State == "PENDING" {
+				continue
+			}
+			login := *review.User.Login
+			if _, ok := latestStates[login]; !ok {
+				users = append(users, login)
+			}
+			latestStates[login] = review.State
+		}
+		if len(reviews) < pageSize {
+			break
+		}
+	}
+	for _, login := range users {
+		// Gitea doesn't allow authors to approve their own pull requests but
+		// we don't count their reviews to be safe.
+		if login != pull.Author && latestStates[login] == "APPROVED" {
+			return models.ApprovalStatus{
+				IsApproved: true,
+				ApprovedBy: login,
+			}, nil
+		}
+	}
+	return approvalStatus, nil
+}
+
+// PullIsMergeable returns true if the pull request is open and has no
+// conflicts.
+func (c *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest, vcsstatusname string) (bool, error) {
+	giteaPull, err := c.GetPullRequest(repo, pull.Num)
+	if err != nil {
+		return false, err
+	}
+	return *giteaPull.State == "open" && giteaPull.Mergeable, nil
+}
+
+// UpdateStatus updates the status of a commit.
+func (c *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string, url string) error {
+	giteaState := "failure"
+	switch status {
+	case models.PendingCommitStatus:
+		giteaState = "pending"
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
+		giteaState = "success"
+	case models.FailedCommitStatus:
+		giteaState = "failure"
+	}
+	path := fmt.Sprintf("%s/repos/%s/statuses/%s", c.apiURL(), repo.FullName, pull.HeadCommit)
+	_, _, err := c.makeRequest("POST", path, map[string]string{
+		"state":       giteaState,
+		"target_url":  url,
+		"description": description,
+		"context":     src,
+	})
+	return err
+}
+
+// MergePull merges the pull request.
+func (c *Client) MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d/merge", c.apiURL(), pull.BaseRepo.FullName, pull.Num)
+	_, _, err := c.makeRequest("POST", path, map[string]interface{}{
+		"Do":                        "merge",
+		"delete_branch_after_merge": pullOptions.DeleteSourceBranchOnMerge,
+	})
+	return err
+}
+
+// MarkdownPullLink specifies the character used in a pull request comment.
+func (c *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	return fmt.Sprintf("#%d", pull.Num), nil
+}
+
+// GetTeamNamesForUser returns the names of the teams that the user belongs to
+// in the organization the repository belongs to. If the repo isn't owned by an
+// organization, there are no teams.
+func (c *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	var teamNames []string
+	for page := 1; page <= maxPages; page++ {
+		path := fmt.Sprintf("%s/orgs/%s/teams?page=%d&limit=%d", c.apiURL(), url.PathEscape(repo.Owner), page, pageSize)
+		var teams []Team
+		resp, statusCode, err := c.makeRequest("GET", path, nil)
+		if statusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &teams); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, team := range teams {
+			memberPath := fmt.Sprintf("%s/teams/%d/members/%s", c.apiURL(), team.ID, url.PathEscape(user.Username))
+			_, statusCode, err := c.makeRequest("GET", memberPath, nil)
+			if statusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			teamNames = append(teamNames, team.Name)
+		}
+		if len(teams) < pageSize {
+			break
+		}
+	}
+	return teamNames, nil
+}
+
+func (c *Client) SupportsSingleFileDownload(repo models.Repo) bool {
+	return true
+}
+
+// DownloadRepoConfigFile return `atlantis.yaml` content from VCS (which support fetch a single file from repository)
+// The first return value indicate that repo contain atlantis.yaml or not
+// if BaseRepo had one repo config file, its content will placed on the second return value
+func (c *Client) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+	path := fmt.Sprintf("%s/repos/%s/raw/%s?ref=%s", c.apiURL(), pull.BaseRepo.FullName, config.AtlantisYAMLFilename, url.QueryEscape(pull.HeadCommit))
+	content, statusCode, err := c.makeRequest("GET", path, nil)
+	if statusCode == http.StatusNotFound {
+		return false, []byte{}, nil
+	}
+	if err != nil {
+		return true, []byte{}, err
+	}
+	return true, content, nil
+}
+
+// GetCloneURL returns the clone URL of repo, ex. owner/repo.
+func (c *Client) GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error) {
+	path := fmt.Sprintf("%s/repos/%s", c.apiURL(), repo)
+	var giteaRepo Repository
+	if err := c.getJSON(path, &giteaRepo); err != nil {
+		return "", err
+	}
+	if err := validator.New().Struct(giteaRepo); err != nil {
+		return "", errors.Wrap(err, "API response was missing fields")
+	}
+	return *giteaRepo.CloneURL, nil
+}
+
+// GetPullRequest gets the pull request with id pullNum for the repo.
+func (c *Client) GetPullRequest(repo models.Repo, pullNum int) (*PullRequest, error) {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d", c.apiURL(), repo.FullName, pullNum)
+	var pull PullRequest
+	if err := c.getJSON(path, &pull); err != nil {
+		return nil, err
+	}
+	if err := validator.New().Struct(pull); err != nil {
+		return nil, errors.Wrap(err, "API response was missing fields")
+	}
+	return &pull, nil
+}
+
+func (c *Client) apiURL() string {
+	return c.BaseURL + APIPath
+}
+
+// getJSON makes a GET request to path and decodes the response into v.
+func (c *Client) getJSON(path string, v interface{}) error {
+	resp, _, err := c.makeRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp, v); err != nil {
+		return errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	return nil
+}
+
+// makeRequest makes a request to path with reqBody, if not nil, encoded as
+// JSON. It returns the response body and status code. It returns an error if
+// the status code isn't 2xx.
+func (c *Client) makeRequest(method string, path string, reqBody interface{}) ([]byte, int, error) {
+	var body io.Reader
+	if reqBody != nil {
+		bodyBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "json encoding")
+		}
+		body = bytes.NewBuffer(bodyBytes)
+	}
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "constructing request")
+	}
+	req.Header.Add("Authorization", "token "+c.Token)
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, resp.StatusCode, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	if err != nil {
+		return nil, resp.StatusCode, errors.Wrapf(err, "reading response from request %q", requestStr)
+	}
+	return respBody, resp.StatusCode, nil
+}
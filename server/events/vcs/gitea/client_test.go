@@ -0,0 +1,305 @@
+package gitea_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*gitea.Client, models.Repo) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "token token", r.Header.Get("Authorization"))
+		handler(w, r)
+	}))
+	t.Cleanup(testServer.Close)
+	client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL+"/")
+	Ok(t, err)
+	repo, err := models.NewRepo(models.Gitea, "owner/repo", "https://gitea.example.com/owner/repo.git", "user", "token")
+	Ok(t, err)
+	return client, repo
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	bytes, err := os.ReadFile(filepath.Join("testdata", name))
+	Ok(t, err)
+	return bytes
+}
+
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	_, err := gitea.NewClient(nil, "token", "gitea.example.com")
+	ErrEquals(t, "must have 'http://' or 'https://' in base url \"gitea.example.com\"", err)
+}
+
+// Should follow pagination and return the old names of renamed files.
+func TestClient_GetModifiedFiles(t *testing.T) {
+	var fullPage []map[string]string
+	for i := 0; i < 50; i++ {
+		fullPage = append(fullPage, map[string]string{"filename": fmt.Sprintf("file%d.tf", i)})
+	}
+	fullPageJSON, err := json.Marshal(fullPage)
+	Ok(t, err)
+
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/pulls/2/files?page=1&limit=50":
+			w.Write(fullPageJSON) // nolint: errcheck
+		case "/api/v1/repos/owner/repo/pulls/2/files?page=2&limit=50":
+			w.Write(readFixture(t, "files.json")) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+
+	files, err := client.GetModifiedFiles(repo, models.PullRequest{Num: 2})
+	Ok(t, err)
+	Equals(t, 53, len(files))
+	Equals(t, []string{"network/main.tf", "network/output.tf", "network/outputs.tf"}, files[50:])
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "POST", r.Method)
+		Equals(t, "/api/v1/repos/owner/repo/issues/2/comments", r.RequestURI)
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		Equals(t, `{"body":"comment"}`, string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	Ok(t, client.CreateComment(repo, 2, "comment", "plan"))
+}
+
+func TestClient_PullIsApproved(t *testing.T) {
+	cases := []struct {
+		description string
+		reviews     string
+		exp         models.ApprovalStatus
+	}{
+		{
+			"no reviews",
+			"[]",
+			models.ApprovalStatus{},
+		},
+		{
+			"approval superseded, dismissed or a comment",
+			string(readFixture(t, "reviews.json")),
+			models.ApprovalStatus{},
+		},
+		{
+			"approved by the author",
+			`[{"user": {"login": "author"}, "state": "APPROVED"}]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"approved",
+			`[{"user": {"login": "reviewer1"}, "state": "REQUEST_CHANGES"}, {"user": {"login": "reviewer1"}, "state": "APPROVED"}]`,
+			models.ApprovalStatus{IsApproved: true, ApprovedBy: "reviewer1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/pulls/2/reviews?page=1&limit=50":
+					w.Write([]byte(c.reviews)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			})
+
+			approvalStatus, err := client.PullIsApproved(repo, models.PullRequest{
+				Num:      2,
+				Author:   "author",
+				BaseRepo: repo,
+			})
+			Ok(t, err)
+			Equals(t, c.exp, approvalStatus)
+		})
+	}
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	pull := string(readFixture(t, "pull.json"))
+	cases := map[string]struct {
+		pull         string
+		expMergeable bool
+	}{
+		"mergeable": {
+			pull,
+			true,
+		},
+		"conflicts": {
+			strings.Replace(pull, `"mergeable": true`, `"mergeable": false`, 1),
+			false,
+		},
+		"closed": {
+			strings.Replace(pull, `"state": "open"`, `"state": "closed"`, 1),
+			false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/pulls/2":
+					w.Write([]byte(c.pull)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			})
+
+			mergeable, err := client.PullIsMergeable(repo, models.PullRequest{Num: 2}, "atlantis-test")
+			Ok(t, err)
+			Equals(t, c.expMergeable, mergeable)
+		})
+	}
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	cases := map[models.CommitStatus]string{
+		models.PendingCommitStatus: "pending",
+		models.SuccessCommitStatus: "success",
+		models.FailedCommitStatus:  "failure",
+		models.NeutralCommitStatus: "success",
+	}
+
+	for status, expState := range cases {
+		t.Run(status.String(), func(t *testing.T) {
+			client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				Equals(t, "POST", r.Method)
+				Equals(t, "/api/v1/repos/owner/repo/statuses/sha", r.RequestURI)
+				var body map[string]string
+				Ok(t, json.NewDecoder(r.Body).Decode(&body))
+				Equals(t, map[string]string{
+					"state":       expState,
+					"target_url":  "https://atlantis.example.com",
+					"description": "description",
+					"context":     "atlantis/plan",
+				}, body)
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			err := client.UpdateStatus(repo, models.PullRequest{Num: 2, HeadCommit: "sha"}, status, "atlantis/plan", "description", "https://atlantis.example.com")
+			Ok(t, err)
+		})
+	}
+}
+
+func TestClient_MergePull(t *testing.T) {
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "POST", r.Method)
+		Equals(t, "/api/v1/repos/owner/repo/pulls/2/merge", r.RequestURI)
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		Equals(t, `{"Do":"merge","delete_branch_after_merge":true}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := client.MergePull(models.PullRequest{Num: 2, BaseRepo: repo}, models.PullRequestOptions{DeleteSourceBranchOnMerge: true})
+	Ok(t, err)
+}
+
+func TestClient_MergePullError(t *testing.T) {
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Please try again later", http.StatusMethodNotAllowed)
+	})
+
+	err := client.MergePull(models.PullRequest{Num: 2, BaseRepo: repo}, models.PullRequestOptions{})
+	Assert(t, err != nil, "exp error")
+	Assert(t, strings.Contains(err.Error(), "unexpected status code: 405"), "exp status code in error, got %s", err)
+}
+
+func TestClient_DownloadRepoConfigFile(t *testing.T) {
+	cases := map[string]struct {
+		statusCode int
+		expExists  bool
+	}{
+		"exists":         {http.StatusOK, true},
+		"does not exist": {http.StatusNotFound, false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				Equals(t, "/api/v1/repos/owner/repo/raw/atlantis.yaml?ref=sha", r.RequestURI)
+				w.WriteHeader(c.statusCode)
+				if c.statusCode == http.StatusOK {
+					w.Write([]byte("version: 3\n")) // nolint: errcheck
+				}
+			})
+
+			exists, content, err := client.DownloadRepoConfigFile(models.PullRequest{Num: 2, BaseRepo: repo, HeadCommit: "sha"})
+			Ok(t, err)
+			Equals(t, c.expExists, exists)
+			if c.expExists {
+				Equals(t, "version: 3\n", string(content))
+			}
+		})
+	}
+}
+
+func TestClient_GetCloneURL(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo":
+			w.Write(readFixture(t, "repo.json")) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+
+	cloneURL, err := client.GetCloneURL(models.Gitea, "owner/repo")
+	Ok(t, err)
+	Equals(t, "https://gitea.example.com/owner/repo.git", cloneURL)
+}
+
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/orgs/owner/teams?page=1&limit=50":
+			w.Write([]byte(`[{"id": 1, "name": "Owners"}, {"id": 2, "name": "infra"}, {"id": 3, "name": "app"}]`)) // nolint: errcheck
+		case "/api/v1/teams/1/members/user", "/api/v1/teams/2/members/user":
+			w.Write([]byte(`{"login": "user"}`)) // nolint: errcheck
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "user"})
+	Ok(t, err)
+	Equals(t, []string{"Owners", "infra"}, teams)
+}
+
+// If the repo is owned by a user there are no teams.
+func TestClient_GetTeamNamesForUserNotOrg(t *testing.T) {
+	client, repo := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "user"})
+	Ok(t, err)
+	Equals(t, 0, len(teams))
+}
+
+func TestClient_MarkdownPullLink(t *testing.T) {
+	client, err := gitea.NewClient(nil, "token", "https://gitea.example.com")
+	Ok(t, err)
+	s, _ := client.MarkdownPullLink(models.PullRequest{Num: 1})
+	Equals(t, "#1", s)
+}
@@ -0,0 +1,7 @@
+// Package gitea holds code for Gitea and its fork Forgejo, which share the
+// same API and webhooks.
+package gitea
+
+// APIPath is the path of the Gitea API relative to the base URL of the Gitea
+// installation.
+const APIPath = "/api/v1"
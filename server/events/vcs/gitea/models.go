@@ -0,0 +1,79 @@
+package gitea
+
+// These are the values of the X-Gitea-Event-Type header for the events
+// Atlantis handles.
+const (
+	PullRequestEventType        = "pull_request"
+	PullRequestSyncEventType    = "pull_request_sync"
+	PullRequestCommentEventType = "pull_request_comment"
+)
+
+// PullRequestEvent is the payload of pull_request and pull_request_sync
+// webhooks.
+type PullRequestEvent struct {
+	Action      *string      `json:"action,omitempty" validate:"required"`
+	Number      *int         `json:"number,omitempty" validate:"required"`
+	PullRequest *PullRequest `json:"pull_request,omitempty" validate:"required"`
+	Repository  *Repository  `json:"repository,omitempty" validate:"required"`
+	Sender      *User        `json:"sender,omitempty" validate:"required"`
+}
+
+// IssueCommentEvent is the payload of pull_request_comment webhooks.
+type IssueCommentEvent struct {
+	Action     *string     `json:"action,omitempty" validate:"required"`
+	Issue      *Issue      `json:"issue,omitempty" validate:"required"`
+	Comment    *Comment    `json:"comment,omitempty" validate:"required"`
+	Repository *Repository `json:"repository,omitempty" validate:"required"`
+	IsPull     bool        `json:"is_pull"`
+}
+
+type PullRequest struct {
+	Number    *int        `json:"number,omitempty" validate:"required"`
+	User      *User       `json:"user,omitempty" validate:"required"`
+	State     *string     `json:"state,omitempty" validate:"required"`
+	HTMLURL   *string     `json:"html_url,omitempty" validate:"required"`
+	Mergeable bool        `json:"mergeable"`
+	Merged    bool        `json:"merged"`
+	Base      *BranchMeta `json:"base,omitempty" validate:"required"`
+	Head      *BranchMeta `json:"head,omitempty" validate:"required"`
+}
+
+type BranchMeta struct {
+	Ref  *string     `json:"ref,omitempty" validate:"required"`
+	SHA  *string     `json:"sha,omitempty" validate:"required"`
+	Repo *Repository `json:"repo,omitempty" validate:"required"`
+}
+
+type Repository struct {
+	FullName *string `json:"full_name,omitempty" validate:"required"`
+	CloneURL *string `json:"clone_url,omitempty" validate:"required"`
+}
+
+type User struct {
+	Login *string `json:"login,omitempty" validate:"required"`
+}
+
+type Issue struct {
+	Number *int `json:"number,omitempty" validate:"required"`
+}
+
+type Comment struct {
+	Body *string `json:"body,omitempty" validate:"required"`
+	User *User   `json:"user,omitempty" validate:"required"`
+}
+
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+}
+
+type Review struct {
+	User      *User  `json:"user,omitempty" validate:"required"`
+	State     string `json:"state"`
+	Dismissed bool   `json:"dismissed"`
+}
+
+type Team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
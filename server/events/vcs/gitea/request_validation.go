@@ -0,0 +1,29 @@
+package gitea
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateSignature checks that signature, the value of the X-Gitea-Signature
+// header, is the hex-encoded HMAC-SHA256 of payload with secretKey.
+func ValidateSignature(payload []byte, signature string, secretKey []byte) error {
+	if signature == "" {
+		return errors.New("missing signature")
+	}
+	messageMAC, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("error decoding signature %q: %v", signature, err)
+	}
+	mac := hmac.New(sha256.New, secretKey)
+	// nolint: errcheck
+	mac.Write(payload)
+	if !hmac.Equal(messageMAC, mac.Sum(nil)) {
+		return errors.New("payload signature check failed")
+	}
+	return nil
+}
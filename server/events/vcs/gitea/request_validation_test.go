@@ -0,0 +1,43 @@
+package gitea_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestValidateSignature(t *testing.T) {
+	payload := []byte(`{"action":"opened"}`)
+	// echo -n '{"action":"opened"}' | openssl dgst -sha256 -hmac secret
+	signature := "d42142b53efbc7cf5cd20b6e074eb33707e0de3b368f698e6d6f6c824ffb8d37"
+
+	Ok(t, gitea.ValidateSignature(payload, signature, []byte("secret")))
+
+	cases := map[string]struct {
+		signature string
+		secret    string
+		expErr    string
+	}{
+		"missing": {
+			"",
+			"secret",
+			"missing signature",
+		},
+		"not hex": {
+			"zz",
+			"secret",
+			"error decoding signature \"zz\": encoding/hex: invalid byte: U+007A 'z'",
+		},
+		"wrong secret": {
+			signature,
+			"other",
+			"payload signature check failed",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ErrEquals(t, c.expErr, gitea.ValidateSignature(payload, c.signature, []byte(c.secret)))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package gitea
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// ParseWebhook parses payload into the event for eventType, the value of the
+// X-Gitea-Event-Type header. It returns *PullRequestEvent or
+// *IssueCommentEvent, or nil if Atlantis doesn't handle eventType.
+func ParseWebhook(eventType string, payload []byte) (interface{}, error) {
+	var event interface{}
+	switch eventType {
+	case PullRequestEventType, PullRequestSyncEventType:
+		event = &PullRequestEvent{}
+	case PullRequestCommentEventType:
+		event = &IssueCommentEvent{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, errors.Wrap(err, "parsing json")
+	}
+	if err := validator.New().Struct(event); err != nil {
+		return nil, errors.Wrapf(err, "payload %q was missing fields", string(payload))
+	}
+	return event, nil
+}
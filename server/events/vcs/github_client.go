@@ -36,6 +36,10 @@ import (
 // by GitHub.
 const maxCommentLength = 65536
 
+// MaxCheckRunOutputLength is the maximum number of chars allowed in the summary
+// and the text of a check run's output by GitHub.
+const MaxCheckRunOutputLength = 65535
+
 // GithubClient is used to perform GitHub actions.
 type GithubClient struct {
 	user           string
@@ -60,6 +64,36 @@ type GithubAppTemporarySecrets struct {
 	URL string
 }
 
+// GithubCheckRun is a check run on the head commit of a pull request.
+type GithubCheckRun struct {
+	// Name identifies the check run on the commit, ex. atlantis/plan: project.
+	Name   string
+	Status models.CommitStatus
+	// Title, Summary and Text are the output of the check run. Summary and
+	// Text are Markdown and can be at most MaxCheckRunOutputLength chars.
+	Title   string
+	Summary string
+	Text    string
+	// DetailsURL links to the full details of the check run. If empty, the
+	// existing URL of the check run is kept.
+	DetailsURL string
+	// ExternalID is Atlantis's reference for the check run. It's sent back in
+	// check_run webhooks when users request Actions.
+	ExternalID string
+	Actions    []GithubCheckRunAction
+}
+
+// GithubCheckRunAction is a button on a check run that users can click to
+// request an action from Atlantis.
+type GithubCheckRunAction struct {
+	// Label is the text of the button, at most 20 chars.
+	Label string
+	// Description explains what the action does, at most 40 chars.
+	Description string
+	// Identifier is sent back in the check_run webhook, at most 20 chars.
+	Identifier string
+}
+
 // NewGithubClient returns a valid GitHub client.
 func NewGithubClient(hostname string, credentials GithubCredentials, logger logging.SimpleLogging) (*GithubClient, error) {
 	transport, err := credentials.Client()
@@ -352,7 +386,8 @@ func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	switch state {
 	case models.PendingCommitStatus:
 		ghState = "pending"
-	case models.SuccessCommitStatus:
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
+		// Commit statuses have no neutral state.
 		ghState = "success"
 	case models.FailedCommitStatus:
 		ghState = "failure"
@@ -368,6 +403,82 @@ func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	return err
 }
 
+// UpdateCheckRun creates or updates the check run named checkRun.Name on the
+// head commit of pull. Check runs can only be created by GitHub Apps.
+// See https://docs.github.com/en/rest/checks/runs.
+func (g *GithubClient) UpdateCheckRun(repo models.Repo, pull models.PullRequest, checkRun GithubCheckRun) error {
+	status := "completed"
+	var conclusion *string
+	switch checkRun.Status {
+	case models.PendingCommitStatus:
+		status = "in_progress"
+	case models.SuccessCommitStatus:
+		conclusion = github.String("success")
+	case models.NeutralCommitStatus:
+		conclusion = github.String("neutral")
+	default:
+		conclusion = github.String("failure")
+	}
+
+	summary := checkRun.Summary
+	if summary == "" {
+		summary = checkRun.Title
+	}
+	output := &github.CheckRunOutput{
+		Title:   github.String(checkRun.Title),
+		Summary: github.String(summary),
+	}
+	if checkRun.Text != "" {
+		output.Text = github.String(checkRun.Text)
+	}
+	var detailsURL, externalID *string
+	if checkRun.DetailsURL != "" {
+		detailsURL = github.String(checkRun.DetailsURL)
+	}
+	if checkRun.ExternalID != "" {
+		externalID = github.String(checkRun.ExternalID)
+	}
+	var actions []*github.CheckRunAction
+	for _, a := range checkRun.Actions {
+		actions = append(actions, &github.CheckRunAction{
+			Label:       a.Label,
+			Description: a.Description,
+			Identifier:  a.Identifier,
+		})
+	}
+
+	existing, _, err := g.client.Checks.ListCheckRunsForRef(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &github.ListCheckRunsOptions{
+		CheckName: github.String(checkRun.Name),
+		Filter:    github.String("latest"),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "listing check runs named %q", checkRun.Name)
+	}
+	if len(existing.CheckRuns) == 0 {
+		_, _, err = g.client.Checks.CreateCheckRun(g.ctx, repo.Owner, repo.Name, github.CreateCheckRunOptions{
+			Name:       checkRun.Name,
+			HeadSHA:    pull.HeadCommit,
+			DetailsURL: detailsURL,
+			ExternalID: externalID,
+			Status:     github.String(status),
+			Conclusion: conclusion,
+			Output:     output,
+			Actions:    actions,
+		})
+		return err
+	}
+	_, _, err = g.client.Checks.UpdateCheckRun(g.ctx, repo.Owner, repo.Name, existing.CheckRuns[0].GetID(), github.UpdateCheckRunOptions{
+		Name:       checkRun.Name,
+		DetailsURL: detailsURL,
+		ExternalID: externalID,
+		Status:     github.String(status),
+		Conclusion: conclusion,
+		Output:     output,
+		Actions:    actions,
+	})
+	return err
+}
+
 // MergePull merges the pull request.
 func (g *GithubClient) MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error {
 	// Users can set their repo to disallow certain types of merging.
@@ -502,6 +613,18 @@ func (g *GithubClient) SupportsSingleFileDownload(repo models.Repo) bool {
 	return true
 }
 
+// CreateIssue opens an issue in repo and returns its URL.
+func (g *GithubClient) CreateIssue(repo models.Repo, title string, body string) (string, error) {
+	issue, _, err := g.client.Issues.Create(g.ctx, repo.Owner, repo.Name, &github.IssueRequest{
+		Title: &title,
+		Body:  &body,
+	})
+	if err != nil {
+		return "", err
+	}
+	return issue.GetHTMLURL(), nil
+}
+
 func (g *GithubClient) GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error) {
 	parts := strings.Split(repo, "/")
 	repository, _, err := g.client.Repositories.Get(g.ctx, parts[0], parts[1])
@@ -327,6 +327,72 @@ func TestGithubClient_HideOldComments(t *testing.T) {
 	Equals(t, githubv4.ReportedContentClassifiersOutdated, gotMinimizeCalls[0].Variables.Input.Classifier)
 }
 
+// Should create the check run if it doesn't exist on the commit and update it
+// otherwise.
+func TestGithubClient_UpdateCheckRun(t *testing.T) {
+	cases := map[string]struct {
+		existing  string
+		status    models.CommitStatus
+		expMethod string
+		expURI    string
+		expBody   string
+	}{
+		"create in progress": {
+			`{"total_count": 0, "check_runs": []}`,
+			models.PendingCommitStatus,
+			"POST",
+			"/api/v3/repos/owner/repo/check-runs",
+			`{"name":"atlantis/plan: project","head_sha":"sha","details_url":"https://atlantis.example.com/jobs/1","status":"in_progress","output":{"title":"Plan in progress...","summary":"Plan in progress..."}}`,
+		},
+		"update neutral": {
+			`{"total_count": 1, "check_runs": [{"id": 4}]}`,
+			models.NeutralCommitStatus,
+			"PATCH",
+			"/api/v3/repos/owner/repo/check-runs/4",
+			`{"name":"atlantis/plan: project","details_url":"https://atlantis.example.com/jobs/1","status":"completed","conclusion":"neutral","output":{"title":"Plan in progress...","summary":"Plan in progress..."}}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/commits/sha/check-runs?check_name=atlantis%2Fplan%3A+project&filter=latest":
+						w.Write([]byte(c.existing)) // nolint: errcheck
+					case c.expURI:
+						Equals(t, c.expMethod, r.Method)
+						body, err := io.ReadAll(r.Body)
+						Ok(t, err)
+						Equals(t, c.expBody+"\n", string(body))
+						w.Write([]byte("{}")) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t))
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			err = client.UpdateCheckRun(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}, models.PullRequest{Num: 1, HeadCommit: "sha"}, vcs.GithubCheckRun{
+				Name:       "atlantis/plan: project",
+				Status:     c.status,
+				Title:      "Plan in progress...",
+				DetailsURL: "https://atlantis.example.com/jobs/1",
+			})
+			Ok(t, err)
+		})
+	}
+}
+
 func TestGithubClient_UpdateStatus(t *testing.T) {
 	cases := []struct {
 		status   models.CommitStatus
@@ -1001,3 +1067,30 @@ func TestGithubClient_GetTeamNamesForUser(t *testing.T) {
 	Ok(t, err)
 	Equals(t, []string{"frontend-developers", "employees"}, teams)
 }
+
+func TestGithubClient_CreateIssue(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/issues":
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"title":"title","body":"body"}`+"\n", string(body))
+				w.Write([]byte(`{"number":1,"html_url":"https://github.com/owner/repo/issues/1"}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+		}))
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logger)
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	issueURL, err := client.CreateIssue(models.Repo{Owner: "owner", Name: "repo"}, "title", "body")
+	Ok(t, err)
+	Equals(t, "https://github.com/owner/repo/issues/1", issueURL)
+}
@@ -39,10 +39,27 @@ import (
 // single comment.
 const gitlabMaxCommentLength = 1000000
 
+// gitlabPlanDiscussionMarker starts the first note of the discussions that
+// plans are posted in. It's followed by the key of the project so that the
+// discussion can be found again on re-plan and apply.
+const gitlabPlanDiscussionMarker = "<!-- atlantis plan discussion"
+
 type GitlabClient struct {
 	Client *gitlab.Client
 	// Version is set to the server version.
 	Version *version.Version
+	// User is the username of the token's user. If set, only discussions
+	// started by this user are treated as plan discussions.
+	User string
+	// PlanDiscussions is true if plans should be posted as a resolvable
+	// discussion that is updated on re-plan and resolved after apply instead
+	// of as a new comment.
+	PlanDiscussions bool
+	// RequireResolvedPlanDiscussions is true if merge requests with an
+	// unresolved plan discussion are not mergeable. Otherwise plan
+	// discussions are ignored when checking that the merge request's
+	// discussions are resolved.
+	RequireResolvedPlanDiscussions bool
 }
 
 // commonMarkSupported is a version constraint that is true when this version of
@@ -151,12 +168,7 @@ func (g *GitlabClient) GetModifiedFiles(repo models.Repo, pull models.PullReques
 
 // CreateComment creates a comment on the merge request.
 func (g *GitlabClient) CreateComment(repo models.Repo, pullNum int, comment string, command string) error {
-	sepEnd := "\n```\n</details>" +
-		"\n<br>\n\n**Warning**: Output length greater than max comment size. Continued in next comment."
-	sepStart := "Continued from previous comment.\n<details><summary>Show Output</summary>\n\n" +
-		"```diff\n"
-	comments := common.SplitComment(comment, gitlabMaxCommentLength, sepEnd, sepStart)
-	for _, c := range comments {
+	for _, c := range splitGitlabComment(comment, gitlabMaxCommentLength) {
 		if _, _, err := g.Client.Notes.CreateMergeRequestNote(repo.FullName, pullNum, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.String(c)}); err != nil {
 			return err
 		}
@@ -168,6 +180,186 @@ func (g *GitlabClient) HidePrevCommandComments(repo models.Repo, pullNum int, co
 	return nil
 }
 
+// splitGitlabComment splits comment into notes of at most maxLength chars.
+func splitGitlabComment(comment string, maxLength int) []string {
+	sepEnd := "\n```\n</details>" +
+		"\n<br>\n\n**Warning**: Output length greater than max comment size. Continued in next comment."
+	sepStart := "Continued from previous comment.\n<details><summary>Show Output</summary>\n\n" +
+		"```diff\n"
+	return common.SplitComment(comment, maxLength, sepEnd, sepStart)
+}
+
+// PostsPlanDiscussions returns true if plan discussions are enabled.
+func (g *GitlabClient) PostsPlanDiscussions(repo models.Repo) bool {
+	return g.PlanDiscussions
+}
+
+// CreateOrUpdatePlanDiscussion posts comment in the plan discussion of the
+// project with key. If there's one already, its first note is replaced, the
+// previous continuation notes are deleted and the discussion is unresolved
+// since the new plan hasn't been applied. Replies by other users are kept.
+func (g *GitlabClient) CreateOrUpdatePlanDiscussion(repo models.Repo, pullNum int, key string, comment string) error {
+	marker := planDiscussionMarker(key)
+	comments := splitGitlabComment(comment, gitlabMaxCommentLength-len(marker))
+	discussion, err := g.getPlanDiscussion(repo, pullNum, key)
+	if err != nil {
+		return err
+	}
+	if discussion == nil {
+		discussion, _, err = g.Client.Discussions.CreateMergeRequestDiscussion(repo.FullName, pullNum, &gitlab.CreateMergeRequestDiscussionOptions{
+			Body: gitlab.String(marker + comments[0]),
+		})
+		if err != nil {
+			return errors.Wrap(err, "creating plan discussion")
+		}
+	} else {
+		first := discussion.Notes[0]
+		if _, _, err := g.Client.Discussions.UpdateMergeRequestDiscussionNote(repo.FullName, pullNum, discussion.ID, first.ID, &gitlab.UpdateMergeRequestDiscussionNoteOptions{
+			Body: gitlab.String(marker + comments[0]),
+		}); err != nil {
+			return errors.Wrap(err, "updating plan discussion")
+		}
+		for _, n := range discussion.Notes[1:] {
+			if n.Author.Username != first.Author.Username {
+				continue
+			}
+			if _, err := g.Client.Discussions.DeleteMergeRequestDiscussionNote(repo.FullName, pullNum, discussion.ID, n.ID); err != nil {
+				return errors.Wrap(err, "deleting previous plan discussion note")
+			}
+		}
+		if isResolved(discussion) {
+			if _, _, err := g.Client.Discussions.ResolveMergeRequestDiscussion(repo.FullName, pullNum, discussion.ID, &gitlab.ResolveMergeRequestDiscussionOptions{
+				Resolved: gitlab.Bool(false),
+			}); err != nil {
+				return errors.Wrap(err, "unresolving plan discussion")
+			}
+		}
+	}
+	for _, c := range comments[1:] {
+		if _, _, err := g.Client.Discussions.AddMergeRequestDiscussionNote(repo.FullName, pullNum, discussion.ID, &gitlab.AddMergeRequestDiscussionNoteOptions{
+			Body: gitlab.String(c),
+		}); err != nil {
+			return errors.Wrap(err, "adding plan discussion note")
+		}
+	}
+	return nil
+}
+
+// ResolvePlanDiscussion resolves the plan discussion of the project with key
+// if plan discussions are enabled and it isn't resolved yet.
+func (g *GitlabClient) ResolvePlanDiscussion(repo models.Repo, pullNum int, key string) error {
+	if !g.PlanDiscussions {
+		return nil
+	}
+	discussion, err := g.getPlanDiscussion(repo, pullNum, key)
+	if err != nil || discussion == nil || isResolved(discussion) {
+		return err
+	}
+	_, _, err = g.Client.Discussions.ResolveMergeRequestDiscussion(repo.FullName, pullNum, discussion.ID, &gitlab.ResolveMergeRequestDiscussionOptions{
+		Resolved: gitlab.Bool(true),
+	})
+	return errors.Wrap(err, "resolving plan discussion")
+}
+
+// getPlanDiscussion returns the latest plan discussion of the project with
+// key in the merge request or nil if there isn't one.
+func (g *GitlabClient) getPlanDiscussion(repo models.Repo, pullNum int, key string) (*gitlab.Discussion, error) {
+	discussions, err := g.listDiscussions(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	marker := planDiscussionMarker(key)
+	var planDiscussion *gitlab.Discussion
+	for _, d := range discussions {
+		if g.isPlanDiscussion(d) && strings.HasPrefix(d.Notes[0].Body, marker) {
+			planDiscussion = d
+		}
+	}
+	return planDiscussion, nil
+}
+
+// planDiscussionMarker returns the marker that starts the first note of the
+// plan discussion of the project with key.
+func planDiscussionMarker(key string) string {
+	return fmt.Sprintf("%s: %s -->\n", gitlabPlanDiscussionMarker, key)
+}
+
+// listDiscussions returns all the discussions of the merge request.
+func (g *GitlabClient) listDiscussions(repo models.Repo, pullNum int) ([]*gitlab.Discussion, error) {
+	const maxPerPage = 100
+	var discussions []*gitlab.Discussion
+	nextPage := 1
+	for {
+		page, resp, err := g.Client.Discussions.ListMergeRequestDiscussions(repo.FullName, pullNum, &gitlab.ListMergeRequestDiscussionsOptions{
+			Page:    nextPage,
+			PerPage: maxPerPage,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "listing discussions")
+		}
+		discussions = append(discussions, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return discussions, nil
+}
+
+// isPlanDiscussion returns true if d was started by Atlantis to post plans.
+func (g *GitlabClient) isPlanDiscussion(d *gitlab.Discussion) bool {
+	if d.IndividualNote || len(d.Notes) == 0 {
+		return false
+	}
+	first := d.Notes[0]
+	if g.User != "" && first.Author.Username != g.User {
+		return false
+	}
+	return strings.HasPrefix(first.Body, gitlabPlanDiscussionMarker)
+}
+
+// isResolved returns true if all the resolvable notes of d are resolved.
+func isResolved(d *gitlab.Discussion) bool {
+	for _, n := range d.Notes {
+		if n.Resolvable && !n.Resolved {
+			return false
+		}
+	}
+	return true
+}
+
+// discussionsResolved returns true if the discussions of the merge request
+// don't block merging. GitLab only reports whether all blocking discussions
+// are resolved, which includes plan discussions, so if plan discussions are
+// enabled we check each discussion and only count plan discussions if they're
+// required to be resolved.
+func (g *GitlabClient) discussionsResolved(repo models.Repo, pullNum int, mr *gitlab.MergeRequest) (bool, error) {
+	if !g.PlanDiscussions || (mr.BlockingDiscussionsResolved && !g.RequireResolvedPlanDiscussions) {
+		return mr.BlockingDiscussionsResolved, nil
+	}
+	discussions, err := g.listDiscussions(repo, pullNum)
+	if err != nil {
+		return false, err
+	}
+	for _, d := range discussions {
+		if isResolved(d) {
+			continue
+		}
+		if g.isPlanDiscussion(d) {
+			if g.RequireResolvedPlanDiscussions {
+				return false, nil
+			}
+			continue
+		}
+		// If GitLab says all blocking discussions are resolved, unresolved
+		// discussions don't block merging in this project.
+		if !mr.BlockingDiscussionsResolved {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // PullIsApproved returns true if the merge request was approved.
 func (g *GitlabClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (approvalStatus models.ApprovalStatus, err error) {
 	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
@@ -221,11 +413,16 @@ func (g *GitlabClient) PullIsMergeable(repo models.Repo, pull models.PullRequest
 		}
 	}
 
+	discussionsResolved, err := g.discussionsResolved(repo, pull.Num, mr)
+	if err != nil {
+		return false, err
+	}
+
 	isPipelineSkipped := mr.HeadPipeline.Status == "skipped"
 	allowSkippedPipeline := project.AllowMergeOnSkippedPipeline && isPipelineSkipped
 	if mr.MergeStatus == "can_be_merged" &&
 		mr.ApprovalsBeforeMerge <= 0 &&
-		mr.BlockingDiscussionsResolved &&
+		discussionsResolved &&
 		!mr.WorkInProgress &&
 		(allowSkippedPipeline || !isPipelineSkipped) {
 		return true, nil
@@ -241,7 +438,7 @@ func (g *GitlabClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 		gitlabState = gitlab.Running
 	case models.FailedCommitStatus:
 		gitlabState = gitlab.Failed
-	case models.SuccessCommitStatus:
+	case models.SuccessCommitStatus, models.NeutralCommitStatus:
 		gitlabState = gitlab.Success
 	}
 	_, _, err := g.Client.Commits.SetCommitStatus(repo.FullName, pull.HeadCommit, &gitlab.SetCommitStatusOptions{
@@ -384,6 +581,18 @@ func (g *GitlabClient) SupportsSingleFileDownload(repo models.Repo) bool {
 	return true
 }
 
+// CreateIssue opens an issue in repo and returns its URL.
+func (g *GitlabClient) CreateIssue(repo models.Repo, title string, body string) (string, error) {
+	issue, _, err := g.Client.Issues.CreateIssue(repo.FullName, &gitlab.CreateIssueOptions{
+		Title:       gitlab.String(title),
+		Description: gitlab.String(body),
+	})
+	if err != nil {
+		return "", err
+	}
+	return issue.WebURL, nil
+}
+
 func (g *GitlabClient) GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error) {
 	project, _, err := g.Client.Projects.GetProject(repo, nil)
 	if err != nil {
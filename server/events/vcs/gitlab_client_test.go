@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	version "github.com/hashicorp/go-version"
@@ -333,6 +334,213 @@ func TestGitlabClient_PullIsMergeable(t *testing.T) {
 	}
 }
 
+func TestGitlabClient_CreateOrUpdatePlanDiscussion(t *testing.T) {
+	gitlabClientUnderTest = true
+	cases := []struct {
+		description string
+		discussions string
+		expRequests []string
+	}{
+		{
+			"no plan discussion",
+			`[{"id":"human","individual_note":false,"notes":[{"id":1,"body":"<!-- atlantis plan discussion: dir: . workspace: default project:  -->\nplan","author":{"username":"lkysow"},"resolvable":true,"resolved":false}]}]`,
+			[]string{
+				`POST /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions {"body":"\u003c!-- atlantis plan discussion: dir: . workspace: default project:  --\u003e\nplan"}`,
+			},
+		},
+		{
+			"plan discussion of other project",
+			`[{"id":"other","individual_note":false,"notes":[{"id":1,"body":"<!-- atlantis plan discussion: dir: other workspace: default project:  -->\nplan","author":{"username":"atlantis"},"resolvable":true,"resolved":false}]}]`,
+			[]string{
+				`POST /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions {"body":"\u003c!-- atlantis plan discussion: dir: . workspace: default project:  --\u003e\nplan"}`,
+			},
+		},
+		{
+			"resolved plan discussion",
+			`[{"id":"plan","individual_note":false,"notes":[` +
+				`{"id":1,"body":"<!-- atlantis plan discussion: dir: . workspace: default project:  -->\nold plan","author":{"username":"atlantis"},"resolvable":true,"resolved":true},` +
+				`{"id":2,"body":"old plan continued","author":{"username":"atlantis"},"resolvable":true,"resolved":true},` +
+				`{"id":3,"body":"lgtm","author":{"username":"lkysow"},"resolvable":true,"resolved":true}]}]`,
+			[]string{
+				`PUT /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions/plan/notes/1 {"body":"\u003c!-- atlantis plan discussion: dir: . workspace: default project:  --\u003e\nplan"}`,
+				`DELETE /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions/plan/notes/2 `,
+				`PUT /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions/plan {"resolved":false}`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var requests []string
+			testServer := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case r.RequestURI == "/api/v4/":
+						// Rate limiter requests.
+						w.WriteHeader(http.StatusOK)
+					case r.Method == "GET" && r.URL.Path == "/api/v4/projects/runatlantis/atlantis/merge_requests/1/discussions":
+						w.Write([]byte(c.discussions)) // nolint: errcheck
+					default:
+						body, err := io.ReadAll(r.Body)
+						Ok(t, err)
+						requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, body))
+						if strings.Contains(r.URL.Path, "/notes/") {
+							w.Write([]byte(`{"id":1}`)) // nolint: errcheck
+						} else {
+							w.Write([]byte(`{"id":"plan"}`)) // nolint: errcheck
+						}
+					}
+				}))
+
+			internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+			Ok(t, err)
+			client := &GitlabClient{
+				Client:          internalClient,
+				User:            "atlantis",
+				PlanDiscussions: true,
+			}
+
+			err = client.CreateOrUpdatePlanDiscussion(models.Repo{FullName: "runatlantis/atlantis"}, 1, "dir: . workspace: default project: ", "plan")
+			Ok(t, err)
+			Equals(t, c.expRequests, requests)
+		})
+	}
+}
+
+func TestGitlabClient_ResolvePlanDiscussion(t *testing.T) {
+	var requests []string
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.RequestURI == "/api/v4/":
+				// Rate limiter requests.
+				w.WriteHeader(http.StatusOK)
+			case r.Method == "GET" && r.URL.Path == "/api/v4/projects/runatlantis/atlantis/merge_requests/1/discussions":
+				w.Write([]byte(`[` + // nolint: errcheck
+					`{"id":"plan","individual_note":false,"notes":[{"id":1,"body":"<!-- atlantis plan discussion: dir: . workspace: default project:  -->\nplan","author":{"username":"atlantis"},"resolvable":true,"resolved":false}]},` +
+					`{"id":"other","individual_note":false,"notes":[{"id":1,"body":"<!-- atlantis plan discussion: dir: other workspace: default project:  -->\nplan","author":{"username":"atlantis"},"resolvable":true,"resolved":false}]}]`))
+			default:
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, body))
+				w.Write([]byte(`{"id":"plan"}`)) // nolint: errcheck
+			}
+		}))
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{
+		Client:          internalClient,
+		User:            "atlantis",
+		PlanDiscussions: true,
+	}
+
+	err = client.ResolvePlanDiscussion(models.Repo{FullName: "runatlantis/atlantis"}, 1, "dir: . workspace: default project: ")
+	Ok(t, err)
+	Equals(t, []string{`PUT /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions/plan {"resolved":true}`}, requests)
+}
+
+func TestGitlabClient_PullIsMergeablePlanDiscussions(t *testing.T) {
+	gitlabClientUnderTest = true
+	planDiscussion := `{"id":"plan","individual_note":false,"notes":[{"id":1,"body":"<!-- atlantis plan discussion: dir: . workspace: default project:  -->\nplan","author":{"username":"atlantis"},"resolvable":true,"resolved":false}]}`
+	humanDiscussion := `{"id":"human","individual_note":false,"notes":[{"id":2,"body":"why?","author":{"username":"lkysow"},"resolvable":true,"resolved":false}]}`
+	cases := []struct {
+		description     string
+		requireResolved bool
+		discussions     string
+		expMergeable    bool
+	}{
+		{
+			"unresolved plan discussion",
+			false,
+			"[" + planDiscussion + "]",
+			true,
+		},
+		{
+			"unresolved plan discussion required to be resolved",
+			true,
+			"[" + planDiscussion + "]",
+			false,
+		},
+		{
+			"unresolved plan and other discussion",
+			false,
+			"[" + planDiscussion + "," + humanDiscussion + "]",
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			mr := strings.Replace(pipelineSuccess, `"blocking_discussions_resolved": true`, `"blocking_discussions_resolved": false`, 1)
+			testServer := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/api/v4/":
+						// Rate limiter requests.
+						w.WriteHeader(http.StatusOK)
+					case "/api/v4/projects/runatlantis/atlantis/merge_requests/1":
+						w.Write([]byte(mr)) // nolint: errcheck
+					case "/api/v4/projects/4580910":
+						w.Write([]byte(projectSuccess)) // nolint: errcheck
+					case "/api/v4/projects/4580910/repository/commits/67cb91d3f6198189f433c045154a885784ba6977/statuses":
+						w.Write([]byte(`[]`)) // nolint: errcheck
+					case "/api/v4/projects/runatlantis/atlantis/merge_requests/1/discussions":
+						w.Write([]byte(c.discussions)) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+			Ok(t, err)
+			client := &GitlabClient{
+				Client:                         internalClient,
+				User:                           "atlantis",
+				PlanDiscussions:                true,
+				RequireResolvedPlanDiscussions: c.requireResolved,
+			}
+
+			repo := models.Repo{FullName: "runatlantis/atlantis"}
+			mergeable, err := client.PullIsMergeable(repo, models.PullRequest{
+				Num:      1,
+				BaseRepo: repo,
+			}, "atlantis")
+			Ok(t, err)
+			Equals(t, c.expMergeable, mergeable)
+		})
+	}
+}
+
+func TestGitlabClient_CreateIssue(t *testing.T) {
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/issues":
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"title":"title","description":"body"}`, string(body))
+				w.Write([]byte(`{"id":1,"iid":1,"web_url":"https://gitlab.com/runatlantis/atlantis/-/issues/1"}`)) // nolint: errcheck
+			case "/api/v4/":
+				// Rate limiter requests.
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{
+		Client:  internalClient,
+		Version: nil,
+	}
+
+	url, err := client.CreateIssue(models.Repo{FullName: "runatlantis/atlantis"}, "title", "body")
+	Ok(t, err)
+	Equals(t, "https://gitlab.com/runatlantis/atlantis/-/issues/1", url)
+}
+
 func TestGitlabClient_MarkdownPullLink(t *testing.T) {
 	gitlabClientUnderTest = true
 	defer func() { gitlabClientUnderTest = false }()
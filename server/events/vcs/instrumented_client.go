@@ -228,6 +228,33 @@ func (c *InstrumentedClient) MergePull(pull models.PullRequest, pullOptions mode
 
 }
 
+// CreateIssue opens an issue if the underlying client supports it.
+func (c *InstrumentedClient) CreateIssue(repo models.Repo, title string, body string) (string, error) {
+	creator, ok := c.Client.(IssueCreator)
+	if !ok {
+		return "", fmt.Errorf("opening issues is not supported on %s", repo.VCSHost.Type.String())
+	}
+
+	scope := c.StatsScope.SubScope("create_issue")
+	logger := c.Logger.WithHistory("repository", repo.FullName)
+
+	executionTime := scope.Timer(metrics.ExecutionTimeMetric).Start()
+	defer executionTime.Stop()
+
+	executionSuccess := scope.Counter(metrics.ExecutionSuccessMetric)
+	executionError := scope.Counter(metrics.ExecutionErrorMetric)
+
+	url, err := creator.CreateIssue(repo, title, body)
+	if err != nil {
+		executionError.Inc(1)
+		logger.Err("Unable to create issue, error: %s", err.Error())
+		return "", err
+	}
+
+	executionSuccess.Inc(1)
+	return url, nil
+}
+
 // taken from other parts of the code, would be great to have this in a shared spot
 func fmtLogSrc(repo models.Repo, pullNum int) []interface{} {
 	return []interface{}{
@@ -14,6 +14,8 @@
 package vcs
 
 import (
+	"fmt"
+
 	"github.com/runatlantis/atlantis/server/events/models"
 )
 
@@ -23,9 +25,13 @@ type ClientProxy struct {
 	// clients maps from the vcs host type to the client that implements the
 	// api for that host type, ex. github -> github client.
 	clients map[models.VCSHostType]Client
+	// hostClients maps from VCS hosts other than the default host of their
+	// type to their clients, ex. a GitHub Enterprise host -> its github client.
+	// Repos on these hosts don't use the client for their host type.
+	hostClients map[models.VCSHost]Client
 }
 
-func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client) *ClientProxy {
+func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client, giteaClient Client) *ClientProxy {
 	if githubClient == nil {
 		githubClient = &NotConfiguredVCSClient{}
 	}
@@ -41,6 +47,9 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 	if azuredevopsClient == nil {
 		azuredevopsClient = &NotConfiguredVCSClient{}
 	}
+	if giteaClient == nil {
+		giteaClient = &NotConfiguredVCSClient{}
+	}
 	return &ClientProxy{
 		clients: map[models.VCSHostType]Client{
 			models.Github:          githubClient,
@@ -48,54 +57,109 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 			models.BitbucketCloud:  bitbucketCloudClient,
 			models.BitbucketServer: bitbucketServerClient,
 			models.AzureDevops:     azuredevopsClient,
+			models.Gitea:           giteaClient,
 		},
+		hostClients: make(map[models.VCSHost]Client),
 	}
 }
 
+// AddHost routes calls for repos on host to client instead of the client for
+// the type of host.
+func (d *ClientProxy) AddHost(host models.VCSHost, client Client) {
+	d.hostClients[host] = client
+}
+
+// client returns the client for the VCS host of repo.
+func (d *ClientProxy) client(repo models.Repo) Client {
+	if c, ok := d.hostClients[repo.VCSHost]; ok {
+		return c
+	}
+	return d.clients[repo.VCSHost.Type]
+}
+
 func (d *ClientProxy) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
-	return d.clients[repo.VCSHost.Type].GetModifiedFiles(repo, pull)
+	return d.client(repo).GetModifiedFiles(repo, pull)
 }
 
 func (d *ClientProxy) CreateComment(repo models.Repo, pullNum int, comment string, command string) error {
-	return d.clients[repo.VCSHost.Type].CreateComment(repo, pullNum, comment, command)
+	return d.client(repo).CreateComment(repo, pullNum, comment, command)
 }
 
 func (d *ClientProxy) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
-	return d.clients[repo.VCSHost.Type].HidePrevCommandComments(repo, pullNum, command)
+	return d.client(repo).HidePrevCommandComments(repo, pullNum, command)
 }
 
 func (d *ClientProxy) PullIsApproved(repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
-	return d.clients[repo.VCSHost.Type].PullIsApproved(repo, pull)
+	return d.client(repo).PullIsApproved(repo, pull)
 }
 
 func (d *ClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest, vcsstatusname string) (bool, error) {
-	return d.clients[repo.VCSHost.Type].PullIsMergeable(repo, pull, vcsstatusname)
+	return d.client(repo).PullIsMergeable(repo, pull, vcsstatusname)
 }
 
 func (d *ClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
-	return d.clients[repo.VCSHost.Type].UpdateStatus(repo, pull, state, src, description, url)
+	return d.client(repo).UpdateStatus(repo, pull, state, src, description, url)
 }
 
 func (d *ClientProxy) MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error {
-	return d.clients[pull.BaseRepo.VCSHost.Type].MergePull(pull, pullOptions)
+	return d.client(pull.BaseRepo).MergePull(pull, pullOptions)
 }
 
 func (d *ClientProxy) MarkdownPullLink(pull models.PullRequest) (string, error) {
-	return d.clients[pull.BaseRepo.VCSHost.Type].MarkdownPullLink(pull)
+	return d.client(pull.BaseRepo).MarkdownPullLink(pull)
 }
 
 func (d *ClientProxy) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
-	return d.clients[repo.VCSHost.Type].GetTeamNamesForUser(repo, user)
+	return d.client(repo).GetTeamNamesForUser(repo, user)
 }
 
 func (d *ClientProxy) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
-	return d.clients[pull.BaseRepo.VCSHost.Type].DownloadRepoConfigFile(pull)
+	return d.client(pull.BaseRepo).DownloadRepoConfigFile(pull)
 }
 
 func (d *ClientProxy) SupportsSingleFileDownload(repo models.Repo) bool {
-	return d.clients[repo.VCSHost.Type].SupportsSingleFileDownload(repo)
+	return d.client(repo).SupportsSingleFileDownload(repo)
 }
 
+// GetCloneURL returns the clone URL of repo on the default host of
+// VCSHostType.
 func (d *ClientProxy) GetCloneURL(VCSHostType models.VCSHostType, repo string) (string, error) {
 	return d.clients[VCSHostType].GetCloneURL(VCSHostType, repo)
 }
+
+// CreateIssue opens an issue in repo if the client for its VCS host supports
+// it.
+func (d *ClientProxy) CreateIssue(repo models.Repo, title string, body string) (string, error) {
+	creator, ok := d.client(repo).(IssueCreator)
+	if !ok {
+		return "", fmt.Errorf("opening issues is not supported on %s", repo.VCSHost.Type.String())
+	}
+	return creator.CreateIssue(repo, title, body)
+}
+
+// PostsPlanDiscussions returns true if the client for the VCS host of repo
+// posts plans in discussions.
+func (d *ClientProxy) PostsPlanDiscussions(repo models.Repo) bool {
+	poster, ok := d.client(repo).(PlanDiscussionPoster)
+	return ok && poster.PostsPlanDiscussions(repo)
+}
+
+// CreateOrUpdatePlanDiscussion posts comment in the plan discussion of the
+// project with key if the client for the VCS host of repo supports it.
+func (d *ClientProxy) CreateOrUpdatePlanDiscussion(repo models.Repo, pullNum int, key string, comment string) error {
+	poster, ok := d.client(repo).(PlanDiscussionPoster)
+	if !ok {
+		return fmt.Errorf("plan discussions are not supported on %s", repo.VCSHost.Type.String())
+	}
+	return poster.CreateOrUpdatePlanDiscussion(repo, pullNum, key, comment)
+}
+
+// ResolvePlanDiscussion resolves the plan discussion of the project with key
+// if the client for the VCS host of repo posts plans in discussions.
+func (d *ClientProxy) ResolvePlanDiscussion(repo models.Repo, pullNum int, key string) error {
+	poster, ok := d.client(repo).(PlanDiscussionPoster)
+	if !ok {
+		return nil
+	}
+	return poster.ResolvePlanDiscussion(repo, pullNum, key)
+}
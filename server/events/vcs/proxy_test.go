@@ -0,0 +1,35 @@
+package vcs_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// Repos on hosts added with AddHost should use that host's client and other
+// repos should use the client for their host type.
+func TestClientProxy_AddHost(t *testing.T) {
+	RegisterMockTestingT(t)
+	githubClient := mocks.NewMockClient()
+	gheClient := mocks.NewMockClient()
+	proxy := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	gheHost := models.VCSHost{Hostname: "github.example.com", Type: models.Github}
+	proxy.AddHost(gheHost, gheClient)
+
+	githubRepo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}
+	gheRepo := models.Repo{FullName: "owner/repo", VCSHost: gheHost}
+	Ok(t, proxy.CreateComment(githubRepo, 1, "comment", ""))
+	Ok(t, proxy.CreateComment(gheRepo, 2, "comment", ""))
+
+	githubClient.VerifyWasCalledOnce().CreateComment(githubRepo, 1, "comment", "")
+	githubClient.VerifyWasCalled(Never()).CreateComment(gheRepo, 2, "comment", "")
+	gheClient.VerifyWasCalledOnce().CreateComment(gheRepo, 2, "comment", "")
+
+	// Hosts are matched on their type as well as their hostname.
+	gitlabRepo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.example.com", Type: models.Gitlab}}
+	ErrContains(t, "not configured", proxy.CreateComment(gitlabRepo, 3, "comment", ""))
+}
@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// AsyncSender sends webhooks in the background so that slow webhook URLs
+// don't hold up the commands that send them. Webhooks are queued and sent by
+// a fixed number of workers. If the queue is full, new webhooks are dropped.
+type AsyncSender struct {
+	Sender Sender
+
+	queue  chan queuedEvent
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+type queuedEvent struct {
+	log   logging.SimpleLogging
+	event Event
+}
+
+// NewAsyncSender returns an AsyncSender that sends webhooks with sender from
+// workers goroutines. At most queueSize webhooks wait to be sent.
+func NewAsyncSender(sender Sender, workers int, queueSize int) *AsyncSender {
+	a := &AsyncSender{
+		Sender: sender,
+		queue:  make(chan queuedEvent, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		a.wg.Add(1)
+		go a.work()
+	}
+	return a
+}
+
+// Send queues event to be sent. It doesn't fail: if the queue is full or the
+// sender is closed, the webhook is dropped and a warning is logged.
+func (a *AsyncSender) Send(log logging.SimpleLogging, event Event) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		log.Warn("dropping %s webhook, webhooks are no longer being sent", event.Type)
+		return nil
+	}
+	select {
+	case a.queue <- queuedEvent{log: log, event: event}:
+	default:
+		log.Warn("dropping %s webhook, too many webhooks are waiting to be sent", event.Type)
+	}
+	return nil
+}
+
+// Close stops queueing webhooks and waits for the queued ones to be sent.
+func (a *AsyncSender) Close() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	close(a.queue)
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// work sends the queued webhooks until the queue is closed.
+func (a *AsyncSender) work() {
+	defer a.wg.Done()
+	for q := range a.queue {
+		if err := a.Sender.Send(q.log, q.event); err != nil {
+			q.log.Warn("error sending %s webhook: %s", q.event.Type, err)
+		}
+	}
+}
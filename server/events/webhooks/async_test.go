@@ -0,0 +1,79 @@
+package webhooks_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// blockingSender records the events it sends once release is closed.
+type blockingSender struct {
+	release chan struct{}
+	mu      sync.Mutex
+	sent    []string
+}
+
+func (b *blockingSender) Send(_ logging.SimpleLogging, event webhooks.Event) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, event.Type)
+	return nil
+}
+
+func TestAsyncSender_Send(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	sender := mocks.NewMockSender()
+	event := webhooks.Event{Type: webhooks.ApplyEvent}
+
+	a := webhooks.NewAsyncSender(sender, 2, 10)
+	Ok(t, a.Send(logger, event))
+	a.Close()
+	sender.VerifyWasCalledOnce().Send(logger, event)
+}
+
+func TestAsyncSender_SendDoesntWait(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	sender := &blockingSender{release: make(chan struct{})}
+
+	// Send returns even though the sender can't send yet and the queued
+	// events are sent in order once it can.
+	a := webhooks.NewAsyncSender(sender, 1, 10)
+	Ok(t, a.Send(logger, webhooks.Event{Type: webhooks.PlanEvent}))
+	Ok(t, a.Send(logger, webhooks.Event{Type: webhooks.ApplyEvent}))
+	close(sender.release)
+	a.Close()
+	Equals(t, []string{webhooks.PlanEvent, webhooks.ApplyEvent}, sender.sent)
+}
+
+func TestAsyncSender_SendQueueFull(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	sender := mocks.NewMockSender()
+
+	// Without workers, the second event doesn't fit in the queue and is
+	// dropped instead of waiting.
+	a := webhooks.NewAsyncSender(sender, 0, 1)
+	Ok(t, a.Send(logger, webhooks.Event{Type: webhooks.PlanEvent}))
+	Ok(t, a.Send(logger, webhooks.Event{Type: webhooks.ApplyEvent}))
+	a.Close()
+	sender.VerifyWasCalled(Never()).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent())
+}
+
+func TestAsyncSender_SendAfterClose(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	sender := mocks.NewMockSender()
+
+	a := webhooks.NewAsyncSender(sender, 1, 1)
+	a.Close()
+	Ok(t, a.Send(logger, webhooks.Event{Type: webhooks.ApplyEvent}))
+	sender.VerifyWasCalled(Never()).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent())
+}
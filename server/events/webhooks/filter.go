@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SuccessStatus only sends webhooks for events that succeeded.
+	SuccessStatus = "success"
+	// FailureStatus only sends webhooks for events that failed.
+	FailureStatus = "failure"
+)
+
+// Filter decides which events a webhook is sent for.
+type Filter struct {
+	// Events are the event types to send the webhook for. If empty, it's sent
+	// for all events.
+	Events []string
+	// WorkspaceRegex must match the workspace of events that have one. If
+	// nil, all workspaces match.
+	WorkspaceRegex *regexp.Regexp
+	// RepoRegex must match the full name of the repo. If nil, all repos
+	// match.
+	RepoRegex *regexp.Regexp
+	// Status is SuccessStatus, FailureStatus or empty to match both.
+	Status string
+}
+
+func newFilter(c Config) (Filter, error) {
+	workspaceRegex, err := regexp.Compile(c.WorkspaceRegex)
+	if err != nil {
+		return Filter{}, err
+	}
+	var repoRegex *regexp.Regexp
+	if c.RepoRegex != "" {
+		repoRegex, err = regexp.Compile(c.RepoRegex)
+		if err != nil {
+			return Filter{}, err
+		}
+	}
+
+	events := c.Events
+	if c.Event != "" {
+		events = append([]string{c.Event}, events...)
+	}
+	if len(events) == 0 {
+		return Filter{}, errors.New("must specify \"kind\" and \"event\" or \"events\" keys for webhooks")
+	}
+	for _, e := range events {
+		if !isSupportedEvent(e) {
+			return Filter{}, fmt.Errorf("\"event: %s\" not supported. Supported events are: %s", e, strings.Join(SupportedEvents, ", "))
+		}
+	}
+
+	if c.Status != "" && c.Status != SuccessStatus && c.Status != FailureStatus {
+		return Filter{}, fmt.Errorf("\"status: %s\" not supported. Must be %q or %q", c.Status, SuccessStatus, FailureStatus)
+	}
+
+	return Filter{
+		Events:         events,
+		WorkspaceRegex: workspaceRegex,
+		RepoRegex:      repoRegex,
+		Status:         c.Status,
+	}, nil
+}
+
+// Matches returns true if the webhook should be sent for event.
+func (f Filter) Matches(event Event) bool {
+	if len(f.Events) > 0 && !containsEvent(f.Events, event.Type) {
+		return false
+	}
+	if f.WorkspaceRegex != nil && event.Workspace != "" && !f.WorkspaceRegex.MatchString(event.Workspace) {
+		return false
+	}
+	if f.RepoRegex != nil && !f.RepoRegex.MatchString(event.Repo.FullName) {
+		return false
+	}
+	switch f.Status {
+	case SuccessStatus:
+		return event.Success
+	case FailureStatus:
+		return !event.Success
+	}
+	return true
+}
+
+func isSupportedEvent(e string) bool {
+	return containsEvent(SupportedEvents, e)
+}
+
+func containsEvent(events []string, e string) bool {
+	for _, ev := range events {
+		if ev == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package webhooks_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	event := webhooks.Event{
+		Type:      webhooks.PlanEvent,
+		Workspace: "production",
+		Repo:      models.Repo{FullName: "owner/repo"},
+		Success:   false,
+	}
+	cases := []struct {
+		description string
+		filter      webhooks.Filter
+		exp         bool
+	}{
+		{
+			"empty filter matches everything",
+			webhooks.Filter{},
+			true,
+		},
+		{
+			"event type matches",
+			webhooks.Filter{Events: []string{webhooks.ApplyEvent, webhooks.PlanEvent}},
+			true,
+		},
+		{
+			"event type doesn't match",
+			webhooks.Filter{Events: []string{webhooks.ApplyEvent}},
+			false,
+		},
+		{
+			"workspace doesn't match",
+			webhooks.Filter{WorkspaceRegex: regexp.MustCompile("staging")},
+			false,
+		},
+		{
+			"repo matches",
+			webhooks.Filter{RepoRegex: regexp.MustCompile("^owner/")},
+			true,
+		},
+		{
+			"repo doesn't match",
+			webhooks.Filter{RepoRegex: regexp.MustCompile("^other/")},
+			false,
+		},
+		{
+			"failure status matches",
+			webhooks.Filter{Status: webhooks.FailureStatus},
+			true,
+		},
+		{
+			"success status doesn't match",
+			webhooks.Filter{Status: webhooks.SuccessStatus},
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, c.filter.Matches(event))
+		})
+	}
+}
+
+func TestFilter_MatchesEventsWithoutWorkspace(t *testing.T) {
+	t.Log("the workspace regex shouldn't apply to events that aren't about a workspace")
+	f := webhooks.Filter{WorkspaceRegex: regexp.MustCompile("production")}
+	Equals(t, true, f.Matches(webhooks.Event{Type: webhooks.AutoplanEvent}))
+}
@@ -0,0 +1,206 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// SignatureHeader is the header that holds the HMAC-SHA256 signature of the
+// body of HTTP webhooks, ex. "sha256=<hex digest>".
+const SignatureHeader = "X-Atlantis-Signature"
+
+// httpMaxAttempts is how many times we try to send an HTTP webhook before
+// giving up.
+const httpMaxAttempts = 4
+
+// httpInitialBackoff is how long we wait before the first retry. The wait
+// doubles with each retry.
+const httpInitialBackoff = time.Second
+
+// httpMaxSendTime is the longest we spend sending an HTTP webhook, including
+// retries, so that an unresponsive URL doesn't hold up the worker sending it.
+const httpMaxSendTime = 10 * time.Second
+
+// HTTPWebhook sends webhooks as JSON POST requests to a URL.
+type HTTPWebhook struct {
+	Client *http.Client
+	Filter Filter
+	URL    string
+	// Secret, if set, is used to sign the body of each request. The signature
+	// is sent in the SignatureHeader header.
+	Secret string
+	// Headers are extra headers to set on each request.
+	Headers map[string]string
+	// Sleep waits between retries. If nil, time.Sleep is used.
+	Sleep func(time.Duration)
+}
+
+// HTTPRepo is the repo of an HTTP webhook payload.
+type HTTPRepo struct {
+	FullName string `json:"full_name"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	VCSHost  string `json:"vcs_host"`
+}
+
+// HTTPPull is the pull request of an HTTP webhook payload.
+type HTTPPull struct {
+	Num        int    `json:"num"`
+	URL        string `json:"url"`
+	Author     string `json:"author"`
+	HeadBranch string `json:"head_branch"`
+	BaseBranch string `json:"base_branch"`
+	HeadCommit string `json:"head_commit"`
+}
+
+// HTTPPayload is the body of the requests sent by HTTPWebhook. We don't send
+// Event directly because its repo's clone URL contains credentials.
+type HTTPPayload struct {
+	Event       string   `json:"event"`
+	Repo        HTTPRepo `json:"repo"`
+	Pull        HTTPPull `json:"pull"`
+	User        string   `json:"user"`
+	Workspace   string   `json:"workspace"`
+	Directory   string   `json:"directory"`
+	ProjectName string   `json:"project_name"`
+	PlanSummary string   `json:"plan_summary"`
+	Success     bool     `json:"success"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func NewHTTP(f Filter, url string, secret string, headers map[string]string) *HTTPWebhook {
+	return &HTTPWebhook{
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Filter:  f,
+		URL:     url,
+		Secret:  secret,
+		Headers: headers,
+	}
+}
+
+// Send sends the webhook to the URL if the event matches the filter. If the
+// request fails with a 5xx response or can't be sent, it's retried with
+// exponential backoff.
+func (h *HTTPWebhook) Send(log logging.SimpleLogging, event Event) error {
+	if !h.Filter.Matches(event) {
+		return nil
+	}
+
+	body, err := json.Marshal(NewHTTPPayload(event))
+	if err != nil {
+		return errors.Wrap(err, "marshalling webhook payload")
+	}
+
+	headers := make(map[string]string)
+	for k, v := range h.Headers {
+		headers[k] = v
+	}
+	if h.Secret != "" {
+		headers[SignatureHeader] = Sign(h.Secret, body)
+	}
+	return postJSON(log, h.Client, h.Sleep, h.URL, body, headers)
+}
+
+// postJSON posts the JSON body to url with the extra headers. If the request
+// fails with a 5xx response or can't be sent, it's retried with exponential
+// backoff until httpMaxSendTime has passed. sleep waits between retries. If
+// nil, time.Sleep is used.
+func postJSON(log logging.SimpleLogging, client *http.Client, sleep func(time.Duration), url string, body []byte, headers map[string]string) error {
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	deadline := time.Now().Add(httpMaxSendTime)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	backoff := httpInitialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := post(ctx, client, url, body, headers)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == httpMaxAttempts || time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+		log.Debug("sending webhook to %s failed, retrying in %s: %s", url, backoff, err)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends body to url. If it fails, it returns whether the request should
+// be retried.
+func post(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "creating webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "atlantis")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "sending webhook")
+	}
+	defer resp.Body.Close()            // nolint: errcheck
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("sending webhook: %s responded with %d", url, resp.StatusCode)
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("sending webhook: %s responded with %d", url, resp.StatusCode)
+	}
+	return false, nil
+}
+
+// Sign returns the value of the SignatureHeader header for body signed with
+// secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint: errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewHTTPPayload returns the payload that's sent for event.
+func NewHTTPPayload(event Event) HTTPPayload {
+	return HTTPPayload{
+		Event: event.Type,
+		Repo: HTTPRepo{
+			FullName: event.Repo.FullName,
+			Owner:    event.Repo.Owner,
+			Name:     event.Repo.Name,
+			VCSHost:  event.Repo.VCSHost.Hostname,
+		},
+		Pull: HTTPPull{
+			Num:        event.Pull.Num,
+			URL:        event.Pull.URL,
+			Author:     event.Pull.Author,
+			HeadBranch: event.Pull.HeadBranch,
+			BaseBranch: event.Pull.BaseBranch,
+			HeadCommit: event.Pull.HeadCommit,
+		},
+		User:        event.User.Username,
+		Workspace:   event.Workspace,
+		Directory:   event.Directory,
+		ProjectName: event.ProjectName,
+		PlanSummary: event.PlanSummary,
+		Success:     event.Success,
+		Error:       event.Error,
+	}
+}
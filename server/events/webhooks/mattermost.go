@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	mattermostSuccessColour = "#2eb886"
+	mattermostFailureColour = "#a30200"
+)
+
+// MattermostWebhook sends webhooks to a Mattermost incoming webhook.
+type MattermostWebhook struct {
+	Client     *http.Client
+	Filter     Filter
+	URL        string
+	PullLinker PullLinker
+	// Channel, if set, overrides the channel of the incoming webhook.
+	Channel string
+	// Sleep waits between retries. If nil, time.Sleep is used.
+	Sleep func(time.Duration)
+}
+
+// MattermostMessage is the body of the requests sent by MattermostWebhook.
+type MattermostMessage struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Attachments []MattermostAttachment `json:"attachments"`
+}
+
+// MattermostAttachment is a message attachment.
+type MattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color"`
+	Text     string            `json:"text"`
+	Fields   []MattermostField `json:"fields,omitempty"`
+}
+
+// MattermostField is a field of a MattermostAttachment.
+type MattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func NewMattermost(f Filter, url string, channel string, linker PullLinker) *MattermostWebhook {
+	return &MattermostWebhook{
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		Filter:     f,
+		URL:        url,
+		PullLinker: linker,
+		Channel:    channel,
+	}
+}
+
+// Send sends the webhook to Mattermost if the event matches the filter.
+func (m *MattermostWebhook) Send(log logging.SimpleLogging, event Event) error {
+	if !m.Filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(m.NewMessage(event))
+	if err != nil {
+		return errors.Wrap(err, "marshalling webhook payload")
+	}
+	return postJSON(log, m.Client, m.Sleep, m.URL, body, nil)
+}
+
+// NewMessage returns the message that's sent for event.
+func (m *MattermostWebhook) NewMessage(event Event) MattermostMessage {
+	colour := mattermostFailureColour
+	if event.Success {
+		colour = mattermostSuccessColour
+	}
+	text := markdownMessageText(m.PullLinker, event)
+	var fields []MattermostField
+	for _, f := range messageFields(event) {
+		fields = append(fields, MattermostField{Title: f.Title, Value: f.Value, Short: f.Short})
+	}
+	return MattermostMessage{
+		Channel: m.Channel,
+		Attachments: []MattermostAttachment{{
+			Fallback: text,
+			Color:    colour,
+			Text:     text,
+			Fields:   fields,
+		}},
+	}
+}
@@ -0,0 +1,88 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestMattermostWebhook_Send(t *testing.T) {
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMattermost(webhooks.Filter{WorkspaceRegex: regexp.MustCompile(".*")}, server.URL, "deploys", testPullLinker{})
+	Ok(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent))
+
+	Equals(t, "application/json", contentType)
+	var msg webhooks.MattermostMessage
+	Ok(t, json.Unmarshal(body, &msg))
+	text := "Apply succeeded for [owner/repo!1](https://github.com/owner/repo/pull/1)"
+	Equals(t, webhooks.MattermostMessage{
+		Channel: "deploys",
+		Attachments: []webhooks.MattermostAttachment{{
+			Fallback: text,
+			Color:    "#2eb886",
+			Text:     text,
+			Fields: []webhooks.MattermostField{
+				{Title: "Workspace", Value: "production", Short: true},
+				{Title: "User", Value: "lkysow", Short: true},
+				{Title: "Directory", Value: "dir", Short: true},
+				{Title: "Project", Value: "project", Short: true},
+				{Title: "Plan", Value: "Plan: 1 to add, 0 to change, 0 to destroy."},
+			},
+		}},
+	}, msg)
+}
+
+func TestMattermostWebhook_Failure(t *testing.T) {
+	t.Log("failed events should be rendered in the failure colour with their error")
+	hook := webhooks.NewMattermost(webhooks.Filter{}, "https://example.com", "", testPullLinker{})
+	event := httpApplyEvent
+	event.Type = webhooks.PolicyCheckEvent
+	event.Success = false
+	event.Error = "policies failed"
+
+	msg := hook.NewMessage(event)
+	Equals(t, "", msg.Channel)
+	Equals(t, "Policy check failed for [owner/repo!1](https://github.com/owner/repo/pull/1)", msg.Attachments[0].Text)
+	Equals(t, "#a30200", msg.Attachments[0].Color)
+	fields := msg.Attachments[0].Fields
+	Equals(t, webhooks.MattermostField{Title: "Error", Value: "policies failed"}, fields[len(fields)-1])
+}
+
+func TestMattermostWebhook_NoopIfWorkspaceDoesNotMatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMattermost(webhooks.Filter{WorkspaceRegex: regexp.MustCompile("staging")}, server.URL, "", testPullLinker{})
+	Ok(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent))
+	Equals(t, 0, requests)
+}
+
+func TestMattermostWebhook_DoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMattermost(webhooks.Filter{}, server.URL, "", testPullLinker{})
+	Assert(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent) != nil, "expected error")
+	Equals(t, 1, requests)
+}
@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// PullLinker renders the reference to a pull request that's used in
+// markdown, ex. "#1" on GitHub. vcs.Client implements it.
+type PullLinker interface {
+	MarkdownPullLink(pull models.PullRequest) (string, error)
+}
+
+// messageField is a titled value shown in chat messages about an event.
+type messageField struct {
+	Title string
+	Value string
+	// Short is true if the field is short enough to be shown next to others.
+	Short bool
+}
+
+// eventTitle returns how the event type is shown in chat messages.
+func eventTitle(eventType string) string {
+	switch eventType {
+	case PolicyCheckEvent:
+		return "Policy check"
+	case ApprovePoliciesEvent:
+		return "Policy approval"
+	case PullClosedEvent:
+		return "Pull request cleanup"
+	case DriftEvent:
+		return "Drift detection"
+	}
+	if eventType == "" {
+		return ""
+	}
+	return strings.ToUpper(eventType[:1]) + eventType[1:]
+}
+
+// markdownMessageText returns the text of chat messages about event that
+// support markdown, ex. "Apply succeeded for [owner/repo#1](url)".
+func markdownMessageText(linker PullLinker, event Event) string {
+	successWord := "failed"
+	if event.Success {
+		successWord = "succeeded"
+	}
+	return fmt.Sprintf("%s %s for %s", eventTitle(event.Type), successWord, markdownPullLink(linker, event))
+}
+
+// markdownPullLink returns a markdown link to the pull request of event, ex.
+// "[owner/repo#1](url)". If linker can't render the reference to the pull
+// request, "#<num>" is used. Events that aren't about a pull request, ex.
+// DriftEvent, only show the repo.
+func markdownPullLink(linker PullLinker, event Event) string {
+	if event.Pull.Num == 0 {
+		return event.Repo.FullName
+	}
+	ref := fmt.Sprintf("#%d", event.Pull.Num)
+	if linker != nil {
+		if l, err := linker.MarkdownPullLink(event.Pull); err == nil {
+			ref = l
+		}
+	}
+	return fmt.Sprintf("[%s%s](%s)", event.Repo.FullName, ref, event.Pull.URL)
+}
+
+// messageFields returns the fields of chat messages about event. Fields that
+// are empty for event are left out.
+func messageFields(event Event) []messageField {
+	directory := event.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+	candidates := []messageField{
+		{Title: "Workspace", Value: event.Workspace, Short: true},
+		{Title: "User", Value: event.User.Username, Short: true},
+		{Title: "Directory", Value: directory, Short: true},
+		{Title: "Project", Value: event.ProjectName, Short: true},
+		{Title: "Plan", Value: event.PlanSummary},
+		{Title: "Error", Value: event.Error},
+	}
+	var fields []messageField
+	for _, f := range candidates {
+		if f.Value != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
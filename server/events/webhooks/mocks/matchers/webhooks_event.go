@@ -9,26 +9,26 @@ import (
 	webhooks "github.com/runatlantis/atlantis/server/events/webhooks"
 )
 
-func AnyWebhooksApplyResult() webhooks.ApplyResult {
-	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(webhooks.ApplyResult))(nil)).Elem()))
-	var nullValue webhooks.ApplyResult
+func AnyWebhooksEvent() webhooks.Event {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(webhooks.Event))(nil)).Elem()))
+	var nullValue webhooks.Event
 	return nullValue
 }
 
-func EqWebhooksApplyResult(value webhooks.ApplyResult) webhooks.ApplyResult {
+func EqWebhooksEvent(value webhooks.Event) webhooks.Event {
 	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
-	var nullValue webhooks.ApplyResult
+	var nullValue webhooks.Event
 	return nullValue
 }
 
-func NotEqWebhooksApplyResult(value webhooks.ApplyResult) webhooks.ApplyResult {
+func NotEqWebhooksEvent(value webhooks.Event) webhooks.Event {
 	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
-	var nullValue webhooks.ApplyResult
+	var nullValue webhooks.Event
 	return nullValue
 }
 
-func WebhooksApplyResultThat(matcher pegomock.ArgumentMatcher) webhooks.ApplyResult {
+func WebhooksEventThat(matcher pegomock.ArgumentMatcher) webhooks.Event {
 	pegomock.RegisterMatcher(matcher)
-	var nullValue webhooks.ApplyResult
+	var nullValue webhooks.Event
 	return nullValue
 }
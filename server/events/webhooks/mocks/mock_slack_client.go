@@ -56,11 +56,11 @@ func (mock *MockSlackClient) TokenIsSet() bool {
 	return ret0
 }
 
-func (mock *MockSlackClient) PostMessage(channel string, applyResult webhooks.ApplyResult) error {
+func (mock *MockSlackClient) PostMessage(channel string, event webhooks.Event) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockSlackClient().")
 	}
-	params := []pegomock.Param{channel, applyResult}
+	params := []pegomock.Param{channel, event}
 	result := pegomock.GetGenericMockFrom(mock).Invoke("PostMessage", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
 	var ret0 error
 	if len(result) != 0 {
@@ -142,10 +142,8 @@ func (c *MockSlackClient_TokenIsSet_OngoingVerification) GetCapturedArguments()
 func (c *MockSlackClient_TokenIsSet_OngoingVerification) GetAllCapturedArguments() {
 }
 
-
-
-func (verifier *VerifierMockSlackClient) PostMessage(channel string, applyResult webhooks.ApplyResult) *MockSlackClient_PostMessage_OngoingVerification {
-	params := []pegomock.Param{channel, applyResult}
+func (verifier *VerifierMockSlackClient) PostMessage(channel string, event webhooks.Event) *MockSlackClient_PostMessage_OngoingVerification {
+	params := []pegomock.Param{channel, event}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PostMessage", params, verifier.timeout)
 	return &MockSlackClient_PostMessage_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -155,21 +153,21 @@ type MockSlackClient_PostMessage_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockSlackClient_PostMessage_OngoingVerification) GetCapturedArguments() (string, webhooks.ApplyResult) {
-	channel, applyResult := c.GetAllCapturedArguments()
-	return channel[len(channel)-1], applyResult[len(applyResult)-1]
+func (c *MockSlackClient_PostMessage_OngoingVerification) GetCapturedArguments() (string, webhooks.Event) {
+	channel, event := c.GetAllCapturedArguments()
+	return channel[len(channel)-1], event[len(event)-1]
 }
 
-func (c *MockSlackClient_PostMessage_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []webhooks.ApplyResult) {
+func (c *MockSlackClient_PostMessage_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []webhooks.Event) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
 		_param0 = make([]string, len(c.methodInvocations))
 		for u, param := range params[0] {
 			_param0[u] = param.(string)
 		}
-		_param1 = make([]webhooks.ApplyResult, len(c.methodInvocations))
+		_param1 = make([]webhooks.Event, len(c.methodInvocations))
 		for u, param := range params[1] {
-			_param1[u] = param.(webhooks.ApplyResult)
+			_param1[u] = param.(webhooks.Event)
 		}
 	}
 	return
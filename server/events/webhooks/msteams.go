@@ -0,0 +1,122 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	msTeamsCardContentType = "application/vnd.microsoft.card.adaptive"
+	msTeamsCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	msTeamsCardVersion     = "1.4"
+	msTeamsSuccessColour   = "Good"
+	msTeamsFailureColour   = "Attention"
+)
+
+// MSTeamsWebhook sends webhooks as Adaptive Cards to a Microsoft Teams
+// incoming webhook.
+type MSTeamsWebhook struct {
+	Client     *http.Client
+	Filter     Filter
+	URL        string
+	PullLinker PullLinker
+	// Sleep waits between retries. If nil, time.Sleep is used.
+	Sleep func(time.Duration)
+}
+
+// MSTeamsMessage is the body of the requests sent by MSTeamsWebhook.
+type MSTeamsMessage struct {
+	Type        string              `json:"type"`
+	Attachments []MSTeamsAttachment `json:"attachments"`
+}
+
+// MSTeamsAttachment is an attachment of an MSTeamsMessage.
+type MSTeamsAttachment struct {
+	ContentType string      `json:"contentType"`
+	Content     MSTeamsCard `json:"content"`
+}
+
+// MSTeamsCard is an Adaptive Card.
+type MSTeamsCard struct {
+	Schema  string               `json:"$schema"`
+	Type    string               `json:"type"`
+	Version string               `json:"version"`
+	Body    []MSTeamsCardElement `json:"body"`
+}
+
+// MSTeamsCardElement is a TextBlock or FactSet element of an Adaptive Card.
+type MSTeamsCardElement struct {
+	Type   string        `json:"type"`
+	Text   string        `json:"text,omitempty"`
+	Weight string        `json:"weight,omitempty"`
+	Color  string        `json:"color,omitempty"`
+	Wrap   bool          `json:"wrap,omitempty"`
+	Facts  []MSTeamsFact `json:"facts,omitempty"`
+}
+
+// MSTeamsFact is a fact of a FactSet.
+type MSTeamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func NewMSTeams(f Filter, url string, linker PullLinker) *MSTeamsWebhook {
+	return &MSTeamsWebhook{
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		Filter:     f,
+		URL:        url,
+		PullLinker: linker,
+	}
+}
+
+// Send sends the webhook to Microsoft Teams if the event matches the filter.
+func (m *MSTeamsWebhook) Send(log logging.SimpleLogging, event Event) error {
+	if !m.Filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(m.NewMessage(event))
+	if err != nil {
+		return errors.Wrap(err, "marshalling webhook payload")
+	}
+	return postJSON(log, m.Client, m.Sleep, m.URL, body, nil)
+}
+
+// NewMessage returns the message that's sent for event.
+func (m *MSTeamsWebhook) NewMessage(event Event) MSTeamsMessage {
+	colour := msTeamsFailureColour
+	if event.Success {
+		colour = msTeamsSuccessColour
+	}
+	var facts []MSTeamsFact
+	for _, f := range messageFields(event) {
+		facts = append(facts, MSTeamsFact{Title: f.Title, Value: f.Value})
+	}
+	return MSTeamsMessage{
+		Type: "message",
+		Attachments: []MSTeamsAttachment{{
+			ContentType: msTeamsCardContentType,
+			Content: MSTeamsCard{
+				Schema:  msTeamsCardSchema,
+				Type:    "AdaptiveCard",
+				Version: msTeamsCardVersion,
+				Body: []MSTeamsCardElement{
+					{
+						Type:   "TextBlock",
+						Text:   markdownMessageText(m.PullLinker, event),
+						Weight: "Bolder",
+						Color:  colour,
+						Wrap:   true,
+					},
+					{
+						Type:  "FactSet",
+						Facts: facts,
+					},
+				},
+			},
+		}},
+	}
+}
@@ -0,0 +1,128 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// testPullLinker renders pull request references like GitLab does.
+type testPullLinker struct {
+	err error
+}
+
+func (l testPullLinker) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	if l.err != nil {
+		return "", l.err
+	}
+	return "!1", nil
+}
+
+func TestMSTeamsWebhook_Send(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMSTeams(webhooks.Filter{WorkspaceRegex: regexp.MustCompile(".*")}, server.URL, testPullLinker{})
+	Ok(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent))
+
+	var msg webhooks.MSTeamsMessage
+	Ok(t, json.Unmarshal(body, &msg))
+	Equals(t, webhooks.MSTeamsMessage{
+		Type: "message",
+		Attachments: []webhooks.MSTeamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: webhooks.MSTeamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []webhooks.MSTeamsCardElement{
+					{
+						Type:   "TextBlock",
+						Text:   "Apply succeeded for [owner/repo!1](https://github.com/owner/repo/pull/1)",
+						Weight: "Bolder",
+						Color:  "Good",
+						Wrap:   true,
+					},
+					{
+						Type: "FactSet",
+						Facts: []webhooks.MSTeamsFact{
+							{Title: "Workspace", Value: "production"},
+							{Title: "User", Value: "lkysow"},
+							{Title: "Directory", Value: "dir"},
+							{Title: "Project", Value: "project"},
+							{Title: "Plan", Value: "Plan: 1 to add, 0 to change, 0 to destroy."},
+						},
+					},
+				},
+			},
+		}},
+	}, msg)
+}
+
+func TestMSTeamsWebhook_Failure(t *testing.T) {
+	t.Log("failed events should be rendered in the failure colour with their error")
+	hook := webhooks.NewMSTeams(webhooks.Filter{}, "https://example.com", testPullLinker{err: errors.New("err")})
+	event := httpApplyEvent
+	event.Success = false
+	event.Error = "exit status 1"
+
+	msg := hook.NewMessage(event)
+	title := msg.Attachments[0].Content.Body[0]
+	Equals(t, "Apply failed for [owner/repo#1](https://github.com/owner/repo/pull/1)", title.Text)
+	Equals(t, "Attention", title.Color)
+	facts := msg.Attachments[0].Content.Body[1].Facts
+	Equals(t, webhooks.MSTeamsFact{Title: "Error", Value: "exit status 1"}, facts[len(facts)-1])
+}
+
+func TestMSTeamsWebhook_Drift(t *testing.T) {
+	t.Log("drift events aren't about a pull request so only the repo should be shown")
+	hook := webhooks.NewMSTeams(webhooks.Filter{}, "https://example.com", testPullLinker{})
+	event := httpApplyEvent
+	event.Type = webhooks.DriftEvent
+	event.Pull = models.PullRequest{}
+	event.Success = false
+
+	msg := hook.NewMessage(event)
+	Equals(t, "Drift detection failed for owner/repo", msg.Attachments[0].Content.Body[0].Text)
+}
+
+func TestMSTeamsWebhook_NoopIfRepoDoesNotMatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMSTeams(webhooks.Filter{RepoRegex: regexp.MustCompile("^other/")}, server.URL, testPullLinker{})
+	Ok(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent))
+	Equals(t, 0, requests)
+}
+
+func TestMSTeamsWebhook_RetriesServerErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	hook := webhooks.NewMSTeams(webhooks.Filter{}, server.URL, testPullLinker{})
+	hook.Sleep = func(time.Duration) {}
+	Ok(t, hook.Send(logging.NewNoopLogger(t), httpApplyEvent))
+	Equals(t, 2, requests)
+}
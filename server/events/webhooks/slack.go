@@ -14,8 +14,6 @@
 package webhooks
 
 import (
-	"regexp"
-
 	"fmt"
 
 	"github.com/runatlantis/atlantis/server/logging"
@@ -23,27 +21,27 @@ import (
 
 // SlackWebhook sends webhooks to Slack.
 type SlackWebhook struct {
-	Client         SlackClient
-	WorkspaceRegex *regexp.Regexp
-	Channel        string
+	Client  SlackClient
+	Filter  Filter
+	Channel string
 }
 
-func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
+func NewSlack(f Filter, channel string, client SlackClient) (*SlackWebhook, error) {
 	if err := client.AuthTest(); err != nil {
 		return nil, fmt.Errorf("testing slack authentication: %s. Verify your slack-token is valid", err)
 	}
 
 	return &SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: r,
-		Channel:        channel,
+		Client:  client,
+		Filter:  f,
+		Channel: channel,
 	}, nil
 }
 
-// Send sends the webhook to Slack if the workspace matches the regex.
-func (s *SlackWebhook) Send(log logging.SimpleLogging, applyResult ApplyResult) error {
-	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) {
+// Send sends the webhook to Slack if the event matches the filter.
+func (s *SlackWebhook) Send(log logging.SimpleLogging, event Event) error {
+	if !s.Filter.Matches(event) {
 		return nil
 	}
-	return s.Client.PostMessage(s.Channel, applyResult)
+	return s.Client.PostMessage(s.Channel, event)
 }
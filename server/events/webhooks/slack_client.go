@@ -30,7 +30,7 @@ const (
 type SlackClient interface {
 	AuthTest() error
 	TokenIsSet() bool
-	PostMessage(channel string, applyResult ApplyResult) error
+	PostMessage(channel string, event Event) error
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_underlying_slack_client.go UnderlyingSlackClient
@@ -64,19 +64,19 @@ func (d *DefaultSlackClient) TokenIsSet() bool {
 	return d.Token != ""
 }
 
-func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult) error {
+func (d *DefaultSlackClient) PostMessage(channel string, event Event) error {
 	params := slack.NewPostMessageParameters()
-	params.Attachments = d.createAttachments(applyResult)
+	params.Attachments = d.createAttachments(event)
 	params.AsUser = true
 	params.EscapeText = false
 	_, _, err := d.Slack.PostMessage(channel, "", params)
 	return err
 }
 
-func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.Attachment {
+func (d *DefaultSlackClient) createAttachments(event Event) []slack.Attachment {
 	var colour string
 	var successWord string
-	if applyResult.Success {
+	if event.Success {
 		colour = slackSuccessColour
 		successWord = "succeeded"
 	} else {
@@ -84,8 +84,11 @@ func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.
 		successWord = "failed"
 	}
 
-	text := fmt.Sprintf("Apply %s for <%s|%s>", successWord, applyResult.Pull.URL, applyResult.Repo.FullName)
-	directory := applyResult.Directory
+	text := fmt.Sprintf("%s %s for <%s|%s>", eventTitle(event.Type), successWord, event.Pull.URL, event.Repo.FullName)
+	if event.Pull.Num == 0 {
+		text = fmt.Sprintf("%s %s for %s", eventTitle(event.Type), successWord, event.Repo.FullName)
+	}
+	directory := event.Directory
 	// Since "." looks weird, replace it with "/" to make it clear this is the root.
 	if directory == "." {
 		directory = "/"
@@ -97,12 +100,12 @@ func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.
 		Fields: []slack.AttachmentField{
 			{
 				Title: "Workspace",
-				Value: applyResult.Workspace,
+				Value: event.Workspace,
 				Short: true,
 			},
 			{
 				Title: "User",
-				Value: applyResult.User.Username,
+				Value: event.User.Username,
 				Short: true,
 			},
 			{
@@ -112,5 +115,11 @@ func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.
 			},
 		},
 	}
+	if event.Error != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Error",
+			Value: event.Error,
+		})
+	}
 	return []slack.Attachment{attachment}
 }
@@ -28,7 +28,7 @@ import (
 
 var underlying *mocks.MockUnderlyingSlackClient
 var client webhooks.DefaultSlackClient
-var result webhooks.ApplyResult
+var result webhooks.Event
 
 func TestAuthTest_Success(t *testing.T) {
 	t.Log("When the underlying client succeeds, function should succeed")
@@ -144,7 +144,8 @@ func setup(t *testing.T) {
 		Slack: underlying,
 		Token: "sometoken",
 	}
-	result = webhooks.ApplyResult{
+	result = webhooks.Event{
+		Type:      webhooks.ApplyEvent,
 		Workspace: "production",
 		Repo: models.Repo{
 			FullName: "runatlantis/atlantis",
@@ -33,11 +33,12 @@ func TestSend_PostMessage(t *testing.T) {
 
 	channel := "somechannel"
 	hook := webhooks.SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: regex,
-		Channel:        channel,
+		Client:  client,
+		Filter:  webhooks.Filter{WorkspaceRegex: regex},
+		Channel: channel,
 	}
-	result := webhooks.ApplyResult{
+	result := webhooks.Event{
+		Type:      webhooks.ApplyEvent,
 		Workspace: "production",
 	}
 
@@ -55,11 +56,12 @@ func TestSend_NoopSuccess(t *testing.T) {
 
 	channel := "somechannel"
 	hook := webhooks.SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: regex,
-		Channel:        channel,
+		Client:  client,
+		Filter:  webhooks.Filter{WorkspaceRegex: regex},
+		Channel: channel,
 	}
-	result := webhooks.ApplyResult{
+	result := webhooks.Event{
+		Type:      webhooks.ApplyEvent,
 		Workspace: "production",
 	}
 	err = hook.Send(logging.NewNoopLogger(t), result)
@@ -15,7 +15,7 @@ package webhooks
 
 import (
 	"fmt"
-	"regexp"
+	"net/url"
 
 	"errors"
 
@@ -24,24 +24,78 @@ import (
 )
 
 const SlackKind = "slack"
-const ApplyEvent = "apply"
+const HTTPKind = "http"
+const MSTeamsKind = "msteams"
+const MattermostKind = "mattermost"
+
+// The events webhooks can be sent for. Events that are named after a command
+// are sent for each project the command runs for.
+const (
+	ApplyEvent           = "apply"
+	PlanEvent            = "plan"
+	PolicyCheckEvent     = "policy_check"
+	ApprovePoliciesEvent = "approve_policies"
+	VersionEvent         = "version"
+	// AutoplanEvent is sent once autoplanning a pull request has finished. It
+	// fails if the projects to plan couldn't be determined or any plan failed.
+	AutoplanEvent = "autoplan"
+	// LockEvent is sent when a project lock is acquired, or fails to be
+	// acquired because another pull request holds it.
+	LockEvent = "lock"
+	// UnlockEvent is sent when a project lock is released.
+	UnlockEvent = "unlock"
+	// PullClosedEvent is sent once the locks and plans of a closed pull
+	// request have been cleaned up.
+	PullClosedEvent = "pull_closed"
+	// DriftEvent is sent when a project on the default branch of its repo
+	// drifted or couldn't be planned during drift detection. Its pull
+	// request is empty.
+	DriftEvent = "drift"
+)
+
+// SupportedEvents are the events that can be configured for webhooks.
+var SupportedEvents = []string{
+	ApplyEvent,
+	PlanEvent,
+	PolicyCheckEvent,
+	ApprovePoliciesEvent,
+	VersionEvent,
+	AutoplanEvent,
+	LockEvent,
+	UnlockEvent,
+	PullClosedEvent,
+	DriftEvent,
+}
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sender.go Sender
 
 // Sender sends webhooks.
 type Sender interface {
 	// Send sends the webhook (if the implementation thinks it should).
-	Send(log logging.SimpleLogging, applyResult ApplyResult) error
+	Send(log logging.SimpleLogging, event Event) error
 }
 
-// ApplyResult is the result of a terraform apply.
-type ApplyResult struct {
+// Event is what webhooks are sent for.
+type Event struct {
+	// Type is the kind of event, ex. ApplyEvent.
+	Type string
+	// Workspace is empty for events that aren't about a single project, ex.
+	// AutoplanEvent.
 	Workspace string
 	Repo      models.Repo
 	Pull      models.PullRequest
-	User      models.User
-	Success   bool
-	Directory string
+	// User is the user that ran the command. For lock and unlock events, it's
+	// the user the lock belongs to.
+	User        models.User
+	Success     bool
+	Directory   string
+	ProjectName string
+	// PlanSummary is the one line summary of the plan that was made or
+	// applied, ex. "Plan: 1 to add, 0 to change, 0 to destroy.". It's empty
+	// if there is no plan.
+	PlanSummary string
+	// Error is why the event failed. It's only set if Success is false.
+	Error string
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
@@ -50,24 +104,35 @@ type MultiWebhookSender struct {
 }
 
 type Config struct {
+	// Event is a single event to send the webhook for. It's kept for
+	// backwards compatibility, use Events instead.
 	Event          string
+	Events         []string
 	WorkspaceRegex string
-	Kind           string
-	Channel        string
+	RepoRegex      string
+	// Status is SuccessStatus or FailureStatus to only send the webhook for
+	// events that succeeded or failed. If empty, it's sent for both.
+	Status string
+	Kind   string
+	// Channel is the channel of slack webhooks, and overrides the channel of
+	// mattermost webhooks.
+	Channel string
+	URL     string
+	Secret  string
+	Headers map[string]string
 }
 
-func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookSender, error) {
+// NewMultiWebhookSender returns a MultiWebhookSender that sends the webhooks
+// in configs. pullLinker renders the links to pull requests in chat messages.
+func NewMultiWebhookSender(configs []Config, client SlackClient, pullLinker PullLinker) (*MultiWebhookSender, error) {
 	var webhooks []Sender
 	for _, c := range configs {
-		r, err := regexp.Compile(c.WorkspaceRegex)
+		f, err := newFilter(c)
 		if err != nil {
 			return nil, err
 		}
-		if c.Kind == "" || c.Event == "" {
-			return nil, errors.New("must specify \"kind\" and \"event\" keys for webhooks")
-		}
-		if c.Event != ApplyEvent {
-			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\" is supported right now", c.Event, ApplyEvent)
+		if c.Kind == "" {
+			return nil, errors.New("must specify \"kind\" and \"event\" or \"events\" keys for webhooks")
 		}
 		switch c.Kind {
 		case SlackKind:
@@ -77,13 +142,28 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 			if c.Channel == "" {
 				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\"")
 			}
-			slack, err := NewSlack(r, c.Channel, client)
+			slack, err := NewSlack(f, c.Channel, client)
 			if err != nil {
 				return nil, err
 			}
 			webhooks = append(webhooks, slack)
+		case HTTPKind:
+			if err := validateURL(c); err != nil {
+				return nil, err
+			}
+			webhooks = append(webhooks, NewHTTP(f, c.URL, c.Secret, c.Headers))
+		case MSTeamsKind:
+			if err := validateURL(c); err != nil {
+				return nil, err
+			}
+			webhooks = append(webhooks, NewMSTeams(f, c.URL, pullLinker))
+		case MattermostKind:
+			if err := validateURL(c); err != nil {
+				return nil, err
+			}
+			webhooks = append(webhooks, NewMattermost(f, c.URL, c.Channel, pullLinker))
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" is supported right now", c.Kind, SlackKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Supported kinds are: %s, %s, %s, %s", c.Kind, SlackKind, HTTPKind, MSTeamsKind, MattermostKind)
 		}
 	}
 
@@ -92,11 +172,23 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 	}, nil
 }
 
+// validateURL returns an error if the URL of c, which is required for its
+// kind, is missing or isn't an http or https URL.
+func validateURL(c Config) error {
+	if c.URL == "" {
+		return fmt.Errorf("must specify \"url\" if using a webhook of \"kind: %s\"", c.Kind)
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("\"url: %s\" must be an http or https URL", c.URL)
+	}
+	return nil
+}
+
 // Send sends the webhook using its Webhooks.
-func (w *MultiWebhookSender) Send(log logging.SimpleLogging, result ApplyResult) error {
+func (w *MultiWebhookSender) Send(log logging.SimpleLogging, event Event) error {
 	for _, w := range w.Webhooks {
-		if err := w.Send(log, result); err != nil {
-			log.Warn("error sending slack webhook: %s", err)
+		if err := w.Send(log, event); err != nil {
+			log.Warn("error sending webhook: %s", err)
 		}
 	}
 	return nil
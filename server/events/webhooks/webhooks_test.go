@@ -50,7 +50,7 @@ func TestNewWebhooksManager_InvalidRegex(t *testing.T) {
 	invalidRegex := "("
 	configs := validConfigs()
 	configs[0].WorkspaceRegex = invalidRegex
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Assert(t, err != nil, "expected error")
 	Assert(t, strings.Contains(err.Error(), "error parsing regexp"), "expected regex error")
 }
@@ -61,9 +61,9 @@ func TestNewWebhooksManager_NoEvent(t *testing.T) {
 	client := mocks.NewMockSlackClient()
 	configs := validConfigs()
 	configs[0].Event = ""
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "must specify \"kind\" and \"event\" keys for webhooks", err.Error())
+	Equals(t, "must specify \"kind\" and \"event\" or \"events\" keys for webhooks", err.Error())
 }
 
 func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
@@ -74,9 +74,9 @@ func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
 	unsupportedEvent := "badevent"
 	configs := validConfigs()
 	configs[0].Event = unsupportedEvent
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\" is supported right now", err.Error())
+	Equals(t, "\"event: badevent\" not supported. Supported events are: apply, plan, policy_check, approve_policies, version, autoplan, lock, unlock, pull_closed, drift", err.Error())
 }
 
 func TestNewWebhooksManager_NoKind(t *testing.T) {
@@ -85,9 +85,9 @@ func TestNewWebhooksManager_NoKind(t *testing.T) {
 	client := mocks.NewMockSlackClient()
 	configs := validConfigs()
 	configs[0].Kind = ""
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "must specify \"kind\" and \"event\" keys for webhooks", err.Error())
+	Equals(t, "must specify \"kind\" and \"event\" or \"events\" keys for webhooks", err.Error())
 }
 
 func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
@@ -98,9 +98,128 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	unsupportedKind := "badkind"
 	configs := validConfigs()
 	configs[0].Kind = unsupportedKind
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" is supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Supported kinds are: slack, http, msteams, mattermost", err.Error())
+}
+
+func TestNewWebhooksManager_InvalidRepoRegex(t *testing.T) {
+	t.Log("When given an invalid repo regex in a config, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].RepoRegex = "("
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
+	Assert(t, err != nil, "expected error")
+	Assert(t, strings.Contains(err.Error(), "error parsing regexp"), "expected regex error")
+}
+
+func TestNewWebhooksManager_UnsupportedEvents(t *testing.T) {
+	t.Log("When given an unsupported event in the events of a config, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].Event = ""
+	configs[0].Events = []string{webhooks.PlanEvent, "badevent"}
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
+	Assert(t, err != nil, "expected error")
+	Assert(t, strings.HasPrefix(err.Error(), "\"event: badevent\" not supported"), "expected unsupported event error")
+}
+
+func TestNewWebhooksManager_UnsupportedStatus(t *testing.T) {
+	t.Log("When given an unsupported status in a config, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].Status = "badstatus"
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
+	Assert(t, err != nil, "expected error")
+	Equals(t, "\"status: badstatus\" not supported. Must be \"success\" or \"failure\"", err.Error())
+}
+
+func TestNewWebhooksManager_EventsSuccess(t *testing.T) {
+	t.Log("When a config has a list of events, they're all sent")
+	configs := []webhooks.Config{{
+		Event:          webhooks.ApplyEvent,
+		Events:         []string{webhooks.PlanEvent, webhooks.LockEvent},
+		WorkspaceRegex: validRegex,
+		Kind:           webhooks.HTTPKind,
+		URL:            "https://example.com/hook",
+	}}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil, nil)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
+	Equals(t, []string{webhooks.ApplyEvent, webhooks.PlanEvent, webhooks.LockEvent}, m.Webhooks[0].(*webhooks.HTTPWebhook).Filter.Events)
+}
+
+func TestNewWebhooksManager_HTTPNoURL(t *testing.T) {
+	t.Log("When the url key is not specified in an http config, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].Kind = webhooks.HTTPKind
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
+	Assert(t, err != nil, "expected error")
+	Equals(t, "must specify \"url\" if using a webhook of \"kind: http\"", err.Error())
+}
+
+func TestNewWebhooksManager_HTTPInvalidURL(t *testing.T) {
+	t.Log("When the url in an http config isn't an http URL, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].Kind = webhooks.HTTPKind
+	configs[0].URL = "ftp://example.com"
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil)
+	Assert(t, err != nil, "expected error")
+	Equals(t, "\"url: ftp://example.com\" must be an http or https URL", err.Error())
+}
+
+func TestNewWebhooksManager_HTTPSuccess(t *testing.T) {
+	t.Log("When there is a valid http config, the slack client isn't needed")
+	configs := []webhooks.Config{{
+		Event:          validEvent,
+		WorkspaceRegex: validRegex,
+		Kind:           webhooks.HTTPKind,
+		URL:            "https://example.com/hook",
+		Secret:         "secret",
+	}}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil, nil)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
+}
+
+func TestNewWebhooksManager_ChatKindsNoURL(t *testing.T) {
+	t.Log("When the url key is not specified in an msteams or mattermost config, an error is returned")
+	for _, kind := range []string{webhooks.MSTeamsKind, webhooks.MattermostKind} {
+		configs := validConfigs()
+		configs[0].Kind = kind
+		_, err := webhooks.NewMultiWebhookSender(configs, nil, nil)
+		Assert(t, err != nil, "expected error")
+		Equals(t, "must specify \"url\" if using a webhook of \"kind: "+kind+"\"", err.Error())
+	}
+}
+
+func TestNewWebhooksManager_ChatKindsSuccess(t *testing.T) {
+	t.Log("When there are valid msteams and mattermost configs, the slack client isn't needed")
+	configs := []webhooks.Config{
+		{
+			Event:          validEvent,
+			WorkspaceRegex: validRegex,
+			Kind:           webhooks.MSTeamsKind,
+			URL:            "https://example.webhook.office.com/webhookb2/id",
+		},
+		{
+			Event:          validEvent,
+			WorkspaceRegex: validRegex,
+			Kind:           webhooks.MattermostKind,
+			URL:            "https://mattermost.example.com/hooks/id",
+			Channel:        "deploys",
+		},
+	}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil, nil)
+	Ok(t, err)
+	Equals(t, 2, len(m.Webhooks)) // nolint: staticcheck
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
@@ -108,12 +227,12 @@ func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
 	t.Log("passing any client should succeed")
 	var emptyConfigs []webhooks.Config
 	emptyToken := ""
-	m, err := webhooks.NewMultiWebhookSender(emptyConfigs, webhooks.NewSlackClient(emptyToken))
+	m, err := webhooks.NewMultiWebhookSender(emptyConfigs, webhooks.NewSlackClient(emptyToken), nil)
 	Ok(t, err)
 	Equals(t, 0, len(m.Webhooks)) // nolint: staticcheck
 
 	t.Log("passing nil client should succeed")
-	m, err = webhooks.NewMultiWebhookSender(emptyConfigs, nil)
+	m, err = webhooks.NewMultiWebhookSender(emptyConfigs, nil, nil)
 	Ok(t, err)
 	Equals(t, 0, len(m.Webhooks)) // nolint: staticcheck
 }
@@ -124,7 +243,7 @@ func TestNewWebhooksManager_SingleConfigSuccess(t *testing.T) {
 	When(client.TokenIsSet()).ThenReturn(true)
 
 	configs := validConfigs()
-	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	m, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Ok(t, err)
 	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
 }
@@ -140,7 +259,7 @@ func TestNewWebhooksManager_MultipleConfigSuccess(t *testing.T) {
 	for i := 0; i < nConfigs; i++ {
 		configs = append(configs, validConfig)
 	}
-	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	m, err := webhooks.NewMultiWebhookSender(configs, client, nil)
 	Ok(t, err)
 	Equals(t, nConfigs, len(m.Webhooks)) // nolint: staticcheck
 }
@@ -153,7 +272,7 @@ func TestSend_SingleSuccess(t *testing.T) {
 		Webhooks: []webhooks.Sender{sender},
 	}
 	logger := logging.NewNoopLogger(t)
-	result := webhooks.ApplyResult{}
+	result := webhooks.Event{}
 	manager.Send(logger, result) // nolint: errcheck
 	sender.VerifyWasCalledOnce().Send(logger, result)
 }
@@ -170,7 +289,7 @@ func TestSend_MultipleSuccess(t *testing.T) {
 		Webhooks: []webhooks.Sender{senders[0], senders[1], senders[2]},
 	}
 	logger := logging.NewNoopLogger(t)
-	result := webhooks.ApplyResult{}
+	result := webhooks.Event{}
 	err := manager.Send(logger, result)
 	Ok(t, err)
 	for _, s := range senders {
@@ -51,6 +51,10 @@ type WorkingDir interface {
 	// Delete deletes the workspace for this repo and pull.
 	Delete(r models.Repo, p models.PullRequest) error
 	DeleteForWorkspace(r models.Repo, p models.PullRequest, workspace string) error
+	// ExpandCheckout adds dirs, which are relative to the root of the repo, to
+	// the checkout of the workspace if it's a sparse checkout. If dirs is nil,
+	// the whole repo is checked out.
+	ExpandCheckout(log logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string, dirs []string) error
 }
 
 // FileWorkspace implements WorkingDir with the file system.
@@ -73,6 +77,13 @@ type FileWorkspace struct {
 	GithubAppEnabled bool
 	// use the global setting without overriding
 	GpgNoSigningEnabled bool
+	// GitMirrors, if set, is the cache of git mirrors that clones reference
+	// so that they only fetch the objects that aren't in the mirror.
+	GitMirrors *GitMirrorCache
+	// SparseCheckout is true if clones should only check out the files in
+	// the root of the repo. The rest of the repo is checked out as needed
+	// with ExpandCheckout.
+	SparseCheckout bool
 }
 
 // Clone git clones headRepo, checks out the branch and then returns the absolute
@@ -237,6 +248,18 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 		baseCloneURL = w.TestingOverrideBaseCloneURL
 	}
 
+	var cloneOpts []string
+	if w.SparseCheckout {
+		cloneOpts = append(cloneOpts, "--sparse")
+	}
+	shallowCloneOpts := append([]string{"--depth=1"}, cloneOpts...)
+	// If we have a mirror of the repo, the clones borrow its objects. Then
+	// there's no need for shallow clones since the history is already on disk.
+	if mirrorDir := w.updateMirror(log, p.BaseRepo, baseCloneURL); mirrorDir != "" {
+		cloneOpts = append(cloneOpts, "--reference", mirrorDir)
+		shallowCloneOpts = cloneOpts
+	}
+
 	var cmds [][]string
 	if w.CheckoutMerge {
 		// NOTE: We can't do a shallow clone when we're merging because we'll
@@ -250,9 +273,7 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 			fetchRemote = "origin"
 		}
 		cmds = [][]string{
-			{
-				"git", "clone", "--branch", p.BaseBranch, "--single-branch", baseCloneURL, cloneDir,
-			},
+			gitClone(cloneOpts, "--branch", p.BaseBranch, "--single-branch", baseCloneURL, cloneDir),
 			{
 				"git", "remote", "add", "head", headCloneURL,
 			},
@@ -274,11 +295,14 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 		cmds = append(cmds, []string{
 			"git", "merge", "-q", "--no-ff", "-m", "atlantis-merge", "FETCH_HEAD",
 		})
+	} else if p.HeadBranch == "" {
+		// Without a branch, the default branch of the repo is cloned.
+		cmds = [][]string{
+			gitClone(shallowCloneOpts, "--single-branch", headCloneURL, cloneDir),
+		}
 	} else {
 		cmds = [][]string{
-			{
-				"git", "clone", "--branch", p.HeadBranch, "--depth=1", "--single-branch", headCloneURL, cloneDir,
-			},
+			gitClone(shallowCloneOpts, "--branch", p.HeadBranch, "--single-branch", headCloneURL, cloneDir),
 		}
 	}
 
@@ -304,6 +328,53 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 	return nil
 }
 
+// updateMirror brings the mirror of repo up to date and returns its path. It
+// returns an empty string if mirrors aren't enabled or the update failed, in
+// which case we clone without the mirror.
+func (w *FileWorkspace) updateMirror(log logging.SimpleLogging, repo models.Repo, cloneURL string) string {
+	if w.GitMirrors == nil {
+		return ""
+	}
+	mirrorDir, err := w.GitMirrors.Update(log, repo, cloneURL)
+	if err != nil {
+		log.Warn("will clone without git mirror, could not update it: %s", err)
+		return ""
+	}
+	return mirrorDir
+}
+
+// gitClone returns the args of a git clone with the options opts followed by
+// args.
+func gitClone(opts []string, args ...string) []string {
+	cmd := append([]string{"git", "clone"}, opts...)
+	return append(cmd, args...)
+}
+
+// ExpandCheckout adds dirs to the checkout of the workspace if it's a sparse
+// checkout. If dirs is nil, the whole repo is checked out.
+func (w *FileWorkspace) ExpandCheckout(log logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string, dirs []string) error {
+	if !w.SparseCheckout {
+		return nil
+	}
+	cmd := exec.Command("git", "sparse-checkout", "disable")
+	if dirs != nil {
+		if len(dirs) == 0 {
+			return nil
+		}
+		// The dirs are passed on stdin since there can be too many for the
+		// command line.
+		cmd = exec.Command("git", "sparse-checkout", "add", "--stdin")
+		cmd.Stdin = strings.NewReader(strings.Join(dirs, "\n") + "\n")
+	}
+	cmd.Dir = w.cloneDir(r, p, workspace)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("running %s: %s: %s", strings.Join(cmd.Args, " "), string(output), err)
+	}
+	log.Debug("ran: %s. Output: %s", strings.Join(cmd.Args, " "), strings.TrimSuffix(string(output), "\n"))
+	return nil
+}
+
 // GetWorkingDir returns the path to the workspace for this repo and pull.
 func (w *FileWorkspace) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error) {
 	repoDir := w.cloneDir(r, p, workspace)
@@ -325,7 +396,10 @@ func (w *FileWorkspace) GetPullDir(r models.Repo, p models.PullRequest) (string,
 
 // Delete deletes the workspace for this repo and pull.
 func (w *FileWorkspace) Delete(r models.Repo, p models.PullRequest) error {
-	return os.RemoveAll(w.repoPullDir(r, p))
+	if err := os.RemoveAll(w.repoPullDir(r, p)); err != nil {
+		return err
+	}
+	return os.RemoveAll(w.legacyRepoPullDir(r, p))
 }
 
 // DeleteForWorkspace deletes the working dir for this workspace.
@@ -333,10 +407,41 @@ func (w *FileWorkspace) DeleteForWorkspace(r models.Repo, p models.PullRequest,
 	return os.RemoveAll(w.cloneDir(r, p, workspace))
 }
 
+// repoPullDir returns the dir where the workspaces for this pull are cloned.
+// If the pull was cloned into its legacy dir, it's moved there first.
 func (w *FileWorkspace) repoPullDir(r models.Repo, p models.PullRequest) string {
+	dir := filepath.Join(w.DataDir, workingDirPrefix, r.VCSHost.Hostname, r.FullName, strconv.Itoa(p.Num))
+	w.migrateLegacyPullDir(r, p, dir)
+	return dir
+}
+
+// legacyRepoPullDir returns the dir the workspaces for this pull were cloned
+// into before the dirs included the VCS hostname.
+func (w *FileWorkspace) legacyRepoPullDir(r models.Repo, p models.PullRequest) string {
 	return filepath.Join(w.DataDir, workingDirPrefix, r.FullName, strconv.Itoa(p.Num))
 }
 
+// migrateLegacyPullDir moves the legacy dir of this pull to dir so that the
+// plans of pulls that were open when Atlantis was upgraded can still be
+// applied. It does nothing if dir already exists. If the move fails, the
+// legacy dir is left to be deleted with the pull.
+func (w *FileWorkspace) migrateLegacyPullDir(r models.Repo, p models.PullRequest, dir string) {
+	if r.VCSHost.Hostname == "" {
+		return
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return
+	}
+	legacyDir := w.legacyRepoPullDir(r, p)
+	if fi, err := os.Stat(legacyDir); err != nil || !fi.IsDir() {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return
+	}
+	os.Rename(legacyDir, dir) // nolint: errcheck
+}
+
 func (w *FileWorkspace) cloneDir(r models.Repo, p models.PullRequest, workspace string) string {
 	return filepath.Join(w.repoPullDir(r, p), workspace)
 }
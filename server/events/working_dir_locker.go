@@ -28,16 +28,18 @@ import (
 // within this workspace.
 type WorkingDirLocker interface {
 	// TryLock tries to acquire a lock for this repo, pull, workspace, and path.
+	// repoID is the ID of the repo, see models.Repo.ID(), so that pulls of
+	// repos with the same name on different VCS hosts don't share locks.
 	// It returns a function that should be used to unlock the workspace and
 	// an error if the workspace is already locked. The error is expected to
 	// be printed to the pull request.
-	TryLock(repoFullName string, pullNum int, workspace string, path string) (func(), error)
+	TryLock(repoID string, pullNum int, workspace string, path string) (func(), error)
 	// TryLockPull tries to acquire a lock for all the workspaces in this repo
 	// and pull.
 	// It returns a function that should be used to unlock the workspace and
 	// an error if the workspace is already locked. The error is expected to
 	// be printed to the pull request.
-	TryLockPull(repoFullName string, pullNum int) (func(), error)
+	TryLockPull(repoID string, pullNum int) (func(), error)
 }
 
 // DefaultWorkingDirLocker implements WorkingDirLocker.
@@ -56,11 +58,11 @@ func NewDefaultWorkingDirLocker() *DefaultWorkingDirLocker {
 	return &DefaultWorkingDirLocker{}
 }
 
-func (d *DefaultWorkingDirLocker) TryLockPull(repoFullName string, pullNum int) (func(), error) {
+func (d *DefaultWorkingDirLocker) TryLockPull(repoID string, pullNum int) (func(), error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	pullKey := d.pullKey(repoFullName, pullNum)
+	pullKey := d.pullKey(repoID, pullNum)
 	for _, l := range d.locks {
 		if l == pullKey || strings.HasPrefix(l, pullKey+"/") {
 			return func() {}, fmt.Errorf("The Atlantis working dir is currently locked by another" +
@@ -70,16 +72,16 @@ func (d *DefaultWorkingDirLocker) TryLockPull(repoFullName string, pullNum int)
 	}
 	d.locks = append(d.locks, pullKey)
 	return func() {
-		d.UnlockPull(repoFullName, pullNum)
+		d.UnlockPull(repoID, pullNum)
 	}, nil
 }
 
-func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, workspace string, path string) (func(), error) {
+func (d *DefaultWorkingDirLocker) TryLock(repoID string, pullNum int, workspace string, path string) (func(), error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	pullKey := d.pullKey(repoFullName, pullNum)
-	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, path)
+	pullKey := d.pullKey(repoID, pullNum)
+	workspaceKey := d.workspaceKey(repoID, pullNum, workspace, path)
 	for _, l := range d.locks {
 		if l == pullKey || l == workspaceKey {
 			return func() {}, fmt.Errorf("The %s workspace at path %s is currently locked by another"+
@@ -89,25 +91,25 @@ func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, work
 	}
 	d.locks = append(d.locks, workspaceKey)
 	return func() {
-		d.unlock(repoFullName, pullNum, workspace, path)
+		d.unlock(repoID, pullNum, workspace, path)
 	}, nil
 }
 
 // Unlock unlocks the workspace for this pull.
-func (d *DefaultWorkingDirLocker) unlock(repoFullName string, pullNum int, workspace string, path string) {
+func (d *DefaultWorkingDirLocker) unlock(repoID string, pullNum int, workspace string, path string) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, path)
+	workspaceKey := d.workspaceKey(repoID, pullNum, workspace, path)
 	d.removeLock(workspaceKey)
 }
 
 // Unlock unlocks all workspaces for this pull.
-func (d *DefaultWorkingDirLocker) UnlockPull(repoFullName string, pullNum int) {
+func (d *DefaultWorkingDirLocker) UnlockPull(repoID string, pullNum int) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	pullKey := d.pullKey(repoFullName, pullNum)
+	pullKey := d.pullKey(repoID, pullNum)
 	d.removeLock(pullKey)
 }
 
@@ -214,3 +214,16 @@ func TestLockPull_WorkspaceFirst(t *testing.T) {
 	_, err = locker.TryLockPull("owner/repo", 1)
 	Ok(t, err)
 }
+
+// Pulls of repos with the same name on different VCS hosts shouldn't share
+// locks.
+func TestTryLock_DifferentVCSHosts(t *testing.T) {
+	locker := events.NewDefaultWorkingDirLocker()
+	_, err := locker.TryLockPull("github.com/owner/repo", 1)
+	Ok(t, err)
+
+	_, err = locker.TryLockPull("github.example.com/owner/repo", 1)
+	Ok(t, err)
+	_, err = locker.TryLock("gitlab.com/owner/repo", 1, "workspace", path)
+	Ok(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/runatlantis/atlantis/server/events"
@@ -53,6 +54,94 @@ func TestClone_NoneExisting(t *testing.T) {
 	Equals(t, expCommit, actCommit)
 }
 
+// Test that pulls of repos with the same name on different VCS hosts are
+// cloned into different dirs.
+func TestClone_DifferentVCSHosts(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               false,
+		TestingOverrideHeadCloneURL: fmt.Sprintf("file://%s", repoDir),
+		GpgNoSigningEnabled:         true,
+	}
+	var cloneDirs []string
+	for _, hostname := range []string{"github.com", "github.example.com"} {
+		repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: hostname}}
+		cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), repo, models.PullRequest{
+			BaseRepo:   repo,
+			Num:        1,
+			HeadBranch: "branch",
+		}, "default")
+		Ok(t, err)
+		cloneDirs = append(cloneDirs, cloneDir)
+	}
+	Equals(t, filepath.Join(dataDir, "repos", "github.com", "owner", "repo", "1", "default"), cloneDirs[0])
+	Equals(t, filepath.Join(dataDir, "repos", "github.example.com", "owner", "repo", "1", "default"), cloneDirs[1])
+}
+
+// Test that pulls cloned before working dirs included the VCS hostname are
+// moved to their new dir and that their legacy dir is deleted with the pull.
+func TestFileWorkspace_LegacyPullDir(t *testing.T) {
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	wd := &events.FileWorkspace{DataDir: dataDir}
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}
+	pull := models.PullRequest{BaseRepo: repo, Num: 1}
+
+	legacyDir := filepath.Join(dataDir, "repos", "owner", "repo", "1")
+	Ok(t, os.MkdirAll(filepath.Join(legacyDir, "default"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(legacyDir, "default", "default.tfplan"), nil, 0600))
+
+	workingDir, err := wd.GetWorkingDir(repo, pull, "default")
+	Ok(t, err)
+	Equals(t, filepath.Join(dataDir, "repos", "github.com", "owner", "repo", "1", "default"), workingDir)
+	_, err = os.Stat(filepath.Join(workingDir, "default.tfplan"))
+	Ok(t, err)
+	_, err = os.Stat(legacyDir)
+	Assert(t, os.IsNotExist(err), "exp legacy dir to be moved, got %v", err)
+
+	// A legacy dir that couldn't be moved is deleted with the pull.
+	Ok(t, os.MkdirAll(filepath.Join(legacyDir, "default"), 0700))
+	Ok(t, wd.Delete(repo, pull))
+	_, err = os.Stat(legacyDir)
+	Assert(t, os.IsNotExist(err), "exp legacy dir to be deleted, got %v", err)
+	_, err = os.Stat(workingDir)
+	Assert(t, os.IsNotExist(err), "exp working dir to be deleted, got %v", err)
+}
+
+// Test that if the pull request has no head branch, we check out the default
+// branch of the repo.
+func TestClone_DefaultBranch(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	runCmd(t, repoDir, "git", "checkout", "master")
+	runCmd(t, repoDir, "touch", "master-file")
+	runCmd(t, repoDir, "git", "add", "master-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "master-commit")
+	expCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		TestingOverrideHeadCloneURL: fmt.Sprintf("file://%s", repoDir),
+		GpgNoSigningEnabled:         true,
+	}
+
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{
+		BaseRepo: models.Repo{},
+	}, "default")
+	Ok(t, err)
+
+	actCommit := runCmd(t, cloneDir, "git", "rev-parse", "HEAD")
+	Equals(t, expCommit, actCommit)
+}
+
 // Test that if we don't have any existing files, we check out the repo
 // successfully when we're using the merge method.
 func TestClone_CheckoutMergeNoneExisting(t *testing.T) {
@@ -487,3 +576,133 @@ func initRepo(t *testing.T) (string, func()) {
 	runCmd(t, repoDir, "git", "branch", "branch")
 	return repoDir, cleanup
 }
+
+// Test that with git mirrors enabled, the clone references the mirror of the
+// base repo and is at the right commit.
+func TestClone_GitMirror(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	expCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	mirrors := &events.GitMirrorCache{Dir: filepath.Join(dataDir, events.GitMirrorDirName)}
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+		GitMirrors:                  mirrors,
+	}
+
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{
+		BaseRepo:   models.Repo{FullName: "runatlantis/atlantis"},
+		HeadBranch: "branch",
+	}, "default")
+	Ok(t, err)
+
+	actCommit := runCmd(t, cloneDir, "git", "rev-parse", "HEAD")
+	Equals(t, expCommit, actCommit)
+	alternates, err := os.ReadFile(filepath.Join(cloneDir, ".git", "objects", "info", "alternates"))
+	Ok(t, err)
+	Assert(t, strings.Contains(string(alternates), filepath.Join(mirrors.Dir, "runatlantis", "atlantis.git")), "expected clone to reference mirror, got alternates %q", string(alternates))
+}
+
+// Test that with git mirrors enabled, the merge strategy fetches the new
+// commits into the existing mirror.
+func TestClone_GitMirrorCheckoutMerge(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               true,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+		GitMirrors:                  &events.GitMirrorCache{Dir: filepath.Join(dataDir, events.GitMirrorDirName)},
+	}
+	pull := models.PullRequest{
+		BaseRepo:   models.Repo{FullName: "runatlantis/atlantis"},
+		HeadBranch: "branch",
+		BaseBranch: "master",
+	}
+	_, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	// Advance both branches after the mirror was created.
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "branch-file")
+	runCmd(t, repoDir, "git", "add", "branch-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+	branchCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+	runCmd(t, repoDir, "git", "checkout", "master")
+	runCmd(t, repoDir, "touch", "master-file")
+	runCmd(t, repoDir, "git", "add", "master-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "master-commit")
+	masterCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	pull.HeadCommit = branchCommit
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	Equals(t, masterCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD~1"))
+	Equals(t, branchCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD^2"))
+	mirrorDir := filepath.Join(dataDir, events.GitMirrorDirName, "runatlantis", "atlantis.git")
+	Equals(t, masterCommit, runCmd(t, mirrorDir, "git", "rev-parse", "refs/heads/master"))
+}
+
+// Test that with sparse checkout enabled, only the root of the repo is checked
+// out until the checkout is expanded.
+func TestClone_SparseCheckout(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	for _, dir := range []string{"project1", "project2"} {
+		runCmd(t, repoDir, "mkdir", dir)
+		runCmd(t, repoDir, "touch", filepath.Join(dir, "main.tf"))
+	}
+	runCmd(t, repoDir, "touch", "atlantis.yaml")
+	runCmd(t, repoDir, "git", "add", ".")
+	runCmd(t, repoDir, "git", "commit", "-m", "projects")
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		TestingOverrideHeadCloneURL: fmt.Sprintf("file://%s", repoDir),
+		GpgNoSigningEnabled:         true,
+		SparseCheckout:              true,
+	}
+	logger := logging.NewNoopLogger(t)
+	pull := models.PullRequest{
+		BaseRepo:   models.Repo{},
+		HeadBranch: "branch",
+	}
+
+	cloneDir, _, err := wd.Clone(logger, models.Repo{}, pull, "default")
+	Ok(t, err)
+	Equals(t, "atlantis.yaml\n", runCmd(t, cloneDir, "ls"))
+
+	Ok(t, wd.ExpandCheckout(logger, models.Repo{}, pull, "default", []string{"project1"}))
+	Equals(t, "atlantis.yaml\nproject1\n", runCmd(t, cloneDir, "ls"))
+
+	Ok(t, wd.ExpandCheckout(logger, models.Repo{}, pull, "default", nil))
+	Equals(t, "atlantis.yaml\nproject1\nproject2\n", runCmd(t, cloneDir, "ls"))
+}
+
+// Test that ExpandCheckout does nothing if sparse checkout isn't enabled.
+func TestExpandCheckout_NotSparse(t *testing.T) {
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+
+	wd := &events.FileWorkspace{DataDir: dataDir}
+	Ok(t, wd.ExpandCheckout(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{}, "default", []string{"project1"}))
+}
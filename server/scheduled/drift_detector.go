@@ -0,0 +1,361 @@
+package scheduled
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/uber-go/tally"
+)
+
+// DefaultDriftDetectionInterval is how often repos are checked for drift by
+// default.
+const DefaultDriftDetectionInterval = 24 * time.Hour
+
+// driftDetectionUser is the user that drift detection plans are run as.
+const driftDetectionUser = "atlantis-drift-detection"
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_drift_store.go DriftStore
+
+// DriftStore stores the result of the last drift detection run for each
+// project.
+type DriftStore interface {
+	// UpdateRepoDrift replaces the drift of the projects of the repo with ID
+	// repoID with drifts.
+	UpdateRepoDrift(repoID string, drifts []models.ProjectDrift) error
+	GetRepoDrift(repoID string) ([]models.ProjectDrift, error)
+	ListProjectDrifts() ([]models.ProjectDrift, error)
+	// ClaimDriftDetection claims the drift detection of the repo with ID
+	// repoID until the time until so that it isn't run by more than one
+	// Atlantis replica. It returns false if the drift detection is already
+	// claimed at the time now.
+	ClaimDriftDetection(repoID string, now time.Time, until time.Time) (bool, error)
+}
+
+// DriftDetector plans the projects on the default branch of the repos that
+// have drift_detection configured to find the ones whose infrastructure no
+// longer matches their code. It records the result for each project in the
+// DB. When a project starts drifting or failing to plan, it sends a drift
+// webhook and, if the repo is configured for it, opens an issue. Each repo
+// is claimed in the DB before it's checked so that Atlantis replicas sharing
+// the DB don't check it more than once per interval.
+type DriftDetector struct {
+	GlobalCfg valid.GlobalCfg
+	DB        DriftStore
+	VCSClient vcs.Client
+	// VCSHostTypes are the VCS hosts Atlantis is configured for. The repos
+	// are looked up on all of them whose client can find a repo by name.
+	VCSHostTypes []models.VCSHostType
+	Parser       events.EventParsing
+	// WorkingDir is where the repos are cloned. It must not be the working
+	// dir of pull requests since the clones are deleted before each run.
+	WorkingDir                   events.WorkingDir
+	ParserValidator              *config.ParserValidator
+	ProjectCommandContextBuilder events.ProjectCommandContextBuilder
+	ProjectDriftCommandRunner    events.ProjectDriftCommandRunner
+	// LogStreamResourceCleaner cleans up the output of each project's plan
+	// once it's checked.
+	LogStreamResourceCleaner events.ResourceCleaner
+	// IssueCreator, if set, opens the issues for repos with create_issue.
+	IssueCreator vcs.IssueCreator
+	Webhooks     events.WebhooksSender
+	Logger       logging.SimpleLogging
+	Scope        tally.Scope
+	// Interval is how often the detector runs. If zero,
+	// DefaultDriftDetectionInterval is used.
+	Interval time.Duration
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Run checks all the repos configured for drift detection.
+func (d *DriftDetector) Run() {
+	for _, repo := range d.GlobalCfg.DriftDetectionRepos() {
+		if err := d.detectRepoDrift(repo.ID, *repo.DriftDetection); err != nil {
+			d.Logger.Err("detecting drift of repo %s: %s", repo.ID, err)
+		}
+	}
+}
+
+func (d *DriftDetector) detectRepoDrift(repoID string, cfg valid.DriftDetection) error {
+	log := d.Logger.WithHistory("repo", repoID)
+
+	// The claim expires a little before the next run so that the replica
+	// that runs next can claim it even if its timer fires slightly early.
+	now := d.now()
+	interval := d.interval()
+	claimed, err := d.DB.ClaimDriftDetection(repoID, now, now.Add(interval-interval/10))
+	if err != nil {
+		return errors.Wrap(err, "claiming drift detection")
+	}
+	if !claimed {
+		log.Debug("drift detection already claimed by another Atlantis replica")
+		return nil
+	}
+
+	baseRepo, err := d.findRepo(repoID)
+	if err != nil {
+		return err
+	}
+
+	// The clone is deleted first so that the latest commit of the default
+	// branch is planned.
+	pull := models.PullRequest{BaseRepo: baseRepo}
+	if err := d.WorkingDir.Delete(baseRepo, pull); err != nil {
+		return errors.Wrap(err, "deleting previous clone")
+	}
+	repoDir, _, err := d.WorkingDir.Clone(log, baseRepo, pull, events.DefaultWorkspace)
+	if err != nil {
+		return errors.Wrap(err, "cloning default branch")
+	}
+	if pull.HeadBranch, err = revParse(repoDir, "--abbrev-ref", "HEAD"); err != nil {
+		return err
+	}
+	if pull.HeadCommit, err = revParse(repoDir, "HEAD"); err != nil {
+		return err
+	}
+	pull.BaseBranch = pull.HeadBranch
+
+	projCfgs, err := d.projectCfgs(log, repoID, repoDir, cfg)
+	if err != nil {
+		return err
+	}
+	previousDrifts, err := d.DB.GetRepoDrift(repoID)
+	if err != nil {
+		return errors.Wrap(err, "getting previous drift")
+	}
+	previous := make(map[string]models.ProjectDrift)
+	for _, drift := range previousDrifts {
+		previous[driftKey(drift.RepoRelDir, drift.Workspace, drift.ProjectName)] = drift
+	}
+
+	cmdCtx := &command.Context{
+		User:     models.User{Username: driftDetectionUser},
+		Log:      log,
+		Pull:     pull,
+		HeadRepo: baseRepo,
+		Scope:    d.Scope,
+	}
+	var drifts []models.ProjectDrift
+	for _, projCfg := range projCfgs {
+		ctxs := d.ProjectCommandContextBuilder.BuildProjectContext(cmdCtx, command.Plan, projCfg, nil, repoDir, false, false, false, false, false)
+		for _, ctx := range ctxs {
+			// Policy checks are built along with plans but aren't needed
+			// to detect drift.
+			if ctx.CommandName != command.Plan {
+				continue
+			}
+			prev, hasPrev := previous[driftKey(ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)]
+			drift, skipped := d.detectProjectDrift(ctx, repoID, repoDir, cfg, prev)
+			if d.LogStreamResourceCleaner != nil {
+				d.LogStreamResourceCleaner.CleanUp(jobs.PullInfo{
+					PullNum:     ctx.Pull.Num,
+					Repo:        ctx.BaseRepo.Name,
+					ProjectName: ctx.ProjectName,
+					Workspace:   ctx.Workspace,
+				})
+			}
+			if skipped {
+				// The previous drift is kept until the project can be
+				// checked again.
+				if hasPrev {
+					drifts = append(drifts, prev)
+				}
+				continue
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+	return errors.Wrap(d.DB.UpdateRepoDrift(repoID, drifts), "saving drift")
+}
+
+// detectProjectDrift plans the project described by ctx and returns its
+// drift. prev is the drift of the project from the previous run, or empty if
+// it wasn't checked before. It returns true if the project was skipped
+// because it's locked by a pull request.
+func (d *DriftDetector) detectProjectDrift(ctx command.ProjectContext, repoID string, repoDir string, cfg valid.DriftDetection, prev models.ProjectDrift) (models.ProjectDrift, bool) {
+	drift := models.ProjectDrift{
+		RepoID:       repoID,
+		RepoFullName: ctx.BaseRepo.FullName,
+		ProjectName:  ctx.ProjectName,
+		RepoRelDir:   ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		Branch:       ctx.Pull.HeadBranch,
+		Commit:       ctx.Pull.HeadCommit,
+		Status:       models.NoDriftStatus,
+		CheckedAt:    d.now(),
+	}
+	drifted, output, err := d.ProjectDriftCommandRunner.DetectDrift(ctx, repoDir)
+	switch {
+	case errors.Is(err, events.ErrProjectLocked):
+		ctx.Log.Info("skipping drift detection of project locked by a pull request")
+		return drift, true
+	case err != nil:
+		ctx.Log.Err("planning project for drift detection: %s", err)
+		drift.Status = models.ErroredDriftStatus
+		drift.Error = fmt.Sprintf("%s\n%s", err, output)
+	case drifted:
+		ctx.Log.Info("project has drifted")
+		drift.Status = models.DriftedStatus
+		planSuccess := models.PlanSuccess{TerraformOutput: output}
+		drift.PlanSummary = planSuccess.Summary()
+	}
+	if drift.Status == models.NoDriftStatus {
+		return drift, false
+	}
+
+	// Projects are only reported once when they start drifting or failing,
+	// not on every run until they're fixed.
+	if drift.Status == prev.Status {
+		drift.IssueURL = prev.IssueURL
+		return drift, false
+	}
+	if d.Webhooks != nil {
+		d.Webhooks.Send(ctx.Log, webhooks.Event{ // nolint: errcheck
+			Type:        webhooks.DriftEvent,
+			Workspace:   ctx.Workspace,
+			Repo:        ctx.BaseRepo,
+			User:        ctx.User,
+			Success:     false,
+			Directory:   ctx.RepoRelDir,
+			ProjectName: ctx.ProjectName,
+			PlanSummary: drift.PlanSummary,
+			Error:       drift.Error,
+		})
+	}
+	if drift.Status == models.DriftedStatus && cfg.CreateIssue && d.IssueCreator != nil {
+		issueURL, err := d.IssueCreator.CreateIssue(ctx.BaseRepo, driftIssueTitle(drift), driftIssueBody(drift, output))
+		if err != nil {
+			ctx.Log.Err("opening drift issue: %s", err)
+		}
+		drift.IssueURL = issueURL
+	}
+	return drift, false
+}
+
+// findRepo looks up the repo with repoID, ex. "github.com/owner/repo", on the
+// VCS hosts Atlantis is configured for. Hosts whose client can't look up a
+// repo by name, like Bitbucket Server and Azure DevOps, return an error and
+// are skipped.
+func (d *DriftDetector) findRepo(repoID string) (models.Repo, error) {
+	parts := strings.SplitN(repoID, "/", 2)
+	if len(parts) != 2 {
+		return models.Repo{}, fmt.Errorf("invalid repo id %q", repoID)
+	}
+	fullName := parts[1]
+	for _, hostType := range d.VCSHostTypes {
+		cloneURL, err := d.VCSClient.GetCloneURL(hostType, fullName)
+		if err != nil {
+			continue
+		}
+		repo, err := d.Parser.ParseAPIPlanRequest(hostType, fullName, cloneURL)
+		if err == nil && repo.ID() == repoID {
+			return repo, nil
+		}
+	}
+	return models.Repo{}, fmt.Errorf("repo not found on any VCS host Atlantis is configured for")
+}
+
+// projectCfgs returns the configs of the projects in repoDir to check. If the
+// repo has an atlantis.yaml file, its projects are checked. Otherwise the
+// configured projects are treated as dirs, and the root of the repo is
+// checked if there are none.
+func (d *DriftDetector) projectCfgs(log logging.SimpleLogging, repoID string, repoDir string, cfg valid.DriftDetection) ([]valid.MergedProjectCfg, error) {
+	hasRepoCfg, err := d.ParserValidator.HasRepoCfg(repoDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking for %s file in %q", config.AtlantisYAMLFilename, repoDir)
+	}
+
+	var projCfgs []valid.MergedProjectCfg
+	if !hasRepoCfg {
+		dirs := cfg.Projects
+		if len(dirs) == 0 {
+			dirs = []string{"."}
+		}
+		for _, dir := range dirs {
+			projCfgs = append(projCfgs, d.GlobalCfg.DefaultProjCfg(log, repoID, filepath.Clean(dir), events.DefaultWorkspace))
+		}
+		return projCfgs, nil
+	}
+
+	repoCfg, err := d.ParserValidator.ParseRepoCfg(repoDir, d.GlobalCfg, repoID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", config.AtlantisYAMLFilename)
+	}
+	for _, project := range repoCfg.Projects {
+		if !driftProjectSelected(cfg.Projects, project) {
+			continue
+		}
+		projCfgs = append(projCfgs, d.GlobalCfg.MergeProjectCfg(log, repoID, project, repoCfg))
+	}
+	return projCfgs, nil
+}
+
+func (d *DriftDetector) interval() time.Duration {
+	if d.Interval != 0 {
+		return d.Interval
+	}
+	return DefaultDriftDetectionInterval
+}
+
+func (d *DriftDetector) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+// driftProjectSelected returns true if project is one of selected, matching
+// by name or dir. All projects are selected if selected is empty.
+func driftProjectSelected(selected []string, project valid.Project) bool {
+	if len(selected) == 0 {
+		return true
+	}
+	for _, s := range selected {
+		if s == project.GetName() || filepath.Clean(s) == filepath.Clean(project.Dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func driftKey(repoRelDir string, workspace string, projectName string) string {
+	return fmt.Sprintf("%s/%s/%s", repoRelDir, workspace, projectName)
+}
+
+func driftIssueTitle(drift models.ProjectDrift) string {
+	project := drift.ProjectName
+	if project == "" {
+		project = fmt.Sprintf("dir: %s workspace: %s", drift.RepoRelDir, drift.Workspace)
+	}
+	return fmt.Sprintf("Atlantis detected drift in %s", project)
+}
+
+func driftIssueBody(drift models.ProjectDrift, output string) string {
+	return fmt.Sprintf("Planning dir: `%s` workspace: `%s` on branch `%s` at commit `%s` has changes, so the infrastructure no longer matches the code.\n\n"+
+		"%s\n\n<details><summary>Show Output</summary>\n\n```diff\n%s\n```\n</details>",
+		drift.RepoRelDir, drift.Workspace, drift.Branch, drift.Commit, drift.PlanSummary, output)
+}
+
+// revParse runs git rev-parse with args in repoDir.
+func revParse(repoDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, args...)...) // #nosec
+	cmd.Dir = repoDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "running git rev-parse %s: %s", strings.Join(args, " "), string(out))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
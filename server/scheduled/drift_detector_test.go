@@ -0,0 +1,226 @@
+package scheduled_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/scheduled"
+	. "github.com/runatlantis/atlantis/testing"
+	"github.com/uber-go/tally"
+)
+
+const driftRepoID = "github.com/owner/repo"
+
+var driftNow = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeIssueCreator records the titles of the issues it's asked to open.
+type fakeIssueCreator struct {
+	titles []string
+}
+
+func (f *fakeIssueCreator) CreateIssue(_ models.Repo, title string, _ string) (string, error) {
+	f.titles = append(f.titles, title)
+	return fmt.Sprintf("https://github.com/owner/repo/issues/%d", len(f.titles)), nil
+}
+
+// initDriftRepo creates a git repo on the main branch with files in it.
+func initDriftRepo(t *testing.T, files map[string]string) string {
+	repoDir, cleanup := TempDir(t)
+	t.Cleanup(cleanup)
+	for name, content := range files {
+		Ok(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(name)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0600))
+	}
+	for _, args := range [][]string{
+		{"init", "--initial-branch=main"},
+		{"config", "--local", "user.email", "atlantisbot@runatlantis.io"},
+		{"config", "--local", "user.name", "atlantisbot"},
+		{"config", "--local", "commit.gpgsign", "false"},
+		{"add", "."},
+		{"commit", "-m", "initial commit"},
+	} {
+		cmd := exec.Command("git", args...) // #nosec
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		Assert(t, err == nil, "err running git %s: %s", strings.Join(args, " "), out)
+	}
+	return repoDir
+}
+
+func newTestDriftDetector(t *testing.T, repoDir string, driftCfg valid.DriftDetection) (*scheduled.DriftDetector, *mocks.MockProjectDriftCommandRunner, *mocks.MockWebhooksSender, *fakeIssueCreator) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetCloneURL(models.Github, "owner/repo")).ThenReturn("https://github.com/owner/repo.git", nil)
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyLoggingSimpleLogging(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(repoDir, false, nil)
+	dataDir, cleanup := TempDir(t)
+	t.Cleanup(cleanup)
+	backend, err := db.New(dataDir)
+	Ok(t, err)
+
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{ID: driftRepoID, DriftDetection: &driftCfg})
+	runner := mocks.NewMockProjectDriftCommandRunner()
+	sender := mocks.NewMockWebhooksSender()
+	issueCreator := &fakeIssueCreator{}
+	d := &scheduled.DriftDetector{
+		GlobalCfg:                    globalCfg,
+		DB:                           backend,
+		VCSClient:                    vcsClient,
+		VCSHostTypes:                 []models.VCSHostType{models.Github},
+		Parser:                       &events.EventParser{GithubUser: "user", GithubToken: "token"},
+		WorkingDir:                   workingDir,
+		ParserValidator:              &config.ParserValidator{},
+		ProjectCommandContextBuilder: events.NewProjectCommandContextBuilder(false, &events.CommentParser{}, tally.NewTestScope("", nil)),
+		ProjectDriftCommandRunner:    runner,
+		LogStreamResourceCleaner:     mocks.NewMockResourceCleaner(),
+		IssueCreator:                 issueCreator,
+		Webhooks:                     sender,
+		Logger:                       logging.NewNoopLogger(t),
+		Scope:                        tally.NewTestScope("", nil),
+		Interval:                     24 * time.Hour,
+		Now:                          func() time.Time { return driftNow },
+	}
+	return d, runner, sender, issueCreator
+}
+
+func TestDriftDetector_RecordsDrift(t *testing.T) {
+	repoDir := initDriftRepo(t, map[string]string{"main.tf": ""})
+	d, runner, sender, issueCreator := newTestDriftDetector(t, repoDir, valid.DriftDetection{Enabled: true, CreateIssue: true})
+	plan := "Plan: 1 to add, 0 to change, 0 to destroy."
+	When(runner.DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())).
+		ThenReturn(true, plan, nil).
+		ThenReturn(true, plan, nil).
+		ThenReturn(false, "No changes.", nil)
+
+	t.Log("the first run should report the drift")
+	d.Run()
+	drifts, err := d.DB.GetRepoDrift(driftRepoID)
+	Ok(t, err)
+	Equals(t, 1, len(drifts))
+	Equals(t, models.DriftedStatus, drifts[0].Status)
+	Equals(t, plan, drifts[0].PlanSummary)
+	Equals(t, "main", drifts[0].Branch)
+	Equals(t, "owner/repo", drifts[0].RepoFullName)
+	Equals(t, ".", drifts[0].RepoRelDir)
+	Equals(t, driftNow, drifts[0].CheckedAt.UTC())
+	Equals(t, "https://github.com/owner/repo/issues/1", drifts[0].IssueURL)
+	_, event := sender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent()).GetCapturedArguments()
+	Equals(t, webhooks.DriftEvent, event.Type)
+	Equals(t, false, event.Success)
+	Equals(t, plan, event.PlanSummary)
+
+	t.Log("a project that's still drifting shouldn't be reported again")
+	d.Now = func() time.Time { return driftNow.Add(24 * time.Hour) }
+	d.Run()
+	drifts, err = d.DB.GetRepoDrift(driftRepoID)
+	Ok(t, err)
+	Equals(t, "https://github.com/owner/repo/issues/1", drifts[0].IssueURL)
+	Equals(t, 1, len(issueCreator.titles))
+	sender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent())
+
+	t.Log("the issue shouldn't be kept once the project no longer drifts")
+	d.Now = func() time.Time { return driftNow.Add(48 * time.Hour) }
+	d.Run()
+	drifts, err = d.DB.GetRepoDrift(driftRepoID)
+	Ok(t, err)
+	Equals(t, models.NoDriftStatus, drifts[0].Status)
+	Equals(t, "", drifts[0].IssueURL)
+}
+
+func TestDriftDetector_OnlyConfiguredProjects(t *testing.T) {
+	repoDir := initDriftRepo(t, map[string]string{
+		"atlantis.yaml": `version: 3
+projects:
+- name: network
+  dir: network
+- name: app
+  dir: app
+- dir: database
+`,
+		"network/main.tf":  "",
+		"app/main.tf":      "",
+		"database/main.tf": "",
+	})
+	d, runner, _, issueCreator := newTestDriftDetector(t, repoDir, valid.DriftDetection{Enabled: true, Projects: []string{"network", "database"}})
+	When(runner.DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())).ThenReturn(false, "", nil)
+
+	d.Run()
+
+	ctxs, _ := runner.VerifyWasCalled(Times(2)).DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString()).GetAllCapturedArguments()
+	Equals(t, "network", ctxs[0].RepoRelDir)
+	Equals(t, "database", ctxs[1].RepoRelDir)
+	drifts, err := d.DB.GetRepoDrift(driftRepoID)
+	Ok(t, err)
+	Equals(t, 2, len(drifts))
+	Equals(t, 0, len(issueCreator.titles))
+}
+
+func TestDriftDetector_CleansUpOutput(t *testing.T) {
+	repoDir := initDriftRepo(t, map[string]string{"main.tf": ""})
+	d, runner, _, _ := newTestDriftDetector(t, repoDir, valid.DriftDetection{Enabled: true})
+	When(runner.DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())).ThenReturn(false, "", nil)
+
+	d.Run()
+
+	pullInfo := d.LogStreamResourceCleaner.(*mocks.MockResourceCleaner).VerifyWasCalledOnce().CleanUp(matchers.AnyJobsPullInfo()).GetCapturedArguments()
+	Equals(t, jobs.PullInfo{Repo: "repo", Workspace: events.DefaultWorkspace}, pullInfo)
+}
+
+func TestDriftDetector_ClaimedByOtherReplica(t *testing.T) {
+	repoDir := initDriftRepo(t, map[string]string{"main.tf": ""})
+	d, runner, _, _ := newTestDriftDetector(t, repoDir, valid.DriftDetection{Enabled: true})
+	When(runner.DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())).ThenReturn(false, "", nil)
+	otherRunner := mocks.NewMockProjectDriftCommandRunner()
+	other := *d
+	other.ProjectDriftCommandRunner = otherRunner
+
+	d.Run()
+	t.Log("another replica sharing the DB shouldn't check the repo in the same interval")
+	other.Now = func() time.Time { return driftNow.Add(time.Hour) }
+	other.Run()
+	otherRunner.VerifyWasCalled(Never()).DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())
+
+	t.Log("it should check it once the next run is due")
+	other.Now = func() time.Time { return driftNow.Add(23 * time.Hour) }
+	other.Run()
+	otherRunner.VerifyWasCalledOnce().DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())
+}
+
+func TestDriftDetector_SkipsLockedProjects(t *testing.T) {
+	repoDir := initDriftRepo(t, map[string]string{"main.tf": ""})
+	d, runner, sender, _ := newTestDriftDetector(t, repoDir, valid.DriftDetection{Enabled: true})
+	plan := "Plan: 1 to add, 0 to change, 0 to destroy."
+	When(runner.DetectDrift(matchers.AnyModelsProjectCommandContext(), AnyString())).
+		ThenReturn(true, plan, nil).
+		ThenReturn(false, "", events.ErrProjectLocked)
+
+	d.Run()
+	t.Log("the previous drift should be kept while a pull request locks the project")
+	d.Now = func() time.Time { return driftNow.Add(24 * time.Hour) }
+	d.Run()
+
+	drifts, err := d.DB.GetRepoDrift(driftRepoID)
+	Ok(t, err)
+	Equals(t, 1, len(drifts))
+	Equals(t, models.DriftedStatus, drifts[0].Status)
+	Equals(t, driftNow, drifts[0].CheckedAt.UTC())
+	sender.VerifyWasCalledOnce().Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksEvent())
+}
@@ -16,11 +16,27 @@ type ExecutorService struct {
 
 	// jobs
 	runtimeStatsPublisher JobDefinition
+	staleLockReaper       JobDefinition
+	policySetRefresher    JobDefinition
+	driftDetector         JobDefinition
+	gitMirrorReaper       *JobDefinition
 }
 
+// StaleLockReaperPeriod is how often we check for expired locks.
+const StaleLockReaperPeriod = 1 * time.Minute
+
+// PolicySetRefreshPeriod is how often we fetch the refs of remote policy sets
+// again.
+const PolicySetRefreshPeriod = 5 * time.Minute
+
 func NewExecutorService(
 	statsScope tally.Scope,
 	log logging.SimpleLogging,
+	staleLockReaper *StaleLockReaper,
+	policySetRefresher Job,
+	driftDetector Job,
+	driftDetectionInterval time.Duration,
+	gitMirrorReaper *GitMirrorReaper,
 ) *ExecutorService {
 
 	scheduledScope := statsScope.SubScope("scheduled")
@@ -31,9 +47,37 @@ func NewExecutorService(
 		Period: 10 * time.Second,
 	}
 
+	staleLockReaperJob := JobDefinition{
+		Job:    staleLockReaper,
+		Period: StaleLockReaperPeriod,
+	}
+
+	policySetRefresherJob := JobDefinition{
+		Job:    policySetRefresher,
+		Period: PolicySetRefreshPeriod,
+	}
+
+	driftDetectorJob := JobDefinition{
+		Job:    driftDetector,
+		Period: driftDetectionInterval,
+	}
+
+	// The git mirror reaper only runs if git mirrors are enabled.
+	var gitMirrorReaperJob *JobDefinition
+	if gitMirrorReaper != nil {
+		gitMirrorReaperJob = &JobDefinition{
+			Job:    gitMirrorReaper,
+			Period: GitMirrorReaperPeriod,
+		}
+	}
+
 	return &ExecutorService{
 		log:                   log,
 		runtimeStatsPublisher: runtimeStatsPublisherJob,
+		staleLockReaper:       staleLockReaperJob,
+		policySetRefresher:    policySetRefresherJob,
+		driftDetector:         driftDetectorJob,
+		gitMirrorReaper:       gitMirrorReaperJob,
 	}
 }
 
@@ -50,6 +94,12 @@ func (s *ExecutorService) Run() {
 	var wg sync.WaitGroup
 
 	s.runScheduledJob(ctx, &wg, s.runtimeStatsPublisher)
+	s.runScheduledJob(ctx, &wg, s.staleLockReaper)
+	s.runScheduledJob(ctx, &wg, s.policySetRefresher)
+	s.runScheduledJob(ctx, &wg, s.driftDetector)
+	if s.gitMirrorReaper != nil {
+		s.runScheduledJob(ctx, &wg, *s.gitMirrorReaper)
+	}
 
 	interrupt := make(chan os.Signal, 1)
 
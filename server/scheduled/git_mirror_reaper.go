@@ -0,0 +1,34 @@
+package scheduled
+
+import (
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// GitMirrorReaperPeriod is how often we check for unused git mirrors.
+const GitMirrorReaperPeriod = 1 * time.Hour
+
+// GitMirrorMaxAge is how long a git mirror is kept after it was last used
+// when there are no working dirs of its repo left.
+const GitMirrorMaxAge = 7 * 24 * time.Hour
+
+// GitMirrorReaper deletes the git mirrors of repos that are no longer seen.
+type GitMirrorReaper struct {
+	Mirrors *events.GitMirrorCache
+	Logger  logging.SimpleLogging
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Run deletes the git mirrors that have been unused for GitMirrorMaxAge.
+func (r *GitMirrorReaper) Run() {
+	now := time.Now()
+	if r.Now != nil {
+		now = r.Now()
+	}
+	if _, err := r.Mirrors.DeleteUnused(r.Logger, GitMirrorMaxAge, now); err != nil {
+		r.Logger.Err("deleting unused git mirrors: %s", err)
+	}
+}
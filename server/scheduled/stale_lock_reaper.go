@@ -0,0 +1,191 @@
+package scheduled
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/uber-go/tally"
+)
+
+// StaleLockReaper releases project locks that have been held for longer than
+// the lock_max_age configured for their repo. For every pull request that
+// held an expired lock, it deletes the pending plans of the projects whose
+// locks were released and comments on the pull request to explain why.
+type StaleLockReaper struct {
+	Locker            locking.Locker
+	DB                locking.Backend
+	GlobalCfg         valid.GlobalCfg
+	WorkingDir        events.WorkingDir
+	WorkingDirLocker  events.WorkingDirLocker
+	PendingPlanFinder events.PendingPlanFinder
+	VCSClient         vcs.Client
+	Logger            logging.SimpleLogging
+	// LockQueueProcessor, if set, hands the released locks to the pull
+	// requests queued for them.
+	LockQueueProcessor events.LockQueueProcessor
+	// Webhooks, if set, sends an unlock webhook for each released lock.
+	Webhooks events.WebhooksSender
+	// ReapedCounter counts the locks that have been released.
+	ReapedCounter tally.Counter
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// NewStaleLockReaper returns a StaleLockReaper that reports its stats under
+// scope.
+func NewStaleLockReaper(
+	scope tally.Scope,
+	locker locking.Locker,
+	db locking.Backend,
+	globalCfg valid.GlobalCfg,
+	workingDir events.WorkingDir,
+	workingDirLocker events.WorkingDirLocker,
+	pendingPlanFinder events.PendingPlanFinder,
+	lockQueueProcessor events.LockQueueProcessor,
+	webhooksSender events.WebhooksSender,
+	vcsClient vcs.Client,
+	logger logging.SimpleLogging,
+) *StaleLockReaper {
+	return &StaleLockReaper{
+		Locker:             locker,
+		DB:                 db,
+		GlobalCfg:          globalCfg,
+		WorkingDir:         workingDir,
+		WorkingDirLocker:   workingDirLocker,
+		PendingPlanFinder:  pendingPlanFinder,
+		LockQueueProcessor: lockQueueProcessor,
+		Webhooks:           webhooksSender,
+		VCSClient:          vcsClient,
+		Logger:             logger,
+		ReapedCounter:      scope.SubScope("stale_lock_reaper").Counter("locks_released"),
+	}
+}
+
+// expiredPull holds the expired locks of a single pull request.
+type expiredPull struct {
+	pull   models.PullRequest
+	maxAge time.Duration
+	keys   []string
+}
+
+// Run releases all expired locks.
+func (r *StaleLockReaper) Run() {
+	locks, err := r.Locker.List()
+	if err != nil {
+		r.Logger.Err("listing locks: %s", err)
+		return
+	}
+
+	now := time.Now()
+	if r.Now != nil {
+		now = r.Now()
+	}
+
+	// Group the expired locks by pull request so that we only comment on
+	// each pull request once.
+	pulls := make(map[string]*expiredPull)
+	for key, lock := range locks {
+		// NOTE: Because BaseRepo was added to the PullRequest model later,
+		// previous installations of Atlantis will have locks in their DB that
+		// do not have this field on PullRequest. We can't look up the repo
+		// config for those or comment on their pull requests so we skip them.
+		if lock.Pull.BaseRepo == (models.Repo{}) {
+			continue
+		}
+		maxAge := r.GlobalCfg.LockMaxAge(lock.Pull.BaseRepo.ID())
+		if maxAge == 0 || now.Sub(lock.Time) < maxAge {
+			continue
+		}
+
+		pullID := fmt.Sprintf("%s/%d", lock.Pull.BaseRepo.ID(), lock.Pull.Num)
+		if _, ok := pulls[pullID]; !ok {
+			pulls[pullID] = &expiredPull{pull: lock.Pull, maxAge: maxAge}
+		}
+		pulls[pullID].keys = append(pulls[pullID].keys, key)
+	}
+
+	for _, p := range pulls {
+		r.reapPull(p)
+	}
+}
+
+func (r *StaleLockReaper) reapPull(p *expiredPull) {
+	pull := p.pull
+	log := r.Logger.WithHistory("repo", pull.BaseRepo.FullName, "pull", fmt.Sprintf("%d", pull.Num))
+
+	// If a command is currently running for this pull request, then its locks
+	// are in use. We leave them alone and try again on the next run.
+	unlockFn, err := r.WorkingDirLocker.TryLockPull(pull.BaseRepo.ID(), pull.Num)
+	if err != nil {
+		log.Debug("not releasing expired locks because a command is running: %s", err)
+		return
+	}
+	defer unlockFn()
+
+	sort.Strings(p.keys)
+	var released []models.ProjectLock
+	for _, key := range p.keys {
+		lock, err := r.Locker.Unlock(key)
+		if err != nil {
+			log.Err("releasing expired lock %q: %s", key, err)
+			continue
+		}
+		// The lock may have been released since we listed it.
+		if lock == nil {
+			continue
+		}
+		log.Info("released lock %q that was held since %s", key, lock.Time.Format(time.RFC3339))
+		released = append(released, *lock)
+		if r.ReapedCounter != nil {
+			r.ReapedCounter.Inc(1)
+		}
+		if err := r.DB.UpdateProjectStatus(lock.Pull, lock.Workspace, lock.Project.Path, models.DiscardedPlanStatus); err != nil {
+			log.Err("updating project status: %s", err)
+		}
+	}
+	if len(released) == 0 {
+		return
+	}
+
+	pullDir, err := r.WorkingDir.GetPullDir(pull.BaseRepo, pull)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Err("getting pull dir: %s", err)
+		}
+	} else {
+		for _, lock := range released {
+			if err := r.PendingPlanFinder.DeleteProjectPlans(pullDir, lock.Workspace, lock.Project.Path); err != nil {
+				log.Err("deleting plans for dir %q workspace %q: %s", lock.Project.Path, lock.Workspace, err)
+			}
+		}
+	}
+
+	if err := r.VCSClient.CreateComment(pull.BaseRepo, pull.Num, r.comment(released, p.maxAge), ""); err != nil {
+		log.Err("commenting on pull request: %s", err)
+	}
+
+	events.SendUnlockWebhooks(r.Webhooks, log, released)
+
+	if r.LockQueueProcessor != nil {
+		r.LockQueueProcessor.ProcessReleasedLocks(released)
+	}
+}
+
+func (r *StaleLockReaper) comment(released []models.ProjectLock, maxAge time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The following locks were held for longer than the maximum lock age of `%s` and have been released:\n", maxAge)
+	for _, lock := range released {
+		fmt.Fprintf(&b, "\n- dir: `%s` workspace: `%s` (locked since %s)", lock.Project.Path, lock.Workspace, lock.Time.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("\n\nThe plans for these projects have been deleted. Comment `atlantis plan` to plan them again.")
+	return b.String()
+}
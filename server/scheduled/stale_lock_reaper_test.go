@@ -0,0 +1,139 @@
+package scheduled_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	eventmatchers "github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/scheduled"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var reaperRepo = models.Repo{
+	FullName: "runatlantis/atlantis",
+	Owner:    "runatlantis",
+	Name:     "atlantis",
+	VCSHost: models.VCSHost{
+		Hostname: "github.com",
+		Type:     models.Github,
+	},
+}
+
+var reaperPull = models.PullRequest{
+	Num:      1,
+	BaseRepo: reaperRepo,
+}
+
+var reaperNow = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestReaper(t *testing.T, maxAge time.Duration) (*scheduled.StaleLockReaper, *lockmocks.MockLocker, *mocks.MockWorkingDirLocker, *mocks.MockPendingPlanFinder, *vcsmocks.MockClient) {
+	RegisterMockTestingT(t)
+	locker := lockmocks.NewMockLocker()
+	workingDirLocker := mocks.NewMockWorkingDirLocker()
+	pendingPlanFinder := mocks.NewMockPendingPlanFinder()
+	vcsClient := vcsmocks.NewMockClient()
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetPullDir(reaperRepo, reaperPull)).ThenReturn("/pull/dir", nil)
+
+	r := &scheduled.StaleLockReaper{
+		Locker:            locker,
+		DB:                lockmocks.NewMockBackend(),
+		GlobalCfg:         valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{LockMaxAge: maxAge}),
+		WorkingDir:        workingDir,
+		WorkingDirLocker:  workingDirLocker,
+		PendingPlanFinder: pendingPlanFinder,
+		VCSClient:         vcsClient,
+		Logger:            logging.NewNoopLogger(t),
+		Now:               func() time.Time { return reaperNow },
+	}
+	return r, locker, workingDirLocker, pendingPlanFinder, vcsClient
+}
+
+func TestStaleLockReaper_ReleasesExpiredLocks(t *testing.T) {
+	r, locker, workingDirLocker, pendingPlanFinder, vcsClient := newTestReaper(t, time.Hour)
+	lock := models.ProjectLock{
+		Project:   models.NewProject(reaperRepo.FullName, "dir"),
+		Workspace: "default",
+		Pull:      reaperPull,
+		Time:      reaperNow.Add(-2 * time.Hour),
+	}
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{"key": lock}, nil)
+	When(locker.Unlock("key")).ThenReturn(&lock, nil)
+	When(workingDirLocker.TryLockPull(reaperRepo.ID(), reaperPull.Num)).ThenReturn(func() {}, nil)
+	sender := mocks.NewMockWebhooksSender()
+	r.Webhooks = sender
+
+	r.Run()
+
+	locker.VerifyWasCalledOnce().Unlock("key")
+	pendingPlanFinder.VerifyWasCalledOnce().DeleteProjectPlans("/pull/dir", "default", "dir")
+	pendingPlanFinder.VerifyWasCalled(Never()).DeletePlans(AnyString())
+	_, event := sender.VerifyWasCalledOnce().Send(eventmatchers.AnyLoggingSimpleLogging(), eventmatchers.AnyWebhooksEvent()).GetCapturedArguments()
+	Equals(t, webhooks.UnlockEvent, event.Type)
+	Equals(t, "dir", event.Directory)
+	_, _, comment, _ := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.EqModelsRepo(reaperRepo), EqInt(reaperPull.Num), AnyString(), EqString("")).GetCapturedArguments()
+	Assert(t, strings.Contains(comment, "maximum lock age of `1h0m0s`"), "comment should contain the max age, got %q", comment)
+	Assert(t, strings.Contains(comment, "dir: `dir` workspace: `default`"), "comment should contain the project, got %q", comment)
+}
+
+func TestStaleLockReaper_KeepsLocksYoungerThanMaxAge(t *testing.T) {
+	r, locker, workingDirLocker, _, vcsClient := newTestReaper(t, time.Hour)
+	lock := models.ProjectLock{
+		Project:   models.NewProject(reaperRepo.FullName, "dir"),
+		Workspace: "default",
+		Pull:      reaperPull,
+		Time:      reaperNow.Add(-30 * time.Minute),
+	}
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{"key": lock}, nil)
+
+	r.Run()
+
+	locker.VerifyWasCalled(Never()).Unlock(AnyString())
+	workingDirLocker.VerifyWasCalled(Never()).TryLockPull(AnyString(), AnyInt())
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestStaleLockReaper_NoMaxAge(t *testing.T) {
+	r, locker, _, _, vcsClient := newTestReaper(t, 0)
+	lock := models.ProjectLock{
+		Project:   models.NewProject(reaperRepo.FullName, "dir"),
+		Workspace: "default",
+		Pull:      reaperPull,
+		Time:      reaperNow.Add(-1000 * time.Hour),
+	}
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{"key": lock}, nil)
+
+	r.Run()
+
+	locker.VerifyWasCalled(Never()).Unlock(AnyString())
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestStaleLockReaper_SkipsPullWithRunningCommand(t *testing.T) {
+	r, locker, workingDirLocker, pendingPlanFinder, vcsClient := newTestReaper(t, time.Hour)
+	lock := models.ProjectLock{
+		Project:   models.NewProject(reaperRepo.FullName, "dir"),
+		Workspace: "default",
+		Pull:      reaperPull,
+		Time:      reaperNow.Add(-2 * time.Hour),
+	}
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{"key": lock}, nil)
+	When(workingDirLocker.TryLockPull(reaperRepo.ID(), reaperPull.Num)).ThenReturn(nil, errors.New("locked"))
+
+	r.Run()
+
+	locker.VerifyWasCalled(Never()).Unlock(AnyString())
+	pendingPlanFinder.VerifyWasCalled(Never()).DeleteProjectPlans(AnyString(), AnyString(), AnyString())
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
@@ -49,6 +49,7 @@ import (
 	"github.com/runatlantis/atlantis/server/controllers/templates"
 	"github.com/runatlantis/atlantis/server/controllers/websocket"
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/redis"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/core/runtime/policy"
 	"github.com/runatlantis/atlantis/server/core/terraform"
@@ -58,6 +59,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/static"
@@ -82,6 +84,20 @@ const (
 	// terraformPluginCacheDir is the name of the dir inside our data dir
 	// where we tell terraform to cache plugins and modules.
 	TerraformPluginCacheDirName = "plugin-cache"
+	// PolicySetsDirName is the name of the dir inside our data dir where we
+	// clone the repos of remote policy sets.
+	PolicySetsDirName = "policies"
+	// DriftDirName is the name of the dir inside our data dir where we clone
+	// the repos that are checked for drift.
+	DriftDirName = "drift"
+	// githubCheckRunOutputUpdateInterval is the minimum time between updates
+	// of in-progress GitHub check runs with the output of their command.
+	githubCheckRunOutputUpdateInterval = 10 * time.Second
+	// webhookWorkers is how many webhooks are sent at the same time.
+	webhookWorkers = 4
+	// webhookQueueSize is how many webhooks can wait to be sent before new
+	// ones are dropped.
+	webhookQueueSize = 1000
 )
 
 // Server runs the Atlantis web server.
@@ -99,6 +115,7 @@ type Server struct {
 	StatsCloser                    io.Closer
 	Locker                         locking.Locker
 	ApplyLocker                    locking.ApplyLocker
+	DriftStore                     scheduled.DriftStore
 	VCSEventsController            *events_controllers.VCSEventsController
 	GithubAppController            *controllers.GithubAppController
 	LocksController                *controllers.LocksController
@@ -107,6 +124,7 @@ type Server struct {
 	APIController                  *controllers.APIController
 	IndexTemplate                  templates.TemplateWriter
 	LockDetailTemplate             templates.TemplateWriter
+	DriftTemplate                  templates.TemplateWriter
 	ProjectJobsTemplate            templates.TemplateWriter
 	ProjectJobsErrorTemplate       templates.TemplateWriter
 	SSLCertFile                    string
@@ -117,6 +135,7 @@ type Server struct {
 	WebPassword                    string
 	ProjectCmdOutputHandler        jobs.ProjectCommandOutputHandler
 	ScheduledExecutorService       *scheduled.ExecutorService
+	Webhooks                       *webhooks.AsyncSender
 }
 
 // Config holds config for server that isn't passed in by the user.
@@ -133,15 +152,98 @@ type Config struct {
 type WebhookConfig struct {
 	// Event is the type of event we should send this webhook for, ex. apply.
 	Event string `mapstructure:"event"`
+	// Events are the types of events we should send this webhook for, ex.
+	// [plan, apply].
+	Events []string `mapstructure:"events"`
 	// WorkspaceRegex is a regex that is used to match against the workspace
 	// that is being modified for this event. If the regex matches, we'll
 	// send the webhook, ex. "production.*".
 	WorkspaceRegex string `mapstructure:"workspace-regex"`
-	// Kind is the type of webhook we should send, ex. slack.
+	// RepoRegex is a regex that is used to match against the full name of the
+	// repo of this event, ex. "runatlantis/.*".
+	RepoRegex string `mapstructure:"repo-regex"`
+	// Status is "success" or "failure" to only send this webhook for events
+	// that succeeded or failed.
+	Status string `mapstructure:"status"`
+	// Kind is the type of webhook we should send, ex. slack or msteams.
 	Kind string `mapstructure:"kind"`
 	// Channel is the channel to send this webhook to. It only applies to
-	// slack webhooks. Should be without '#'.
+	// slack and mattermost webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
+	// URL is the URL to POST this webhook to. It only applies to http,
+	// msteams and mattermost webhooks.
+	URL string `mapstructure:"url"`
+	// Secret is used to sign the body of http webhooks so the receiver can
+	// verify they were sent by Atlantis.
+	Secret string `mapstructure:"secret"`
+	// Headers are extra headers to send with http webhooks.
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// VCSHostConfig is nested within UserConfig. It's used to configure VCS hosts
+// on top of the host of each type configured with flags, ex. a GitHub
+// Enterprise host alongside github.com.
+type VCSHostConfig struct {
+	// Type is the type of the host: github, gitlab or gitea.
+	Type string `mapstructure:"type"`
+	// Hostname is the hostname of github and gitlab hosts, ex.
+	// github.example.com.
+	Hostname string `mapstructure:"hostname"`
+	// BaseURL is the URL of gitea hosts, ex. https://gitea.example.com.
+	BaseURL string `mapstructure:"base-url"`
+	// User is the username of the API user.
+	User string `mapstructure:"user"`
+	// Token is the access token of the API user.
+	Token string `mapstructure:"token"`
+	// WebhookSecret is used to validate webhooks from the host. If empty, no
+	// request validation is done.
+	WebhookSecret string `mapstructure:"webhook-secret"`
+}
+
+// VCSHost returns the type and hostname of the host, or an error if the
+// config is invalid.
+func (c VCSHostConfig) VCSHost() (models.VCSHost, error) {
+	switch c.Type {
+	case "github", "gitlab":
+		if c.Hostname == "" {
+			return models.VCSHost{}, fmt.Errorf("hostname must be set for %s hosts", c.Type)
+		}
+		hostType := models.Github
+		if c.Type == "gitlab" {
+			hostType = models.Gitlab
+		}
+		host := c.Hostname
+		// GitLab hostnames can include the scheme like --gitlab-hostname.
+		if hostType == models.Gitlab && strings.Contains(host, "://") {
+			parsed, err := url.Parse(host)
+			if err != nil {
+				return models.VCSHost{}, errors.Wrapf(err, "parsing hostname %q", c.Hostname)
+			}
+			host = parsed.Host
+		}
+		// Repos are matched to hosts by hostname without the port.
+		return models.VCSHost{Type: hostType, Hostname: (&url.URL{Host: host}).Hostname()}, nil
+	case "gitea":
+		parsed, err := url.Parse(c.BaseURL)
+		if err != nil {
+			return models.VCSHost{}, errors.Wrapf(err, "parsing base-url %q", c.BaseURL)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return models.VCSHost{}, fmt.Errorf("base-url must be set and have http:// or https:// for gitea hosts, got %q", c.BaseURL)
+		}
+		return models.VCSHost{Type: models.Gitea, Hostname: parsed.Hostname()}, nil
+	}
+	return models.VCSHost{}, fmt.Errorf("type %q is not supported, must be one of github, gitlab or gitea", c.Type)
+}
+
+// gitHostname returns the hostname to write git credentials for.
+func (c VCSHostConfig) gitHostname() string {
+	if c.Type == "gitea" {
+		if u, err := url.Parse(c.BaseURL); err == nil {
+			return u.Host
+		}
+	}
+	return c.Hostname
 }
 
 // NewServer returns a new server. If there are issues starting the server or
@@ -156,12 +258,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 
 	var supportedVCSHosts []models.VCSHostType
 	var githubClient vcs.IGithubClient
+	var rawGithubClient *vcs.GithubClient
 	var githubAppEnabled bool
 	var githubCredentials vcs.GithubCredentials
 	var gitlabClient *vcs.GitlabClient
 	var bitbucketCloudClient *bitbucketcloud.Client
 	var bitbucketServerClient *bitbucketserver.Client
 	var azuredevopsClient *vcs.AzureDevopsClient
+	var giteaClient *gitea.Client
 
 	policyChecksEnabled := false
 	if userConfig.EnablePolicyChecksFlag {
@@ -171,6 +275,21 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 
 	validator := &cfg.ParserValidator{}
 
+	var lockMaxAge time.Duration
+	if userConfig.LockMaxAge != "" {
+		lockMaxAge, err = time.ParseDuration(userConfig.LockMaxAge)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing lock max age")
+		}
+	}
+	driftDetectionInterval := scheduled.DefaultDriftDetectionInterval
+	if userConfig.DriftDetectionInterval != "" {
+		driftDetectionInterval, err = time.ParseDuration(userConfig.DriftDetectionInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing drift detection interval")
+		}
+	}
+
 	globalCfg := valid.NewGlobalCfgFromArgs(
 		valid.GlobalCfgArgs{
 			AllowRepoCfg:       userConfig.AllowRepoConfig,
@@ -178,6 +297,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			ApprovedReq:        userConfig.RequireApproval,
 			UnDivergedReq:      userConfig.RequireUnDiverged,
 			PolicyCheckEnabled: userConfig.EnablePolicyChecksFlag,
+			LockMaxAge:         lockMaxAge,
 		})
 	if userConfig.RepoConfig != "" {
 		globalCfg, err = validator.ParseGlobalCfg(userConfig.RepoConfig, globalCfg)
@@ -190,6 +310,13 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, errors.Wrapf(err, "parsing --%s", config.RepoConfigJSONFlag)
 		}
 	}
+	// The API secret is checked before the API tokens so a token with the same
+	// value would be given the secret's unrestricted access.
+	for _, t := range globalCfg.APITokens {
+		if userConfig.APISecret != "" && t.Token == userConfig.APISecret {
+			return nil, fmt.Errorf("api token %q has the same token as --api-secret", t.Name)
+		}
+	}
 
 	statsScope, statsReporter, closer, err := metrics.NewScope(globalCfg.Metrics, logger, userConfig.StatsNamespace)
 
@@ -227,7 +354,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 
 		var err error
-		rawGithubClient, err := vcs.NewGithubClient(userConfig.GithubHostname, githubCredentials, logger)
+		rawGithubClient, err = vcs.NewGithubClient(userConfig.GithubHostname, githubCredentials, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -241,6 +368,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		if err != nil {
 			return nil, err
 		}
+		gitlabClient.User = userConfig.GitlabUser
+		gitlabClient.PlanDiscussions = userConfig.GitlabPlanDiscussions
+		gitlabClient.RequireResolvedPlanDiscussions = userConfig.GitlabRequireResolved
 	}
 	if userConfig.BitbucketUser != "" {
 		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
@@ -273,6 +403,77 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, err
 		}
 	}
+	if userConfig.GiteaUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.Gitea)
+
+		var err error
+		giteaClient, err = gitea.NewClient(http.DefaultClient, userConfig.GiteaToken, userConfig.GiteaBaseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up Gitea client")
+		}
+	}
+
+	// Hosts in the vcs-hosts config get their own clients, credentials and
+	// webhook secrets, which are used instead of those of their type for repos
+	// on those hosts.
+	vcsHostClients := make(map[models.VCSHost]vcs.Client)
+	vcsHostCredentials := make(map[models.VCSHost]events.VCSHostCredentials)
+	vcsHostUsers := make(map[models.VCSHostType][]string)
+	vcsHostWebhookSecrets := make(map[models.VCSHost][]byte)
+	hostGithubPullGetters := make(map[string]events.GithubPullGetter)
+	hostGitlabMergeRequestGetters := make(map[string]events.GitlabMergeRequestGetter)
+	hostGiteaPullGetters := make(map[string]events.GiteaPullGetter)
+	for _, c := range userConfig.VCSHosts {
+		host, err := c.VCSHost()
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing vcs-hosts config")
+		}
+		switch host.Type {
+		case models.Github:
+			rawClient, err := vcs.NewGithubClient(c.Hostname, &vcs.GithubUserCredentials{User: c.User, Token: c.Token}, logger)
+			if err != nil {
+				return nil, errors.Wrapf(err, "setting up GitHub client for %s", host.Hostname)
+			}
+			client := vcs.NewInstrumentedGithubClient(rawClient, statsScope, logger)
+			vcsHostClients[host] = client
+			hostGithubPullGetters[host.Hostname] = client
+		case models.Gitlab:
+			client, err := vcs.NewGitlabClient(c.Hostname, c.Token, logger)
+			if err != nil {
+				return nil, errors.Wrapf(err, "setting up GitLab client for %s", host.Hostname)
+			}
+			client.User = c.User
+			client.PlanDiscussions = userConfig.GitlabPlanDiscussions
+			client.RequireResolvedPlanDiscussions = userConfig.GitlabRequireResolved
+			vcsHostClients[host] = client
+			hostGitlabMergeRequestGetters[host.Hostname] = client
+		case models.Gitea:
+			client, err := gitea.NewClient(http.DefaultClient, c.Token, c.BaseURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "setting up Gitea client for %s", host.Hostname)
+			}
+			vcsHostClients[host] = client
+			hostGiteaPullGetters[host.Hostname] = client
+		}
+		if !vcsHostTypeSupported(supportedVCSHosts, host.Type) {
+			supportedVCSHosts = append(supportedVCSHosts, host.Type)
+		}
+		vcsHostCredentials[host] = events.VCSHostCredentials{User: c.User, Token: c.Token}
+		vcsHostUsers[host.Type] = append(vcsHostUsers[host.Type], c.User)
+		vcsHostWebhookSecrets[host] = []byte(c.WebhookSecret)
+	}
+
+	// Policy owner teams are looked up on the VCS host so they'd never match
+	// if none of the configured hosts supports teams.
+	if globalCfg.PolicySets.HasTeamOwners() {
+		teamsSupported := false
+		for _, hostType := range supportedVCSHosts {
+			teamsSupported = teamsSupported || vcs.TeamsSupported(hostType)
+		}
+		if !teamsSupported {
+			return nil, errors.New("policy owners teams are only supported on GitHub and Gitea, use users instead")
+		}
+	}
 
 	if userConfig.WriteGitCreds {
 		home, err := homedir.Dir()
@@ -305,6 +506,25 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				return nil, err
 			}
 		}
+		if userConfig.GiteaUser != "" {
+			giteaURL, err := url.Parse(userConfig.GiteaBaseURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", userConfig.GiteaBaseURL)
+			}
+			if err := events.WriteGitCreds(userConfig.GiteaUser, userConfig.GiteaToken, giteaURL.Host, home, logger, false); err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range userConfig.VCSHosts {
+			if err := events.WriteGitCreds(c.User, c.Token, c.gitHostname(), home, logger, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient)
+	for host, client := range vcsHostClients {
+		vcsClient.AddHost(host, client)
 	}
 
 	var webhooksConfig []webhooks.Config
@@ -312,17 +532,36 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		config := webhooks.Config{
 			Channel:        c.Channel,
 			Event:          c.Event,
+			Events:         c.Events,
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
+			RepoRegex:      c.RepoRegex,
+			Status:         c.Status,
+			URL:            c.URL,
+			Secret:         c.Secret,
+			Headers:        c.Headers,
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
-	webhooksManager, err := webhooks.NewMultiWebhookSender(webhooksConfig, webhooks.NewSlackClient(userConfig.SlackToken))
+	multiWebhookSender, err := webhooks.NewMultiWebhookSender(webhooksConfig, webhooks.NewSlackClient(userConfig.SlackToken), vcsClient)
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing webhooks")
 	}
-	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient)
-	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName}
+	// Webhooks are sent in the background so they don't hold up commands.
+	webhooksManager := webhooks.NewAsyncSender(multiWebhookSender, webhookWorkers, webhookQueueSize)
+	var commitStatusUpdater events.CommitStatusUpdater = &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName}
+	var githubChecksUpdater *events.GithubChecksUpdater
+	if userConfig.EnableGithubChecks && rawGithubClient != nil {
+		githubChecksUpdater = &events.GithubChecksUpdater{
+			CommitStatusUpdater:  commitStatusUpdater,
+			Client:               rawGithubClient,
+			Hostname:             userConfig.GithubHostname,
+			StatusName:           userConfig.VCSStatusName,
+			ApplyDisabled:        userConfig.DisableApply,
+			OutputUpdateInterval: githubCheckRunOutputUpdateInterval,
+		}
+		commitStatusUpdater = githubChecksUpdater
+	}
 
 	binDir, err := mkSubDir(userConfig.DataDir, BinDirName)
 
@@ -336,6 +575,18 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, err
 	}
 
+	policySetsDir, err := mkSubDir(userConfig.DataDir, PolicySetsDirName)
+
+	if err != nil {
+		return nil, err
+	}
+	policySourceResolver := policy.NewGitSourceResolver(
+		policySetsDir,
+		userConfig.GithubHostname,
+		policyGitCredentials(userConfig, githubCredentials),
+		logger,
+	)
+
 	parsedURL, err := ParseAtlantisURL(userConfig.AtlantisURL)
 	if err != nil {
 		return nil, errors.Wrapf(err,
@@ -363,6 +614,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			logger,
 		)
 	}
+	if githubChecksUpdater != nil {
+		projectCmdOutputHandler = githubChecksUpdater.OutputHandler(projectCmdOutputHandler)
+	}
 
 	terraformClient, err := terraform.NewClient(
 		logger,
@@ -372,7 +626,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.TFEHostname,
 		userConfig.DefaultTFVersion,
 		config.DefaultTFVersionFlag,
+		userConfig.DefaultTFDistribution,
 		userConfig.TFDownloadURL,
+		userConfig.TofuDownloadURL,
 		&terraform.DefaultDownloader{},
 		true,
 		projectCmdOutputHandler)
@@ -391,24 +647,65 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		EnableDiffMarkdownFormat: userConfig.EnableDiffMarkdownFormat,
 	}
 
-	boltdb, err := db.New(userConfig.DataDir)
-	if err != nil {
-		return nil, err
+	var backend locking.Backend
+	var lockQueueStore locking.LockQueueStore
+	var driftStore scheduled.DriftStore
+	var apiJobStore controllers.APIJobStore
+	switch userConfig.LockingDBType {
+	case "redis":
+		logger.Info("Utilizing Redis DB")
+		var redisLockTTL time.Duration
+		if userConfig.RedisLockTTL != "" {
+			redisLockTTL, err = time.ParseDuration(userConfig.RedisLockTTL)
+			if err != nil {
+				return nil, err
+			}
+		}
+		redisDB, err := redis.New(userConfig.RedisHost, userConfig.RedisPort, userConfig.RedisPassword, userConfig.RedisTLSEnabled, userConfig.RedisInsecureSkipVerify, userConfig.RedisDB, redisLockTTL)
+		if err != nil {
+			return nil, err
+		}
+		backend, lockQueueStore, driftStore, apiJobStore = redisDB, redisDB, redisDB, redisDB
+	default:
+		logger.Info("Utilizing BoltDB")
+		boltDB, err := db.New(userConfig.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		backend, lockQueueStore, driftStore, apiJobStore = boltDB, boltDB, boltDB, boltDB
 	}
 	var lockingClient locking.Locker
+	var lockQueue locking.LockQueue
 	var applyLockingClient locking.ApplyLocker
 	if userConfig.DisableRepoLocking {
 		lockingClient = locking.NewNoOpLocker()
 	} else {
-		lockingClient = locking.NewClient(boltdb)
+		lockingClient = locking.NewClient(backend)
+		if userConfig.EnableLockQueue {
+			lockQueue = locking.NewQueueClient(lockQueueStore)
+		}
 	}
-	applyLockingClient = locking.NewApplyClient(boltdb, userConfig.DisableApply)
+	applyLockingClient = locking.NewApplyClient(backend, userConfig.DisableApply)
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 
+	// The clones of pull requests and of drift detection share the git
+	// mirrors.
+	var gitMirrors *events.GitMirrorCache
+	if userConfig.EnableGitMirrors {
+		gitMirrors = &events.GitMirrorCache{
+			Dir: filepath.Join(userConfig.DataDir, events.GitMirrorDirName),
+			WorkingDataDirs: []string{
+				userConfig.DataDir,
+				filepath.Join(userConfig.DataDir, DriftDirName),
+			},
+		}
+	}
 	var workingDir events.WorkingDir = &events.FileWorkspace{
 		DataDir:          userConfig.DataDir,
 		CheckoutMerge:    userConfig.CheckoutStrategy == "merge",
 		GithubAppEnabled: githubAppEnabled,
+		GitMirrors:       gitMirrors,
+		SparseCheckout:   userConfig.EnableSparseCheckout,
 	}
 	// provide fresh tokens before clone from the GitHub Apps integration, proxy workingDir
 	if githubAppEnabled {
@@ -422,16 +719,36 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 	}
 
+	// The lock queue processor's CommandRunner is set once the command runner
+	// has been created because the command runner depends on it.
+	drainer := &events.Drainer{}
+	var lockQueueProcessor events.LockQueueProcessor
+	var defaultLockQueueProcessor *events.DefaultLockQueueProcessor
+	if lockQueue != nil {
+		defaultLockQueueProcessor = &events.DefaultLockQueueProcessor{
+			Locker:  lockingClient,
+			Queue:   lockQueue,
+			Logger:  logger,
+			Drainer: drainer,
+		}
+		lockQueueProcessor = defaultLockQueueProcessor
+	}
+
 	projectLocker := &events.DefaultProjectLocker{
-		Locker:    lockingClient,
-		VCSClient: vcsClient,
+		Locker:             lockingClient,
+		VCSClient:          vcsClient,
+		Queue:              lockQueue,
+		LockQueueProcessor: lockQueueProcessor,
+		Webhooks:           webhooksManager,
 	}
 	deleteLockCommand := &events.DefaultDeleteLockCommand{
-		Locker:           lockingClient,
-		Logger:           logger,
-		WorkingDir:       workingDir,
-		WorkingDirLocker: workingDirLocker,
-		DB:               boltdb,
+		Locker:             lockingClient,
+		Logger:             logger,
+		WorkingDir:         workingDir,
+		WorkingDirLocker:   workingDirLocker,
+		DB:                 backend,
+		LockQueueProcessor: lockQueueProcessor,
+		Webhooks:           webhooksManager,
 	}
 
 	pullClosedExecutor := events.NewInstrumentedPullClosedExecutor(
@@ -441,10 +758,12 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			Locker:                   lockingClient,
 			WorkingDir:               workingDir,
 			Logger:                   logger,
-			DB:                       boltdb,
+			DB:                       backend,
 			PullClosedTemplate:       &events.PullClosedEventTemplate{},
 			LogStreamResourceCleaner: projectCmdOutputHandler,
 			VCSClient:                vcsClient,
+			LockQueueProcessor:       lockQueueProcessor,
+			Webhooks:                 webhooksManager,
 		},
 	)
 	eventParser := &events.EventParser{
@@ -458,12 +777,17 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		BitbucketServerURL: userConfig.BitbucketBaseURL,
 		AzureDevopsUser:    userConfig.AzureDevopsUser,
 		AzureDevopsToken:   userConfig.AzureDevopsToken,
+		GiteaUser:          userConfig.GiteaUser,
+		GiteaToken:         userConfig.GiteaToken,
+		VCSHostCredentials: vcsHostCredentials,
 	}
 	commentParser := &events.CommentParser{
 		GithubUser:      userConfig.GithubUser,
 		GitlabUser:      userConfig.GitlabUser,
 		BitbucketUser:   userConfig.BitbucketUser,
 		AzureDevopsUser: userConfig.AzureDevopsUser,
+		GiteaUser:       userConfig.GiteaUser,
+		VCSHostUsers:    vcsHostUsers,
 		ApplyDisabled:   userConfig.DisableApply,
 	}
 	defaultTfVersion := terraformClient.DefaultVersion()
@@ -474,7 +798,6 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		TerraformBinDir:         terraformClient.TerraformBinDir(),
 		ProjectCmdOutputHandler: projectCmdOutputHandler,
 	}
-	drainer := &events.Drainer{}
 	statusController := &controllers.StatusController{
 		Logger:          logger,
 		Drainer:         drainer,
@@ -497,7 +820,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	projectCommandBuilder := events.NewInstrumentedProjectCommandBuilder(
 		policyChecksEnabled,
 		validator,
-		&events.DefaultProjectFinder{},
+		&events.DefaultProjectFinder{
+			AutoplanModules: userConfig.AutoplanModules,
+			ModuleGraphs:    &events.ModuleGraphCache{},
+		},
 		vcsClient,
 		workingDir,
 		workingDirLocker,
@@ -517,9 +843,15 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, errors.Wrap(err, "initializing show step runner")
 	}
 
+	var policyExecutorWorkflow runtime.VersionedExecutorWorkflow
+	if userConfig.PolicyEngine == policy.OPAPolicyEngine {
+		policyExecutorWorkflow = policy.NewOPAExecutorWorkflow(policySourceResolver)
+	} else {
+		policyExecutorWorkflow = policy.NewConfTestExecutorWorkflow(logger, binDir, &terraform.DefaultDownloader{}, policySourceResolver)
+	}
 	policyCheckRunner, err := runtime.NewPolicyCheckStepRunner(
 		defaultTfVersion,
-		policy.NewConfTestExecutorWorkflow(logger, binDir, &terraform.DefaultDownloader{}),
+		policyExecutorWorkflow,
 	)
 
 	if err != nil {
@@ -566,16 +898,19 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Webhooks:                   webhooksManager,
 		WorkingDirLocker:           workingDirLocker,
 		AggregateApplyRequirements: applyRequirementHandler,
+		VcsClient:                  vcsClient,
+		DefaultTFVersions:          terraformClient.DefaultVersions(),
 	}
 
 	dbUpdater := &events.DBUpdater{
-		DB: boltdb,
+		DB: backend,
 	}
 
 	pullUpdater := &events.PullUpdater{
 		HidePrevPlanComments: userConfig.HidePrevPlanComments,
 		VCSClient:            vcsClient,
 		MarkdownRenderer:     markdownRenderer,
+		PlanDiscussionPoster: vcsClient,
 	}
 
 	autoMerger := &events.AutoMerger{
@@ -588,6 +923,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		ProjectCommandRunner: projectCommandRunner,
 		JobURLSetter:         jobs.NewJobURLSetter(router, commitStatusUpdater),
 	}
+	if githubChecksUpdater != nil {
+		projectOutputWrapper.ProjectResultUpdater = githubChecksUpdater
+	}
 	instrumentedProjectCmdRunner := &events.InstrumentedProjectCommandRunner{
 		ProjectCommandRunner: projectOutputWrapper,
 	}
@@ -616,7 +954,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		autoMerger,
 		userConfig.ParallelPoolSize,
 		userConfig.SilenceNoProjects,
-		boltdb,
+		backend,
+		webhooksManager,
 	)
 
 	pullReqStatusFetcher := vcs.NewPullReqStatusFetcher(vcsClient)
@@ -630,7 +969,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		autoMerger,
 		pullUpdater,
 		dbUpdater,
-		boltdb,
+		backend,
 		userConfig.ParallelPoolSize,
 		userConfig.SilenceNoProjects,
 		userConfig.SilenceVCSStatusNoProjects,
@@ -684,6 +1023,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GithubPullGetter:               githubClient,
 		GitlabMergeRequestGetter:       gitlabClient,
 		AzureDevopsPullGetter:          azuredevopsClient,
+		GiteaPullGetter:                giteaClient,
+		HostGithubPullGetters:          hostGithubPullGetters,
+		HostGitlabMergeRequestGetters:  hostGitlabMergeRequestGetters,
+		HostGiteaPullGetters:           hostGiteaPullGetters,
 		CommentCommandRunnerByCmd:      commentCommandRunnerByCmd,
 		EventParser:                    eventParser,
 		Logger:                         logger,
@@ -697,10 +1040,22 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Drainer:                        drainer,
 		PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
 		PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
-		PullStatusFetcher:              boltdb,
+		PullStatusFetcher:              backend,
 		TeamAllowlistChecker:           githubTeamAllowlistChecker,
 		VarFileAllowlistChecker:        varFileAllowlistChecker,
 	}
+	// The clients are only set if Bitbucket is configured, and a nil client
+	// mustn't be stored in an interface.
+	if bitbucketCloudClient != nil {
+		commandRunner.BitbucketCloudPullGetter = bitbucketCloudClient
+	}
+	if bitbucketServerClient != nil {
+		commandRunner.BitbucketServerPullGetter = bitbucketServerClient
+	}
+	if defaultLockQueueProcessor != nil {
+		defaultLockQueueProcessor.CommandRunner = commandRunner
+		defaultLockQueueProcessor.PullGetter = commandRunner
+	}
 	repoAllowlist, err := events.NewRepoAllowlistChecker(userConfig.RepoAllowlist)
 	if err != nil {
 		return nil, err
@@ -715,8 +1070,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		LockDetailTemplate: templates.LockTemplate,
 		WorkingDir:         workingDir,
 		WorkingDirLocker:   workingDirLocker,
-		DB:                 boltdb,
+		DB:                 backend,
 		DeleteLockCommand:  deleteLockCommand,
+		LockQueue:          lockQueue,
 	}
 
 	wsMux := websocket.NewMultiplexor(
@@ -731,14 +1087,24 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Logger:                   logger,
 		ProjectJobsTemplate:      templates.ProjectJobsTemplate,
 		ProjectJobsErrorTemplate: templates.ProjectJobsErrorTemplate,
-		Db:                       boltdb,
+		Db:                       backend,
 		WsMux:                    wsMux,
 		KeyGenerator:             controllers.JobIDKeyGenerator{},
 		StatsScope:               statsScope.SubScope("api"),
 	}
 	apiController := &controllers.APIController{
 		APISecret:                 []byte(userConfig.APISecret),
+		APITokens:                 globalCfg.APITokens,
 		Locker:                    lockingClient,
+		LockQueue:                 lockQueue,
+		ApplyLocker:               applyLockingClient,
+		CommandRunner:             commandRunner,
+		DeleteLockCommand:         deleteLockCommand,
+		DB:                        backend,
+		DriftStore:                driftStore,
+		Drainer:                   drainer,
+		Jobs:                      controllers.NewAPIJobs(apiJobStore),
+		JobURLGenerator:           router,
 		Logger:                    logger,
 		Parser:                    eventParser,
 		ProjectCommandBuilder:     projectCommandBuilder,
@@ -747,6 +1113,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		RepoAllowlistChecker:      repoAllowlist,
 		Scope:                     statsScope.SubScope("api"),
 		VCSClient:                 vcsClient,
+		WorkingDirLocker:          workingDirLocker,
 	}
 
 	eventsController := &events_controllers.VCSEventsController{
@@ -769,6 +1136,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AzureDevopsWebhookBasicUser:     []byte(userConfig.AzureDevopsWebhookUser),
 		AzureDevopsWebhookBasicPassword: []byte(userConfig.AzureDevopsWebhookPassword),
 		AzureDevopsRequestValidator:     &events_controllers.DefaultAzureDevopsRequestValidator{},
+		GiteaWebhookSecret:              []byte(userConfig.GiteaWebhookSecret),
+		VCSHostWebhookSecrets:           vcsHostWebhookSecrets,
 	}
 	githubAppController := &controllers.GithubAppController{
 		AtlantisURL:         parsedURL,
@@ -777,9 +1146,69 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GithubHostname:      userConfig.GithubHostname,
 		GithubOrg:           userConfig.GithubOrg,
 	}
+	staleLockReaper := scheduled.NewStaleLockReaper(
+		statsScope.SubScope("scheduled"),
+		lockingClient,
+		backend,
+		globalCfg,
+		workingDir,
+		workingDirLocker,
+		pendingPlanFinder,
+		lockQueueProcessor,
+		webhooksManager,
+		vcsClient,
+		logger,
+	)
+	// Repos are cloned into their own dir for drift detection so that
+	// deleting the clones doesn't affect pull requests.
+	var driftWorkingDir events.WorkingDir = &events.FileWorkspace{
+		DataDir:          filepath.Join(userConfig.DataDir, DriftDirName),
+		GithubAppEnabled: githubAppEnabled,
+		GitMirrors:       gitMirrors,
+	}
+	if githubAppEnabled {
+		driftWorkingDir = &events.GithubAppWorkingDir{
+			WorkingDir:     driftWorkingDir,
+			Credentials:    githubCredentials,
+			GithubHostname: userConfig.GithubHostname,
+		}
+	}
+	driftDetector := &scheduled.DriftDetector{
+		GlobalCfg:       globalCfg,
+		DB:              driftStore,
+		VCSClient:       vcsClient,
+		VCSHostTypes:    supportedVCSHosts,
+		Parser:          eventParser,
+		WorkingDir:      driftWorkingDir,
+		ParserValidator: validator,
+		ProjectCommandContextBuilder: events.NewProjectCommandContextBuilder(
+			false,
+			commentParser,
+			statsScope.SubScope("drift_detection"),
+		),
+		ProjectDriftCommandRunner: projectCommandRunner,
+		LogStreamResourceCleaner:  projectCmdOutputHandler,
+		IssueCreator:              vcsClient,
+		Webhooks:                  webhooksManager,
+		Logger:                    logger,
+		Scope:                     statsScope.SubScope("drift_detection"),
+		Interval:                  driftDetectionInterval,
+	}
+	var gitMirrorReaper *scheduled.GitMirrorReaper
+	if gitMirrors != nil {
+		gitMirrorReaper = &scheduled.GitMirrorReaper{
+			Mirrors: gitMirrors,
+			Logger:  logger,
+		}
+	}
 	scheduledExecutorService := scheduled.NewExecutorService(
 		statsScope,
 		logger,
+		staleLockReaper,
+		policySourceResolver,
+		driftDetector,
+		driftDetectionInterval,
+		gitMirrorReaper,
 	)
 
 	return &Server{
@@ -796,6 +1225,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		StatsCloser:                    closer,
 		Locker:                         lockingClient,
 		ApplyLocker:                    applyLockingClient,
+		DriftStore:                     driftStore,
 		VCSEventsController:            eventsController,
 		GithubAppController:            githubAppController,
 		LocksController:                locksController,
@@ -804,6 +1234,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		APIController:                  apiController,
 		IndexTemplate:                  templates.IndexTemplate,
 		LockDetailTemplate:             templates.LockTemplate,
+		DriftTemplate:                  templates.DriftTemplate,
 		ProjectJobsTemplate:            templates.ProjectJobsTemplate,
 		ProjectJobsErrorTemplate:       templates.ProjectJobsErrorTemplate,
 		SSLKeyFile:                     userConfig.SSLKeyFile,
@@ -814,6 +1245,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WebUsername:                    userConfig.WebUsername,
 		WebPassword:                    userConfig.WebPassword,
 		ScheduledExecutorService:       scheduledExecutorService,
+		Webhooks:                       webhooksManager,
 	}, nil
 }
 
@@ -824,10 +1256,21 @@ func (s *Server) Start() error {
 	})
 	s.Router.HandleFunc("/healthz", s.Healthz).Methods("GET")
 	s.Router.HandleFunc("/status", s.StatusController.Get).Methods("GET")
+	s.Router.HandleFunc("/drift", s.Drift).Methods("GET")
 	s.Router.PathPrefix("/static/").Handler(http.FileServer(&assetfs.AssetFS{Asset: static.Asset, AssetDir: static.AssetDir, AssetInfo: static.AssetInfo}))
 	s.Router.HandleFunc("/events", s.VCSEventsController.Post).Methods("POST")
 	s.Router.HandleFunc("/api/plan", s.APIController.Plan).Methods("POST")
 	s.Router.HandleFunc("/api/apply", s.APIController.Apply).Methods("POST")
+	s.Router.HandleFunc("/api/jobs/{id}", s.APIController.GetJob).Methods("GET")
+	s.Router.HandleFunc("/api/locks/queue", s.APIController.GetLockQueue).Methods("GET").Queries("id", "{id:.*}")
+	s.Router.HandleFunc("/api/locks", s.APIController.GetLock).Methods("GET").Queries("id", "{id:.*}")
+	s.Router.HandleFunc("/api/locks", s.APIController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
+	s.Router.HandleFunc("/api/locks", s.APIController.ListLocks).Methods("GET")
+	s.Router.HandleFunc("/api/pulls", s.APIController.ListPulls).Methods("GET")
+	s.Router.HandleFunc("/api/drift", s.APIController.ListDrift).Methods("GET")
+	s.Router.HandleFunc("/api/apply/lock", s.APIController.GetApplyLock).Methods("GET")
+	s.Router.HandleFunc("/api/apply/lock", s.APIController.LockApply).Methods("POST")
+	s.Router.HandleFunc("/api/apply/lock", s.APIController.UnlockApply).Methods("DELETE")
 	s.Router.HandleFunc("/github-app/exchange-code", s.GithubAppController.ExchangeCode).Methods("GET")
 	s.Router.HandleFunc("/github-app/setup", s.GithubAppController.New).Methods("GET")
 	s.Router.HandleFunc("/apply/lock", s.LocksController.LockApply).Methods("POST").Queries()
@@ -884,6 +1327,9 @@ func (s *Server) Start() error {
 	s.Logger.Warn("Received interrupt. Waiting for in-progress operations to complete")
 	s.waitForDrain()
 
+	// send the webhooks of the completed operations before shutdown
+	s.Webhooks.Close()
+
 	// flush stats before shutdown
 	if err := s.StatsCloser.Close(); err != nil {
 		s.Logger.Err(err.Error())
@@ -967,6 +1413,49 @@ func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// Drift is the GET /drift route. It renders the result of the last drift
+// detection run for each project.
+func (s *Server) Drift(w http.ResponseWriter, _ *http.Request) {
+	drifts, err := s.DriftStore.ListProjectDrifts()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "Could not retrieve drift: %s", err)
+		return
+	}
+
+	var projects []templates.DriftProjectData
+	for _, d := range drifts {
+		projects = append(projects, templates.DriftProjectData{
+			RepoFullName:       d.RepoFullName,
+			ProjectName:        d.ProjectName,
+			RepoRelDir:         d.RepoRelDir,
+			Workspace:          d.Workspace,
+			Branch:             d.Branch,
+			Commit:             d.Commit,
+			Status:             d.Status.String(),
+			PlanSummary:        d.PlanSummary,
+			Error:              d.Error,
+			IssueURL:           d.IssueURL,
+			CheckedAtFormatted: d.CheckedAt.Format("02-01-2006 15:04:05"),
+		})
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		if projects[i].RepoFullName != projects[j].RepoFullName {
+			return projects[i].RepoFullName < projects[j].RepoFullName
+		}
+		return projects[i].RepoRelDir < projects[j].RepoRelDir
+	})
+
+	err = s.DriftTemplate.Execute(w, templates.DriftData{
+		Projects:        projects,
+		AtlantisVersion: s.AtlantisVersion,
+		CleanedBasePath: s.AtlantisURL.Path,
+	})
+	if err != nil {
+		s.Logger.Err(err.Error())
+	}
+}
+
 func mkSubDir(parentDir string, subDir string) (string, error) {
 	fullDir := filepath.Join(parentDir, subDir)
 	if err := os.MkdirAll(fullDir, 0700); err != nil {
@@ -976,6 +1465,86 @@ func mkSubDir(parentDir string, subDir string) (string, error) {
 	return fullDir, nil
 }
 
+// policyGitCredentials returns the credentials of the VCS hosts Atlantis is
+// configured for so that remote policy sets can be cloned from them.
+func policyGitCredentials(userConfig UserConfig, githubCredentials vcs.GithubCredentials) []policy.GitCredential {
+	staticToken := func(token string) func() (string, error) {
+		return func() (string, error) { return token, nil }
+	}
+	var creds []policy.GitCredential
+	if githubCredentials != nil {
+		// GitHub accepts any username with a token. App installation tokens
+		// must use x-access-token.
+		user := userConfig.GithubUser
+		if user == "" {
+			user = "x-access-token"
+		}
+		creds = append(creds, policy.GitCredential{
+			Hostname: userConfig.GithubHostname,
+			User:     user,
+			Token:    githubCredentials.GetToken,
+		})
+	}
+	if userConfig.GitlabUser != "" {
+		creds = append(creds, policy.GitCredential{
+			Hostname: userConfig.GitlabHostname,
+			User:     userConfig.GitlabUser,
+			Token:    staticToken(userConfig.GitlabToken),
+		})
+	}
+	if userConfig.BitbucketUser != "" {
+		// The default BitbucketBaseURL is https://api.bitbucket.org which
+		// can't be used for git.
+		hostname := "bitbucket.org"
+		if userConfig.BitbucketBaseURL != bitbucketcloud.BaseURL {
+			if u, err := url.Parse(userConfig.BitbucketBaseURL); err == nil {
+				hostname = u.Hostname()
+			}
+		}
+		creds = append(creds, policy.GitCredential{
+			Hostname: hostname,
+			User:     userConfig.BitbucketUser,
+			Token:    staticToken(userConfig.BitbucketToken),
+		})
+	}
+	if userConfig.AzureDevopsUser != "" {
+		creds = append(creds, policy.GitCredential{
+			Hostname: userConfig.AzureDevOpsHostname,
+			User:     userConfig.AzureDevopsUser,
+			Token:    staticToken(userConfig.AzureDevopsToken),
+		})
+	}
+	if userConfig.GiteaUser != "" {
+		hostname := ""
+		if u, err := url.Parse(userConfig.GiteaBaseURL); err == nil {
+			hostname = u.Host
+		}
+		creds = append(creds, policy.GitCredential{
+			Hostname: hostname,
+			User:     userConfig.GiteaUser,
+			Token:    staticToken(userConfig.GiteaToken),
+		})
+	}
+	for _, c := range userConfig.VCSHosts {
+		creds = append(creds, policy.GitCredential{
+			Hostname: c.gitHostname(),
+			User:     c.User,
+			Token:    staticToken(c.Token),
+		})
+	}
+	return creds
+}
+
+// vcsHostTypeSupported returns true if hostType is in supported.
+func vcsHostTypeSupported(supported []models.VCSHostType, hostType models.VCSHostType) bool {
+	for _, t := range supported {
+		if t == hostType {
+			return true
+		}
+	}
+	return false
+}
+
 // Healthz returns the health check response. It always returns a 200 currently.
 func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
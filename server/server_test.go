@@ -32,6 +32,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
+	scheduledmocks "github.com/runatlantis/atlantis/server/scheduled/mocks"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -133,6 +134,51 @@ func TestIndex_Success(t *testing.T) {
 	ResponseContains(t, w, http.StatusOK, "")
 }
 
+func TestDrift_Success(t *testing.T) {
+	t.Log("Drift should render the drift template sorted by repo and dir.")
+	RegisterMockTestingT(t)
+	driftStore := scheduledmocks.NewMockDriftStore()
+	checkedAt := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	When(driftStore.ListProjectDrifts()).ThenReturn([]models.ProjectDrift{
+		{RepoFullName: "owner/repo", RepoRelDir: "b", Workspace: "default", Status: models.NoDriftStatus, CheckedAt: checkedAt},
+		{RepoFullName: "owner/repo", RepoRelDir: "a", Workspace: "default", Status: models.DriftedStatus, PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.", CheckedAt: checkedAt},
+	}, nil)
+	dt := tMocks.NewMockTemplateWriter()
+	u, err := url.Parse("https://example.com")
+	Ok(t, err)
+	s := server.Server{
+		DriftStore:      driftStore,
+		DriftTemplate:   dt,
+		AtlantisVersion: "0.3.1",
+		AtlantisURL:     u,
+		Logger:          logging.NewNoopLogger(t),
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Drift(w, req)
+	dt.VerifyWasCalledOnce().Execute(w, templates.DriftData{
+		Projects: []templates.DriftProjectData{
+			{
+				RepoFullName:       "owner/repo",
+				RepoRelDir:         "a",
+				Workspace:          "default",
+				Status:             "drifted",
+				PlanSummary:        "Plan: 1 to add, 0 to change, 0 to destroy.",
+				CheckedAtFormatted: "01-01-2022 12:00:00",
+			},
+			{
+				RepoFullName:       "owner/repo",
+				RepoRelDir:         "b",
+				Workspace:          "default",
+				Status:             "no_drift",
+				CheckedAtFormatted: "01-01-2022 12:00:00",
+			},
+		},
+		AtlantisVersion: "0.3.1",
+	})
+	ResponseContains(t, w, http.StatusOK, "")
+}
+
 func TestHealthz(t *testing.T) {
 	s := server.Server{}
 	req, _ := http.NewRequest("GET", "/healthz", bytes.NewBuffer(nil))
@@ -245,3 +291,66 @@ func TestParseAtlantisURL(t *testing.T) {
 		})
 	}
 }
+
+func TestVCSHostConfig_VCSHost(t *testing.T) {
+	cases := []struct {
+		description string
+		config      server.VCSHostConfig
+		expHost     models.VCSHost
+		expErr      string
+	}{
+		{
+			"github",
+			server.VCSHostConfig{Type: "github", Hostname: "github.example.com"},
+			models.VCSHost{Type: models.Github, Hostname: "github.example.com"},
+			"",
+		},
+		{
+			"github with port",
+			server.VCSHostConfig{Type: "github", Hostname: "github.example.com:8443"},
+			models.VCSHost{Type: models.Github, Hostname: "github.example.com"},
+			"",
+		},
+		{
+			"gitlab with scheme",
+			server.VCSHostConfig{Type: "gitlab", Hostname: "https://gitlab.example.com"},
+			models.VCSHost{Type: models.Gitlab, Hostname: "gitlab.example.com"},
+			"",
+		},
+		{
+			"gitea",
+			server.VCSHostConfig{Type: "gitea", BaseURL: "http://gitea.example.com:3000/"},
+			models.VCSHost{Type: models.Gitea, Hostname: "gitea.example.com"},
+			"",
+		},
+		{
+			"missing hostname",
+			server.VCSHostConfig{Type: "gitlab"},
+			models.VCSHost{},
+			"hostname must be set for gitlab hosts",
+		},
+		{
+			"gitea without scheme",
+			server.VCSHostConfig{Type: "gitea", BaseURL: "gitea.example.com"},
+			models.VCSHost{},
+			"base-url must be set and have http:// or https:// for gitea hosts, got \"gitea.example.com\"",
+		},
+		{
+			"unsupported type",
+			server.VCSHostConfig{Type: "bitbucket", Hostname: "bitbucket.org"},
+			models.VCSHost{},
+			"type \"bitbucket\" is not supported, must be one of github, gitlab or gitea",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			host, err := c.config.VCSHost()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expHost, host)
+		})
+	}
+}
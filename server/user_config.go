@@ -13,6 +13,7 @@ type UserConfig struct {
 	AtlantisURL                string `mapstructure:"atlantis-url"`
 	Automerge                  bool   `mapstructure:"automerge"`
 	AutoplanFileList           string `mapstructure:"autoplan-file-list"`
+	AutoplanModules            bool   `mapstructure:"autoplan-modules"`
 	AzureDevopsToken           string `mapstructure:"azuredevops-token"`
 	AzureDevopsUser            string `mapstructure:"azuredevops-user"`
 	AzureDevopsWebhookPassword string `mapstructure:"azuredevops-webhook-password"`
@@ -29,9 +30,18 @@ type UserConfig struct {
 	DisableAutoplan            bool   `mapstructure:"disable-autoplan"`
 	DisableMarkdownFolding     bool   `mapstructure:"disable-markdown-folding"`
 	DisableRepoLocking         bool   `mapstructure:"disable-repo-locking"`
+	DriftDetectionInterval     string `mapstructure:"drift-detection-interval"`
+	EnableGitMirrors           bool   `mapstructure:"enable-git-mirrors"`
+	EnableGithubChecks         bool   `mapstructure:"enable-github-checks"`
+	EnableLockQueue            bool   `mapstructure:"enable-lock-queue"`
 	EnablePolicyChecksFlag     bool   `mapstructure:"enable-policy-checks"`
 	EnableRegExpCmd            bool   `mapstructure:"enable-regexp-cmd"`
+	EnableSparseCheckout       bool   `mapstructure:"enable-sparse-checkout"`
 	EnableDiffMarkdownFormat   bool   `mapstructure:"enable-diff-markdown-format"`
+	GiteaBaseURL               string `mapstructure:"gitea-base-url"`
+	GiteaToken                 string `mapstructure:"gitea-token"`
+	GiteaUser                  string `mapstructure:"gitea-user"`
+	GiteaWebhookSecret         string `mapstructure:"gitea-webhook-secret"`
 	GithubHostname             string `mapstructure:"gh-hostname"`
 	GithubToken                string `mapstructure:"gh-token"`
 	GithubUser                 string `mapstructure:"gh-user"`
@@ -43,16 +53,28 @@ type UserConfig struct {
 	GithubAppSlug              string `mapstructure:"gh-app-slug"`
 	GithubTeamAllowlist        string `mapstructure:"gh-team-allowlist"`
 	GitlabHostname             string `mapstructure:"gitlab-hostname"`
+	GitlabPlanDiscussions      bool   `mapstructure:"gitlab-plan-discussions"`
+	GitlabRequireResolved      bool   `mapstructure:"gitlab-require-resolved-plan-discussions"`
 	GitlabToken                string `mapstructure:"gitlab-token"`
 	GitlabUser                 string `mapstructure:"gitlab-user"`
 	GitlabWebhookSecret        string `mapstructure:"gitlab-webhook-secret"`
 	APISecret                  string `mapstructure:"api-secret"`
 	HidePrevPlanComments       bool   `mapstructure:"hide-prev-plan-comments"`
+	LockingDBType              string `mapstructure:"locking-db-type"`
+	LockMaxAge                 string `mapstructure:"lock-max-age"`
 	LogLevel                   string `mapstructure:"log-level"`
 	ParallelPoolSize           int    `mapstructure:"parallel-pool-size"`
 	StatsNamespace             string `mapstructure:"stats-namespace"`
 	PlanDrafts                 bool   `mapstructure:"allow-draft-prs"`
+	PolicyEngine               string `mapstructure:"policy-engine"`
 	Port                       int    `mapstructure:"port"`
+	RedisHost                  string `mapstructure:"redis-host"`
+	RedisPassword              string `mapstructure:"redis-password"`
+	RedisPort                  int    `mapstructure:"redis-port"`
+	RedisDB                    int    `mapstructure:"redis-db"`
+	RedisTLSEnabled            bool   `mapstructure:"redis-tls-enabled"`
+	RedisInsecureSkipVerify    bool   `mapstructure:"redis-insecure-skip-verify"`
+	RedisLockTTL               string `mapstructure:"redis-lock-ttl"`
 	RepoConfig                 string `mapstructure:"repo-config"`
 	RepoConfigJSON             string `mapstructure:"repo-config-json"`
 	RepoAllowlist              string `mapstructure:"repo-allowlist"`
@@ -85,11 +107,14 @@ type UserConfig struct {
 	SSLCertFile            string          `mapstructure:"ssl-cert-file"`
 	SSLKeyFile             string          `mapstructure:"ssl-key-file"`
 	TFDownloadURL          string          `mapstructure:"tf-download-url"`
+	TofuDownloadURL        string          `mapstructure:"tofu-download-url"`
 	TFEHostname            string          `mapstructure:"tfe-hostname"`
 	TFELocalExecutionMode  bool            `mapstructure:"tfe-local-execution-mode"`
 	TFEToken               string          `mapstructure:"tfe-token"`
 	VarFileAllowlist       string          `mapstructure:"var-file-allowlist"`
+	VCSHosts               []VCSHostConfig `mapstructure:"vcs-hosts"`
 	VCSStatusName          string          `mapstructure:"vcs-status-name"`
+	DefaultTFDistribution  string          `mapstructure:"default-tf-distribution"`
 	DefaultTFVersion       string          `mapstructure:"default-tf-version"`
 	Webhooks               []WebhookConfig `mapstructure:"webhooks"`
 	WebBasicAuth           bool            `mapstructure:"web-basic-auth"`